@@ -14,6 +14,7 @@ import (
 
 	"github.com/ycdesu/spreaddog/pkg/cmd/cmdutil"
 	"github.com/ycdesu/spreaddog/pkg/exchange/binance"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -98,8 +99,8 @@ var rootCmd = &cobra.Command{
 			Market:           market,
 			Side:             types.SideTypeBuy,
 			Type:             types.OrderTypeLimit,
-			Price:            price,
-			Quantity:         quantity,
+			Price:            fixedpoint.NewFromFloat(price),
+			Quantity:         fixedpoint.NewFromFloat(quantity),
 			MarginSideEffect: types.SideEffectTypeMarginBuy,
 			TimeInForce:      "GTC",
 		})
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/ycdesu/spreaddog/pkg/cmd/cmdutil"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -100,8 +101,8 @@ var rootCmd = &cobra.Command{
 				Market:      market,
 				Side:        types.SideTypeBuy,
 				Type:        types.OrderTypeLimit,
-				Price:       price,
-				Quantity:    quantity,
+				Price:       fixedpoint.NewFromFloat(price),
+				Quantity:    fixedpoint.NewFromFloat(quantity),
 				TimeInForce: "GTC",
 			},
 			{
@@ -109,8 +110,8 @@ var rootCmd = &cobra.Command{
 				Market:      market,
 				Side:        types.SideTypeSell,
 				Type:        types.OrderTypeLimit,
-				Price:       price,
-				Quantity:    quantity,
+				Price:       fixedpoint.NewFromFloat(price),
+				Quantity:    fixedpoint.NewFromFloat(quantity),
 				TimeInForce: "GTC",
 			},
 		}...)
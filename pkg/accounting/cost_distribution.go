@@ -8,47 +8,40 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
-func zero(a float64) bool {
-	return int(math.Round(a*1e8)) == 0
-}
-
-func round(a float64) float64 {
-	return math.Round(a*1e8) / 1e8
-}
-
 type Stock types.Trade
 
 func (stock *Stock) String() string {
-	return fmt.Sprintf("%f (%f)", stock.Price, stock.Quantity)
+	return fmt.Sprintf("%s (%s)", stock.Price, stock.Quantity)
 }
 
-func (stock *Stock) Consume(quantity float64) float64 {
-	q := math.Min(stock.Quantity, quantity)
-	stock.Quantity = round(stock.Quantity - q)
+func (stock *Stock) Consume(quantity fixedpoint.Value) fixedpoint.Value {
+	q := fixedpoint.Min(stock.Quantity, quantity)
+	stock.Quantity = stock.Quantity - q
 	return q
 }
 
 type StockSlice []Stock
 
-func (slice StockSlice) QuantityBelowPrice(price float64) (quantity float64) {
+func (slice StockSlice) QuantityBelowPrice(price fixedpoint.Value) (quantity fixedpoint.Value) {
 	for _, stock := range slice {
 		if stock.Price < price {
 			quantity += stock.Quantity
 		}
 	}
 
-	return round(quantity)
+	return quantity
 }
 
-func (slice StockSlice) Quantity() (total float64) {
+func (slice StockSlice) Quantity() (total fixedpoint.Value) {
 	for _, stock := range slice {
 		total += stock.Quantity
 	}
 
-	return round(total)
+	return total
 }
 
 type StockDistribution struct {
@@ -61,23 +54,24 @@ type StockDistribution struct {
 }
 
 type DistributionStats struct {
-	PriceLevels   []string              `json:"priceLevels"`
-	TotalQuantity float64               `json:"totalQuantity"`
-	Quantities    map[string]float64    `json:"quantities"`
-	Stocks        map[string]StockSlice `json:"stocks"`
+	PriceLevels   []string                    `json:"priceLevels"`
+	TotalQuantity fixedpoint.Value            `json:"totalQuantity"`
+	Quantities    map[string]fixedpoint.Value `json:"quantities"`
+	Stocks        map[string]StockSlice       `json:"stocks"`
 }
 
 func (m *StockDistribution) DistributionStats(level int) *DistributionStats {
 	var d = DistributionStats{
-		Quantities: map[string]float64{},
+		Quantities: map[string]fixedpoint.Value{},
 		Stocks:     map[string]StockSlice{},
 	}
 
 	for _, stock := range m.Stocks {
-		n := math.Ceil(math.Log10(stock.Price))
+		price := stock.Price.Float64()
+		n := math.Ceil(math.Log10(price))
 		digits := int(n - math.Max(float64(level), 1.0))
 		div := math.Pow10(digits)
-		priceLevel := math.Floor(stock.Price/div) * div
+		priceLevel := math.Floor(price/div) * div
 		key := strconv.FormatFloat(priceLevel, 'f', 2, 64)
 
 		d.TotalQuantity += stock.Quantity
@@ -114,7 +108,7 @@ func (m *StockDistribution) squash() {
 
 	var squashed StockSlice
 	for _, stock := range m.Stocks {
-		if !zero(stock.Quantity) {
+		if stock.Quantity != 0 {
 			squashed = append(squashed, stock)
 		}
 	}
@@ -156,7 +150,7 @@ func (m *StockDistribution) consume(sell Stock) error {
 			continue
 		}
 
-		if zero(stock.Quantity) {
+		if stock.Quantity == 0 {
 			continue
 		}
 
@@ -164,7 +158,7 @@ func (m *StockDistribution) consume(sell Stock) error {
 		sell.Consume(delta)
 		m.Stocks[idx] = stock
 
-		if zero(sell.Quantity) {
+		if sell.Quantity == 0 {
 			return nil
 		}
 	}
@@ -173,7 +167,7 @@ func (m *StockDistribution) consume(sell Stock) error {
 	for ; idx >= 0; idx-- {
 		stock := m.Stocks[idx]
 
-		if zero(stock.Quantity) {
+		if stock.Quantity == 0 {
 			continue
 		}
 
@@ -181,12 +175,12 @@ func (m *StockDistribution) consume(sell Stock) error {
 		sell.Consume(delta)
 		m.Stocks[idx] = stock
 
-		if zero(sell.Quantity) {
+		if sell.Quantity == 0 {
 			return nil
 		}
 	}
 
-	if sell.Quantity > 0.0 {
+	if sell.Quantity > 0 {
 		m.PendingSells = append(m.PendingSells, sell)
 	}
 
@@ -203,7 +197,7 @@ func (m *StockDistribution) AddTrades(trades []types.Trade) (checkpoints []int,
 				trade.Symbol = m.Symbol
 				trade.IsBuyer = false
 				trade.Quantity = trade.Fee
-				trade.Fee = 0.0
+				trade.Fee = 0
 			}
 		}
 
@@ -242,7 +236,7 @@ func toStock(trade types.Trade) Stock {
 		} else {
 			trade.Quantity += trade.Fee
 		}
-		trade.Fee = 0.0
+		trade.Fee = 0
 	}
 	return Stock(trade)
 }
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -11,16 +12,16 @@ type AverageCostCalculator struct {
 	TradingFeeCurrency string
 }
 
-func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, currentPrice float64) *AverageCostPnlReport {
+func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, currentPrice fixedpoint.Value) *AverageCostPnlReport {
 	// copy trades, so that we can truncate it.
-	var bidVolume = 0.0
-	var bidAmount = 0.0
+	var bidVolume fixedpoint.Value
+	var bidAmount fixedpoint.Value
 
-	var askVolume = 0.0
+	var askVolume fixedpoint.Value
 
-	var feeUSD = 0.0
-	var bidFeeUSD = 0.0
-	var feeRate = 0.0015
+	var feeUSD fixedpoint.Value
+	var bidFeeUSD fixedpoint.Value
+	var feeRate = fixedpoint.NewFromFloat(0.0015)
 
 	if len(trades) == 0 {
 		return &AverageCostPnlReport{
@@ -33,21 +34,21 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 		}
 	}
 
-	var currencyFees = map[string]float64{}
+	var currencyFees = map[string]fixedpoint.Value{}
 
 	for _, trade := range trades {
 		if trade.Symbol == symbol {
 			if trade.IsBuyer && trade.Side != types.SideTypeSelf {
 				bidVolume += trade.Quantity
-				bidAmount += trade.Price * trade.Quantity
+				bidAmount += trade.Price.Mul(trade.Quantity)
 			}
 
 			// since we use USDT as the quote currency, we simply check if it matches the currency symbol
 			if strings.HasPrefix(trade.Symbol, trade.FeeCurrency) {
 				bidVolume -= trade.Fee
-				feeUSD += trade.Price * trade.Fee
+				feeUSD += trade.Price.Mul(trade.Fee)
 				if trade.IsBuyer {
-					bidFeeUSD += trade.Price * trade.Fee
+					bidFeeUSD += trade.Price.Mul(trade.Fee)
 				}
 			} else if trade.FeeCurrency == "USDT" {
 				feeUSD += trade.Fee
@@ -62,14 +63,14 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 			}
 		}
 
-		if _, ok := currencyFees[trade.FeeCurrency]; !ok {
-			currencyFees[trade.FeeCurrency] = 0.0
-		}
 		currencyFees[trade.FeeCurrency] += trade.Fee
 	}
 
-	profit := 0.0
-	averageCost := (bidAmount + bidFeeUSD) / bidVolume
+	var profit fixedpoint.Value
+	var averageCost fixedpoint.Value
+	if bidVolume != 0 {
+		averageCost = (bidAmount + bidFeeUSD).Div(bidVolume)
+	}
 
 	for _, t := range trades {
 		if t.Symbol != symbol {
@@ -80,7 +81,7 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 			continue
 		}
 
-		profit += (t.Price - averageCost) * t.Quantity
+		profit += (t.Price - averageCost).Mul(t.Quantity)
 		askVolume += t.Quantity
 	}
 
@@ -89,8 +90,8 @@ func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, c
 
 	stock := bidVolume - askVolume
 	if stock > 0 {
-		stockFee := currentPrice * stock * feeRate
-		unrealizedProfit += (currentPrice-averageCost)*stock - stockFee
+		stockFee := currentPrice.Mul(stock).Mul(feeRate)
+		unrealizedProfit += (currentPrice - averageCost).Mul(stock) - stockFee
 	}
 
 	return &AverageCostPnlReport{
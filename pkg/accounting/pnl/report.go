@@ -7,42 +7,43 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/slack/slackstyle"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
 type AverageCostPnlReport struct {
-	CurrentPrice float64
+	CurrentPrice fixedpoint.Value
 	StartTime    time.Time
 	Symbol       string
 	Market       types.Market
 
 	NumTrades        int
-	Profit           float64
-	UnrealizedProfit float64
-	AverageBidCost   float64
-	BuyVolume        float64
-	SellVolume       float64
-	FeeInUSD         float64
-	Stock            float64
-	CurrencyFees     map[string]float64
+	Profit           fixedpoint.Value
+	UnrealizedProfit fixedpoint.Value
+	AverageBidCost   fixedpoint.Value
+	BuyVolume        fixedpoint.Value
+	SellVolume       fixedpoint.Value
+	FeeInUSD         fixedpoint.Value
+	Stock            fixedpoint.Value
+	CurrencyFees     map[string]fixedpoint.Value
 }
 
 func (report AverageCostPnlReport) Print() {
 	log.Infof("TRADES SINCE: %v", report.StartTime)
 	log.Infof("NUMBER OF TRADES: %d", report.NumTrades)
-	log.Infof("AVERAGE COST: %s", types.USD.FormatMoneyFloat64(report.AverageBidCost))
-	log.Infof("TOTAL BUY VOLUME: %f", report.BuyVolume)
-	log.Infof("TOTAL SELL VOLUME: %f", report.SellVolume)
-	log.Infof("STOCK: %f", report.Stock)
-	log.Infof("FEE (USD): %f", report.FeeInUSD)
-	log.Infof("CURRENT PRICE: %s", types.USD.FormatMoneyFloat64(report.CurrentPrice))
+	log.Infof("AVERAGE COST: %s", types.USD.FormatMoneyFloat64(report.AverageBidCost.Float64()))
+	log.Infof("TOTAL BUY VOLUME: %s", report.BuyVolume)
+	log.Infof("TOTAL SELL VOLUME: %s", report.SellVolume)
+	log.Infof("STOCK: %s", report.Stock)
+	log.Infof("FEE (USD): %s", report.FeeInUSD)
+	log.Infof("CURRENT PRICE: %s", types.USD.FormatMoneyFloat64(report.CurrentPrice.Float64()))
 	log.Infof("CURRENCY FEES:")
 	for currency, fee := range report.CurrencyFees {
-		log.Infof(" - %s: %f", currency, fee)
+		log.Infof(" - %s: %s", currency, fee)
 	}
-	log.Infof("PROFIT: %s", types.USD.FormatMoneyFloat64(report.Profit))
-	log.Infof("UNREALIZED PROFIT: %s", types.USD.FormatMoneyFloat64(report.UnrealizedProfit))
+	log.Infof("PROFIT: %s", types.USD.FormatMoneyFloat64(report.Profit.Float64()))
+	log.Infof("UNREALIZED PROFIT: %s", types.USD.FormatMoneyFloat64(report.UnrealizedProfit.Float64()))
 }
 
 func (report AverageCostPnlReport) SlackAttachment() slack.Attachment {
@@ -54,17 +55,17 @@ func (report AverageCostPnlReport) SlackAttachment() slack.Attachment {
 
 	return slack.Attachment{
 		Title: report.Symbol + " Profit and Loss report",
-		Text:  "Profit " + types.USD.FormatMoney(report.Profit),
+		Text:  "Profit " + types.USD.FormatMoneyFloat64(report.Profit.Float64()),
 		Color: color,
 		// Pretext:       "",
 		// Text:          "",
 		Fields: []slack.AttachmentField{
-			{Title: "Profit", Value: types.USD.FormatMoney(report.Profit)},
-			{Title: "Unrealized Profit", Value: types.USD.FormatMoney(report.UnrealizedProfit)},
-			{Title: "Current Price", Value: report.Market.FormatPrice(report.CurrentPrice), Short: true},
-			{Title: "Average Cost", Value: report.Market.FormatPrice(report.AverageBidCost), Short: true},
-			{Title: "Fee (USD)", Value: types.USD.FormatMoney(report.FeeInUSD), Short: true},
-			{Title: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Short: true},
+			{Title: "Profit", Value: types.USD.FormatMoneyFloat64(report.Profit.Float64())},
+			{Title: "Unrealized Profit", Value: types.USD.FormatMoneyFloat64(report.UnrealizedProfit.Float64())},
+			{Title: "Current Price", Value: report.Market.FormatPrice(report.CurrentPrice.Float64()), Short: true},
+			{Title: "Average Cost", Value: report.Market.FormatPrice(report.AverageBidCost.Float64()), Short: true},
+			{Title: "Fee (USD)", Value: types.USD.FormatMoneyFloat64(report.FeeInUSD.Float64()), Short: true},
+			{Title: "Stock", Value: strconv.FormatFloat(report.Stock.Float64(), 'f', 8, 64), Short: true},
 			{Title: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Short: true},
 		},
 		Footer:     report.StartTime.Format(time.RFC822),
@@ -8,8 +8,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
-	"github.com/ycdesu/spreaddog/pkg/exchange/binance"
-	"github.com/ycdesu/spreaddog/pkg/exchange/max"
+	"github.com/ycdesu/spreaddog/pkg/exchange"
 	"github.com/ycdesu/spreaddog/pkg/service"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
@@ -242,6 +241,16 @@ func (e Exchange) PlatformFeeCurrency() string {
 	return e.publicExchange.PlatformFeeCurrency()
 }
 
+// Capabilities returns the features simulated by the backtest, only the klines are streamed and the orders are matched
+// by the simple price matching.
+func (e Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		Channels:    []types.Channel{types.KLineChannel},
+		OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeMarket, types.OrderTypeStopLimit, types.OrderTypeStopMarket},
+		TimeInForce: []string{"GTC"},
+	}
+}
+
 func (e Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	return e.publicExchange.QueryMarkets(ctx)
 }
@@ -254,13 +263,11 @@ func (e Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since,
 	return nil, nil
 }
 
+// newPublicExchange creates the registered exchange without the credentials, only the public api is used
 func newPublicExchange(sourceExchange types.ExchangeName) (types.Exchange, error) {
-	switch sourceExchange {
-	case types.ExchangeBinance:
-		return binance.New("", ""), nil
-	case types.ExchangeMax:
-		return max.New("", ""), nil
+	ex, err := exchange.New(sourceExchange, exchange.Credentials{})
+	if err != nil {
+		return nil, fmt.Errorf("exchange %s is not supported: %w", sourceExchange, err)
 	}
-
-	return nil, fmt.Errorf("exchange %s is not supported", sourceExchange)
+	return ex, nil
 }
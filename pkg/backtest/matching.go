@@ -96,12 +96,12 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 
 	switch o.Side {
 	case types.SideTypeBuy:
-		if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(o.Price*o.Quantity)); err != nil {
+		if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, o.Price.Mul(o.Quantity)); err != nil {
 			return o, err
 		}
 
 	case types.SideTypeSell:
-		if err := m.Account.UnlockBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(o.Quantity)); err != nil {
+		if err := m.Account.UnlockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
 			return o, err
 		}
 	}
@@ -118,21 +118,21 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (closedOrders *typ
 	price := o.Price
 	switch o.Type {
 	case types.OrderTypeMarket:
-		price = m.LastPrice.Float64()
+		price = m.LastPrice
 	case types.OrderTypeLimit:
 		price = o.Price
 	}
 
 	switch o.Side {
 	case types.SideTypeBuy:
-		quote := price * o.Quantity
-		if err := m.Account.LockBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(quote)); err != nil {
+		quote := price.Mul(o.Quantity)
+		if err := m.Account.LockBalance(m.Market.QuoteCurrency, quote); err != nil {
 			return nil, nil, err
 		}
 
 	case types.SideTypeSell:
 		baseQuantity := o.Quantity
-		if err := m.Account.LockBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(baseQuantity)); err != nil {
+		if err := m.Account.LockBalance(m.Market.BaseCurrency, baseQuantity); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -182,13 +182,13 @@ func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
 	var err error
 	// execute trade, update account balances
 	if trade.IsBuyer {
-		err = m.Account.UseLockedBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(trade.Price*trade.Quantity))
+		err = m.Account.UseLockedBalance(m.Market.QuoteCurrency, trade.Price.Mul(trade.Quantity))
 
-		_ = m.Account.AddBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(trade.Quantity))
+		_ = m.Account.AddBalance(m.Market.BaseCurrency, trade.Quantity)
 	} else {
-		err = m.Account.UseLockedBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(trade.Quantity))
+		err = m.Account.UseLockedBalance(m.Market.BaseCurrency, trade.Quantity)
 
-		_ = m.Account.AddBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(trade.Quantity*trade.Price))
+		_ = m.Account.AddBalance(m.Market.QuoteCurrency, trade.Quantity.Mul(trade.Price))
 	}
 
 	if err != nil {
@@ -203,24 +203,24 @@ func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
 func (m *SimplePriceMatching) newTradeFromOrder(order types.Order, isMaker bool) types.Trade {
 	// BINANCE uses 0.1% for both maker and taker
 	// MAX uses 0.050% for maker and 0.15% for taker
-	var commission = DefaultFeeRate
+	var commission = fixedpoint.NewFromFloat(DefaultFeeRate)
 	if isMaker && m.Account.MakerCommission > 0 {
-		commission = fixedpoint.NewFromFloat(0.0001).Mul(m.Account.MakerCommission).Float64() // binance uses 10~15
+		commission = fixedpoint.NewFromFloat(0.0001).Mul(m.Account.MakerCommission) // binance uses 10~15
 	} else if m.Account.TakerCommission > 0 {
-		commission = fixedpoint.NewFromFloat(0.0001).Mul(m.Account.TakerCommission).Float64() // binance uses 10~15
+		commission = fixedpoint.NewFromFloat(0.0001).Mul(m.Account.TakerCommission) // binance uses 10~15
 	}
 
-	var fee float64
+	var fee fixedpoint.Value
 	var feeCurrency string
 
 	switch order.Side {
 
 	case types.SideTypeBuy:
-		fee = order.Quantity * commission
+		fee = order.Quantity.Mul(commission)
 		feeCurrency = m.Market.BaseCurrency
 
 	case types.SideTypeSell:
-		fee = order.Quantity * order.Price * commission
+		fee = order.Quantity.Mul(order.Price).Mul(commission)
 		feeCurrency = m.Market.QuoteCurrency
 
 	}
@@ -232,7 +232,7 @@ func (m *SimplePriceMatching) newTradeFromOrder(order types.Order, isMaker bool)
 		Exchange:      "backtest",
 		Price:         order.Price,
 		Quantity:      order.Quantity,
-		QuoteQuantity: order.Quantity * order.Price,
+		QuoteQuantity: order.Quantity.Mul(order.Price),
 		Symbol:        order.Symbol,
 		Side:          order.Side,
 		IsBuyer:       order.Side == types.SideTypeBuy,
@@ -244,7 +244,6 @@ func (m *SimplePriceMatching) newTradeFromOrder(order types.Order, isMaker bool)
 }
 
 func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders []types.Order, trades []types.Trade) {
-	var priceF = price.Float64()
 	var askOrders []types.Order
 
 	for _, o := range m.askOrders {
@@ -252,7 +251,7 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 
 		case types.OrderTypeStopMarket:
 			// should we trigger the order
-			if priceF <= o.StopPrice {
+			if price <= o.StopPrice {
 				// not triggering it, put it back
 				askOrders = append(askOrders, o)
 				break
@@ -260,7 +259,7 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 
 			o.Type = types.OrderTypeMarket
 			o.ExecutedQuantity = o.Quantity
-			o.Price = priceF
+			o.Price = price
 			o.Status = types.OrderStatusFilled
 			closedOrders = append(closedOrders, o)
 
@@ -273,7 +272,7 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 
 		case types.OrderTypeStopLimit:
 			// should we trigger the order?
-			if priceF <= o.StopPrice {
+			if price <= o.StopPrice {
 				askOrders = append(askOrders, o)
 				break
 			}
@@ -281,7 +280,7 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 			o.Type = types.OrderTypeLimit
 
 			// is it a taker order?
-			if priceF >= o.Price {
+			if price >= o.Price {
 				o.ExecutedQuantity = o.Quantity
 				o.Status = types.OrderStatusFilled
 				closedOrders = append(closedOrders, o)
@@ -298,7 +297,7 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 			}
 
 		case types.OrderTypeLimit:
-			if priceF >= o.Price {
+			if price >= o.Price {
 				o.ExecutedQuantity = o.Quantity
 				o.Status = types.OrderStatusFilled
 				closedOrders = append(closedOrders, o)
@@ -326,7 +325,7 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 }
 
 func (m *SimplePriceMatching) SellToPrice(price fixedpoint.Value) (closedOrders []types.Order, trades []types.Trade) {
-	var sellPrice = price.Float64()
+	var sellPrice = price
 	var bidOrders []types.Order
 	for _, o := range m.bidOrders {
 		switch o.Type {
@@ -15,8 +15,8 @@ func newLimitOrder(symbol string, side types.SideType, price, quantity float64)
 		Symbol:      symbol,
 		Side:        side,
 		Type:        types.OrderTypeLimit,
-		Quantity:    quantity,
-		Price:       price,
+		Quantity:    fixedpoint.NewFromFloat(quantity),
+		Price:       fixedpoint.NewFromFloat(price),
 		TimeInForce: "GTC",
 	}
 }
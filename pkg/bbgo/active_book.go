@@ -113,6 +113,22 @@ func (b *LocalActiveOrderBook) Remove(order types.Order) bool {
 	return false
 }
 
+// Replace replaces the amended order with the new order, the order id could be changed by the amendment.
+// The new order is not added if it's already closed, and the filled callbacks are emitted if it's filled immediately.
+func (b *LocalActiveOrderBook) Replace(oldOrder, newOrder types.Order) {
+	b.Remove(oldOrder)
+
+	switch newOrder.Status {
+	case types.OrderStatusFilled:
+		b.EmitFilled(newOrder)
+
+	case types.OrderStatusCanceled, types.OrderStatusRejected:
+
+	default:
+		b.Add(newOrder)
+	}
+}
+
 // WriteOff writes off the filled order on the opposite side.
 // This method does not write off order by order amount or order quantity.
 func (b *LocalActiveOrderBook) WriteOff(order types.Order) bool {
@@ -72,6 +72,9 @@ type NotificationConfig struct {
 	SessionChannels map[string]string `json:"sessionChannels,omitempty" yaml:"sessionChannels,omitempty"`
 
 	Routing *SlackNotificationRouting `json:"routing,omitempty" yaml:"routing,omitempty"`
+
+	// MaintenanceWindows defines the recurring windows that suppress or downgrade the notifications
+	MaintenanceWindows []MaintenanceWindowConfig `json:"maintenanceWindows,omitempty" yaml:"maintenanceWindows,omitempty"`
 }
 
 type Session struct {
@@ -7,6 +7,7 @@ import (
 	"image/png"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -107,6 +108,42 @@ func (environ *Environment) Sessions() map[string]*ExchangeSession {
 	return environ.sessions
 }
 
+// StreamStatuses returns the stream status of each session
+func (environ *Environment) StreamStatuses() map[string]types.StreamStatus {
+	statuses := make(map[string]types.StreamStatus, len(environ.sessions))
+	for name, session := range environ.sessions {
+		statuses[name] = session.StreamStatus()
+	}
+
+	return statuses
+}
+
+// CheckStreamHealth returns an error if any of the session streams is not connected,
+// or it doesn't receive any message in maxIdle. A zero maxIdle disables the idle check,
+// and the streams without the market data subscriptions are never considered idle.
+func (environ *Environment) CheckStreamHealth(maxIdle time.Duration) error {
+	now := time.Now()
+
+	var names []string
+	for name := range environ.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []string
+	for _, name := range names {
+		if err := environ.sessions[name].StreamStatus().Check(now, maxIdle); err != nil {
+			errs = append(errs, fmt.Sprintf("session %s: %s", name, err.Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
 func (environ *Environment) SelectSessions(names ...string) map[string]*ExchangeSession {
 	if len(names) == 0 {
 		return environ.sessions
@@ -174,7 +211,7 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 // AddExchangeSession adds the existing exchange session or pre-created exchange session
 func (environ *Environment) AddExchangeSession(name string, session *ExchangeSession) *ExchangeSession {
 	// update Notifiability from the environment
-	session.Notifiability = environ.Notifiability
+	session.Notifiability = environ.Notifiability.WithSession(name)
 
 	environ.sessions[name] = session
 	return session
@@ -195,7 +232,7 @@ func (environ *Environment) ConfigureExchangeSessions(userConfig *Config) error
 }
 
 func (environ *Environment) AddExchangesByViperKeys() error {
-	for _, n := range SupportedExchanges {
+	for _, n := range types.ExchangeNames() {
 		if viper.IsSet(string(n) + "-api-key") {
 			exchange, err := cmdutil.NewExchangeWithEnvVarPrefix(n, "")
 			if err != nil {
@@ -219,10 +256,10 @@ func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (
 
 	if sessionConfig.PublicOnly {
 
-		exchange, err = cmdutil.NewExchangeStandard(exchangeName, "", "", sessionConfig.SubAccount)
+		exchange, err = cmdutil.NewExchangeStandard(exchangeName, "", "", "", sessionConfig.SubAccount)
 	} else {
 		if sessionConfig.Key != "" && sessionConfig.Secret != "" {
-			exchange, err = cmdutil.NewExchangeStandard(exchangeName, sessionConfig.Key, sessionConfig.Secret, sessionConfig.SubAccount)
+			exchange, err = cmdutil.NewExchangeStandard(exchangeName, sessionConfig.Key, sessionConfig.Secret, sessionConfig.Passphrase, sessionConfig.SubAccount)
 		} else {
 			exchange, err = cmdutil.NewExchangeWithEnvVarPrefix(exchangeName, sessionConfig.EnvVarPrefix)
 		}
@@ -233,6 +270,19 @@ func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (
 	}
 
 	// configure exchange
+	if sessionConfig.Futures {
+		if sessionConfig.Margin {
+			return nil, fmt.Errorf("session %s can not enable both margin and futures", name)
+		}
+
+		futuresExchange, ok := exchange.(types.FuturesExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support futures", exchangeName)
+		}
+
+		futuresExchange.UseFutures()
+	}
+
 	if sessionConfig.Margin {
 		marginExchange, ok := exchange.(types.MarginExchange)
 		if !ok {
@@ -251,11 +301,13 @@ func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (
 	session.EnvVarPrefix = sessionConfig.EnvVarPrefix
 	session.Key = sessionConfig.Key
 	session.Secret = sessionConfig.Secret
+	session.Passphrase = sessionConfig.Passphrase
 	session.SubAccount = sessionConfig.SubAccount
 	session.PublicOnly = sessionConfig.PublicOnly
 	session.Margin = sessionConfig.Margin
 	session.IsolatedMargin = sessionConfig.IsolatedMargin
 	session.IsolatedMarginSymbol = sessionConfig.IsolatedMarginSymbol
+	session.Futures = sessionConfig.Futures
 	if session.PublicOnly {
 		session.Stream.SetPublicOnly()
 	}
@@ -343,9 +395,10 @@ func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfi
 				// if we can route session name to channel successfully...
 				channel, ok := environ.SessionChannelRouter.Route(name)
 				if ok {
+					notifiability := environ.Notifiability.WithSession(name)
 					session.Stream.OnTradeUpdate(func(trade types.Trade) {
 						text := util.Render(TemplateTradeReport, trade)
-						environ.NotifyTo(channel, text, &trade)
+						notifiability.NotifyTo(channel, text, &trade)
 					})
 				} else {
 					session.Stream.OnTradeUpdate(defaultTradeUpdateHandler)
@@ -393,9 +446,10 @@ func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfi
 				// if we can route session name to channel successfully...
 				channel, ok := environ.SessionChannelRouter.Route(name)
 				if ok {
+					notifiability := environ.Notifiability.WithSession(name)
 					session.Stream.OnOrderUpdate(func(order types.Order) {
 						text := util.Render(TemplateOrderReport, order)
-						environ.NotifyTo(channel, text, &order)
+						notifiability.NotifyTo(channel, text, &order)
 					})
 				} else {
 					session.Stream.OnOrderUpdate(defaultOrderUpdateHandler)
@@ -478,19 +532,7 @@ func (environ *Environment) Connect(ctx context.Context) error {
 	for n := range environ.sessions {
 		// avoid using the placeholder variable for the session because we use that in the callbacks
 		var session = environ.sessions[n]
-		var logger = log.WithField("session", n)
-
-		if len(session.Subscriptions) == 0 {
-		} else {
-			// add the subscribe requests to the stream
-			for _, s := range session.Subscriptions {
-				logger.Infof("subscribing %s %s %v", s.Symbol, s.Channel, s.Options)
-				session.Stream.Subscribe(s.Channel, s.Symbol, s.Options)
-			}
-		}
-
-		logger.Infof("connecting session %s...", session.Name)
-		if err := session.Stream.Connect(ctx); err != nil {
+		if err := session.connectStream(ctx); err != nil {
 			return err
 		}
 	}
@@ -559,10 +601,18 @@ func getSessionSymbols(session *ExchangeSession, defaultSymbols ...string) ([]st
 }
 
 func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) error {
+	persistence := environ.PersistenceServiceFacade.Get()
+
+	maintenanceSchedule, err := NewMaintenanceScheduleFromConfig(persistence, userConfig.Notifications)
+	if err != nil {
+		return err
+	}
+
 	environ.Notifiability = Notifiability{
 		SymbolChannelRouter:  NewPatternChannelRouter(nil),
 		SessionChannelRouter: NewPatternChannelRouter(nil),
 		ObjectChannelRouter:  NewObjectChannelRouter(),
+		MaintenanceSchedule:  maintenanceSchedule,
 	}
 
 	slackToken := viper.GetString("slack-token")
@@ -579,7 +629,6 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		}
 	}
 
-	persistence := environ.PersistenceServiceFacade.Get()
 	telegramBotToken := viper.GetString("telegram-bot-token")
 	if len(telegramBotToken) > 0 {
 		tt := strings.Split(telegramBotToken, ":")
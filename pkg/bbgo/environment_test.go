@@ -0,0 +1,45 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestNewExchangeSessionFromConfig_Futures(t *testing.T) {
+	session, err := NewExchangeSessionFromConfig("binance_futures", &ExchangeSession{
+		ExchangeName: "binance",
+		Key:          "key",
+		Secret:       "secret",
+		Futures:      true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, session.Futures)
+
+	futuresExchange, ok := session.Exchange.(types.FuturesExchange)
+	if assert.True(t, ok) {
+		assert.True(t, futuresExchange.GetFuturesSettings().IsFutures)
+	}
+	assert.True(t, session.Exchange.Capabilities().Futures)
+
+	_, err = NewExchangeSessionFromConfig("binance_futures", &ExchangeSession{
+		ExchangeName: "binance",
+		Key:          "key",
+		Secret:       "secret",
+		Futures:      true,
+		Margin:       true,
+	})
+	assert.Error(t, err)
+
+	_, err = NewExchangeSessionFromConfig("kraken_futures", &ExchangeSession{
+		ExchangeName: "kraken",
+		Key:          "key",
+		Secret:       "secret",
+		Futures:      true,
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not support futures")
+	}
+}
@@ -0,0 +1,319 @@
+package bbgo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ycdesu/spreaddog/pkg/service"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// maintenanceReloadInterval is the interval of reloading the ad-hoc windows from the persistence store,
+// so that the windows added by the `bbgo maintenance` command can be picked up by the running process.
+const maintenanceReloadInterval = 30 * time.Second
+
+type MaintenanceMode string
+
+const (
+	// MaintenanceModeSuppress drops the notifications during the window
+	MaintenanceModeSuppress = MaintenanceMode("suppress")
+
+	// MaintenanceModeDowngrade re-routes the notifications to the (low priority) channel of the window
+	MaintenanceModeDowngrade = MaintenanceMode("downgrade")
+)
+
+// MaintenanceWindowConfig defines a recurring maintenance window, for example:
+//
+//	maintenanceWindows:
+//	- session: max
+//	  cron: "0 2 * * 3"
+//	  duration: 1h
+type MaintenanceWindowConfig struct {
+	// Session is the session name of the window, an empty session means the window applies to all sessions
+	Session string `json:"session,omitempty" yaml:"session,omitempty"`
+
+	// Cron is the standard cron spec of the window start time
+	Cron string `json:"cron" yaml:"cron"`
+
+	Duration types.Duration `json:"duration" yaml:"duration"`
+
+	Mode MaintenanceMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+
+	// Channel is the channel that receives the downgraded notifications
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+}
+
+// MaintenanceWindow is an ad-hoc maintenance window with a fixed time range
+type MaintenanceWindow struct {
+	Session   string          `json:"session,omitempty"`
+	StartTime time.Time       `json:"startTime"`
+	EndTime   time.Time       `json:"endTime"`
+	Mode      MaintenanceMode `json:"mode,omitempty"`
+	Channel   string          `json:"channel,omitempty"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+func (w MaintenanceWindow) Contains(session string, t time.Time) bool {
+	if len(w.Session) > 0 && w.Session != session {
+		return false
+	}
+
+	return !t.Before(w.StartTime) && t.Before(w.EndTime)
+}
+
+func (w MaintenanceWindow) String() string {
+	session := w.Session
+	if len(session) == 0 {
+		session = "*"
+	}
+
+	return fmt.Sprintf("MaintenanceWindow{ session: %s, mode: %s, from: %s, to: %s, reason: %q }",
+		session, w.Mode, w.StartTime.Format(time.RFC3339), w.EndTime.Format(time.RFC3339), w.Reason)
+}
+
+type recurringMaintenanceWindow struct {
+	MaintenanceWindowConfig
+
+	schedule cron.Schedule
+}
+
+// window returns the window that contains the given time
+func (w *recurringMaintenanceWindow) window(t time.Time) (MaintenanceWindow, bool) {
+	duration := w.Duration.Duration()
+
+	// find the first activation after (t - duration), if it's not after t, then t is inside the window
+	startTime := w.schedule.Next(t.Add(-duration))
+	if startTime.After(t) {
+		return MaintenanceWindow{}, false
+	}
+
+	return MaintenanceWindow{
+		Session:   w.Session,
+		StartTime: startTime,
+		EndTime:   startTime.Add(duration),
+		Mode:      w.Mode,
+		Channel:   w.Channel,
+		Reason:    "scheduled maintenance: " + w.Cron,
+	}, true
+}
+
+// MaintenanceSchedule maintains the recurring windows from the config and the ad-hoc windows,
+// the ad-hoc windows are saved in the persistence store so that they survive restarts.
+type MaintenanceSchedule struct {
+	mu sync.Mutex
+
+	store service.Store
+
+	recurring []*recurringMaintenanceWindow
+	windows   []MaintenanceWindow
+
+	loadedAt time.Time
+
+	// version is increased when the windows are changed, the windows loaded before the change are discarded
+	version int
+}
+
+func NewMaintenanceSchedule(store service.Store) *MaintenanceSchedule {
+	return &MaintenanceSchedule{
+		store: store,
+	}
+}
+
+// NewMaintenanceScheduleFromConfig creates the maintenance schedule with the store of the given persistence service,
+// and loads the recurring windows from the notification config.
+func NewMaintenanceScheduleFromConfig(persistence service.PersistenceService, conf *NotificationConfig) (*MaintenanceSchedule, error) {
+	schedule := NewMaintenanceSchedule(persistence.NewStore("bbgo", "maintenance"))
+	if err := schedule.Load(); err != nil {
+		return nil, err
+	}
+
+	if conf == nil {
+		return schedule, nil
+	}
+
+	for _, windowConf := range conf.MaintenanceWindows {
+		if err := schedule.AddRecurringWindow(windowConf); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedule, nil
+}
+
+func (s *MaintenanceSchedule) AddRecurringWindow(conf MaintenanceWindowConfig) error {
+	schedule, err := cron.ParseStandard(conf.Cron)
+	if err != nil {
+		return fmt.Errorf("invalid maintenance window cron spec %q: %w", conf.Cron, err)
+	}
+
+	if conf.Duration <= 0 {
+		return fmt.Errorf("maintenance window %q: duration must be greater than zero", conf.Cron)
+	}
+
+	if err := validateMaintenanceMode(conf.Mode, conf.Channel); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.recurring = append(s.recurring, &recurringMaintenanceWindow{
+		MaintenanceWindowConfig: conf,
+		schedule:                schedule,
+	})
+	s.mu.Unlock()
+	return nil
+}
+
+// AddWindow adds an ad-hoc window and saves the active windows into the persistence store
+func (s *MaintenanceSchedule) AddWindow(window MaintenanceWindow) error {
+	if !window.EndTime.After(window.StartTime) {
+		return fmt.Errorf("maintenance window end time %s must be after the start time %s", window.EndTime, window.StartTime)
+	}
+
+	if err := validateMaintenanceMode(window.Mode, window.Channel); err != nil {
+		return err
+	}
+
+	if err := s.Load(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.windows = append(s.windows, window)
+	s.version++
+	return s.save()
+}
+
+// Load loads the ad-hoc windows from the persistence store, expired windows are dropped.
+func (s *MaintenanceSchedule) Load() error {
+	s.mu.Lock()
+	version := s.version
+	s.mu.Unlock()
+
+	return s.load(time.Now(), version)
+}
+
+// load reads the persistence store without holding the lock, and then replaces the windows if they are not changed
+// since the given version.
+func (s *MaintenanceSchedule) load(now time.Time, version int) error {
+	windows, ok, err := s.loadWindows(now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loadedAt = now
+	if err != nil || !ok || s.version != version {
+		return err
+	}
+
+	s.windows = windows
+	s.version++
+	return nil
+}
+
+// loadWindows returns the windows in the persistence store which are not expired yet, ok is false when there is no
+// store or nothing is saved.
+func (s *MaintenanceSchedule) loadWindows(now time.Time) (active []MaintenanceWindow, ok bool, err error) {
+	if s.store == nil {
+		return nil, false, nil
+	}
+
+	var windows []MaintenanceWindow
+	if err := s.store.Load(&windows); err != nil {
+		if err == service.ErrPersistenceNotExists {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	for _, w := range windows {
+		if w.EndTime.After(now) {
+			active = append(active, w)
+		}
+	}
+
+	return active, true, nil
+}
+
+func (s *MaintenanceSchedule) save() error {
+	if s.store == nil {
+		return nil
+	}
+
+	windows := make([]MaintenanceWindow, len(s.windows))
+	copy(windows, s.windows)
+	return s.store.Save(&windows)
+}
+
+// Windows returns the ad-hoc windows that are not expired yet
+func (s *MaintenanceSchedule) Windows() []MaintenanceWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	windows := make([]MaintenanceWindow, len(s.windows))
+	copy(windows, s.windows)
+	return windows
+}
+
+// Lookup finds the window that covers the given session at the given time.
+// Global windows (without session) cover all the sessions.
+func (s *MaintenanceSchedule) Lookup(session string, now time.Time) (MaintenanceWindow, bool) {
+	if s == nil {
+		return MaintenanceWindow{}, false
+	}
+
+	// the windows are reloaded without holding the lock, so that the persistence I/O does not block the other lookups,
+	// and loadedAt is updated first so that only one lookup reloads them
+	s.mu.Lock()
+	reload := now.Sub(s.loadedAt) > maintenanceReloadInterval
+	if reload {
+		s.loadedAt = now
+	}
+	version := s.version
+	s.mu.Unlock()
+
+	if reload {
+		if err := s.load(now, version); err != nil {
+			log.WithError(err).Error("can not load maintenance windows")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range s.windows {
+		if w.Contains(session, now) {
+			return w, true
+		}
+	}
+
+	for _, r := range s.recurring {
+		if w, ok := r.window(now); ok && w.Contains(session, now) {
+			return w, true
+		}
+	}
+
+	return MaintenanceWindow{}, false
+}
+
+func validateMaintenanceMode(mode MaintenanceMode, channel string) error {
+	switch mode {
+	case "", MaintenanceModeSuppress:
+		return nil
+
+	case MaintenanceModeDowngrade:
+		if len(channel) == 0 {
+			return fmt.Errorf("maintenance mode %s requires a channel", mode)
+		}
+		return nil
+
+	}
+
+	return fmt.Errorf("unsupported maintenance mode: %s, valid modes are: %s, %s", mode, MaintenanceModeSuppress, MaintenanceModeDowngrade)
+}
@@ -0,0 +1,169 @@
+package bbgo
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/service"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestMaintenanceSchedule_RecurringWindow(t *testing.T) {
+	schedule := NewMaintenanceSchedule(nil)
+	err := schedule.AddRecurringWindow(MaintenanceWindowConfig{
+		Session:  "max",
+		Cron:     "0 2 * * 3", // every Wednesday 02:00
+		Duration: types.Duration(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	wednesday := time.Date(2021, time.March, 3, 0, 0, 0, 0, time.Local)
+
+	var testcases = []struct {
+		name     string
+		session  string
+		t        time.Time
+		expected bool
+	}{
+		{name: "before the window", session: "max", t: wednesday.Add(time.Hour + 59*time.Minute), expected: false},
+		{name: "window start", session: "max", t: wednesday.Add(2 * time.Hour), expected: true},
+		{name: "inside the window", session: "max", t: wednesday.Add(2*time.Hour + 30*time.Minute), expected: true},
+		{name: "window end", session: "max", t: wednesday.Add(3 * time.Hour), expected: false},
+		{name: "other session", session: "binance", t: wednesday.Add(2*time.Hour + 30*time.Minute), expected: false},
+		{name: "next day", session: "max", t: wednesday.Add(26 * time.Hour), expected: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := schedule.Lookup(tc.session, tc.t)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestMaintenanceSchedule_AdHocWindow(t *testing.T) {
+	memory := service.NewMemoryService()
+	schedule := NewMaintenanceSchedule(memory.NewStore("bbgo", "maintenance"))
+
+	now := time.Now()
+	err := schedule.AddWindow(MaintenanceWindow{
+		StartTime: now,
+		EndTime:   now.Add(2 * time.Hour),
+		Reason:    "upgrade",
+	})
+	assert.NoError(t, err)
+
+	window, ok := schedule.Lookup("binance", now.Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, "upgrade", window.Reason)
+
+	_, ok = schedule.Lookup("binance", now.Add(3*time.Hour))
+	assert.False(t, ok)
+
+	// the window should be loaded from the store after restart
+	restarted := NewMaintenanceSchedule(memory.NewStore("bbgo", "maintenance"))
+	assert.NoError(t, restarted.Load())
+	assert.Len(t, restarted.Windows(), 1)
+
+	err = schedule.AddWindow(MaintenanceWindow{
+		StartTime: now,
+		EndTime:   now.Add(time.Hour),
+		Mode:      MaintenanceModeDowngrade,
+	})
+	assert.Error(t, err, "downgrade mode requires a channel")
+}
+
+// blockingStore blocks the first load until it's released
+type blockingStore struct {
+	service.Store
+
+	loading chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingStore) Load(val interface{}) error {
+	s.once.Do(func() {
+		close(s.loading)
+		<-s.release
+	})
+	return s.Store.Load(val)
+}
+
+func TestMaintenanceSchedule_LookupWhileReloading(t *testing.T) {
+	store := &blockingStore{
+		Store:   service.NewMemoryService().NewStore("bbgo", "maintenance"),
+		loading: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	schedule := NewMaintenanceSchedule(store)
+	assert.NoError(t, schedule.AddRecurringWindow(MaintenanceWindowConfig{
+		Cron:     "0 2 * * 3", // every Wednesday 02:00
+		Duration: types.Duration(time.Hour),
+	}))
+
+	now := time.Date(2021, time.March, 3, 2, 30, 0, 0, time.Local)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, ok := schedule.Lookup("max", now)
+		assert.True(t, ok)
+	}()
+
+	// the other lookups are not blocked by the reload, and they do not reload again
+	<-store.loading
+	_, ok := schedule.Lookup("binance", now)
+	assert.True(t, ok)
+	assert.Empty(t, schedule.Windows())
+
+	close(store.release)
+	<-done
+}
+
+type recordNotifier struct {
+	channels []string
+}
+
+func (n *recordNotifier) NotifyTo(channel, format string, args ...interface{}) {
+	n.channels = append(n.channels, channel)
+}
+
+func (n *recordNotifier) Notify(format string, args ...interface{}) {
+	n.channels = append(n.channels, "")
+}
+
+func TestNotifiability_Maintenance(t *testing.T) {
+	now := time.Now()
+	schedule := NewMaintenanceSchedule(nil)
+	assert.NoError(t, schedule.AddWindow(MaintenanceWindow{
+		Session:   "max",
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now.Add(time.Hour),
+	}))
+	assert.NoError(t, schedule.AddWindow(MaintenanceWindow{
+		Session:   "ftx",
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now.Add(time.Hour),
+		Mode:      MaintenanceModeDowngrade,
+		Channel:   "low-priority",
+	}))
+
+	notifier := &recordNotifier{}
+	notifiability := Notifiability{MaintenanceSchedule: schedule}
+	notifiability.AddNotifier(notifier)
+
+	maxNotifiability := notifiability.WithSession("max")
+	maxNotifiability.Notify("suppressed")
+	maxNotifiability.NotifyTo("alert", "suppressed")
+	assert.Empty(t, notifier.channels)
+
+	ftxNotifiability := notifiability.WithSession("ftx")
+	ftxNotifiability.NotifyTo("alert", "downgraded")
+	assert.Equal(t, []string{"low-priority"}, notifier.channels)
+
+	notifiability.NotifyTo("alert", "global")
+	assert.Equal(t, []string{"low-priority", "alert"}, notifier.channels)
+}
@@ -15,13 +15,19 @@ type MarketDataStore struct {
 	orderBook *types.StreamOrderBook
 
 	orderBookUpdateCallbacks []func(orderBook *types.StreamOrderBook)
+
+	// treeOrderBook is the skiplist copy of the order book, OrderBook() and the best prices are read from it
+	treeOrderBook *types.TreeOrderBook
+
+	treeOrderBookUpdateCallbacks []func(orderBook *types.TreeOrderBook)
 }
 
 func NewMarketDataStore(symbol string) *MarketDataStore {
 	return &MarketDataStore{
 		Symbol: symbol,
 
-		orderBook: types.NewStreamBook(symbol),
+		orderBook:     types.NewStreamBook(symbol),
+		treeOrderBook: types.NewTreeOrderBook(symbol, 0),
 
 		// KLineWindows stores all loaded klines per interval
 		KLineWindows: make(map[types.Interval]types.KLineWindow, len(types.SupportedIntervals)), // 12 interval, 1m,5m,15m,30m,1h,2h,4h,6h,12h,1d,3d,1w
@@ -32,8 +38,14 @@ func (store *MarketDataStore) SetKLineWindows(windows map[types.Interval]types.K
 	store.KLineWindows = windows
 }
 
+// OrderBook returns a copy of the order book
 func (store *MarketDataStore) OrderBook() types.OrderBook {
-	return store.orderBook.Copy()
+	return store.treeOrderBook.Get()
+}
+
+// BestBidAndAsk returns the best bid and ask without copying the order book, the zero volume means the side is empty
+func (store *MarketDataStore) BestBidAndAsk() (bid, ask types.PriceVolume) {
+	return store.treeOrderBook.BestBidAndAsk()
 }
 
 // KLinesOfInterval returns the kline window of the given interval
@@ -47,9 +59,16 @@ func (store *MarketDataStore) handleOrderBookUpdate(book types.OrderBook) {
 		return
 	}
 
+	// the updates are dropped until the next snapshot if the book is invalidated
+	if !store.treeOrderBook.IsReady() {
+		return
+	}
+
 	store.orderBook.Update(book)
+	store.treeOrderBook.Update(book)
 
 	store.EmitOrderBookUpdate(store.orderBook)
+	store.EmitTreeOrderBookUpdate(store.treeOrderBook)
 }
 
 func (store *MarketDataStore) handleOrderBookSnapshot(book types.OrderBook) {
@@ -58,14 +77,31 @@ func (store *MarketDataStore) handleOrderBookSnapshot(book types.OrderBook) {
 	}
 
 	store.orderBook.Load(book)
+	store.treeOrderBook.Load(book)
 }
 
 func (store *MarketDataStore) BindStream(stream types.Stream) {
 	stream.OnKLineClosed(store.handleKLineClosed)
 	stream.OnBookSnapshot(store.handleOrderBookSnapshot)
 	stream.OnBookUpdate(store.handleOrderBookUpdate)
+	stream.OnBookInvalidated(store.handleOrderBookInvalidated)
+}
 
-	store.orderBook.BindStream(stream)
+// IsOrderBookReady returns false if the order book is invalidated and the next snapshot is not received yet
+func (store *MarketDataStore) IsOrderBookReady() bool {
+	return store.treeOrderBook.IsReady()
+}
+
+func (store *MarketDataStore) handleOrderBookInvalidated(symbol string, reason types.BookInvalidReason) {
+	if symbol != store.Symbol {
+		return
+	}
+
+	store.orderBook.Invalidate()
+	store.treeOrderBook.Invalidate()
+
+	store.EmitOrderBookUpdate(store.orderBook)
+	store.EmitTreeOrderBookUpdate(store.treeOrderBook)
 }
 
 func (store *MarketDataStore) handleKLineClosed(kline types.KLine) {
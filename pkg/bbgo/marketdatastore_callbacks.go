@@ -25,3 +25,13 @@ func (store *MarketDataStore) EmitOrderBookUpdate(orderBook *types.StreamOrderBo
 		cb(orderBook)
 	}
 }
+
+func (store *MarketDataStore) OnTreeOrderBookUpdate(cb func(orderBook *types.TreeOrderBook)) {
+	store.treeOrderBookUpdateCallbacks = append(store.treeOrderBookUpdateCallbacks, cb)
+}
+
+func (store *MarketDataStore) EmitTreeOrderBookUpdate(orderBook *types.TreeOrderBook) {
+	for _, cb := range store.treeOrderBookUpdateCallbacks {
+		cb(orderBook)
+	}
+}
@@ -0,0 +1,60 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestMarketDataStore_OrderBook(t *testing.T) {
+	stream := &testSessionStream{}
+	store := NewMarketDataStore("BTCUSDT")
+	store.BindStream(stream)
+
+	var updates, treeUpdates int
+	store.OnOrderBookUpdate(func(orderBook *types.StreamOrderBook) {
+		updates++
+	})
+	store.OnTreeOrderBookUpdate(func(orderBook *types.TreeOrderBook) {
+		treeUpdates++
+	})
+
+	update := types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	}
+
+	// the updates before the snapshot are dropped
+	stream.EmitBookUpdate(update)
+	assert.False(t, store.IsOrderBookReady())
+	assert.Equal(t, 0, updates)
+
+	stream.EmitBookSnapshot(types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.5)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(102.0), Volume: fixedpoint.NewFromFloat(2.0)}},
+	})
+	stream.EmitBookUpdate(update)
+	assert.True(t, store.IsOrderBookReady())
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, 1, treeUpdates)
+
+	book := store.OrderBook()
+	assert.Len(t, book.Bids, 2)
+	assert.Len(t, book.Asks, 1)
+
+	bid, ask := store.BestBidAndAsk()
+	assert.Equal(t, fixedpoint.NewFromFloat(101.0), bid.Price)
+	assert.Equal(t, fixedpoint.NewFromFloat(102.0), ask.Price)
+
+	stream.EmitBookInvalidated("BTCUSDT", types.BookInvalidReasonSequenceGap)
+	assert.False(t, store.IsOrderBookReady())
+	assert.Equal(t, 2, updates)
+	assert.Equal(t, 2, treeUpdates)
+
+	bid, _ = store.BestBidAndAsk()
+	assert.Equal(t, fixedpoint.Value(0), bid.Volume)
+}
@@ -1,5 +1,11 @@
 package bbgo
 
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
 type Notifier interface {
 	NotifyTo(channel, format string, args ...interface{})
 	Notify(format string, args ...interface{})
@@ -16,6 +22,19 @@ type Notifiability struct {
 	SessionChannelRouter *PatternChannelRouter `json:"-"`
 	SymbolChannelRouter  *PatternChannelRouter `json:"-"`
 	ObjectChannelRouter  *ObjectChannelRouter  `json:"-"`
+
+	// MaintenanceSchedule suppresses or downgrades the notifications during the maintenance windows
+	MaintenanceSchedule *MaintenanceSchedule `json:"-"`
+
+	// session is the session name used for looking up the session maintenance windows
+	session string
+}
+
+// WithSession returns a copy of the notifiability that applies the maintenance windows of the given session
+func (m *Notifiability) WithSession(session string) Notifiability {
+	n := *m
+	n.session = session
+	return n
 }
 
 // RouteSession routes symbol name to channel
@@ -47,13 +66,52 @@ func (m *Notifiability) AddNotifier(notifier Notifier) {
 	m.notifiers = append(m.notifiers, notifier)
 }
 
+// maintenanceChannel checks the maintenance windows, if the notification is suppressed, ok will be false.
+// if the notification is downgraded, the downgrade channel will be returned.
+func (m *Notifiability) maintenanceChannel(format string) (channel string, ok bool) {
+	window, found := m.MaintenanceSchedule.Lookup(m.session, time.Now())
+	if !found {
+		return "", true
+	}
+
+	if window.Mode == MaintenanceModeDowngrade {
+		return window.Channel, true
+	}
+
+	log.Debugf("notification suppressed by %s: %s", window, format)
+	return "", false
+}
+
 func (m *Notifiability) Notify(format string, args ...interface{}) {
+	channel, ok := m.maintenanceChannel(format)
+	if !ok {
+		return
+	}
+
+	if len(channel) > 0 {
+		m.notifyTo(channel, format, args...)
+		return
+	}
+
 	for _, n := range m.notifiers {
 		n.Notify(format, args...)
 	}
 }
 
 func (m *Notifiability) NotifyTo(channel, format string, args ...interface{}) {
+	downgradeChannel, ok := m.maintenanceChannel(format)
+	if !ok {
+		return
+	}
+
+	if len(downgradeChannel) > 0 {
+		channel = downgradeChannel
+	}
+
+	m.notifyTo(channel, format, args...)
+}
+
+func (m *Notifiability) notifyTo(channel, format string, args ...interface{}) {
 	for _, n := range m.notifiers {
 		n.NotifyTo(channel, format, args...)
 	}
@@ -0,0 +1,39 @@
+package bbgo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/ycdesu/spreaddog/pkg/exchange"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// AmendOrder changes the price and the quantity of the open order, the quantity includes the executed quantity. It uses
+// ExchangeOrderAmendService if the exchange supports it, otherwise the order is canceled and placed again by
+// exchange.CancelReplaceOrder. The order in the active order book is replaced by the new order if the book is given,
+// and it's removed if the order is canceled but the replacing order is not placed.
+func AmendOrder(ctx context.Context, ex types.Exchange, book *LocalActiveOrderBook, order types.Order, price, quantity fixedpoint.Value) (*types.Order, error) {
+	var newOrder *types.Order
+	var err error
+	if service, ok := ex.(types.ExchangeOrderAmendService); ok {
+		newOrder, err = service.AmendOrder(ctx, order, price, quantity)
+	} else {
+		newOrder, err = exchange.CancelReplaceOrder(ctx, ex, order, price, quantity)
+	}
+
+	if err != nil {
+		// the canceled order is not replaced, it's not active anymore
+		if book != nil && errors.Is(err, exchange.ErrReplacementNotPlaced) {
+			book.Remove(order)
+		}
+		return nil, err
+	}
+
+	if book != nil {
+		book.Replace(order, *newOrder)
+	}
+
+	return newOrder, nil
+}
@@ -0,0 +1,123 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+type amendExchange struct {
+	cancelOrdersExchange
+
+	amendedOrders []types.Order
+}
+
+func (e *amendExchange) AmendOrder(ctx context.Context, order types.Order, price, quantity fixedpoint.Value) (*types.Order, error) {
+	e.amendedOrders = append(e.amendedOrders, order)
+
+	newOrder := order
+	newOrder.OrderID = order.OrderID + 1
+	newOrder.Price = price
+	newOrder.Quantity = quantity
+	return &newOrder, nil
+}
+
+type submitOrdersExchange struct {
+	cancelOrdersExchange
+
+	submitErr error
+}
+
+func (e *submitOrdersExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	if e.submitErr != nil {
+		return nil, e.submitErr
+	}
+	for _, so := range orders {
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: so, OrderID: 100, Status: types.OrderStatusNew})
+	}
+	return createdOrders, nil
+}
+
+func TestAmendOrder(t *testing.T) {
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit},
+		OrderID:     1,
+		Status:      types.OrderStatusNew,
+	}
+
+	t.Run("amend service", func(t *testing.T) {
+		book := NewLocalActiveOrderBook()
+		book.Add(order)
+
+		ex := &amendExchange{}
+		newOrder, err := AmendOrder(context.Background(), ex, book, order, fixedpoint.NewFromFloat(10.0), fixedpoint.NewFromFloat(1.0))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), newOrder.OrderID)
+		assert.Len(t, ex.amendedOrders, 1)
+		assert.Empty(t, ex.canceledOrders)
+
+		assert.False(t, book.Bids.Exists(1))
+		assert.True(t, book.Bids.Exists(2))
+	})
+
+	t.Run("cancel and replace", func(t *testing.T) {
+		book := NewLocalActiveOrderBook()
+		book.Add(order)
+
+		ex := &submitOrdersExchange{}
+		newOrder, err := AmendOrder(context.Background(), ex, book, order, fixedpoint.NewFromFloat(10.0), fixedpoint.NewFromFloat(1.0))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), newOrder.OrderID)
+		assert.Len(t, ex.canceledOrders, 1)
+
+		assert.Equal(t, []uint64{100}, book.Bids.IDs())
+	})
+
+	t.Run("canceled but not replaced", func(t *testing.T) {
+		book := NewLocalActiveOrderBook()
+		book.Add(order)
+
+		ex := &submitOrdersExchange{submitErr: errors.New("submit error")}
+		_, err := AmendOrder(context.Background(), ex, book, order, fixedpoint.NewFromFloat(10.0), fixedpoint.NewFromFloat(1.0))
+		assert.Error(t, err)
+		assert.Equal(t, 0, book.NumOfBids())
+	})
+}
+
+func TestLocalActiveOrderBook_Replace(t *testing.T) {
+	oldOrder := types.Order{SubmitOrder: types.SubmitOrder{Side: types.SideTypeSell}, OrderID: 1, Status: types.OrderStatusNew}
+	newOrder := types.Order{SubmitOrder: types.SubmitOrder{Side: types.SideTypeSell}, OrderID: 2, Status: types.OrderStatusNew}
+
+	book := NewLocalActiveOrderBook()
+	var filledOrders []types.Order
+	book.OnFilled(func(o types.Order) {
+		filledOrders = append(filledOrders, o)
+	})
+
+	book.Add(oldOrder)
+	book.Replace(oldOrder, newOrder)
+	assert.Equal(t, []uint64{2}, book.Asks.IDs())
+
+	// the cancel update of the replaced order arrives after the replacement
+	canceledOrder := oldOrder
+	canceledOrder.Status = types.OrderStatusCanceled
+	book.orderUpdateHandler(canceledOrder)
+	assert.Equal(t, []uint64{2}, book.Asks.IDs())
+
+	filledOrder := newOrder
+	filledOrder.Status = types.OrderStatusFilled
+	book.orderUpdateHandler(filledOrder)
+	assert.Equal(t, 0, book.NumOfAsks())
+	assert.Equal(t, []types.Order{filledOrder}, filledOrders)
+
+	// the new order is filled immediately
+	book.Add(oldOrder)
+	book.Replace(oldOrder, filledOrder)
+	assert.Equal(t, 0, book.NumOfAsks())
+	assert.Len(t, filledOrders, 2)
+}
@@ -0,0 +1,64 @@
+package bbgo
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// CancelOrdersByGroup cancels the orders of the order group. It cancels by the group id if the exchange supports
+// ExchangeCancelAllService, so that the orders missing from the local active order book are also canceled, and then
+// cancels the given local orders which are not canceled by the group, e.g., the orders placed with an explicit client
+// order id. It falls back to cancel the given local orders if the group can not be canceled.
+func CancelOrdersByGroup(ctx context.Context, exchange types.Exchange, groupID uint32, orders ...types.Order) error {
+	if service, ok := exchange.(types.ExchangeCancelAllService); ok && groupID > 0 {
+		canceledOrders, err := service.CancelOrdersByGroupID(ctx, groupID)
+		if err == nil {
+			log.Infof("canceled %d orders of group %d", len(canceledOrders), groupID)
+			return cancelLeftoverOrders(ctx, exchange, excludeOrders(orders, canceledOrders))
+		}
+
+		log.WithError(err).Errorf("can not cancel orders by group id %d, canceling the local orders...", groupID)
+	}
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	return exchange.CancelOrders(ctx, orders...)
+}
+
+// cancelLeftoverOrders cancels the local orders which are not canceled by the group. The local active order book
+// might be behind the exchange, so the orders already filled or canceled are not treated as errors.
+func cancelLeftoverOrders(ctx context.Context, exchange types.Exchange, orders []types.Order) (err2 error) {
+	for _, o := range orders {
+		if err := exchange.CancelOrders(ctx, o); err != nil {
+			if types.IsOrderNotFoundError(err) {
+				log.Infof("order %d is not found, it might be filled or canceled already", o.OrderID)
+				continue
+			}
+
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+// excludeOrders returns the orders whose order id is not in the excluded orders
+func excludeOrders(orders, excluded []types.Order) (remaining []types.Order) {
+	excludedIDs := make(map[uint64]struct{}, len(excluded))
+	for _, o := range excluded {
+		excludedIDs[o.OrderID] = struct{}{}
+	}
+
+	for _, o := range orders {
+		if _, ok := excludedIDs[o.OrderID]; !ok {
+			remaining = append(remaining, o)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,119 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+type cancelOrdersExchange struct {
+	types.Exchange
+
+	canceledOrders []types.Order
+
+	// cancelErrs are the errors returned by canceling the orders
+	cancelErrs map[uint64]error
+}
+
+func (e *cancelOrdersExchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	e.canceledOrders = append(e.canceledOrders, orders...)
+	for _, o := range orders {
+		if err, ok := e.cancelErrs[o.OrderID]; ok {
+			err2 = err
+		}
+	}
+	return err2
+}
+
+type cancelAllExchange struct {
+	cancelOrdersExchange
+
+	groupErr        error
+	groupOrders     []types.Order
+	canceledGroupID uint32
+}
+
+func (e *cancelAllExchange) CancelAllOrders(ctx context.Context) ([]types.Order, error) {
+	return nil, nil
+}
+
+func (e *cancelAllExchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	return nil, nil
+}
+
+func (e *cancelAllExchange) CancelOrdersByGroupID(ctx context.Context, groupID uint32) ([]types.Order, error) {
+	e.canceledGroupID = groupID
+	if e.groupErr != nil {
+		return nil, e.groupErr
+	}
+	return e.groupOrders, nil
+}
+
+func TestCancelOrdersByGroup(t *testing.T) {
+	orders := []types.Order{{OrderID: 1}, {OrderID: 2}}
+
+	t.Run("cancel by group id", func(t *testing.T) {
+		ex := &cancelAllExchange{groupOrders: orders}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...))
+		assert.Equal(t, uint32(100), ex.canceledGroupID)
+		assert.Empty(t, ex.canceledOrders)
+	})
+
+	t.Run("cancel the local orders not in the group", func(t *testing.T) {
+		// e.g., the orders placed before the group client order id or with an explicit client order id
+		ex := &cancelAllExchange{groupOrders: orders[:1]}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...))
+		assert.Equal(t, uint32(100), ex.canceledGroupID)
+		assert.Equal(t, orders[1:], ex.canceledOrders)
+
+		ex = &cancelAllExchange{}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...))
+		assert.Equal(t, orders, ex.canceledOrders)
+	})
+
+	t.Run("ignore the local orders not found after the group is canceled", func(t *testing.T) {
+		// e.g., the local orders are filled or canceled but the active order book is not updated yet
+		notFoundErr := types.NewExchangeError(types.ErrOrderNotFound, errors.New("Unknown order sent."))
+		ex := &cancelAllExchange{cancelOrdersExchange: cancelOrdersExchange{
+			cancelErrs: map[uint64]error{1: notFoundErr, 2: notFoundErr},
+		}}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...))
+		assert.Equal(t, orders, ex.canceledOrders)
+
+		// the other errors are still returned
+		ex = &cancelAllExchange{cancelOrdersExchange: cancelOrdersExchange{
+			cancelErrs: map[uint64]error{1: notFoundErr, 2: errors.New("unavailable")},
+		}}
+		assert.EqualError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...), "unavailable")
+
+		// the not found errors are returned if the group can not be canceled
+		ex = &cancelAllExchange{groupErr: errors.New("unavailable"), cancelOrdersExchange: cancelOrdersExchange{
+			cancelErrs: map[uint64]error{1: notFoundErr},
+		}}
+		err := CancelOrdersByGroup(context.Background(), ex, 100, orders...)
+		assert.True(t, types.IsOrderNotFoundError(err))
+	})
+
+	t.Run("fallback on group cancel error", func(t *testing.T) {
+		ex := &cancelAllExchange{groupErr: errors.New("unavailable")}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...))
+		assert.Equal(t, orders, ex.canceledOrders)
+	})
+
+	t.Run("no group id", func(t *testing.T) {
+		ex := &cancelAllExchange{}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 0, orders...))
+		assert.Equal(t, uint32(0), ex.canceledGroupID)
+		assert.Equal(t, orders, ex.canceledOrders)
+	})
+
+	t.Run("exchange without cancel all service", func(t *testing.T) {
+		ex := &cancelOrdersExchange{}
+		assert.NoError(t, CancelOrdersByGroup(context.Background(), ex, 100, orders...))
+		assert.Equal(t, orders, ex.canceledOrders)
+	})
+}
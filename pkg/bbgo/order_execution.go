@@ -100,6 +100,10 @@ type BasicRiskController struct {
 	MinQuoteBalance     fixedpoint.Value `json:"minQuoteBalance,omitempty" yaml:"minQuoteBalance,omitempty"`
 	MaxBaseAssetBalance fixedpoint.Value `json:"maxBaseAssetBalance,omitempty" yaml:"maxBaseAssetBalance,omitempty"`
 	MinBaseAssetBalance fixedpoint.Value `json:"minBaseAssetBalance,omitempty" yaml:"minBaseAssetBalance,omitempty"`
+
+	// MaxSlippageBps rejects the market order if its average execution price on the order book is worse than the
+	// best price by more than the bps, the check is skipped while the order book is not ready.
+	MaxSlippageBps float64 `json:"maxSlippageBps,omitempty" yaml:"maxSlippageBps,omitempty"`
 }
 
 // ProcessOrders filters and modifies the submit order objects by:
@@ -128,8 +132,9 @@ func (c *BasicRiskController) ProcessOrders(session *ExchangeSession, orders ...
 			continue
 		}
 
-		price := order.Price
-		quantity := order.Quantity
+		// the market filters are in float64, so the quantity is adjusted in float64
+		price := order.Price.Float64()
+		quantity := order.Quantity.Float64()
 		switch order.Type {
 		case types.OrderTypeMarket:
 			price = lastPrice
@@ -271,12 +276,40 @@ func (c *BasicRiskController) ProcessOrders(session *ExchangeSession, orders ...
 			accumulativeBaseSellQuantity += quantity
 		}
 
+		if order.Type == types.OrderTypeMarket && c.MaxSlippageBps > 0 {
+			if err := c.checkSlippage(session, order.Symbol, order.Side, quantity); err != nil {
+				addError(errors.Wrapf(err, "order: %s", order.String()))
+				continue
+			}
+		}
+
 		// update quantity and format the order
-		order.Quantity = quantity
+		order.Quantity = fixedpoint.NewFromFloat(quantity)
 		outOrders = append(outOrders, order)
 	}
 
-	return outOrders, nil
+	return outOrders, errs
+}
+
+// checkSlippage walks the order book of the session with the market order quantity
+func (c *BasicRiskController) checkSlippage(session *ExchangeSession, symbol string, side types.SideType, quantity float64) error {
+	store, ok := session.MarketDataStore(symbol)
+	if !ok || !store.IsOrderBookReady() {
+		return nil
+	}
+
+	book := store.OrderBook()
+	result, ok := book.ExecutionPriceByQuantity(side, fixedpoint.NewFromFloat(quantity))
+	if !ok {
+		return errors.Wrapf(ErrSlippageTooHigh, "the order book of %s is not deep enough for quantity %f", symbol, quantity)
+	}
+
+	if result.SlippageBps > c.MaxSlippageBps {
+		return errors.Wrapf(ErrSlippageTooHigh, "the slippage of %s is %.2f bps > %.2f bps, average price %f",
+			symbol, result.SlippageBps, c.MaxSlippageBps, result.AveragePrice.Float64())
+	}
+
+	return nil
 }
 
 func formatOrders(session *ExchangeSession, orders []types.SubmitOrder) (formattedOrders []types.SubmitOrder, err error) {
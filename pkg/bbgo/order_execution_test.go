@@ -0,0 +1,144 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestBasicRiskController_checkSlippage(t *testing.T) {
+	book := types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(99.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(98.0), Volume: fixedpoint.NewFromFloat(1.0)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.0)},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		maxSlippageBps float64
+		side           types.SideType
+		quantity       float64
+		// bookReady is false if the snapshot is not loaded yet
+		bookReady bool
+		rejected  bool
+	}{
+		{
+			name:           "best level",
+			maxSlippageBps: 10,
+			side:           types.SideTypeBuy,
+			quantity:       1,
+			bookReady:      true,
+		},
+		{
+			name:           "buy slippage 50 bps",
+			maxSlippageBps: 10,
+			side:           types.SideTypeBuy,
+			quantity:       2,
+			bookReady:      true,
+			rejected:       true,
+		},
+		{
+			name:           "buy slippage within the limit",
+			maxSlippageBps: 60,
+			side:           types.SideTypeBuy,
+			quantity:       2,
+			bookReady:      true,
+		},
+		{
+			name:           "sell slippage 50.5 bps",
+			maxSlippageBps: 50,
+			side:           types.SideTypeSell,
+			quantity:       2,
+			bookReady:      true,
+			rejected:       true,
+		},
+		{
+			name:           "book is not deep enough",
+			maxSlippageBps: 1000,
+			side:           types.SideTypeBuy,
+			quantity:       3,
+			bookReady:      true,
+			rejected:       true,
+		},
+		{
+			name:           "book is not ready",
+			maxSlippageBps: 10,
+			side:           types.SideTypeBuy,
+			quantity:       2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := newTestSession(&testSessionStream{})
+			store := session.initMarketDataStore("BTCUSDT")
+			if test.bookReady {
+				store.handleOrderBookSnapshot(book)
+			}
+
+			controller := &BasicRiskController{MaxSlippageBps: test.maxSlippageBps}
+			err := controller.checkSlippage(session, "BTCUSDT", test.side, test.quantity)
+			if test.rejected {
+				assert.True(t, errors.Is(err, ErrSlippageTooHigh), "unexpected error: %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBasicRiskController_ProcessOrders_MaxSlippageBps(t *testing.T) {
+	session := newTestSession(&testSessionStream{})
+	session.Account = &types.Account{}
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+	session.markets = map[string]types.Market{
+		"BTCUSDT": {
+			Symbol:        "BTCUSDT",
+			BaseCurrency:  "BTC",
+			QuoteCurrency: "USDT",
+			MinAmount:     10,
+			MinNotional:   10,
+			MinQuantity:   0.001,
+		},
+	}
+	session.lastPrices = map[string]float64{"BTCUSDT": 100}
+
+	store := session.initMarketDataStore("BTCUSDT")
+	store.handleOrderBookSnapshot(types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(99.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.0)},
+		},
+	})
+
+	controller := &BasicRiskController{MaxSlippageBps: 10}
+	orders, errs := controller.ProcessOrders(session,
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: fixedpoint.NewFromFloat(0.5)},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: fixedpoint.NewFromFloat(2.0)},
+		// the limit orders are not checked
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: fixedpoint.NewFromFloat(100.0), Quantity: fixedpoint.NewFromFloat(2.0)},
+	)
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(0.5), orders[0].Quantity)
+		assert.Equal(t, types.OrderTypeLimit, orders[1].Type)
+	}
+
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], ErrSlippageTooHigh))
+	}
+}
@@ -11,6 +11,8 @@ var (
 	ErrAssetBalanceLevelTooLow  = errors.New("asset balance level too low")
 	ErrInsufficientAssetBalance = errors.New("insufficient asset balance")
 	ErrAssetBalanceLevelTooHigh = errors.New("asset balance level too high")
+
+	ErrSlippageTooHigh = errors.New("slippage too high")
 )
 
 // adjustQuantityByMinAmount adjusts the quantity to make the amount greater than the given minAmount
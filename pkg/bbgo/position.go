@@ -46,10 +46,10 @@ func (p *Position) AddTrades(trades []types.Trade) (fixedpoint.Value, bool) {
 }
 
 func (p *Position) AddTrade(t types.Trade) (fixedpoint.Value, bool) {
-	price := fixedpoint.NewFromFloat(t.Price)
-	quantity := fixedpoint.NewFromFloat(t.Quantity)
-	quoteQuantity := fixedpoint.NewFromFloat(t.QuoteQuantity)
-	fee := fixedpoint.NewFromFloat(t.Fee)
+	price := t.Price
+	quantity := t.Quantity
+	quoteQuantity := t.QuoteQuantity
+	fee := t.Fee
 
 	switch t.FeeCurrency {
 
@@ -82,7 +82,10 @@ func (p *Position) AddTrade(t types.Trade) (fixedpoint.Value, bool) {
 			}
 		}
 
-		p.AverageCost = (p.AverageCost.Mul(p.Base) + quoteQuantity).Div(p.Base + quantity)
+		// the zero quantity trade doesn't change the average cost
+		if p.Base+quantity != 0 {
+			p.AverageCost = (p.AverageCost.Mul(p.Base) + quoteQuantity).Div(p.Base + quantity)
+		}
 		p.Base += quantity
 		p.Quote -= quoteQuantity
 
@@ -105,7 +108,9 @@ func (p *Position) AddTrade(t types.Trade) (fixedpoint.Value, bool) {
 		}
 
 		// handling short position
-		p.AverageCost = (p.AverageCost.Mul(-p.Base) + quoteQuantity).Div(-p.Base + quantity)
+		if -p.Base+quantity != 0 {
+			p.AverageCost = (p.AverageCost.Mul(-p.Base) + quoteQuantity).Div(-p.Base + quantity)
+		}
 		p.Base -= quantity
 		p.Quote += quoteQuantity
 
@@ -24,10 +24,10 @@ func TestPosition(t *testing.T) {
 			trades: []types.Trade{
 				{
 					Side:          types.SideTypeBuy,
-					Price:         1000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 1000.0 * 0.01,
-					Fee:           0.01 * 0.05 * 0.01, // 0.05%
+					Price:         fixedpoint.NewFromFloat(1000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.01),
+					Fee:           fixedpoint.NewFromFloat(0.01 * 0.05 * 0.01), // 0.05%
 					FeeCurrency:   "BTC",
 				},
 			},
@@ -41,10 +41,10 @@ func TestPosition(t *testing.T) {
 			trades: []types.Trade{
 				{
 					Side:          types.SideTypeSell,
-					Price:         1000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 1000.0 * 0.01,
-					Fee:           (1000.0 * 0.01) * feeRate, // 0.05%
+					Price:         fixedpoint.NewFromFloat(1000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.01),
+					Fee:           fixedpoint.NewFromFloat((1000.0 * 0.01) * feeRate), // 0.05%
 					FeeCurrency:   "USDT",
 				},
 			},
@@ -53,20 +53,35 @@ func TestPosition(t *testing.T) {
 			expectedQuote:       fixedpoint.NewFromFloat(0 + 1000.0*0.01*(1.0-feeRate)),
 			expectedProfit:      fixedpoint.NewFromFloat(0.0),
 		},
+		{
+			name: "zero quantity",
+			trades: []types.Trade{
+				{
+					Side:          types.SideTypeBuy,
+					Price:         fixedpoint.NewFromFloat(1000.0),
+					Quantity:      0,
+					QuoteQuantity: 0,
+				},
+			},
+			expectedAverageCost: 0,
+			expectedBase:        0,
+			expectedQuote:       0,
+			expectedProfit:      0,
+		},
 		{
 			name: "long",
 			trades: []types.Trade{
 				{
 					Side:          types.SideTypeBuy,
-					Price:         1000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 1000.0 * 0.01,
+					Price:         fixedpoint.NewFromFloat(1000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.01),
 				},
 				{
 					Side:          types.SideTypeBuy,
-					Price:         2000.0,
-					Quantity:      0.03,
-					QuoteQuantity: 2000.0 * 0.03,
+					Price:         fixedpoint.NewFromFloat(2000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.03),
+					QuoteQuantity: fixedpoint.NewFromFloat(2000.0 * 0.03),
 				},
 			},
 			expectedAverageCost: fixedpoint.NewFromFloat((1000.0*0.01 + 2000.0*0.03) / 0.04),
@@ -80,21 +95,21 @@ func TestPosition(t *testing.T) {
 			trades: []types.Trade{
 				{
 					Side:          types.SideTypeBuy,
-					Price:         1000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 1000.0 * 0.01,
+					Price:         fixedpoint.NewFromFloat(1000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.01),
 				},
 				{
 					Side:          types.SideTypeBuy,
-					Price:         2000.0,
-					Quantity:      0.03,
-					QuoteQuantity: 2000.0 * 0.03,
+					Price:         fixedpoint.NewFromFloat(2000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.03),
+					QuoteQuantity: fixedpoint.NewFromFloat(2000.0 * 0.03),
 				},
 				{
 					Side:          types.SideTypeSell,
-					Price:         3000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 3000.0 * 0.01,
+					Price:         fixedpoint.NewFromFloat(3000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(3000.0 * 0.01),
 				},
 			},
 			expectedAverageCost: fixedpoint.NewFromFloat((1000.0*0.01 + 2000.0*0.03) / 0.04),
@@ -108,21 +123,21 @@ func TestPosition(t *testing.T) {
 			trades: []types.Trade{
 				{
 					Side:          types.SideTypeBuy,
-					Price:         1000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 1000.0 * 0.01,
+					Price:         fixedpoint.NewFromFloat(1000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.01),
 				},
 				{
 					Side:          types.SideTypeBuy,
-					Price:         2000.0,
-					Quantity:      0.03,
-					QuoteQuantity: 2000.0 * 0.03,
+					Price:         fixedpoint.NewFromFloat(2000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.03),
+					QuoteQuantity: fixedpoint.NewFromFloat(2000.0 * 0.03),
 				},
 				{
 					Side:          types.SideTypeSell,
-					Price:         3000.0,
-					Quantity:      0.10,
-					QuoteQuantity: 3000.0 * 0.10,
+					Price:         fixedpoint.NewFromFloat(3000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.10),
+					QuoteQuantity: fixedpoint.NewFromFloat(3000.0 * 0.10),
 				},
 			},
 
@@ -137,15 +152,15 @@ func TestPosition(t *testing.T) {
 			trades: []types.Trade{
 				{
 					Side:          types.SideTypeSell,
-					Price:         2000.0,
-					Quantity:      0.01,
-					QuoteQuantity: 2000.0 * 0.01,
+					Price:         fixedpoint.NewFromFloat(2000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.01),
+					QuoteQuantity: fixedpoint.NewFromFloat(2000.0 * 0.01),
 				},
 				{
 					Side:          types.SideTypeSell,
-					Price:         3000.0,
-					Quantity:      0.03,
-					QuoteQuantity: 3000.0 * 0.03,
+					Price:         fixedpoint.NewFromFloat(3000.0),
+					Quantity:      fixedpoint.NewFromFloat(0.03),
+					QuoteQuantity: fixedpoint.NewFromFloat(3000.0 * 0.03),
 				},
 			},
 
@@ -6,6 +6,7 @@ import (
 	"github.com/robfig/cron/v3"
 
 	"github.com/ycdesu/spreaddog/pkg/accounting/pnl"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 )
 
 type PnLReporter interface {
@@ -74,7 +75,7 @@ func (reporter *AverageCostPnLReporter) Run() {
 		}
 
 		for _, symbol := range reporter.Symbols {
-			report := calculator.Calculate(symbol, session.Trades[symbol].Copy(), session.lastPrices[symbol])
+			report := calculator.Calculate(symbol, session.Trades[symbol].Copy(), fixedpoint.NewFromFloat(session.lastPrices[symbol]))
 			report.Print()
 		}
 	}
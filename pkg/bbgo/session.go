@@ -3,7 +3,9 @@ package bbgo
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -111,6 +113,7 @@ type ExchangeSession struct {
 	EnvVarPrefix string `json:"envVarPrefix" yaml:"envVarPrefix"`
 	Key          string `json:"key,omitempty" yaml:"key,omitempty"`
 	Secret       string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Passphrase   string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
 	SubAccount   string `json:"subAccount,omitempty" yaml:"subAccount,omitempty"`
 
 	PublicOnly           bool   `json:"publicOnly,omitempty" yaml:"publicOnly"`
@@ -118,6 +121,9 @@ type ExchangeSession struct {
 	IsolatedMargin       bool   `json:"isolatedMargin,omitempty" yaml:"isolatedMargin,omitempty"`
 	IsolatedMarginSymbol string `json:"isolatedMarginSymbol,omitempty" yaml:"isolatedMarginSymbol,omitempty"`
 
+	// Futures switches the session to the futures markets of the exchange, e.g., the USDⓈ-M futures of binance
+	Futures bool `json:"futures,omitempty" yaml:"futures,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -130,8 +136,18 @@ type ExchangeSession struct {
 	// Stream is the connection stream of the exchange
 	Stream types.Stream `json:"-" yaml:"-"`
 
+	// streamMonitor tracks the connection status of the stream
+	streamMonitor *types.StreamMonitor
+
 	Subscriptions map[types.Subscription]types.Subscription `json:"-" yaml:"-"`
 
+	// subscriptionMutex protects Subscriptions, usedSymbols and streamConnected, the subscriptions can be changed after
+	// connecting
+	subscriptionMutex sync.Mutex
+
+	// streamConnected is set after the stream is connected, the subscriptions are forwarded to the stream directly after it
+	streamConnected bool
+
 	Exchange types.Exchange `json:"-" yaml:"-"`
 
 	// markets defines market configuration of a symbol
@@ -147,6 +163,10 @@ type ExchangeSession struct {
 	// map: symbol -> []trade
 	Trades map[string]*types.TradeSlice `json:"-" yaml:"-"`
 
+	// marketDataStoreMutex protects marketDataStores and standardIndicatorSets, the stores of the symbols subscribed
+	// after connecting are created on subscribe
+	marketDataStoreMutex sync.RWMutex
+
 	// marketDataStores contains the market data store of each market
 	marketDataStores map[string]*MarketDataStore
 
@@ -166,7 +186,7 @@ type ExchangeSession struct {
 }
 
 func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
-	return &ExchangeSession{
+	session := &ExchangeSession{
 		Notifiability: Notifiability{
 			SymbolChannelRouter:  NewPatternChannelRouter(nil),
 			SessionChannelRouter: NewPatternChannelRouter(nil),
@@ -190,7 +210,11 @@ func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
 		usedSymbols:           make(map[string]struct{}),
 		initializedSymbols:    make(map[string]struct{}),
 		logger:                log.WithField("session", name),
+		streamMonitor:         types.NewStreamMonitor(),
 	}
+
+	session.streamMonitor.BindStream(session.Stream)
+	return session
 }
 
 func (session *ExchangeSession) Init(ctx context.Context, environ *Environment) error {
@@ -261,7 +285,14 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 
 // InitSymbols uses usedSymbols to initialize the related data structure
 func (session *ExchangeSession) InitSymbols(ctx context.Context, environ *Environment) error {
+	session.subscriptionMutex.Lock()
+	var symbols []string
 	for symbol := range session.usedSymbols {
+		symbols = append(symbols, symbol)
+	}
+	session.subscriptionMutex.Unlock()
+
+	for _, symbol := range symbols {
 		// skip initialized symbols
 		if _, ok := session.initializedSymbols[symbol]; ok {
 			continue
@@ -327,12 +358,7 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 	orderStore.BindStream(session.Stream)
 	session.orderStores[symbol] = orderStore
 
-	marketDataStore := NewMarketDataStore(symbol)
-	marketDataStore.BindStream(session.Stream)
-	session.marketDataStores[symbol] = marketDataStore
-
-	standardIndicatorSet := NewStandardIndicatorSet(symbol, marketDataStore)
-	session.standardIndicatorSets[symbol] = standardIndicatorSet
+	marketDataStore := session.initMarketDataStore(symbol)
 
 	// used kline intervals by the given symbol
 	var usedKLineIntervals = map[types.Interval]struct{}{}
@@ -393,7 +419,27 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 	return nil
 }
 
+// initMarketDataStore returns the market data store of the symbol, the store and the standard indicator set are created
+// if they don't exist
+func (session *ExchangeSession) initMarketDataStore(symbol string) *MarketDataStore {
+	session.marketDataStoreMutex.Lock()
+	defer session.marketDataStoreMutex.Unlock()
+
+	if store, ok := session.marketDataStores[symbol]; ok {
+		return store
+	}
+
+	marketDataStore := NewMarketDataStore(symbol)
+	marketDataStore.BindStream(session.Stream)
+	session.marketDataStores[symbol] = marketDataStore
+	session.standardIndicatorSets[symbol] = NewStandardIndicatorSet(symbol, marketDataStore)
+	return marketDataStore
+}
+
 func (session *ExchangeSession) StandardIndicatorSet(symbol string) (*StandardIndicatorSet, bool) {
+	session.marketDataStoreMutex.RLock()
+	defer session.marketDataStoreMutex.RUnlock()
+
 	set, ok := session.standardIndicatorSets[symbol]
 	return set, ok
 }
@@ -409,6 +455,9 @@ func (session *ExchangeSession) Positions() map[string]*Position {
 
 // MarketDataStore returns the market data store of a symbol
 func (session *ExchangeSession) MarketDataStore(symbol string) (s *MarketDataStore, ok bool) {
+	session.marketDataStoreMutex.RLock()
+	defer session.marketDataStoreMutex.RUnlock()
+
 	s, ok = session.marketDataStores[symbol]
 	return s, ok
 }
@@ -445,7 +494,37 @@ func (session *ExchangeSession) OrderStores() map[string]*OrderStore {
 	return session.orderStores
 }
 
-// Subscribe save the subscription info, later it will be assigned to the stream
+// StreamStatus returns the connection status and the subscriptions of the session stream
+func (session *ExchangeSession) StreamStatus() types.StreamStatus {
+	status := session.streamMonitor.Status()
+
+	session.subscriptionMutex.Lock()
+	status.Subscriptions = make([]types.Subscription, 0, len(session.Subscriptions))
+	for _, sub := range session.Subscriptions {
+		status.Subscriptions = append(status.Subscriptions, sub)
+	}
+	session.subscriptionMutex.Unlock()
+
+	if sharded, ok := session.Stream.(*types.ShardedStream); ok {
+		status.Shards = sharded.ShardStatuses()
+	}
+
+	sort.Slice(status.Subscriptions, func(i, j int) bool {
+		a, b := status.Subscriptions[i], status.Subscriptions[j]
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.Options.String() < b.Options.String()
+	})
+
+	return status
+}
+
+// Subscribe save the subscription info, later it will be assigned to the stream.
+// If the stream is already connected, the subscription is sent to the stream immediately.
 func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) *ExchangeSession {
 	if channel == types.KLineChannel && len(options.Interval) == 0 {
 		panic("subscription interval for kline can not be empty")
@@ -457,12 +536,63 @@ func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string,
 		Options: options,
 	}
 
+	session.subscriptionMutex.Lock()
+	defer session.subscriptionMutex.Unlock()
+
 	// add to the loaded symbol table
 	session.usedSymbols[symbol] = struct{}{}
+
+	if _, ok := session.Subscriptions[sub]; ok {
+		return session
+	}
+
 	session.Subscriptions[sub] = sub
+	if session.streamConnected {
+		// the symbols subscribed after connecting are not initialized by InitSymbols, create the market data store so
+		// that the strategies can use it
+		session.initMarketDataStore(symbol)
+		session.Stream.Subscribe(channel, symbol, options)
+	}
+	return session
+}
+
+// Unsubscribe removes the subscriptions of the channel and the symbol, the stream is unsubscribed if it's connected
+func (session *ExchangeSession) Unsubscribe(channel types.Channel, symbol string) *ExchangeSession {
+	session.subscriptionMutex.Lock()
+	defer session.subscriptionMutex.Unlock()
+
+	for sub := range session.Subscriptions {
+		if sub.Channel == channel && sub.Symbol == symbol {
+			delete(session.Subscriptions, sub)
+		}
+	}
+
+	if session.streamConnected {
+		session.Stream.Unsubscribe(channel, symbol)
+	}
 	return session
 }
 
+// connectStream subscribes the saved subscriptions and connects the stream
+func (session *ExchangeSession) connectStream(ctx context.Context) error {
+	session.subscriptionMutex.Lock()
+	defer session.subscriptionMutex.Unlock()
+
+	// add the subscribe requests to the stream
+	for _, s := range session.Subscriptions {
+		session.logger.Infof("subscribing %s %s %v", s.Symbol, s.Channel, s.Options)
+		session.Stream.Subscribe(s.Channel, s.Symbol, s.Options)
+	}
+
+	session.logger.Infof("connecting session %s...", session.Name)
+	if err := session.Stream.Connect(ctx); err != nil {
+		return err
+	}
+
+	session.streamConnected = true
+	return nil
+}
+
 func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.SubmitOrder, error) {
 	market, ok := session.Market(order.Symbol)
 	if !ok {
@@ -473,21 +603,21 @@ func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.Subm
 
 	switch order.Type {
 	case types.OrderTypeStopMarket, types.OrderTypeStopLimit:
-		order.StopPriceString = market.FormatPrice(order.StopPrice)
+		order.StopPriceString = market.FormatPrice(order.StopPrice.Float64())
 
 	}
 
 	switch order.Type {
 	case types.OrderTypeMarket, types.OrderTypeStopMarket:
-		order.Price = 0.0
+		order.Price = 0
 		order.PriceString = ""
 
 	default:
-		order.PriceString = market.FormatPrice(order.Price)
+		order.PriceString = market.FormatPrice(order.Price.Float64())
 
 	}
 
-	order.QuantityString = market.FormatQuantity(order.Quantity)
+	order.QuantityString = market.FormatQuantity(order.Quantity.Float64())
 	return order, nil
 }
 
@@ -0,0 +1,74 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+type testSessionStream struct {
+	types.StandardStream
+}
+
+func (s *testSessionStream) SetPublicOnly() {}
+
+func (s *testSessionStream) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (s *testSessionStream) Close() error {
+	return nil
+}
+
+func newTestSession(stream types.Stream) *ExchangeSession {
+	return &ExchangeSession{
+		Name:                  "test",
+		Stream:                stream,
+		Subscriptions:         make(map[types.Subscription]types.Subscription),
+		marketDataStores:      make(map[string]*MarketDataStore),
+		standardIndicatorSets: make(map[string]*StandardIndicatorSet),
+		usedSymbols:           make(map[string]struct{}),
+		initializedSymbols:    make(map[string]struct{}),
+		logger:                log.WithField("session", "test"),
+	}
+}
+
+func TestExchangeSession_Subscribe(t *testing.T) {
+	t.Run("subscribe before connecting", func(t *testing.T) {
+		stream := &testSessionStream{}
+		session := newTestSession(stream)
+
+		session.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+		assert.Len(t, stream.GetSubscriptions(), 0)
+
+		_, ok := session.MarketDataStore("BTCUSDT")
+		assert.False(t, ok)
+	})
+
+	t.Run("subscribe after connecting", func(t *testing.T) {
+		stream := &testSessionStream{}
+		session := newTestSession(stream)
+
+		session.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+		assert.NoError(t, session.connectStream(context.Background()))
+
+		session.Subscribe(types.KLineChannel, "ETHUSDT", types.SubscribeOptions{Interval: "1m"})
+		assert.Len(t, stream.GetSubscriptions(), 2)
+
+		store, ok := session.MarketDataStore("ETHUSDT")
+		if assert.True(t, ok) {
+			// the store created on subscribe is reused by InitSymbol
+			assert.Equal(t, store, session.initMarketDataStore("ETHUSDT"))
+		}
+
+		_, ok = session.StandardIndicatorSet("ETHUSDT")
+		assert.True(t, ok)
+
+		session.Unsubscribe(types.KLineChannel, "ETHUSDT")
+		assert.Len(t, stream.GetSubscriptions(), 1)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -14,8 +15,6 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
-var SupportedExchanges = []types.ExchangeName{"binance", "max", "ftx"}
-
 // SingleExchangeStrategy represents the single Exchange strategy
 type SingleExchangeStrategy interface {
 	ID() string
@@ -39,6 +38,18 @@ type Validator interface {
 	Validate() error
 }
 
+// ExchangeCapabilityRequirer is implemented by the single exchange strategies that need the optional exchange
+// capabilities, the strategy is refused at startup if its session lacks any of them.
+type ExchangeCapabilityRequirer interface {
+	RequiredCapabilities() types.ExchangeCapabilities
+}
+
+// CrossExchangeCapabilityRequirer is implemented by the cross exchange strategies that need the optional exchange
+// capabilities, the required capabilities are keyed by the session name.
+type CrossExchangeCapabilityRequirer interface {
+	CrossRequiredCapabilities() map[string]types.ExchangeCapabilities
+}
+
 //go:generate callbackgen -type Graceful
 type Graceful struct {
 	shutdownCallbacks []func(ctx context.Context, wg *sync.WaitGroup)
@@ -192,6 +203,53 @@ func (trader *Trader) Subscribe() {
 	}
 }
 
+// CheckCapabilities returns an error if a strategy needs a capability that its session lacks
+func (trader *Trader) CheckCapabilities() error {
+	for sessionName, strategies := range trader.exchangeStrategies {
+		session := trader.environment.sessions[sessionName]
+		for _, strategy := range strategies {
+			requirer, ok := strategy.(ExchangeCapabilityRequirer)
+			if !ok {
+				continue
+			}
+
+			if err := checkSessionCapabilities(strategy.ID(), session, requirer.RequiredCapabilities()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		requirer, ok := strategy.(CrossExchangeCapabilityRequirer)
+		if !ok {
+			continue
+		}
+
+		for sessionName, required := range requirer.CrossRequiredCapabilities() {
+			session, ok := trader.environment.sessions[sessionName]
+			if !ok {
+				return fmt.Errorf("strategy %s requires session %s which is not defined", strategy.ID(), sessionName)
+			}
+
+			if err := checkSessionCapabilities(strategy.ID(), session, required); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkSessionCapabilities(strategyID string, session *ExchangeSession, required types.ExchangeCapabilities) error {
+	missing := session.Exchange.Capabilities().Missing(required)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("strategy %s can not run on session %s: exchange %s does not support %s",
+		strategyID, session.Name, session.Exchange.Name(), strings.Join(missing, ", "))
+}
+
 func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy SingleExchangeStrategy, session *ExchangeSession, orderExecutor OrderExecutor) error {
 	rs := reflect.ValueOf(strategy)
 
@@ -206,6 +264,12 @@ func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy Si
 		return err
 	}
 
+	// override the common notifiability, so that the maintenance windows of the session are applied
+	notifiability := trader.environment.Notifiability.WithSession(session.Name)
+	if err := injectField(rs, "Notifiability", &notifiability, false); err != nil {
+		return errors.Wrapf(err, "failed to inject Notifiability on %T", strategy)
+	}
+
 	if err := injectField(rs, "OrderExecutor", orderExecutor, false); err != nil {
 		return errors.Wrapf(err, "failed to inject OrderExecutor on %T", strategy)
 	}
@@ -286,6 +350,10 @@ func (trader *Trader) RunAllSingleExchangeStrategy(ctx context.Context) error {
 }
 
 func (trader *Trader) Run(ctx context.Context) error {
+	if err := trader.CheckCapabilities(); err != nil {
+		return err
+	}
+
 	trader.Subscribe()
 
 	if err := trader.environment.Init(ctx); err != nil {
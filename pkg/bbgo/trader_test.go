@@ -0,0 +1,92 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/exchange/binance"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+type capabilityTestStrategy struct {
+	required types.ExchangeCapabilities
+}
+
+func (s *capabilityTestStrategy) ID() string {
+	return "capability-test"
+}
+
+func (s *capabilityTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *capabilityTestStrategy) RequiredCapabilities() types.ExchangeCapabilities {
+	return s.required
+}
+
+type crossCapabilityTestStrategy struct {
+	required map[string]types.ExchangeCapabilities
+}
+
+func (s *crossCapabilityTestStrategy) ID() string {
+	return "cross-capability-test"
+}
+
+func (s *crossCapabilityTestStrategy) CrossRun(ctx context.Context, orderExecutionRouter OrderExecutionRouter, sessions map[string]*ExchangeSession) error {
+	return nil
+}
+
+func (s *crossCapabilityTestStrategy) CrossRequiredCapabilities() map[string]types.ExchangeCapabilities {
+	return s.required
+}
+
+func TestTrader_CheckCapabilities(t *testing.T) {
+	environ := NewEnvironment()
+	environ.AddExchange("binance", binance.New("", ""))
+
+	trader := NewTrader(environ)
+	assert.NoError(t, trader.AttachStrategyOn("binance", &capabilityTestStrategy{
+		required: types.ExchangeCapabilities{
+			Margin:     true,
+			Channels:   []types.Channel{types.BookChannel},
+			OrderTypes: []types.OrderType{types.OrderTypeLimit},
+		},
+	}))
+	assert.NoError(t, trader.CheckCapabilities())
+
+	assert.NoError(t, trader.AttachStrategyOn("binance", &capabilityTestStrategy{
+		required: types.ExchangeCapabilities{
+			Rewards:    true,
+			OrderTypes: []types.OrderType{types.OrderTypeLimitMaker},
+		},
+	}))
+	err := trader.CheckCapabilities()
+	if assert.Error(t, err) {
+		assert.Equal(t, "strategy capability-test can not run on session binance: exchange binance does not support rewards, order type LIMIT_MAKER", err.Error())
+	}
+}
+
+func TestTrader_CheckCrossExchangeCapabilities(t *testing.T) {
+	environ := NewEnvironment()
+	environ.AddExchange("binance", binance.New("", ""))
+
+	trader := NewTrader(environ)
+	trader.AttachCrossExchangeStrategy(&crossCapabilityTestStrategy{
+		required: map[string]types.ExchangeCapabilities{
+			"binance": {TimeInForce: []string{"IOC"}},
+		},
+	})
+	assert.NoError(t, trader.CheckCapabilities())
+
+	trader.AttachCrossExchangeStrategy(&crossCapabilityTestStrategy{
+		required: map[string]types.ExchangeCapabilities{
+			"max": {Channels: []types.Channel{types.BookChannel}},
+		},
+	})
+	err := trader.CheckCapabilities()
+	if assert.Error(t, err) {
+		assert.Equal(t, "strategy cross-capability-test requires session max which is not defined", err.Error())
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/ycdesu/spreaddog/pkg/backtest"
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
 	"github.com/ycdesu/spreaddog/pkg/cmd/cmdutil"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/service"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
@@ -253,7 +254,7 @@ var BacktestCmd = &cobra.Command{
 					return fmt.Errorf("last price not found: %s", symbol)
 				}
 
-				report := calculator.Calculate(symbol, trades.Trades, lastPrice)
+				report := calculator.Calculate(symbol, trades.Trades, fixedpoint.NewFromFloat(lastPrice))
 				report.Print()
 
 				initBalances := userConfig.Backtest.Account.Balances.BalanceMap()
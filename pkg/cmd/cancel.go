@@ -12,16 +12,10 @@ import (
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
-type advancedOrderCancelApi interface {
-	CancelAllOrders(ctx context.Context) ([]types.Order, error)
-	CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error)
-	CancelOrdersByGroupID(ctx context.Context, groupID int64) ([]types.Order, error)
-}
-
 func init() {
 	CancelCmd.Flags().String("session", "", "session to execute cancel orders")
 	CancelCmd.Flags().String("symbol", "", "symbol to cancel orders")
-	CancelCmd.Flags().Int64("group-id", 0, "groupID to cancel orders")
+	CancelCmd.Flags().Uint32("group-id", 0, "groupID to cancel orders")
 	CancelCmd.Flags().Bool("all", false, "cancel all orders")
 	RootCmd.AddCommand(CancelCmd)
 }
@@ -43,7 +37,7 @@ var CancelCmd = &cobra.Command{
 			return err
 		}
 
-		groupID, err := cmd.Flags().GetInt64("group-id")
+		groupID, err := cmd.Flags().GetUint32("group-id")
 		if err != nil {
 			return err
 		}
@@ -96,7 +90,7 @@ var CancelCmd = &cobra.Command{
 		for sessionID, session := range sessions {
 			var log = logrus.WithField("session", sessionID)
 
-			e, ok := session.Exchange.(advancedOrderCancelApi)
+			e, ok := session.Exchange.(types.ExchangeCancelAllService)
 			if ok {
 				if all {
 					log.Infof("canceling all orders")
@@ -141,6 +135,10 @@ var CancelCmd = &cobra.Command{
 				if err := session.Exchange.CancelOrders(ctx, openOrders...); err != nil {
 					return err
 				}
+
+				for _, o := range openOrders {
+					log.Info("CANCELED ", o.String())
+				}
 			} else {
 				log.Error("unsupported operation")
 			}
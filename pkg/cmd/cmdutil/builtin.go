@@ -0,0 +1,10 @@
+package cmdutil
+
+// import built-in exchanges, they are registered to the exchange registry
+import (
+	_ "github.com/ycdesu/spreaddog/pkg/exchange/binance"
+	_ "github.com/ycdesu/spreaddog/pkg/exchange/ftx"
+	_ "github.com/ycdesu/spreaddog/pkg/exchange/kraken"
+	_ "github.com/ycdesu/spreaddog/pkg/exchange/max"
+	_ "github.com/ycdesu/spreaddog/pkg/exchange/okex"
+)
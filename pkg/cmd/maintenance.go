@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/ycdesu/spreaddog/pkg/bbgo"
+)
+
+func init() {
+	MaintenanceCmd.Flags().String("session", "", "the session of the maintenance window, all sessions if not set")
+	MaintenanceCmd.Flags().Duration("for", time.Hour, "the duration of the maintenance window")
+	MaintenanceCmd.Flags().String("mode", string(bbgo.MaintenanceModeSuppress), "notification mode during the window: suppress or downgrade")
+	MaintenanceCmd.Flags().String("channel", "", "the channel that receives the downgraded notifications")
+	MaintenanceCmd.Flags().String("reason", "", "the reason of the maintenance")
+	MaintenanceCmd.Flags().Bool("list", false, "list the active maintenance windows")
+	RootCmd.AddCommand(MaintenanceCmd)
+}
+
+// go run ./cmd/bbgo maintenance --session max --for 2h
+var MaintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "start a maintenance window that suppresses the notifications",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		duration, err := cmd.Flags().GetDuration("for")
+		if err != nil {
+			return err
+		}
+
+		mode, err := cmd.Flags().GetString("mode")
+		if err != nil {
+			return err
+		}
+
+		channel, err := cmd.Flags().GetString("channel")
+		if err != nil {
+			return err
+		}
+
+		reason, err := cmd.Flags().GetString("reason")
+		if err != nil {
+			return err
+		}
+
+		list, err := cmd.Flags().GetBool("list")
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		if userConfig.Persistence == nil {
+			return errors.New("persistence is not configured, the maintenance window can not be shared with the running process")
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigurePersistence(userConfig.Persistence); err != nil {
+			return err
+		}
+
+		if len(sessionName) > 0 {
+			if _, ok := userConfig.Sessions[sessionName]; !ok {
+				return fmt.Errorf("session %s not found", sessionName)
+			}
+		}
+
+		schedule, err := bbgo.NewMaintenanceScheduleFromConfig(environ.PersistenceServiceFacade.Get(), userConfig.Notifications)
+		if err != nil {
+			return err
+		}
+
+		if !list {
+			now := time.Now()
+			window := bbgo.MaintenanceWindow{
+				Session:   sessionName,
+				StartTime: now,
+				EndTime:   now.Add(duration),
+				Mode:      bbgo.MaintenanceMode(mode),
+				Channel:   channel,
+				Reason:    reason,
+			}
+
+			if err := schedule.AddWindow(window); err != nil {
+				return err
+			}
+
+			log.Infof("maintenance window added: %s", window)
+		}
+
+		for _, window := range schedule.Windows() {
+			log.Info(window.String())
+		}
+
+		return nil
+	},
+}
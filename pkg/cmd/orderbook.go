@@ -12,6 +12,7 @@ import (
 
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
 	"github.com/ycdesu/spreaddog/pkg/cmd/cmdutil"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -46,14 +47,32 @@ var orderbookCmd = &cobra.Command{
 			return fmt.Errorf("--symbol option is required")
 		}
 
+		depthBps, err := cmd.Flags().GetFloat64("depth-bps")
+		if err != nil {
+			return err
+		}
+
+		levels, err := cmd.Flags().GetInt("imbalance-levels")
+		if err != nil {
+			return err
+		}
+
+		quantity, err := cmd.Flags().GetFloat64("quantity")
+		if err != nil {
+			return err
+		}
+
 		s := ex.NewStream()
 		s.SetPublicOnly()
 		s.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
 		s.OnBookSnapshot(func(book types.OrderBook) {
 			log.Infof("orderbook snapshot: %s", book.String())
 		})
-		s.OnBookUpdate(func(book types.OrderBook) {
-			log.Infof("orderbook update: %s", book.String())
+
+		streamBook := types.NewStreamBook(symbol)
+		streamBook.BindStream(s)
+		streamBook.OnUpdate(func(book *types.OrderBook) {
+			logOrderBookAnalytics(book, depthBps, levels, fixedpoint.NewFromFloat(quantity))
 		})
 
 		log.Infof("connecting...")
@@ -66,6 +85,35 @@ var orderbookCmd = &cobra.Command{
 	},
 }
 
+func logOrderBookAnalytics(book *types.OrderBook, depthBps float64, levels int, quantity fixedpoint.Value) {
+	mid, ok := book.MidPrice()
+	if !ok {
+		log.Infof("orderbook %s: one of the sides is empty", book.Symbol)
+		return
+	}
+
+	microPrice, _ := book.MicroPrice()
+	imbalance, _ := book.Imbalance(levels)
+	bidVolume, askVolume := book.DepthWithinBps(depthBps)
+
+	log.Infof("orderbook %s: mid %f, microprice %f, imbalance(%d) %.4f, depth within %.1f bps: bids %f, asks %f",
+		book.Symbol, mid.Float64(), microPrice.Float64(), levels, imbalance, depthBps, bidVolume.Float64(), askVolume.Float64())
+
+	if quantity <= 0 {
+		return
+	}
+
+	for _, side := range []types.SideType{types.SideTypeBuy, types.SideTypeSell} {
+		result, ok := book.ExecutionPriceByQuantity(side, quantity)
+		if !ok {
+			log.Infof("orderbook %s: %s %f, insufficient depth, filled %f", book.Symbol, side, quantity.Float64(), result.Quantity.Float64())
+			continue
+		}
+
+		log.Infof("orderbook %s: %s %f, average price %f, slippage %.2f bps", book.Symbol, side, quantity.Float64(), result.AveragePrice.Float64(), result.SlippageBps)
+	}
+}
+
 // go run ./cmd/bbgo orderupdate --session=ftx
 var orderUpdateCmd = &cobra.Command{
 	Use: "orderupdate",
@@ -134,6 +182,9 @@ func init() {
 	// since the public data does not require trading authentication, we use --exchange option here.
 	orderbookCmd.Flags().String("exchange", "", "the exchange name for sync")
 	orderbookCmd.Flags().String("symbol", "", "the trading pair. e.g, BTCUSDT, LTCUSDT...")
+	orderbookCmd.Flags().Float64("depth-bps", 10, "show the cumulative depth within the bps of the mid price")
+	orderbookCmd.Flags().Int("imbalance-levels", 5, "the number of the levels for the bid/ask volume imbalance, 0 means all the levels")
+	orderbookCmd.Flags().Float64("quantity", 0, "show the average execution price and the slippage of the base quantity on each side")
 
 	orderUpdateCmd.Flags().String("session", "", "session name")
 	RootCmd.AddCommand(orderbookCmd)
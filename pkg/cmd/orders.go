@@ -12,8 +12,8 @@ import (
 
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
 	"github.com/ycdesu/spreaddog/pkg/exchange/ftx"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
-	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
 // go run ./cmd/bbgo listorders [open|closed] --session=ftx --symbol=BTC/USDT
@@ -177,8 +177,8 @@ var placeOrderCmd = &cobra.Command{
 			Symbol:        symbol,
 			Side:          types.SideType(ftx.TrimUpperString(side)),
 			Type:          types.OrderTypeLimit,
-			Quantity:      util.MustParseFloat(quantity),
-			Price:         util.MustParseFloat(price),
+			Quantity:      fixedpoint.MustNewFromString(quantity),
+			Price:         fixedpoint.MustNewFromString(price),
 			Market:        types.Market{Symbol: symbol},
 			TimeInForce:   "GTC",
 		}
@@ -14,6 +14,7 @@ import (
 	"github.com/ycdesu/spreaddog/pkg/accounting"
 	"github.com/ycdesu/spreaddog/pkg/accounting/pnl"
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/service"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
@@ -159,7 +160,7 @@ var PnLCmd = &cobra.Command{
 		}
 
 		log.Infof("found checkpoints: %+v", checkpoints)
-		log.Infof("stock: %f", stockManager.Stocks.Quantity())
+		log.Infof("stock: %s", stockManager.Stocks.Quantity())
 
 		tickers, err := exchange.QueryTickers(ctx, symbol)
 
@@ -179,7 +180,7 @@ var PnLCmd = &cobra.Command{
 			TradingFeeCurrency: tradingFeeCurrency,
 		}
 
-		report := calculator.Calculate(symbol, trades, currentPrice)
+		report := calculator.Calculate(symbol, trades, fixedpoint.NewFromFloat(currentPrice))
 		report.Print()
 		return nil
 	},
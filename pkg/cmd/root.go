@@ -15,6 +15,8 @@ import (
 	"github.com/spf13/viper"
 	"github.com/x-cray/logrus-prefixed-formatter"
 
+	"github.com/ycdesu/spreaddog/pkg/exchange"
+
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -69,15 +71,17 @@ func init() {
 	RootCmd.PersistentFlags().String("telegram-bot-token", "", "telegram bot token from bot father")
 	RootCmd.PersistentFlags().String("telegram-bot-auth-token", "", "telegram auth token")
 
-	RootCmd.PersistentFlags().String("binance-api-key", "", "binance api key")
-	RootCmd.PersistentFlags().String("binance-api-secret", "", "binance api secret")
-
-	RootCmd.PersistentFlags().String("max-api-key", "", "max api key")
-	RootCmd.PersistentFlags().String("max-api-secret", "", "max api secret")
-
-	RootCmd.PersistentFlags().String("ftx-api-key", "", "ftx api key")
-	RootCmd.PersistentFlags().String("ftx-api-secret", "", "ftx api secret")
-	RootCmd.PersistentFlags().String("ftx-subaccount-name", "", "subaccount name. Specify it if the credential is for subaccount.")
+	// the credential flags of the registered exchanges, e.g., binance-api-key and binance-api-secret
+	for _, r := range exchange.Registrations() {
+		RootCmd.PersistentFlags().String(r.Name.String()+"-api-key", "", r.Name.String()+" api key")
+		RootCmd.PersistentFlags().String(r.Name.String()+"-api-secret", "", r.Name.String()+" api secret")
+		if r.UsePassphrase {
+			RootCmd.PersistentFlags().String(r.Name.String()+"-api-passphrase", "", r.Name.String()+" api passphrase")
+		}
+		if r.UseSubAccount {
+			RootCmd.PersistentFlags().String(r.Name.String()+"-subaccount-name", "", "subaccount name. Specify it if the credential is for subaccount.")
+		}
+	}
 }
 
 func Execute() {
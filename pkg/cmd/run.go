@@ -25,6 +25,7 @@ func init() {
 	RunCmd.Flags().String("totp-issuer", "", "")
 	RunCmd.Flags().String("totp-account-name", "", "")
 	RunCmd.Flags().Bool("enable-web-server", false, "enable web server")
+	RunCmd.Flags().String("web-server-bind", server.DefaultBindAddress, "the bind address of the web server, for example: 0.0.0.0:8080")
 	RunCmd.Flags().Bool("setup", false, "use setup mode")
 	RootCmd.AddCommand(RunCmd)
 }
@@ -99,7 +100,7 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 	return nil
 }
 
-func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer bool) error {
+func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer bool, webServerBind string) error {
 	ctx, cancelTrading := context.WithCancel(basectx)
 	defer cancelTrading()
 
@@ -129,7 +130,7 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer
 				Trader:  trader,
 			}
 
-			if err := s.Run(ctx); err != nil {
+			if err := s.Run(ctx, webServerBind); err != nil {
 				log.WithError(err).Errorf("server error")
 			}
 		}()
@@ -158,6 +159,11 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	webServerBind, err := cmd.Flags().GetString("web-server-bind")
+	if err != nil {
+		return err
+	}
+
 	noCompile, err := cmd.Flags().GetBool("no-compile")
 	if err != nil {
 		return err
@@ -204,7 +210,7 @@ func run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		return runConfig(ctx, userConfig, enableWebServer)
+		return runConfig(ctx, userConfig, enableWebServer, webServerBind)
 	}
 
 	return runWrapperBinary(ctx, userConfig, cmd, args)
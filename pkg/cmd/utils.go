@@ -5,7 +5,7 @@ import (
 
 	"github.com/spf13/viper"
 
-	"github.com/ycdesu/spreaddog/pkg/exchange/ftx"
+	"github.com/ycdesu/spreaddog/pkg/exchange"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -15,15 +15,17 @@ func inBaseAsset(balances types.BalanceMap, market types.Market, price float64)
 	return (base.Locked.Float64() + base.Available.Float64()) + ((quote.Locked.Float64() + quote.Available.Float64()) / price)
 }
 
+// newExchange creates the exchange with the credentials of the command line flags
 func newExchange(session string) (types.Exchange, error) {
-	switch session {
-	case "ftx":
-		return ftx.NewExchange(
-			viper.GetString("ftx-api-key"),
-			viper.GetString("ftx-api-secret"),
-			viper.GetString("ftx-subaccount-name"),
-		), nil
-
+	r, err := exchange.Lookup(session)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported session %s: %w", session, err)
 	}
-	return nil, fmt.Errorf("unsupported session %s", session)
+
+	return r.New(exchange.Credentials{
+		Key:        viper.GetString(r.Name.String() + "-api-key"),
+		Secret:     viper.GetString(r.Name.String() + "-api-secret"),
+		Passphrase: viper.GetString(r.Name.String() + "-api-passphrase"),
+		SubAccount: viper.GetString(r.Name.String() + "-subaccount-name"),
+	}), nil
 }
@@ -0,0 +1,116 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// ErrReplacementNotPlaced is matched by the error of CancelReplaceOrder when the order is canceled but the replacing
+// order is not placed, the canceled order should be removed from the local active order book.
+var ErrReplacementNotPlaced = errors.New("order is canceled but the replacing order is not placed")
+
+type replaceError struct {
+	orderID uint64
+	err     error
+}
+
+func (e *replaceError) Error() string {
+	return fmt.Sprintf("order %d is canceled but the replacing order is not placed: %s", e.orderID, e.err.Error())
+}
+
+func (e *replaceError) Unwrap() error {
+	return e.err
+}
+
+func (e *replaceError) Is(target error) bool {
+	return target == ErrReplacementNotPlaced
+}
+
+// CancelReplaceOrder amends the order by canceling it and placing a new order with the new price and the remaining
+// quantity, it's the fallback of the exchanges without the native modify order api. The quantity includes the executed
+// quantity of the order, so the replacing order is not over-sized if the order is partially filled. The executed
+// quantity is queried after the cancellation if the exchange supports ExchangeOrderQueryService.
+//
+// The new order is not placed if the cancellation fails, e.g., the order has been filled or canceled, so an order is
+// never replaced twice. The error matches ErrReplacementNotPlaced if the order is canceled but not replaced.
+func CancelReplaceOrder(ctx context.Context, ex types.Exchange, order types.Order, price, quantity fixedpoint.Value) (*types.Order, error) {
+	// cancel the order only, the exchanges with the order group cancel the whole group if the group id is set
+	canceledOrder := order
+	canceledOrder.GroupID = 0
+	if err := ex.CancelOrders(ctx, canceledOrder); err != nil {
+		return nil, fmt.Errorf("can not cancel order %d for replacing: %w", order.OrderID, err)
+	}
+
+	executedQuantity := queryExecutedQuantity(ctx, ex, order)
+	if executedQuantity >= quantity {
+		return nil, &replaceError{
+			orderID: order.OrderID,
+			err:     fmt.Errorf("executed quantity %s reaches the new quantity %s", executedQuantity, quantity),
+		}
+	}
+
+	createdOrders, err := ex.SubmitOrders(ctx, NewReplaceSubmitOrder(order, price, quantity.Sub(executedQuantity)))
+	if err != nil {
+		return nil, &replaceError{orderID: order.OrderID, err: err}
+	}
+
+	if len(createdOrders) == 0 {
+		return nil, &replaceError{orderID: order.OrderID, err: errors.New("no order is created")}
+	}
+
+	return &createdOrders[0], nil
+}
+
+// queryExecutedQuantity returns the executed quantity of the canceled order, the local executed quantity is used if
+// the exchange doesn't support ExchangeOrderQueryService or the query fails
+func queryExecutedQuantity(ctx context.Context, ex types.Exchange, order types.Order) fixedpoint.Value {
+	service, ok := ex.(types.ExchangeOrderQueryService)
+	if !ok {
+		return order.ExecutedQuantity
+	}
+
+	canceledOrder, err := service.QueryOrder(ctx, types.OrderQuery{
+		Symbol:        order.Symbol,
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+	})
+	if err != nil {
+		log.WithError(err).Warnf("can not query the canceled order %d, using the local executed quantity", order.OrderID)
+		return order.ExecutedQuantity
+	}
+
+	if canceledOrder.ExecutedQuantity > order.ExecutedQuantity {
+		return canceledOrder.ExecutedQuantity
+	}
+	return order.ExecutedQuantity
+}
+
+// NewReplaceSubmitOrder returns the submit order replacing the order with the new price and quantity, the order group
+// of the original order is kept.
+func NewReplaceSubmitOrder(order types.Order, price, quantity fixedpoint.Value) types.SubmitOrder {
+	so := order.SubmitOrder
+	so.Price = price
+	so.Quantity = quantity
+
+	// the formatted strings are of the original order, the adapters format the new price and quantity by the market
+	so.PriceString = ""
+	so.QuantityString = ""
+
+	if groupID, ok := types.ParseGroupClientOrderID(so.ClientOrderID); ok && so.GroupID == 0 {
+		so.GroupID = groupID
+	}
+
+	// the client order id can not be reused
+	so.ClientOrderID = ""
+	if so.GroupID > 0 {
+		so.ClientOrderID = types.NewGroupClientOrderID(so.GroupID)
+	}
+
+	return so
+}
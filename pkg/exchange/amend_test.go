@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+type replaceExchange struct {
+	types.Exchange
+
+	cancelErr      error
+	submitErr      error
+	canceledOrders []types.Order
+	submitOrders   []types.SubmitOrder
+}
+
+type queryReplaceExchange struct {
+	replaceExchange
+
+	executedQuantity fixedpoint.Value
+}
+
+func (e *queryReplaceExchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	return &types.Order{OrderID: q.OrderID, ExecutedQuantity: e.executedQuantity, Status: types.OrderStatusCanceled}, nil
+}
+
+func (e *replaceExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.canceledOrders = append(e.canceledOrders, orders...)
+	return e.cancelErr
+}
+
+func (e *replaceExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	e.submitOrders = append(e.submitOrders, orders...)
+	if e.submitErr != nil {
+		return nil, e.submitErr
+	}
+
+	var createdOrders types.OrderSlice
+	for _, so := range orders {
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: so, OrderID: 2, Status: types.OrderStatusNew})
+	}
+	return createdOrders, nil
+}
+
+func TestCancelReplaceOrder(t *testing.T) {
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID:  "g100-abc",
+			Symbol:         "BTCUSDT",
+			Side:           types.SideTypeBuy,
+			Type:           types.OrderTypeLimit,
+			Quantity:       fixedpoint.NewFromFloat(1.0),
+			Price:          fixedpoint.NewFromFloat(100.0),
+			PriceString:    "100.00",
+			QuantityString: "1.0",
+			GroupID:        100,
+		},
+		OrderID: 1,
+		Status:  types.OrderStatusNew,
+	}
+
+	t.Run("replace", func(t *testing.T) {
+		ex := &replaceExchange{}
+		newOrder, err := CancelReplaceOrder(context.Background(), ex, order, fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(0.5))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), newOrder.OrderID)
+
+		// the group of the order should not be canceled
+		if assert.Len(t, ex.canceledOrders, 1) {
+			assert.Equal(t, uint64(1), ex.canceledOrders[0].OrderID)
+			assert.Equal(t, uint32(0), ex.canceledOrders[0].GroupID)
+		}
+
+		if assert.Len(t, ex.submitOrders, 1) {
+			so := ex.submitOrders[0]
+			assert.Equal(t, fixedpoint.NewFromFloat(101.0), so.Price)
+			assert.Equal(t, fixedpoint.NewFromFloat(0.5), so.Quantity)
+			assert.Empty(t, so.PriceString)
+			assert.Empty(t, so.QuantityString)
+			assert.Equal(t, uint32(100), so.GroupID)
+			assert.True(t, types.IsGroupClientOrderID(so.ClientOrderID, 100))
+			assert.NotEqual(t, order.ClientOrderID, so.ClientOrderID)
+		}
+	})
+
+	t.Run("partially filled", func(t *testing.T) {
+		filledOrder := order
+		filledOrder.ExecutedQuantity = fixedpoint.NewFromFloat(0.2)
+
+		ex := &replaceExchange{}
+		_, err := CancelReplaceOrder(context.Background(), ex, filledOrder, fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0))
+		assert.NoError(t, err)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.8), ex.submitOrders[0].Quantity)
+
+		// the order is filled more before the cancellation
+		qex := &queryReplaceExchange{executedQuantity: fixedpoint.NewFromFloat(0.5)}
+		_, err = CancelReplaceOrder(context.Background(), qex, filledOrder, fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0))
+		assert.NoError(t, err)
+		assert.Equal(t, fixedpoint.NewFromFloat(0.5), qex.submitOrders[0].Quantity)
+
+		qex = &queryReplaceExchange{executedQuantity: fixedpoint.NewFromFloat(1.0)}
+		_, err = CancelReplaceOrder(context.Background(), qex, filledOrder, fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0))
+		assert.True(t, errors.Is(err, ErrReplacementNotPlaced))
+		assert.Empty(t, qex.submitOrders)
+	})
+
+	t.Run("submit error", func(t *testing.T) {
+		ex := &replaceExchange{submitErr: types.NewExchangeError(types.ErrInsufficientBalance, errors.New("insufficient balance"))}
+		_, err := CancelReplaceOrder(context.Background(), ex, order, fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(0.5))
+		assert.True(t, errors.Is(err, ErrReplacementNotPlaced))
+		assert.True(t, errors.Is(err, types.ErrInsufficientBalance))
+	})
+
+	t.Run("cancel error", func(t *testing.T) {
+		ex := &replaceExchange{cancelErr: types.NewExchangeError(types.ErrOrderNotFound, errors.New("unknown order"))}
+		_, err := CancelReplaceOrder(context.Background(), ex, order, fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(0.5))
+		assert.True(t, errors.Is(err, types.ErrOrderNotFound))
+		assert.False(t, errors.Is(err, ErrReplacementNotPlaced))
+		assert.Empty(t, ex.submitOrders)
+	})
+}
+
+func TestNewReplaceSubmitOrder(t *testing.T) {
+	// the group id is recovered from the client order id for the exchanges without the native order group
+	so := NewReplaceSubmitOrder(types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: "g7-abc"}}, fixedpoint.NewFromFloat(1.0), fixedpoint.NewFromFloat(2.0))
+	assert.Equal(t, uint32(7), so.GroupID)
+	assert.True(t, types.IsGroupClientOrderID(so.ClientOrderID, 7))
+
+	so = NewReplaceSubmitOrder(types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: "my-order"}}, fixedpoint.NewFromFloat(1.0), fixedpoint.NewFromFloat(2.0))
+	assert.Equal(t, uint32(0), so.GroupID)
+	assert.Empty(t, so.ClientOrderID)
+}
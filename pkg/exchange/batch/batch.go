@@ -5,11 +5,22 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
 
 	"github.com/ycdesu/spreaddog/pkg/types"
+	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
+// rateLimitRetries is the number of the attempts of a query when the exchange rejects it for the rate limit, the delay
+// between the attempts is extended to the retry-after duration of the exchange. The requests are throttled by the rate
+// limiter of the exchange adapter, see pkg/exchange/ratelimit
+const rateLimitRetries = 3
+
+const rateLimitRetryDelay = 5 * time.Second
+
+func logRetryError(err error) {
+	logrus.WithError(err).Warn("rate limited, retrying")
+}
+
 type ClosedOrderBatchQuery struct {
 	types.Exchange
 }
@@ -19,8 +30,6 @@ func (e ClosedOrderBatchQuery) Query(ctx context.Context, symbol string, startTi
 	errC = make(chan error, 1)
 
 	go func() {
-		limiter := rate.NewLimiter(rate.Every(5*time.Second), 2) // from binance (original 1200, use 1000 for safety)
-
 		defer close(c)
 		defer close(errC)
 
@@ -30,13 +39,13 @@ func (e ClosedOrderBatchQuery) Query(ctx context.Context, symbol string, startTi
 		}
 
 		for startTime.Before(endTime) {
-			if err := limiter.Wait(ctx); err != nil {
-				logrus.WithError(err).Error("rate limit error")
-			}
-
 			logrus.Infof("batch querying %s closed orders %s <=> %s", symbol, startTime, endTime)
 
-			orders, err := e.QueryClosedOrders(ctx, symbol, startTime, endTime, lastOrderID)
+			var orders []types.Order
+			err := util.Retry(ctx, rateLimitRetries, rateLimitRetryDelay, func() (err error) {
+				orders, err = e.QueryClosedOrders(ctx, symbol, startTime, endTime, lastOrderID)
+				return err
+			}, logRetryError, types.IsRateLimitError)
 			if err != nil {
 				errC <- err
 				return
@@ -72,20 +81,18 @@ func (e KLineBatchQuery) Query(ctx context.Context, symbol string, interval type
 	errC = make(chan error, 1)
 
 	go func() {
-		limiter := rate.NewLimiter(rate.Every(5*time.Second), 2) // from binance (original 1200, use 1000 for safety)
-
 		defer close(c)
 		defer close(errC)
 
 		for startTime.Before(endTime) {
-			if err := limiter.Wait(ctx); err != nil {
-				logrus.WithError(err).Error("rate limit error")
-			}
-
-			kLines, err := e.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
-				StartTime: &startTime,
-				Limit:     1000,
-			})
+			var kLines []types.KLine
+			err := util.Retry(ctx, rateLimitRetries, rateLimitRetryDelay, func() (err error) {
+				kLines, err = e.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
+					StartTime: &startTime,
+					Limit:     1000,
+				})
+				return err
+			}, logRetryError, types.IsRateLimitError)
 
 			if err != nil {
 				errC <- err
@@ -126,27 +133,22 @@ func (e TradeBatchQuery) Query(ctx context.Context, symbol string, options *type
 	var lastTradeID = options.LastTradeID
 
 	go func() {
-		limiter := rate.NewLimiter(rate.Every(5*time.Second), 2) // from binance (original 1200, use 1000 for safety)
-
 		defer close(c)
 		defer close(errC)
 
 		var tradeKeys = map[types.TradeKey]struct{}{}
 
 		for {
-			if err := limiter.Wait(ctx); err != nil {
-				logrus.WithError(err).Error("rate limit error")
-			}
-
 			logrus.Infof("querying %s trades from id=%d limit=%d", symbol, lastTradeID, options.Limit)
 
-			var err error
 			var trades []types.Trade
-
-			trades, err = e.Exchange.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
-				Limit:       options.Limit,
-				LastTradeID: lastTradeID,
-			})
+			err := util.Retry(ctx, rateLimitRetries, rateLimitRetryDelay, func() (err error) {
+				trades, err = e.Exchange.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
+					Limit:       options.Limit,
+					LastTradeID: lastTradeID,
+				})
+				return err
+			}, logRetryError, types.IsRateLimitError)
 
 			if err != nil {
 				errC <- err
@@ -190,8 +192,6 @@ func (q *RewardBatchQuery) Query(ctx context.Context, startTime, endTime time.Ti
 	errC = make(chan error, 1)
 
 	go func() {
-		limiter := rate.NewLimiter(rate.Every(5*time.Second), 2) // from binance (original 1200, use 1000 for safety)
-
 		defer close(c)
 		defer close(errC)
 
@@ -199,13 +199,13 @@ func (q *RewardBatchQuery) Query(ctx context.Context, startTime, endTime time.Ti
 		rewardKeys := make(map[string]struct{}, 500)
 
 		for startTime.Before(endTime) {
-			if err := limiter.Wait(ctx); err != nil {
-				logrus.WithError(err).Error("rate limit error")
-			}
-
 			logrus.Infof("batch querying rewards %s <=> %s", startTime, endTime)
 
-			rewards, err := q.Service.QueryRewards(ctx, startTime)
+			var rewards []types.Reward
+			err := util.Retry(ctx, rateLimitRetries, rateLimitRetryDelay, func() (err error) {
+				rewards, err = q.Service.QueryRewards(ctx, startTime)
+				return err
+			}, logRetryError, types.IsRateLimitError)
 			if err != nil {
 				errC <- err
 				return
@@ -3,9 +3,11 @@ package binance
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/pkg/errors"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
@@ -141,15 +143,15 @@ func ToGlobalOrder(binanceOrder *binance.Order, isMargin bool) (*types.Order, er
 			Symbol:        binanceOrder.Symbol,
 			Side:          toGlobalSideType(binanceOrder.Side),
 			Type:          toGlobalOrderType(binanceOrder.Type),
-			Quantity:      util.MustParseFloat(binanceOrder.OrigQuantity),
-			Price:         util.MustParseFloat(binanceOrder.Price),
+			Quantity:      fixedpoint.MustNewFromString(binanceOrder.OrigQuantity),
+			Price:         fixedpoint.MustNewFromString(binanceOrder.Price),
 			TimeInForce:   string(binanceOrder.TimeInForce),
 		},
 		Exchange:         types.ExchangeBinance.String(),
 		IsWorking:        binanceOrder.IsWorking,
 		OrderID:          uint64(binanceOrder.OrderID),
 		Status:           toGlobalOrderStatus(binanceOrder.Status),
-		ExecutedQuantity: util.MustParseFloat(binanceOrder.ExecutedQuantity),
+		ExecutedQuantity: fixedpoint.MustNewFromString(binanceOrder.ExecutedQuantity),
 		CreationTime:     datatype.Time(millisecondTime(binanceOrder.Time)),
 		UpdateTime:       datatype.Time(millisecondTime(binanceOrder.UpdateTime)),
 		IsMargin:         isMargin,
@@ -170,27 +172,27 @@ func ToGlobalTrade(t binance.TradeV3, isMargin bool) (*types.Trade, error) {
 		side = types.SideTypeSell
 	}
 
-	price, err := strconv.ParseFloat(t.Price, 64)
+	price, err := fixedpoint.NewFromString(t.Price)
 	if err != nil {
 		return nil, errors.Wrapf(err, "price parse error, price: %+v", t.Price)
 	}
 
-	quantity, err := strconv.ParseFloat(t.Quantity, 64)
+	quantity, err := fixedpoint.NewFromString(t.Quantity)
 	if err != nil {
 		return nil, errors.Wrapf(err, "quantity parse error, quantity: %+v", t.Quantity)
 	}
 
-	var quoteQuantity = 0.0
+	var quoteQuantity fixedpoint.Value
 	if len(t.QuoteQuantity) > 0 {
-		quoteQuantity, err = strconv.ParseFloat(t.QuoteQuantity, 64)
+		quoteQuantity, err = fixedpoint.NewFromString(t.QuoteQuantity)
 		if err != nil {
 			return nil, errors.Wrapf(err, "quote quantity parse error, quoteQuantity: %+v", t.QuoteQuantity)
 		}
 	} else {
-		quoteQuantity = price * quantity
+		quoteQuantity = price.Mul(quantity)
 	}
 
-	fee, err := strconv.ParseFloat(t.Commission, 64)
+	fee, err := fixedpoint.NewFromString(t.Commission)
 	if err != nil {
 		return nil, errors.Wrapf(err, "commission parse error, commission: %+v", t.Commission)
 	}
@@ -284,3 +286,211 @@ func ConvertTrades(remoteTrades []*binance.TradeV3) (trades []types.Trade, err e
 
 	return trades, err
 }
+
+func toGlobalFuturesTicker(stats *futures.PriceChangeStats) types.Ticker {
+	return types.Ticker{
+		Volume: util.MustParseFloat(stats.Volume),
+		Last:   util.MustParseFloat(stats.LastPrice),
+		Open:   util.MustParseFloat(stats.OpenPrice),
+		High:   util.MustParseFloat(stats.HighPrice),
+		Low:    util.MustParseFloat(stats.LowPrice),
+		Time:   millisecondTime(stats.CloseTime),
+	}
+}
+
+func toGlobalFuturesMarket(symbol futures.Symbol) types.Market {
+	market := types.Market{
+		Symbol:          symbol.Symbol,
+		PricePrecision:  symbol.PricePrecision,
+		VolumePrecision: symbol.QuantityPrecision,
+		QuoteCurrency:   symbol.QuoteAsset,
+		BaseCurrency:    symbol.BaseAsset,
+	}
+
+	// the MIN_NOTIONAL filter of the futures symbols uses the "notional" field, which is not parsed by go-binance
+	for _, filter := range symbol.Filters {
+		if filter["filterType"] != "MIN_NOTIONAL" {
+			continue
+		}
+
+		if notional, ok := filter["notional"].(string); ok {
+			market.MinNotional = util.MustParseFloat(notional)
+			market.MinAmount = util.MustParseFloat(notional)
+		}
+	}
+
+	if f := symbol.LotSizeFilter(); f != nil {
+		market.MinQuantity = util.MustParseFloat(f.MinQuantity)
+		market.MaxQuantity = util.MustParseFloat(f.MaxQuantity)
+		market.StepSize = util.MustParseFloat(f.StepSize)
+	}
+
+	if f := symbol.PriceFilter(); f != nil {
+		market.MaxPrice = util.MustParseFloat(f.MaxPrice)
+		market.MinPrice = util.MustParseFloat(f.MinPrice)
+		market.TickSize = util.MustParseFloat(f.TickSize)
+	}
+
+	return market
+}
+
+// toGlobalFuturesBalance converts the futures wallet balance, the margin of the positions and the orders is not
+// deducted from the wallet balance, it's reported by the positions.
+func toGlobalFuturesBalance(asset, walletBalance string) types.Balance {
+	return types.Balance{
+		Currency:  asset,
+		Available: fixedpoint.MustNewFromString(walletBalance),
+	}
+}
+
+func toGlobalFuturesKLine(symbol string, interval types.Interval, k *futures.Kline) types.KLine {
+	return types.KLine{
+		Exchange:       "binance",
+		Symbol:         symbol,
+		Interval:       interval,
+		StartTime:      millisecondTime(k.OpenTime),
+		EndTime:        millisecondTime(k.CloseTime),
+		Open:           util.MustParseFloat(k.Open),
+		Close:          util.MustParseFloat(k.Close),
+		High:           util.MustParseFloat(k.High),
+		Low:            util.MustParseFloat(k.Low),
+		Volume:         util.MustParseFloat(k.Volume),
+		QuoteVolume:    util.MustParseFloat(k.QuoteAssetVolume),
+		NumberOfTrades: uint64(k.TradeNum),
+		Closed:         true,
+	}
+}
+
+func toLocalFuturesOrderType(orderType types.OrderType) (futures.OrderType, error) {
+	switch orderType {
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		return futures.OrderTypeLimit, nil
+
+	case types.OrderTypeStopLimit:
+		return futures.OrderTypeStop, nil
+
+	case types.OrderTypeStopMarket:
+		return futures.OrderTypeStopMarket, nil
+
+	case types.OrderTypeMarket:
+		return futures.OrderTypeMarket, nil
+	}
+
+	return "", fmt.Errorf("futures order type %s not supported", orderType)
+}
+
+func toGlobalFuturesOrderType(orderType futures.OrderType, timeInForce futures.TimeInForceType) types.OrderType {
+	switch orderType {
+
+	case futures.OrderTypeLimit:
+		if timeInForce == futures.TimeInForceTypeGTX {
+			return types.OrderTypeLimitMaker
+		}
+		return types.OrderTypeLimit
+
+	case futures.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case futures.OrderTypeStop, futures.OrderTypeTakeProfit:
+		return types.OrderTypeStopLimit
+
+	case futures.OrderTypeStopMarket, futures.OrderTypeTakeProfitMarket, futures.OrderTypeTrailingStopMarket:
+		return types.OrderTypeStopMarket
+
+	default:
+		log.Errorf("unsupported futures order type: %v", orderType)
+		return ""
+	}
+}
+
+func toGlobalFuturesOrders(futuresOrders []*futures.Order) (orders []types.Order, err error) {
+	for _, futuresOrder := range futuresOrders {
+		order, err := toGlobalFuturesOrder(futuresOrder)
+		if err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, *order)
+	}
+
+	return orders, err
+}
+
+func toGlobalFuturesOrder(futuresOrder *futures.Order) (*types.Order, error) {
+	status := toGlobalOrderStatus(binance.OrderStatusType(futuresOrder.Status))
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: futuresOrder.ClientOrderID,
+			Symbol:        futuresOrder.Symbol,
+			Side:          toGlobalSideType(binance.SideType(futuresOrder.Side)),
+			Type:          toGlobalFuturesOrderType(futuresOrder.Type, futuresOrder.TimeInForce),
+			Quantity:      fixedpoint.MustNewFromString(futuresOrder.OrigQuantity),
+			Price:         fixedpoint.MustNewFromString(futuresOrder.Price),
+			TimeInForce:   string(futuresOrder.TimeInForce),
+		},
+		Exchange:         types.ExchangeBinance.String(),
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		OrderID:          uint64(futuresOrder.OrderID),
+		Status:           status,
+		ExecutedQuantity: fixedpoint.MustNewFromString(futuresOrder.ExecutedQuantity),
+		CreationTime:     datatype.Time(millisecondTime(futuresOrder.Time)),
+		UpdateTime:       datatype.Time(millisecondTime(futuresOrder.UpdateTime)),
+	}, nil
+}
+
+func toGlobalFuturesTrade(t futures.AccountTrade) (*types.Trade, error) {
+	price, err := fixedpoint.NewFromString(t.Price)
+	if err != nil {
+		return nil, errors.Wrapf(err, "price parse error, price: %+v", t.Price)
+	}
+
+	quantity, err := fixedpoint.NewFromString(t.Quantity)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quantity parse error, quantity: %+v", t.Quantity)
+	}
+
+	quoteQuantity, err := fixedpoint.NewFromString(t.QuoteQuantity)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quote quantity parse error, quoteQuantity: %+v", t.QuoteQuantity)
+	}
+
+	fee, err := fixedpoint.NewFromString(t.Commission)
+	if err != nil {
+		return nil, errors.Wrapf(err, "commission parse error, commission: %+v", t.Commission)
+	}
+
+	return &types.Trade{
+		ID:            t.ID,
+		OrderID:       uint64(t.OrderID),
+		Price:         price,
+		Symbol:        t.Symbol,
+		Exchange:      "binance",
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Side:          toGlobalSideType(binance.SideType(t.Side)),
+		IsBuyer:       t.Buyer,
+		IsMaker:       t.Maker,
+		Fee:           fee,
+		FeeCurrency:   t.CommissionAsset,
+		Time:          datatype.Time(millisecondTime(t.Time)),
+	}, nil
+}
+
+func toGlobalFuturesPosition(risk *futures.PositionRisk) (*types.FuturesPosition, error) {
+	leverage, err := strconv.Atoi(risk.Leverage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "leverage parse error, leverage: %+v", risk.Leverage)
+	}
+
+	return &types.FuturesPosition{
+		Symbol:           risk.Symbol,
+		PositionSide:     risk.PositionSide,
+		Quantity:         fixedpoint.MustNewFromString(risk.PositionAmt),
+		EntryPrice:       fixedpoint.MustNewFromString(risk.EntryPrice),
+		MarkPrice:        fixedpoint.MustNewFromString(risk.MarkPrice),
+		LiquidationPrice: fixedpoint.MustNewFromString(risk.LiquidationPrice),
+		UnrealizedProfit: fixedpoint.MustNewFromString(risk.UnRealizedProfit),
+		Leverage:         leverage,
+		Isolated:         strings.EqualFold(risk.MarginType, "isolated"),
+	}, nil
+}
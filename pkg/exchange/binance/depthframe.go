@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
 //go:generate callbackgen -type DepthFrame
@@ -14,14 +17,18 @@ type DepthFrame struct {
 	client  *binance.Client
 	context context.Context
 
+	// futuresClient is set for the futures books, the futures depth events are chained by the previous update ID
+	futuresClient *futures.Client
+
 	mu            sync.Mutex
 	once          sync.Once
 	SnapshotDepth *DepthEvent
 	Symbol        string
 	BufEvents     []DepthEvent
 
-	readyCallbacks []func(snapshotDepth DepthEvent, bufEvents []DepthEvent)
-	pushCallbacks  []func(e DepthEvent)
+	readyCallbacks   []func(snapshotDepth DepthEvent, bufEvents []DepthEvent)
+	pushCallbacks    []func(e DepthEvent)
+	invalidCallbacks []func(reason types.BookInvalidReason)
 }
 
 func (f *DepthFrame) reset() {
@@ -60,7 +67,12 @@ func (f *DepthFrame) loadDepthSnapshot() {
 	// filter the events by the event IDs
 	var events []DepthEvent
 	for _, e := range f.BufEvents {
-		if e.FirstUpdateID <= depth.FinalUpdateID || e.FinalUpdateID <= depth.FinalUpdateID {
+		if f.futuresClient != nil {
+			// the first futures event should cover the snapshot, U <= lastUpdateId <= u
+			if e.FinalUpdateID < depth.FinalUpdateID {
+				continue
+			}
+		} else if e.FirstUpdateID <= depth.FinalUpdateID || e.FinalUpdateID <= depth.FinalUpdateID {
 			continue
 		}
 
@@ -73,16 +85,25 @@ func (f *DepthFrame) loadDepthSnapshot() {
 	// then there are something missed, we need to restart the process.
 	if len(events) > 0 {
 		e := events[0]
-		if e.FirstUpdateID > depth.FinalUpdateID+1 {
+		if !f.follows(e, depth.FinalUpdateID) {
 			log.Warn("miss matched final update id for order book")
 			f.SnapshotDepth = nil
 			f.BufEvents = nil
 			f.mu.Unlock()
+
+			f.EmitInvalid(types.BookInvalidReasonSequenceGap)
 			return
 		}
 	}
 
 	f.SnapshotDepth = depth
+	if f.futuresClient != nil && len(events) > 0 {
+		// the next futures event is chained to the last buffered event, keep the emitted snapshot untouched
+		snapshot := *depth
+		snapshot.FinalUpdateID = events[len(events)-1].FinalUpdateID
+		f.SnapshotDepth = &snapshot
+	}
+
 	f.BufEvents = nil
 	f.mu.Unlock()
 
@@ -132,13 +153,15 @@ func (f *DepthFrame) PushEvent(e DepthEvent) {
 		}
 
 		// if the first update ID > final update ID + 1, it means something is missing, we need to reload.
-		if e.FirstUpdateID > f.SnapshotDepth.FinalUpdateID+1 {
+		if !f.follows(e, f.SnapshotDepth.FinalUpdateID) {
 			if debugBinanceDepth {
 				log.Warnf("event first update id %d > final update id + 1 (%d), resetting snapshot", e.FirstUpdateID, f.SnapshotDepth.FirstUpdateID+1)
 			}
 
 			f.SnapshotDepth = nil
 			f.mu.Unlock()
+
+			f.EmitInvalid(types.BookInvalidReasonSequenceGap)
 			return
 		}
 
@@ -150,12 +173,27 @@ func (f *DepthFrame) PushEvent(e DepthEvent) {
 	}
 }
 
+// follows returns true if there is no missing update between the final update ID and the event.
+// The spot events are continuous by the update IDs, and the futures events are chained by the previous update ID "pu",
+// except the first event after the snapshot, which covers the last update ID of the snapshot.
+func (f *DepthFrame) follows(e DepthEvent, finalUpdateID int64) bool {
+	if f.futuresClient != nil {
+		return e.PreviousUpdateID == finalUpdateID || e.FirstUpdateID <= finalUpdateID
+	}
+
+	return e.FirstUpdateID <= finalUpdateID+1
+}
+
 // fetch fetches the depth and convert to the depth event so that we can reuse the event structure to convert it to the global orderbook type
 func (f *DepthFrame) fetch(ctx context.Context) (*DepthEvent, error) {
 	if debugBinanceDepth {
 		log.Infof("fetching %s depth snapshot", f.Symbol)
 	}
 
+	if f.futuresClient != nil {
+		return f.fetchFutures(ctx)
+	}
+
 	response, err := f.client.NewDepthService().Symbol(f.Symbol).Do(ctx)
 	if err != nil {
 		return nil, err
@@ -176,3 +214,25 @@ func (f *DepthFrame) fetch(ctx context.Context) (*DepthEvent, error) {
 
 	return &event, nil
 }
+
+func (f *DepthFrame) fetchFutures(ctx context.Context) (*DepthEvent, error) {
+	response, err := f.futuresClient.NewDepthService().Symbol(f.Symbol).Limit(1000).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	event := DepthEvent{
+		FirstUpdateID: 0,
+		FinalUpdateID: response.LastUpdateID,
+	}
+
+	for _, entry := range response.Bids {
+		event.Bids = append(event.Bids, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+	}
+
+	for _, entry := range response.Asks {
+		event.Asks = append(event.Asks, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+	}
+
+	return &event, nil
+}
@@ -2,7 +2,9 @@
 
 package binance
 
-import ()
+import (
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
 
 func (f *DepthFrame) OnReady(cb func(snapshotDepth DepthEvent, bufEvents []DepthEvent)) {
 	f.readyCallbacks = append(f.readyCallbacks, cb)
@@ -23,3 +25,13 @@ func (f *DepthFrame) EmitPush(e DepthEvent) {
 		cb(e)
 	}
 }
+
+func (f *DepthFrame) OnInvalid(cb func(reason types.BookInvalidReason)) {
+	f.invalidCallbacks = append(f.invalidCallbacks, cb)
+}
+
+func (f *DepthFrame) EmitInvalid(reason types.BookInvalidReason) {
+	for _, cb := range f.invalidCallbacks {
+		cb(reason)
+	}
+}
@@ -0,0 +1,99 @@
+package binance
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/pkg/errors"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// the error codes of the binance api, see https://binance-docs.github.io/apidocs/spot/en/#error-codes
+const (
+	errCodeUnauthorized       = -1002
+	errCodeTooManyRequests    = -1003
+	errCodeTooManyOrders      = -1015
+	errCodeInvalidSignature   = -1022
+	errCodeFilterFailure      = -1013
+	errCodeBadPrecision       = -1111
+	errCodeBadSymbol          = -1121
+	errCodeNewOrderRejected   = -2010
+	errCodeCancelRejected     = -2011
+	errCodeNoSuchOrder        = -2013
+	errCodeBadAPIKeyFormat    = -2014
+	errCodeRejectedAPIKey     = -2015
+	errCodeMarginInsufficient = -2019
+)
+
+// bannedUntilRegExp matches the ban timestamp of the 418 response, e.g. "IP banned until 1625097600000"
+var bannedUntilRegExp = regexp.MustCompile(`banned until (\d+)`)
+
+// toGlobalError maps the api error of binance to the error types, the other errors are returned as they are
+func toGlobalError(err error) error {
+	var apiErr *common.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.Code {
+	case errCodeTooManyRequests, errCodeTooManyOrders:
+		return types.NewRateLimitError(bannedDuration(apiErr.Message), err)
+
+	case errCodeUnauthorized, errCodeInvalidSignature, errCodeBadAPIKeyFormat, errCodeRejectedAPIKey:
+		return types.NewExchangeError(types.ErrAuthentication, err)
+
+	case errCodeBadSymbol:
+		return types.NewExchangeError(types.ErrInvalidSymbol, err)
+
+	case errCodeNoSuchOrder:
+		return types.NewExchangeError(types.ErrOrderNotFound, err)
+
+	case errCodeMarginInsufficient:
+		return types.NewExchangeError(types.ErrInsufficientBalance, err)
+
+	case errCodeBadPrecision:
+		return types.NewExchangeError(types.ErrInvalidPrecision, err)
+
+	case errCodeFilterFailure, errCodeNewOrderRejected, errCodeCancelRejected:
+		message := strings.ToLower(apiErr.Message)
+		switch {
+		case strings.Contains(message, "market is closed"):
+			return types.NewExchangeError(types.ErrMarketClosed, err)
+
+		case strings.Contains(message, "insufficient balance"):
+			return types.NewExchangeError(types.ErrInsufficientBalance, err)
+
+		case strings.Contains(message, "unknown order"):
+			return types.NewExchangeError(types.ErrOrderNotFound, err)
+
+		case apiErr.Code == errCodeFilterFailure:
+			// Filter failure: PRICE_FILTER, LOT_SIZE or MIN_NOTIONAL
+			return types.NewExchangeError(types.ErrInvalidPrecision, err)
+		}
+	}
+
+	return err
+}
+
+// bannedDuration returns the duration until the ip ban is lifted, it returns zero if the message has no ban timestamp
+func bannedDuration(message string) time.Duration {
+	matches := bannedUntilRegExp.FindStringSubmatch(message)
+	if len(matches) < 2 {
+		return 0
+	}
+
+	ms, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	if d := time.Until(time.Unix(0, ms*int64(time.Millisecond))); d > 0 {
+		return d
+	}
+
+	return 0
+}
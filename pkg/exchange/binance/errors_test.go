@@ -0,0 +1,64 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func Test_toGlobalError(t *testing.T) {
+	tests := []struct {
+		code    int64
+		message string
+		kind    error
+	}{
+		{-1003, "Too much request weight used; current limit is 1200 request weight per 1 MINUTE.", types.ErrRateLimited},
+		{-1015, "Too many new orders; current limit is 50 orders per TEN_SECONDS.", types.ErrRateLimited},
+		{-2015, "Invalid API-key, IP, or permissions for action.", types.ErrAuthentication},
+		{-1022, "Signature for this request is not valid.", types.ErrAuthentication},
+		{-1121, "Invalid symbol.", types.ErrInvalidSymbol},
+		{-2013, "Order does not exist.", types.ErrOrderNotFound},
+		{-2011, "Unknown order sent.", types.ErrOrderNotFound},
+		{-2010, "Account has insufficient balance for requested action.", types.ErrInsufficientBalance},
+		{-2019, "Margin is insufficient.", types.ErrInsufficientBalance},
+		{-1013, "Market is closed.", types.ErrMarketClosed},
+		{-1013, "Filter failure: LOT_SIZE", types.ErrInvalidPrecision},
+		{-1111, "Precision is over the maximum defined for this asset.", types.ErrInvalidPrecision},
+	}
+
+	for _, test := range tests {
+		apiErr := &common.APIError{Code: test.code, Message: test.message}
+		err := toGlobalError(apiErr)
+		assert.True(t, errors.Is(err, test.kind), "code %d: %s", test.code, test.message)
+
+		var origin *common.APIError
+		if assert.True(t, errors.As(err, &origin)) {
+			assert.Equal(t, test.code, origin.Code)
+		}
+	}
+
+	// the unknown errors are returned as they are
+	apiErr := &common.APIError{Code: -1000, Message: "An unknown error occurred while processing the request."}
+	assert.Equal(t, error(apiErr), toGlobalError(apiErr))
+
+	networkErr := fmt.Errorf("connection reset by peer")
+	assert.Equal(t, networkErr, toGlobalError(networkErr))
+}
+
+func Test_toGlobalError_IPBanned(t *testing.T) {
+	bannedUntil := time.Now().Add(2 * time.Minute)
+	err := toGlobalError(&common.APIError{
+		Code:    -1003,
+		Message: fmt.Sprintf("Way too much request weight used; IP banned until %d.", bannedUntil.UnixNano()/int64(time.Millisecond)),
+	})
+
+	retryAfter, ok := types.RetryAfter(err)
+	assert.True(t, ok)
+	assert.InDelta(t, float64(2*time.Minute), float64(retryAfter), float64(5*time.Second))
+}
@@ -9,12 +9,14 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/exchange"
 	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 	"github.com/ycdesu/spreaddog/pkg/util"
@@ -24,9 +26,44 @@ var log = logrus.WithFields(logrus.Fields{
 	"exchange": "binance",
 })
 
+var capabilities = types.ExchangeCapabilities{
+	Margin:      true,
+	Transfer:    true,
+	Channels:    []types.Channel{types.BookChannel, types.KLineChannel, types.MarketTradeChannel, types.BookTickerChannel},
+	OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeMarket, types.OrderTypeStopLimit, types.OrderTypeStopMarket},
+	TimeInForce: []string{"GTC", "IOC", "FOK"},
+
+	// MaxOpenOrders is the MAX_NUM_ORDERS filter of the spot symbols
+	MaxOpenOrders: 200,
+}
+
+var futuresCapabilities = types.ExchangeCapabilities{
+	Futures:  true,
+	Channels: []types.Channel{types.BookChannel, types.KLineChannel, types.MarketTradeChannel, types.BookTickerChannel},
+	// the limit maker orders are sent as the GTX (post only) limit orders
+	OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeMarket, types.OrderTypeStopLimit, types.OrderTypeStopMarket},
+	TimeInForce: []string{"GTC", "IOC", "FOK", "GTX"},
+
+	// MaxOpenOrders is the MAX_NUM_ORDERS filter of the USDⓈ-M futures symbols
+	MaxOpenOrders: 200,
+}
+
 func init() {
 	_ = types.Exchange(&Exchange{})
 	_ = types.MarginExchange(&Exchange{})
+	_ = types.FuturesExchange(&Exchange{})
+	_ = types.ExchangeFuturesService(&Exchange{})
+	_ = types.ExchangeOrderQueryService(&Exchange{})
+	_ = types.ExchangeCancelAllService(&Exchange{})
+
+	exchange.Register(exchange.Registration{
+		Name:    types.ExchangeBinance,
+		Aliases: []string{"bn"},
+		New: func(c exchange.Credentials) types.Exchange {
+			return New(c.Key, c.Secret)
+		},
+		Capabilities: capabilities,
+	})
 
 	if ok, _ := strconv.ParseBool(os.Getenv("DEBUG_BINANCE_STREAM")); ok {
 		log.Level = logrus.DebugLevel
@@ -35,14 +72,24 @@ func init() {
 
 type Exchange struct {
 	types.MarginSettings
+	types.FuturesSettings
 
 	Client *binance.Client
+
+	// FuturesClient is the client of the USDⓈ-M futures api, it's used when the futures mode is enabled
+	FuturesClient *futures.Client
 }
 
 func New(key, secret string) *Exchange {
 	var client = binance.NewClient(key, secret)
+	client.HTTPClient = newHTTPClient("binance", key, spotLimits)
+
+	var futuresClient = futures.NewClient(key, secret)
+	futuresClient.HTTPClient = newHTTPClient("binance_futures", key, futuresLimits)
+
 	return &Exchange{
-		Client: client,
+		Client:        client,
+		FuturesClient: futuresClient,
 	}
 }
 
@@ -51,11 +98,15 @@ func (e *Exchange) Name() types.ExchangeName {
 }
 
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	if e.IsFutures {
+		return e.queryFuturesTicker(ctx, symbol)
+	}
+
 	req := e.Client.NewListPriceChangeStatsService()
 	req.Symbol(strings.ToUpper(symbol))
 	stats, err := req.Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	ticker := toGlobalTicker(stats[0])
@@ -75,10 +126,14 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[stri
 		return tickers, nil
 	}
 
+	if e.IsFutures {
+		return e.queryFuturesTickers(ctx, symbol...)
+	}
+
 	var req = e.Client.NewListPriceChangeStatsService()
 	changeStats, err := req.Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	m := make(map[string]struct{})
@@ -111,11 +166,15 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[stri
 }
 
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if e.IsFutures {
+		return e.queryFuturesMarkets(ctx)
+	}
+
 	log.Info("querying market info...")
 
 	exchangeInfo, err := e.Client.NewExchangeInfoService().Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	markets := types.MarketMap{}
@@ -157,24 +216,44 @@ func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 }
 
 func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float64, error) {
+	if e.IsFutures {
+		// the futures api doesn't have the average price, the mark price is used instead
+		index, err := e.QueryPremiumIndex(ctx, symbol)
+		if err != nil {
+			return 0, err
+		}
+
+		return index.MarkPrice.Float64(), nil
+	}
+
 	resp, err := e.Client.NewAveragePriceService().Symbol(symbol).Do(ctx)
 	if err != nil {
-		return 0, err
+		return 0, toGlobalError(err)
 	}
 
 	return util.MustParseFloat(resp.Price), nil
 }
 
+// NewStream returns a sharded stream, the subscriptions are spread across the connections by maxStreamsPerConnection
 func (e *Exchange) NewStream() types.Stream {
-	stream := NewStream(e.Client)
-	stream.MarginSettings = e.MarginSettings
-	return stream
+	var maxStreams = maxStreamsPerConnection
+	if e.IsFutures {
+		maxStreams = maxFuturesStreamsPerConnection
+	}
+
+	return types.NewShardedStream(maxStreams, func() types.Stream {
+		stream := NewStream(e.Client)
+		stream.MarginSettings = e.MarginSettings
+		stream.FuturesSettings = e.FuturesSettings
+		stream.FuturesClient = e.FuturesClient
+		return stream
+	})
 }
 
 func (e *Exchange) QueryMarginAccount(ctx context.Context) (*types.MarginAccount, error) {
 	account, err := e.Client.NewGetMarginAccountService().Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	return toGlobalMarginAccount(account), nil
@@ -188,7 +267,7 @@ func (e *Exchange) QueryIsolatedMarginAccount(ctx context.Context, symbols ...st
 
 	account, err := req.Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	return toGlobalIsolatedMarginAccount(account), nil
@@ -362,10 +441,22 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return "BNB"
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	if e.IsFutures {
+		return futuresCapabilities
+	}
+
+	return capabilities
+}
+
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if e.IsFutures {
+		return e.queryFuturesAccount(ctx)
+	}
+
 	account, err := e.Client.NewGetAccountService().Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	var balances = map[string]types.Balance{}
@@ -387,13 +478,17 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 }
 
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if e.IsFutures {
+		return e.queryFuturesOpenOrders(ctx, symbol)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOpenOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
 
 		binanceOrders, err := req.Do(ctx)
 		if err != nil {
-			return orders, err
+			return orders, toGlobalError(err)
 		}
 
 		return ToGlobalOrders(binanceOrders)
@@ -401,7 +496,7 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 
 	binanceOrders, err := e.Client.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
 	if err != nil {
-		return orders, err
+		return orders, toGlobalError(err)
 	}
 
 	return ToGlobalOrders(binanceOrders)
@@ -414,6 +509,10 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 	log.Infof("querying closed orders %s from %s <=> %s ...", symbol, since, until)
 
+	if e.IsFutures {
+		return e.queryFuturesClosedOrders(ctx, symbol, since, until, lastOrderID)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
@@ -427,7 +526,7 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 		binanceOrders, err := req.Do(ctx)
 		if err != nil {
-			return orders, err
+			return orders, toGlobalError(err)
 		}
 
 		return ToGlobalOrders(binanceOrders)
@@ -445,13 +544,17 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 	binanceOrders, err := req.Do(ctx)
 	if err != nil {
-		return orders, err
+		return orders, toGlobalError(err)
 	}
 
 	return ToGlobalOrders(binanceOrders)
 }
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	if e.IsFutures {
+		return e.cancelFuturesOrders(ctx, orders...)
+	}
+
 	for _, o := range orders {
 		var req = e.Client.NewCancelOrderService()
 
@@ -461,30 +564,163 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 		if o.OrderID > 0 {
 			req.OrderID(int64(o.OrderID))
 		} else if len(o.ClientOrderID) > 0 {
-			req.NewClientOrderID(o.ClientOrderID)
+			req.OrigClientOrderID(o.ClientOrderID)
 		}
 
 		_, err := req.Do(ctx)
 		if err != nil {
 			log.WithError(err).Errorf("order cancel error")
-			err2 = err
+			err2 = toGlobalError(err)
 		}
 	}
 
 	return err2
 }
 
-func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
-	orderType, err := toLocalOrderType(order.Type)
+// QueryOrder queries the order of the symbol by the order id or the client order id
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	if e.IsFutures {
+		return e.queryFuturesOrder(ctx, q)
+	}
+
+	var binanceOrder *binance.Order
+	var err error
+	if e.IsMargin {
+		req := e.Client.NewGetMarginOrderService().Symbol(q.Symbol)
+		req.IsIsolated(e.IsIsolatedMargin)
+		if q.OrderID > 0 {
+			req.OrderID(int64(q.OrderID))
+		} else {
+			req.OrigClientOrderID(q.ClientOrderID)
+		}
+		binanceOrder, err = req.Do(ctx)
+	} else {
+		req := e.Client.NewGetOrderService().Symbol(q.Symbol)
+		if q.OrderID > 0 {
+			req.OrderID(int64(q.OrderID))
+		} else {
+			req.OrigClientOrderID(q.ClientOrderID)
+		}
+		binanceOrder, err = req.Do(ctx)
+	}
+
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	return ToGlobalOrder(binanceOrder, e.IsMargin)
+}
+
+// CancelAllOrders cancels the open orders of all the symbols, it returns the open orders before the cancellation
+func (e *Exchange) CancelAllOrders(ctx context.Context) ([]types.Order, error) {
+	openOrders, err := e.QueryOpenOrders(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var ordersBySymbol = make(map[string][]types.Order)
+	for _, o := range openOrders {
+		ordersBySymbol[o.Symbol] = append(ordersBySymbol[o.Symbol], o)
+	}
+
+	var canceledOrders []types.Order
+	for symbol, orders := range ordersBySymbol {
+		if err := e.cancelOpenOrders(ctx, symbol, orders); err != nil {
+			return canceledOrders, err
+		}
+		canceledOrders = append(canceledOrders, orders...)
+	}
+
+	return types.OrderSlice(canceledOrders).Canceled(), nil
+}
+
+// CancelOrdersBySymbol cancels the open orders of the symbol, it returns the open orders before the cancellation
+func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	orders, err := e.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.cancelOpenOrders(ctx, symbol, orders); err != nil {
+		return nil, err
+	}
+
+	return types.OrderSlice(orders).Canceled(), nil
+}
+
+// CancelOrdersByGroupID cancels the open orders of the group, binance doesn't have the order group, so the orders are
+// selected by the client order id prefix of the group
+func (e *Exchange) CancelOrdersByGroupID(ctx context.Context, groupID uint32) ([]types.Order, error) {
+	openOrders, err := e.QueryOpenOrders(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	clientOrderID := uuid.New().String()
+	var orders []types.Order
+	for _, o := range openOrders {
+		if types.IsGroupClientOrderID(o.ClientOrderID, groupID) {
+			orders = append(orders, o)
+		}
+	}
+
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	if err := e.CancelOrders(ctx, orders...); err != nil {
+		return nil, err
+	}
+
+	return types.OrderSlice(orders).Canceled(), nil
+}
+
+// cancelOpenOrders cancels all the open orders of the symbol in one request, the orders of the margin account are
+// canceled one by one
+func (e *Exchange) cancelOpenOrders(ctx context.Context, symbol string, orders []types.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	switch {
+	case e.IsFutures:
+		if err := e.FuturesClient.NewCancelAllOpenOrdersService().Symbol(symbol).Do(ctx); err != nil {
+			return toGlobalError(err)
+		}
+
+	case e.IsMargin:
+		return e.CancelOrders(ctx, orders...)
+
+	default:
+		if _, err := e.Client.NewCancelOpenOrdersService().Symbol(symbol).Do(ctx); err != nil {
+			return toGlobalError(err)
+		}
+	}
+
+	return nil
+}
+
+// newClientOrderID returns the client order id of the order, the id is generated with the group prefix if the order
+// has the group id, so that the orders of the group can be canceled by CancelOrdersByGroupID
+func newClientOrderID(order types.SubmitOrder) string {
 	if len(order.ClientOrderID) > 0 {
-		clientOrderID = order.ClientOrderID
+		return order.ClientOrderID
+	}
+
+	if order.GroupID > 0 {
+		return types.NewGroupClientOrderID(order.GroupID)
+	}
+
+	return uuid.New().String()
+}
+
+func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	orderType, err := toLocalOrderType(order.Type)
+	if err != nil {
+		return nil, err
 	}
 
+	clientOrderID := newClientOrderID(order)
+
 	req := e.Client.NewCreateMarginOrderService().
 		Symbol(order.Symbol).
 		Type(orderType).
@@ -508,9 +744,9 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 	if len(order.QuantityString) > 0 {
 		req.Quantity(order.QuantityString)
 	} else if order.Market.Symbol != "" {
-		req.Quantity(order.Market.FormatQuantity(order.Quantity))
+		req.Quantity(order.Market.FormatQuantity(order.Quantity.Float64()))
 	} else {
-		req.Quantity(strconv.FormatFloat(order.Quantity, 'f', 8, 64))
+		req.Quantity(order.Quantity.String())
 	}
 
 	// set price field for limit orders
@@ -519,7 +755,7 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 		if len(order.PriceString) > 0 {
 			req.Price(order.PriceString)
 		} else if order.Market.Symbol != "" {
-			req.Price(order.Market.FormatPrice(order.Price))
+			req.Price(order.Market.FormatPrice(order.Price.Float64()))
 		}
 	}
 
@@ -542,7 +778,7 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 
 	response, err := req.Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	log.Infof("margin order creation response: %+v", response)
@@ -573,10 +809,7 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 		return nil, err
 	}
 
-	clientOrderID := uuid.New().String()
-	if len(order.ClientOrderID) > 0 {
-		clientOrderID = order.ClientOrderID
-	}
+	clientOrderID := newClientOrderID(order)
 
 	req := e.Client.NewCreateOrderService().
 		Symbol(order.Symbol).
@@ -587,9 +820,9 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 	if len(order.QuantityString) > 0 {
 		req.Quantity(order.QuantityString)
 	} else if order.Market.Symbol != "" {
-		req.Quantity(order.Market.FormatQuantity(order.Quantity))
+		req.Quantity(order.Market.FormatQuantity(order.Quantity.Float64()))
 	} else {
-		req.Quantity(strconv.FormatFloat(order.Quantity, 'f', 8, 64))
+		req.Quantity(order.Quantity.String())
 	}
 
 	// set price field for limit orders
@@ -598,7 +831,7 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 		if len(order.PriceString) > 0 {
 			req.Price(order.PriceString)
 		} else if order.Market.Symbol != "" {
-			req.Price(order.Market.FormatPrice(order.Price))
+			req.Price(order.Market.FormatPrice(order.Price.Float64()))
 		}
 	}
 
@@ -618,7 +851,7 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 
 	response, err := req.Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	log.Infof("order creation response: %+v", response)
@@ -651,7 +884,9 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 	for _, order := range orders {
 		var createdOrder *types.Order
 
-		if e.IsMargin {
+		if e.IsFutures {
+			createdOrder, err = e.submitFuturesOrder(ctx, order)
+		} else if e.IsMargin {
 			createdOrder, err = e.submitMarginOrder(ctx, order)
 		} else {
 			createdOrder, err = e.submitSpotOrder(ctx, order)
@@ -682,6 +917,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 
 	log.Infof("querying kline %s %s %v", symbol, interval, options)
 
+	if e.IsFutures {
+		return e.queryFuturesKLines(ctx, symbol, interval, limit, options)
+	}
+
 	req := e.Client.NewKlinesService().
 		Symbol(symbol).
 		Interval(string(interval)).
@@ -697,7 +936,7 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 
 	resp, err := req.Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, toGlobalError(err)
 	}
 
 	var kLines []types.KLine
@@ -723,6 +962,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 }
 
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	if e.IsFutures {
+		return e.queryFuturesTrades(ctx, symbol, options)
+	}
+
 	var remoteTrades []*binance.TradeV3
 
 	if e.IsMargin {
@@ -751,7 +994,7 @@ func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *type
 
 		remoteTrades, err = req.Do(ctx)
 		if err != nil {
-			return nil, err
+			return nil, toGlobalError(err)
 		}
 	} else {
 		req := e.Client.NewListTradesService().
@@ -777,7 +1020,7 @@ func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *type
 
 		remoteTrades, err = req.Do(ctx)
 		if err != nil {
-			return nil, err
+			return nil, toGlobalError(err)
 		}
 	}
 
@@ -0,0 +1,423 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/pkg/errors"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// maxFundingRateLimit is the max number of the funding rates of a funding rate history request
+const maxFundingRateLimit = 1000
+
+func (e *Exchange) queryFuturesTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	stats, err := e.FuturesClient.NewListPriceChangeStatsService().Symbol(strings.ToUpper(symbol)).Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("futures ticker %s not found", symbol)
+	}
+
+	ticker := toGlobalFuturesTicker(stats[0])
+	return &ticker, nil
+}
+
+func (e *Exchange) queryFuturesTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	changeStats, err := e.FuturesClient.NewListPriceChangeStatsService().Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	m := make(map[string]struct{})
+	for _, s := range symbol {
+		m[strings.ToUpper(s)] = struct{}{}
+	}
+
+	var tickers = make(map[string]types.Ticker)
+	for _, stats := range changeStats {
+		if _, ok := m[stats.Symbol]; len(symbol) != 0 && !ok {
+			continue
+		}
+
+		tickers[stats.Symbol] = toGlobalFuturesTicker(stats)
+	}
+
+	return tickers, nil
+}
+
+func (e *Exchange) queryFuturesMarkets(ctx context.Context) (types.MarketMap, error) {
+	log.Info("querying futures market info...")
+
+	exchangeInfo, err := e.FuturesClient.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	markets := types.MarketMap{}
+	for _, symbol := range exchangeInfo.Symbols {
+		markets[symbol.Symbol] = toGlobalFuturesMarket(symbol)
+	}
+
+	return markets, nil
+}
+
+// queryFuturesAccount queries the futures wallet balances, the commission rates are not provided by the futures
+// account api.
+func (e *Exchange) queryFuturesAccount(ctx context.Context) (*types.Account, error) {
+	account, err := e.FuturesClient.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	var balances = map[string]types.Balance{}
+	for _, asset := range account.Assets {
+		balances[asset.Asset] = toGlobalFuturesBalance(asset.Asset, asset.WalletBalance)
+	}
+
+	a := &types.Account{}
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+func (e *Exchange) queryFuturesOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	futuresOrders, err := e.FuturesClient.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	return toGlobalFuturesOrders(futuresOrders)
+}
+
+func (e *Exchange) queryFuturesClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	req := e.FuturesClient.NewListOrdersService().Symbol(symbol)
+
+	if lastOrderID > 0 {
+		req.OrderID(int64(lastOrderID))
+	} else {
+		req.StartTime(since.UnixNano() / int64(time.Millisecond)).
+			EndTime(until.UnixNano() / int64(time.Millisecond))
+	}
+
+	futuresOrders, err := req.Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	return toGlobalFuturesOrders(futuresOrders)
+}
+
+func (e *Exchange) cancelFuturesOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	for _, o := range orders {
+		req := e.FuturesClient.NewCancelOrderService().Symbol(o.Symbol)
+
+		if o.OrderID > 0 {
+			req.OrderID(int64(o.OrderID))
+		} else if len(o.ClientOrderID) > 0 {
+			req.OrigClientOrderID(o.ClientOrderID)
+		}
+
+		if _, err := req.Do(ctx); err != nil {
+			log.WithError(err).Errorf("futures order cancel error")
+			err2 = toGlobalError(err)
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) queryFuturesOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	req := e.FuturesClient.NewGetOrderService().Symbol(q.Symbol)
+	if q.OrderID > 0 {
+		req.OrderID(int64(q.OrderID))
+	} else {
+		req.OrigClientOrderID(q.ClientOrderID)
+	}
+
+	futuresOrder, err := req.Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	return toGlobalFuturesOrder(futuresOrder)
+}
+
+func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	orderType, err := toLocalFuturesOrderType(order.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOrderID := newClientOrderID(order)
+
+	req := e.FuturesClient.NewCreateOrderService().
+		Symbol(order.Symbol).
+		Side(futures.SideType(order.Side)).
+		NewClientOrderID(clientOrderID).
+		Type(orderType).
+		NewOrderResponseType(futures.NewOrderRespTypeRESULT)
+
+	if len(order.QuantityString) > 0 {
+		req.Quantity(order.QuantityString)
+	} else if order.Market.Symbol != "" {
+		req.Quantity(order.Market.FormatQuantity(order.Quantity.Float64()))
+	} else {
+		req.Quantity(order.Quantity.String())
+	}
+
+	// set price field for limit orders
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		if len(order.PriceString) > 0 {
+			req.Price(order.PriceString)
+		} else if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(order.Price.Float64()))
+		} else {
+			req.Price(order.Price.String())
+		}
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if len(order.StopPriceString) == 0 {
+			return nil, fmt.Errorf("stop price string can not be empty")
+		}
+
+		req.StopPrice(order.StopPriceString)
+	}
+
+	// the time in force is required by the futures limit orders, and the limit maker orders are the GTX orders
+	switch {
+	case order.Type == types.OrderTypeLimitMaker:
+		req.TimeInForce(futures.TimeInForceTypeGTX)
+
+	case len(order.TimeInForce) > 0:
+		req.TimeInForce(futures.TimeInForceType(order.TimeInForce))
+
+	case order.Type == types.OrderTypeLimit || order.Type == types.OrderTypeStopLimit:
+		req.TimeInForce(futures.TimeInForceTypeGTC)
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	log.Infof("futures order creation response: %+v", response)
+
+	return toGlobalFuturesOrder(&futures.Order{
+		Symbol:           response.Symbol,
+		OrderID:          response.OrderID,
+		ClientOrderID:    response.ClientOrderID,
+		Price:            response.Price,
+		ReduceOnly:       response.ReduceOnly,
+		OrigQuantity:     response.OrigQuantity,
+		ExecutedQuantity: response.ExecutedQuantity,
+		CumQuote:         response.CumQuote,
+		Status:           response.Status,
+		TimeInForce:      response.TimeInForce,
+		Type:             response.Type,
+		Side:             response.Side,
+		StopPrice:        response.StopPrice,
+		Time:             response.UpdateTime,
+		UpdateTime:       response.UpdateTime,
+		PositionSide:     response.PositionSide,
+	})
+}
+
+func (e *Exchange) queryFuturesKLines(ctx context.Context, symbol string, interval types.Interval, limit int, options types.KLineQueryOptions) ([]types.KLine, error) {
+	req := e.FuturesClient.NewKlinesService().
+		Symbol(symbol).
+		Interval(string(interval)).
+		Limit(limit)
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	var kLines []types.KLine
+	for _, k := range resp {
+		kLines = append(kLines, toGlobalFuturesKLine(symbol, interval, k))
+	}
+
+	return kLines, nil
+}
+
+func (e *Exchange) queryFuturesTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	req := e.FuturesClient.NewListAccountTradeService().Symbol(symbol)
+
+	if options.Limit > 0 {
+		req.Limit(int(options.Limit))
+	} else {
+		req.Limit(1000)
+	}
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	// BINANCE uses inclusive last trade ID
+	if options.LastTradeID > 0 {
+		req.FromID(options.LastTradeID)
+	}
+
+	remoteTrades, err := req.Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	for _, t := range remoteTrades {
+		localTrade, err := toGlobalFuturesTrade(*t)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert binance futures trade: %+v", t)
+			continue
+		}
+
+		trades = append(trades, *localTrade)
+	}
+
+	return trades, nil
+}
+
+// premiumIndex is the response of /fapi/v1/premiumIndex, the PremiumIndex of go-binance doesn't have the index price
+type premiumIndex struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	LastFundingRate string `json:"lastFundingRate"`
+	NextFundingTime int64  `json:"nextFundingTime"`
+	Time            int64  `json:"time"`
+}
+
+// QueryPremiumIndex queries the mark price, the index price and the last funding rate of the perpetual contract
+func (e *Exchange) QueryPremiumIndex(ctx context.Context, symbol string) (*types.PremiumIndex, error) {
+	endpoint := e.FuturesClient.BaseURL + "/fapi/v1/premiumIndex?" + url.Values{"symbol": {strings.ToUpper(symbol)}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.FuturesClient.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := new(common.APIError)
+		if err := json.Unmarshal(data, apiErr); err != nil {
+			return nil, errors.Wrapf(err, "premium index request error, status: %d", resp.StatusCode)
+		}
+		return nil, toGlobalError(apiErr)
+	}
+
+	var index premiumIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+
+	return &types.PremiumIndex{
+		Symbol:          index.Symbol,
+		MarkPrice:       fixedpoint.MustNewFromString(index.MarkPrice),
+		IndexPrice:      fixedpoint.MustNewFromString(index.IndexPrice),
+		LastFundingRate: fixedpoint.MustNewFromString(index.LastFundingRate),
+		NextFundingTime: millisecondTime(index.NextFundingTime),
+		Time:            millisecondTime(index.Time),
+	}, nil
+}
+
+// QueryFundingRateHistory queries the funding rates of the perpetual contract in the ascending order of the funding
+// time, the history is queried page by page.
+func (e *Exchange) QueryFundingRateHistory(ctx context.Context, symbol string, since, until time.Time) (rates []types.FundingRate, err error) {
+	startTime := since
+	for startTime.Before(until) {
+		resp, err := e.FuturesClient.NewFundingRateService().
+			Symbol(symbol).
+			StartTime(startTime.UnixNano() / int64(time.Millisecond)).
+			EndTime(until.UnixNano() / int64(time.Millisecond)).
+			Limit(maxFundingRateLimit).
+			Do(ctx)
+		if err != nil {
+			return rates, err
+		}
+
+		for _, r := range resp {
+			rates = append(rates, types.FundingRate{
+				Symbol:      r.Symbol,
+				FundingRate: fixedpoint.MustNewFromString(r.FundingRate),
+				FundingTime: millisecondTime(r.FundingTime),
+			})
+		}
+
+		if len(resp) < maxFundingRateLimit {
+			break
+		}
+
+		startTime = millisecondTime(resp[len(resp)-1].FundingTime).Add(time.Millisecond)
+	}
+
+	return rates, nil
+}
+
+// QueryPositions queries the open positions, the symbols without position are not returned
+func (e *Exchange) QueryPositions(ctx context.Context) (positions []types.FuturesPosition, err error) {
+	risks, err := e.FuturesClient.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, toGlobalError(err)
+	}
+
+	for _, risk := range risks {
+		position, err := toGlobalFuturesPosition(risk)
+		if err != nil {
+			return nil, err
+		}
+
+		if position.Quantity == 0 {
+			continue
+		}
+
+		positions = append(positions, *position)
+	}
+
+	return positions, nil
+}
+
+func (e *Exchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	resp, err := e.FuturesClient.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	if err != nil {
+		return toGlobalError(err)
+	}
+
+	log.Infof("%s leverage is changed to %d, max notional value: %s", resp.Symbol, resp.Leverage, resp.MaxNotionalValue)
+	return nil
+}
@@ -0,0 +1,415 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// futuresFixtures are the recorded responses of the futures api by the request method and path
+var futuresFixtures = map[string]string{
+	"GET /fapi/v1/exchangeInfo":     "testdata/futures/exchange_info.json",
+	"GET /fapi/v1/premiumIndex":     "testdata/futures/premium_index.json",
+	"GET /fapi/v1/fundingRate":      "testdata/futures/funding_rate.json",
+	"GET /fapi/v1/positionRisk":     "testdata/futures/position_risk.json",
+	"POST /fapi/v1/leverage":        "testdata/futures/leverage.json",
+	"GET /fapi/v1/klines":           "testdata/futures/klines.json",
+	"GET /fapi/v1/depth":            "testdata/futures/depth.json",
+	"GET /fapi/v1/account":          "testdata/futures/account.json",
+	"GET /fapi/v1/openOrders":       "testdata/futures/open_orders.json",
+	"POST /fapi/v1/order":           "testdata/futures/order.json",
+	"GET /fapi/v1/order":            "testdata/futures/order.json",
+	"DELETE /fapi/v1/allOpenOrders": "testdata/futures/cancel_all_open_orders.json",
+	"GET /fapi/v1/userTrades":       "testdata/futures/user_trades.json",
+}
+
+// newTestFuturesExchange returns the futures exchange of the test server, the server serves the fixtures and verifies
+// the signatures of the signed requests. The form values of the requests are sent to the returned channel.
+func newTestFuturesExchange(t *testing.T) (*Exchange, chan url.Values, func()) {
+	requests := make(chan url.Values, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		if i := strings.Index(r.URL.RawQuery, "signature="); i >= 0 {
+			mac := hmac.New(sha256.New, []byte("secret"))
+			_, _ = mac.Write([]byte(strings.TrimSuffix(r.URL.RawQuery[:i], "&") + string(body)))
+			assert.Equal(t, fmt.Sprintf("%x", mac.Sum(nil)), r.URL.Query().Get("signature"))
+			assert.Equal(t, "key", r.Header.Get("X-MBX-APIKEY"))
+		}
+
+		form, err := url.ParseQuery(string(body))
+		assert.NoError(t, err)
+		for k, v := range r.URL.Query() {
+			form[k] = v
+		}
+		requests <- form
+
+		fixture, ok := futuresFixtures[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(w, `{"code":-5000,"msg":"Path not found"}`)
+			return
+		}
+
+		resp, err := ioutil.ReadFile(fixture)
+		assert.NoError(t, err)
+		_, _ = w.Write(resp)
+	}))
+
+	ex := New("key", "secret")
+	ex.UseFutures()
+	ex.FuturesClient.BaseURL = ts.URL
+	return ex, requests, ts.Close
+}
+
+func TestExchange_UseFutures(t *testing.T) {
+	ex := New("key", "secret")
+	assert.False(t, ex.Capabilities().Futures)
+
+	ex.UseFutures()
+	assert.True(t, ex.GetFuturesSettings().IsFutures)
+	assert.True(t, ex.Capabilities().Futures)
+	assert.True(t, ex.Capabilities().HasOrderType(types.OrderTypeLimitMaker))
+	assert.Empty(t, ex.Capabilities().Missing(types.ExchangeCapabilities{
+		Futures:  true,
+		Channels: []types.Channel{types.BookChannel, types.KLineChannel},
+	}))
+}
+
+func TestExchange_QueryFuturesMarkets(t *testing.T) {
+	ex, _, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	markets, err := ex.QueryMarkets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, types.MarketMap{
+		"BTCUSDT": {
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 3,
+			QuoteCurrency:   "USDT",
+			BaseCurrency:    "BTC",
+			MinNotional:     5,
+			MinAmount:       5,
+			MinQuantity:     0.001,
+			MaxQuantity:     1000,
+			StepSize:        0.001,
+			MinPrice:        556.72,
+			MaxPrice:        4529764,
+			TickSize:        0.01,
+		},
+	}, markets)
+}
+
+func TestExchange_QueryPremiumIndex(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	index, err := ex.QueryPremiumIndex(context.Background(), "btcusdt")
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", (<-requests).Get("symbol"))
+	assert.Equal(t, &types.PremiumIndex{
+		Symbol:          "BTCUSDT",
+		MarkPrice:       fixedpoint.MustNewFromString("35012.34"),
+		IndexPrice:      fixedpoint.MustNewFromString("34998.12345678"),
+		LastFundingRate: fixedpoint.MustNewFromString("0.0001"),
+		NextFundingTime: time.Unix(1625126400, 0),
+		Time:            time.Unix(1625112000, 0),
+	}, index)
+	assert.Equal(t, "14.21654322", index.Basis().String())
+
+	price, err := ex.QueryAveragePrice(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 35012.34, price)
+}
+
+func TestExchange_QueryPremiumIndex_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, `{"code":-1121,"msg":"Invalid symbol."}`)
+	}))
+	defer ts.Close()
+
+	ex := New("key", "secret")
+	ex.FuturesClient.BaseURL = ts.URL
+
+	_, err := ex.QueryPremiumIndex(context.Background(), "NOPE")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Invalid symbol.")
+	}
+}
+
+func TestExchange_QueryFundingRateHistory(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	since := time.Unix(1625011200, 0)
+	until := time.Unix(1625097600, 0)
+	rates, err := ex.QueryFundingRateHistory(context.Background(), "BTCUSDT", since, until)
+	assert.NoError(t, err)
+
+	req := <-requests
+	assert.Equal(t, "1625011200000", req.Get("startTime"))
+	assert.Equal(t, "1625097600000", req.Get("endTime"))
+	assert.Equal(t, "1000", req.Get("limit"))
+
+	// the history is shorter than the page limit, no more request is sent
+	assert.Len(t, requests, 0)
+
+	if assert.Len(t, rates, 3) {
+		assert.Equal(t, types.FundingRate{
+			Symbol:      "BTCUSDT",
+			FundingRate: fixedpoint.MustNewFromString("-0.00005218"),
+			FundingTime: time.Unix(1625040000, 0),
+		}, rates[1])
+	}
+}
+
+func TestExchange_QueryPositions(t *testing.T) {
+	ex, _, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	positions, err := ex.QueryPositions(context.Background())
+	assert.NoError(t, err)
+
+	// the symbols without position are not returned
+	assert.Equal(t, []types.FuturesPosition{
+		{
+			Symbol:           "BTCUSDT",
+			PositionSide:     "BOTH",
+			Quantity:         fixedpoint.MustNewFromString("-0.1"),
+			EntryPrice:       fixedpoint.MustNewFromString("34500.5"),
+			MarkPrice:        fixedpoint.MustNewFromString("35012.34"),
+			LiquidationPrice: fixedpoint.MustNewFromString("31250.75"),
+			UnrealizedProfit: fixedpoint.MustNewFromString("-51.184"),
+			Leverage:         10,
+			Isolated:         true,
+		},
+	}, positions)
+	assert.True(t, positions[0].IsShort())
+}
+
+func TestExchange_SetLeverage(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	assert.NoError(t, ex.SetLeverage(context.Background(), "BTCUSDT", 5))
+
+	req := <-requests
+	assert.Equal(t, "BTCUSDT", req.Get("symbol"))
+	assert.Equal(t, "5", req.Get("leverage"))
+}
+
+func TestExchange_QueryFuturesKLines(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	klines, err := ex.QueryKLines(context.Background(), "BTCUSDT", types.Interval1m, types.KLineQueryOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", (<-requests).Get("limit"))
+
+	if assert.Len(t, klines, 2) {
+		assert.Equal(t, types.KLine{
+			Exchange:       "binance",
+			Symbol:         "BTCUSDT",
+			Interval:       types.Interval1m,
+			StartTime:      time.Unix(1625097600, 0),
+			EndTime:        time.Unix(1625097659, 999*int64(time.Millisecond)),
+			Open:           35040,
+			Close:          35012.34,
+			High:           35100,
+			Low:            34980.5,
+			Volume:         152.345,
+			QuoteVolume:    5334567.12,
+			NumberOfTrades: 2345,
+			Closed:         true,
+		}, klines[0])
+	}
+}
+
+func TestExchange_QueryFuturesAccount(t *testing.T) {
+	ex, _, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	balances, err := ex.QueryAccountBalances(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, types.BalanceMap{
+		"USDT": {
+			Currency:  "USDT",
+			Available: fixedpoint.NewFromInt(10000),
+		},
+	}, balances)
+}
+
+func TestExchange_FuturesOrders(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	orders, err := ex.QueryOpenOrders(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	<-requests
+
+	assert.Equal(t, []types.Order{
+		{
+			SubmitOrder: types.SubmitOrder{
+				ClientOrderID: "maker-1",
+				Symbol:        "BTCUSDT",
+				Side:          types.SideTypeBuy,
+				Type:          types.OrderTypeLimitMaker,
+				Quantity:      fixedpoint.MustNewFromString("0.05"),
+				Price:         fixedpoint.NewFromInt(34800),
+				TimeInForce:   "GTX",
+			},
+			Exchange:         types.ExchangeBinance.String(),
+			OrderID:          8389765498,
+			Status:           types.OrderStatusPartiallyFilled,
+			ExecutedQuantity: fixedpoint.MustNewFromString("0.01"),
+			IsWorking:        true,
+			CreationTime:     orders[0].CreationTime,
+			UpdateTime:       orders[0].UpdateTime,
+		},
+	}, orders)
+	assert.Equal(t, time.Unix(1625097600, 0), orders[0].CreationTime.Time())
+
+	created, err := ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		ClientOrderID: "taker-1",
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeSell,
+		Type:          types.OrderTypeLimit,
+		Quantity:      fixedpoint.MustNewFromString("0.05"),
+		Price:         fixedpoint.NewFromInt(35500),
+	})
+	assert.NoError(t, err)
+
+	req := <-requests
+	assert.Equal(t, "LIMIT", req.Get("type"))
+	assert.Equal(t, "SELL", req.Get("side"))
+	assert.Equal(t, "0.05", req.Get("quantity"))
+	assert.Equal(t, "35500", req.Get("price"))
+	assert.Equal(t, "GTC", req.Get("timeInForce"))
+	assert.Equal(t, "taker-1", req.Get("newClientOrderId"))
+
+	if assert.Len(t, created, 1) {
+		assert.Equal(t, uint64(8389765499), created[0].OrderID)
+		assert.Equal(t, types.OrderStatusNew, created[0].Status)
+		assert.True(t, created[0].IsWorking)
+	}
+
+	// the limit maker orders are sent as the GTX limit orders
+	_, err = ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimitMaker,
+		Quantity: fixedpoint.MustNewFromString("0.05"),
+		Price:    fixedpoint.NewFromInt(34000),
+	})
+	assert.NoError(t, err)
+
+	req = <-requests
+	assert.Equal(t, "LIMIT", req.Get("type"))
+	assert.Equal(t, "GTX", req.Get("timeInForce"))
+}
+
+func TestExchange_QueryFuturesOrder(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	order, err := ex.QueryOrder(context.Background(), types.OrderQuery{Symbol: "BTCUSDT", ClientOrderID: "taker-1"})
+	assert.NoError(t, err)
+
+	req := <-requests
+	assert.Equal(t, "BTCUSDT", req.Get("symbol"))
+	assert.Equal(t, "taker-1", req.Get("origClientOrderId"))
+	assert.Empty(t, req.Get("orderId"))
+
+	assert.Equal(t, uint64(8389765499), order.OrderID)
+	assert.Equal(t, types.SideTypeSell, order.Side)
+	assert.Equal(t, types.OrderStatusNew, order.Status)
+
+	_, err = ex.QueryOrder(context.Background(), types.OrderQuery{Symbol: "BTCUSDT", OrderID: 8389765499, ClientOrderID: "taker-1"})
+	assert.NoError(t, err)
+
+	req = <-requests
+	assert.Equal(t, "8389765499", req.Get("orderId"))
+	assert.Empty(t, req.Get("origClientOrderId"))
+}
+
+func TestExchange_CancelFuturesOrdersBySymbol(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	orders, err := ex.CancelOrdersBySymbol(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+
+	// the open orders are queried before the cancellation, since the cancel all api doesn't return the orders
+	assert.Equal(t, "BTCUSDT", (<-requests).Get("symbol"))
+	assert.Equal(t, "BTCUSDT", (<-requests).Get("symbol"))
+
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, uint64(8389765498), orders[0].OrderID)
+		assert.Equal(t, types.OrderStatusCanceled, orders[0].Status)
+		assert.False(t, orders[0].IsWorking)
+	}
+}
+
+func TestExchange_SubmitFuturesOrders_GroupID(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	_, err := ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.MustNewFromString("0.05"),
+		Price:    fixedpoint.NewFromInt(35500),
+		GroupID:  1234,
+	})
+	assert.NoError(t, err)
+
+	// the client order id is generated with the group prefix for CancelOrdersByGroupID
+	clientOrderID := (<-requests).Get("newClientOrderId")
+	assert.True(t, types.IsGroupClientOrderID(clientOrderID, 1234), clientOrderID)
+	assert.LessOrEqual(t, len(clientOrderID), 36)
+}
+
+func TestExchange_QueryFuturesTrades(t *testing.T) {
+	ex, requests, closeServer := newTestFuturesExchange(t)
+	defer closeServer()
+
+	trades, err := ex.QueryTrades(context.Background(), "BTCUSDT", &types.TradeQueryOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "1000", (<-requests).Get("limit"))
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, types.Trade{
+			ID:            698759,
+			OrderID:       8389765498,
+			Exchange:      "binance",
+			Price:         fixedpoint.NewFromInt(34800),
+			Quantity:      fixedpoint.MustNewFromString("0.01"),
+			QuoteQuantity: fixedpoint.NewFromInt(348),
+			Symbol:        "BTCUSDT",
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			IsMaker:       true,
+			Fee:           fixedpoint.MustNewFromString("0.0696"),
+			FeeCurrency:   "USDT",
+			Time:          trades[0].Time,
+		}, trades[0])
+	}
+}
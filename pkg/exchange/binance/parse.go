@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/valyala/fastjson"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
@@ -107,13 +109,13 @@ func (e *ExecutionReportEvent) Order() (*types.Order, error) {
 			ClientOrderID: e.ClientOrderID,
 			Side:          toGlobalSideType(binance.SideType(e.Side)),
 			Type:          toGlobalOrderType(binance.OrderType(e.OrderType)),
-			Quantity:      util.MustParseFloat(e.OrderQuantity),
-			Price:         util.MustParseFloat(e.OrderPrice),
+			Quantity:      fixedpoint.MustNewFromString(e.OrderQuantity),
+			Price:         fixedpoint.MustNewFromString(e.OrderPrice),
 			TimeInForce:   e.TimeInForce,
 		},
 		OrderID:          uint64(e.OrderID),
 		Status:           toGlobalOrderStatus(binance.OrderStatusType(e.CurrentOrderStatus)),
-		ExecutedQuantity: util.MustParseFloat(e.CumulativeFilledQuantity),
+		ExecutedQuantity: fixedpoint.MustNewFromString(e.CumulativeFilledQuantity),
 		CreationTime:     datatype.Time(orderCreationTime),
 	}, nil
 }
@@ -130,13 +132,13 @@ func (e *ExecutionReportEvent) Trade() (*types.Trade, error) {
 		Symbol:        e.Symbol,
 		OrderID:       uint64(e.OrderID),
 		Side:          toGlobalSideType(binance.SideType(e.Side)),
-		Price:         util.MustParseFloat(e.LastExecutedPrice),
-		Quantity:      util.MustParseFloat(e.LastExecutedQuantity),
-		QuoteQuantity: util.MustParseFloat(e.LastQuoteAssetTransactedQuantity),
+		Price:         fixedpoint.MustNewFromString(e.LastExecutedPrice),
+		Quantity:      fixedpoint.MustNewFromString(e.LastExecutedQuantity),
+		QuoteQuantity: fixedpoint.MustNewFromString(e.LastQuoteAssetTransactedQuantity),
 		IsBuyer:       e.Side == "BUY",
 		IsMaker:       e.IsMaker,
 		Time:          datatype.Time(tt),
-		Fee:           util.MustParseFloat(e.CommissionAmount),
+		Fee:           fixedpoint.MustNewFromString(e.CommissionAmount),
 		FeeCurrency:   e.CommissionAsset,
 	}, nil
 }
@@ -278,19 +280,195 @@ func ParseEvent(message string) (interface{}, error) {
 		err := json.Unmarshal([]byte(message), &event)
 		return &event, err
 
+	case "ACCOUNT_UPDATE":
+		var event FuturesAccountUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
+	case "ORDER_TRADE_UPDATE":
+		var event FuturesOrderTradeUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	case "depthUpdate":
 		return parseDepthEvent(val)
 
+	case "trade":
+		var event MarketTradeEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
+	case "aggTrade":
+		var event AggTradeEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	default:
 		id := val.GetInt("id")
 		if id > 0 {
 			return &ResultEvent{ID: id}, nil
 		}
+
+		// the book ticker payload does not contain the event type
+		if val.Exists("u") && val.Exists("b") && val.Exists("a") {
+			var event BookTickerEvent
+			err := json.Unmarshal([]byte(message), &event)
+			return &event, err
+		}
 	}
 
 	return nil, fmt.Errorf("unsupported message: %s", message)
 }
 
+/*
+
+trade
+
+{
+  "e": "trade",     // Event type
+  "E": 123456789,   // Event time
+  "s": "BNBBTC",    // Symbol
+  "t": 12345,       // Trade ID
+  "p": "0.001",     // Price
+  "q": "100",       // Quantity
+  "b": 88,          // Buyer order ID
+  "a": 50,          // Seller order ID
+  "T": 123456785,   // Trade time
+  "m": true,        // Is the buyer the market maker?
+  "M": true         // Ignore
+}
+*/
+type MarketTradeEvent struct {
+	EventBase
+
+	Symbol        string `json:"s"`
+	TradeID       int64  `json:"t"`
+	Price         string `json:"p"`
+	Quantity      string `json:"q"`
+	BuyerOrderID  int64  `json:"b"`
+	SellerOrderID int64  `json:"a"`
+	TradeTime     int64  `json:"T"`
+	IsBuyerMaker  bool   `json:"m"`
+
+	// Ignore is declared to prevent "M" being matched to "m" by the case-insensitive json decoder
+	Ignore bool `json:"M"`
+}
+
+func (e *MarketTradeEvent) Trade() (*types.Trade, error) {
+	return toGlobalMarketTrade(e.Symbol, e.TradeID, e.Price, e.Quantity, e.TradeTime, e.IsBuyerMaker)
+}
+
+/*
+
+aggTrade
+
+{
+  "e": "aggTrade",  // Event type
+  "E": 123456789,   // Event time
+  "s": "BNBBTC",    // Symbol
+  "a": 12345,       // Aggregate trade ID
+  "p": "0.001",     // Price
+  "q": "100",       // Quantity
+  "f": 100,         // First trade ID
+  "l": 105,         // Last trade ID
+  "T": 123456785,   // Trade time
+  "m": true,        // Is the buyer the market maker?
+  "M": true         // Ignore
+}
+*/
+type AggTradeEvent struct {
+	EventBase
+
+	Symbol       string `json:"s"`
+	AggTradeID   int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	FirstTradeID int64  `json:"f"`
+	LastTradeID  int64  `json:"l"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+
+	// Ignore is declared to prevent "M" being matched to "m" by the case-insensitive json decoder
+	Ignore bool `json:"M"`
+}
+
+func (e *AggTradeEvent) Trade() (*types.Trade, error) {
+	return toGlobalMarketTrade(e.Symbol, e.AggTradeID, e.Price, e.Quantity, e.TradeTime, e.IsBuyerMaker)
+}
+
+func toGlobalMarketTrade(symbol string, id int64, priceStr, quantityStr string, tradeTime int64, isBuyerMaker bool) (*types.Trade, error) {
+	price, err := fixedpoint.NewFromString(priceStr)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := fixedpoint.NewFromString(quantityStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the taker is the seller if the buyer is the maker
+	side := types.SideTypeBuy
+	if isBuyerMaker {
+		side = types.SideTypeSell
+	}
+
+	return &types.Trade{
+		ID:            id,
+		Exchange:      types.ExchangeBinance.String(),
+		Symbol:        symbol,
+		Side:          side,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       false,
+		Time:          datatype.Time(millisecondTime(tradeTime)),
+	}, nil
+}
+
+/*
+
+bookTicker
+
+{
+  "u":400900217,     // order book updateId
+  "s":"BNBUSDT",     // symbol
+  "b":"25.35190000", // best bid price
+  "B":"31.21000000", // best bid qty
+  "a":"25.36520000", // best ask price
+  "A":"40.66000000"  // best ask qty
+}
+*/
+type BookTickerEvent struct {
+	UpdateID int64  `json:"u"`
+	Symbol   string `json:"s"`
+	Buy      string `json:"b"`
+	BuySize  string `json:"B"`
+	Sell     string `json:"a"`
+	SellSize string `json:"A"`
+}
+
+// BookTicker converts the event, an error is returned if any of the prices or the quantities is malformed
+func (e *BookTickerEvent) BookTicker() (types.BookTicker, error) {
+	var values [4]float64
+	for i, s := range []string{e.Buy, e.BuySize, e.Sell, e.SellSize} {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return types.BookTicker{}, fmt.Errorf("invalid book ticker of %s: %w", e.Symbol, err)
+		}
+		values[i] = v
+	}
+
+	return types.BookTicker{
+		Symbol:   e.Symbol,
+		Buy:      values[0],
+		BuySize:  values[1],
+		Sell:     values[2],
+		SellSize: values[3],
+	}, nil
+}
+
 type DepthEntry struct {
 	PriceLevel string
 	Quantity   string
@@ -303,6 +481,9 @@ type DepthEvent struct {
 	FirstUpdateID int64  `json:"U"`
 	FinalUpdateID int64  `json:"u"`
 
+	// PreviousUpdateID is the final update ID of the previous event, it's only sent by the futures stream
+	PreviousUpdateID int64 `json:"pu"`
+
 	Bids []DepthEntry
 	Asks []DepthEntry
 }
@@ -375,8 +556,9 @@ func parseDepthEvent(val *fastjson.Value) (*DepthEvent, error) {
 			Time:  val.GetInt64("E"),
 		},
 		Symbol:        string(val.GetStringBytes("s")),
-		FirstUpdateID: val.GetInt64("U"),
-		FinalUpdateID: val.GetInt64("u"),
+		FirstUpdateID:    val.GetInt64("U"),
+		FinalUpdateID:    val.GetInt64("u"),
+		PreviousUpdateID: val.GetInt64("pu"),
 	}
 
 	for _, ev := range val.GetArray("b") {
@@ -484,3 +666,207 @@ type EventBase struct {
 	Event string `json:"e"` // event
 	Time  int64  `json:"E"`
 }
+
+/*
+
+ACCOUNT_UPDATE of the futures user data stream
+
+{
+  "e": "ACCOUNT_UPDATE",                // Event Type
+  "E": 1564745798939,                   // Event Time
+  "T": 1564745798938 ,                  // Transaction
+  "a": {
+    "m": "ORDER",                       // Event reason type
+    "B": [                              // Balances
+      {
+        "a": "USDT",                    // Asset
+        "wb": "122624.12345678",        // Wallet Balance
+        "cw": "100.12345678",           // Cross Wallet Balance
+        "bc": "50.12345678"             // Balance Change except PnL and Commission
+      }
+    ],
+    "P": [
+      {
+        "s": "BTCUSDT",                 // Symbol
+        "pa": "0",                      // Position Amount
+        "ep": "0.00000",                // Entry Price
+        "cr": "200",                    // (Pre-fee) Accumulated Realized
+        "up": "0",                      // Unrealized PnL
+        "mt": "isolated",               // Margin Type
+        "iw": "0.00000000",             // Isolated Wallet (if isolated position)
+        "ps": "BOTH"                    // Position Side
+      }
+    ]
+  }
+}
+
+*/
+type FuturesBalance struct {
+	Asset              string `json:"a"`
+	WalletBalance      string `json:"wb"`
+	CrossWalletBalance string `json:"cw"`
+}
+
+type FuturesPositionUpdate struct {
+	Symbol              string `json:"s"`
+	PositionAmount      string `json:"pa"`
+	EntryPrice          string `json:"ep"`
+	AccumulatedRealized string `json:"cr"`
+	UnrealizedPnL       string `json:"up"`
+	MarginType          string `json:"mt"`
+	IsolatedWallet      string `json:"iw"`
+	PositionSide        string `json:"ps"`
+}
+
+type FuturesAccountUpdate struct {
+	Reason    string                  `json:"m"`
+	Balances  []FuturesBalance        `json:"B,omitempty"`
+	Positions []FuturesPositionUpdate `json:"P,omitempty"`
+}
+
+type FuturesAccountUpdateEvent struct {
+	EventBase
+
+	TransactionTime int64                `json:"T"`
+	Account         FuturesAccountUpdate `json:"a"`
+}
+
+/*
+
+ORDER_TRADE_UPDATE of the futures user data stream
+
+{
+  "e": "ORDER_TRADE_UPDATE",     // Event Type
+  "E": 1568879465651,            // Event Time
+  "T": 1568879465650,            // Transaction Time
+  "o": {
+    "s": "BTCUSDT",              // Symbol
+    "c": "TEST",                 // Client Order Id
+    "S": "SELL",                 // Side
+    "o": "TRAILING_STOP_MARKET", // Order Type
+    "f": "GTC",                  // Time in Force
+    "q": "0.001",                // Original Quantity
+    "p": "0",                    // Original Price
+    "ap": "0",                   // Average Price
+    "sp": "7103.04",             // Stop Price
+    "x": "NEW",                  // Execution Type
+    "X": "NEW",                  // Order Status
+    "i": 8886774,                // Order Id
+    "l": "0",                    // Order Last Filled Quantity
+    "z": "0",                    // Order Filled Accumulated Quantity
+    "L": "0",                    // Last Filled Price
+    "N": "USDT",                 // Commission Asset
+    "n": "0",                    // Commission
+    "T": 1568879465651,          // Order Trade Time
+    "t": 0,                      // Trade Id
+    "b": "0",                    // Bids Notional
+    "a": "9.91",                 // Ask Notional
+    "m": false,                  // Is this trade the maker side?
+    "R": false,                  // Is this reduce only
+    "wt": "CONTRACT_PRICE",      // Stop Price Working Type
+    "ot": "TRAILING_STOP_MARKET",// Original Order Type
+    "ps": "LONG",                // Position Side
+    "cp": false,                 // If Close-All
+    "AP": "7476.89",             // Activation Price, only puhed with TRAILING_STOP_MARKET order
+    "cr": "5.0",                 // Callback Rate, only puhed with TRAILING_STOP_MARKET order
+    "rp": "0"                    // Realized Profit of the trade
+  }
+}
+
+*/
+type FuturesOrderUpdate struct {
+	Symbol        string `json:"s"`
+	ClientOrderID string `json:"c"`
+	Side          string `json:"S"`
+	OrderType     string `json:"o"`
+	TimeInForce   string `json:"f"`
+
+	OrderQuantity string `json:"q"`
+	OrderPrice    string `json:"p"`
+	AveragePrice  string `json:"ap"`
+	StopPrice     string `json:"sp"`
+
+	// ActivationPrice is declared to avoid the case insensitive matching of "AP" to "ap"
+	ActivationPrice string `json:"AP"`
+
+	CurrentExecutionType string `json:"x"`
+	CurrentOrderStatus   string `json:"X"`
+
+	OrderID int64 `json:"i"`
+
+	LastFilledQuantity       string `json:"l"`
+	CumulativeFilledQuantity string `json:"z"`
+	LastFilledPrice          string `json:"L"`
+
+	CommissionAsset  string `json:"N"`
+	CommissionAmount string `json:"n"`
+
+	TradeTime int64 `json:"T"`
+	TradeID   int64 `json:"t"`
+
+	IsMaker      bool   `json:"m"`
+	IsReduceOnly bool   `json:"R"`
+	PositionSide string `json:"ps"`
+
+	RealizedProfit string `json:"rp"`
+}
+
+type FuturesOrderTradeUpdateEvent struct {
+	EventBase
+
+	TransactionTime int64              `json:"T"`
+	OrderUpdate     FuturesOrderUpdate `json:"o"`
+}
+
+func (e *FuturesOrderTradeUpdateEvent) Order() (*types.Order, error) {
+	switch e.OrderUpdate.CurrentExecutionType {
+	case "NEW", "CANCELED", "CALCULATED", "EXPIRED", "AMENDMENT":
+	default:
+		return nil, errors.New("futures order trade update is not for order")
+	}
+
+	o := e.OrderUpdate
+	status := toGlobalOrderStatus(binance.OrderStatusType(o.CurrentOrderStatus))
+	return &types.Order{
+		Exchange: string(types.ExchangeBinance),
+		SubmitOrder: types.SubmitOrder{
+			Symbol:        o.Symbol,
+			ClientOrderID: o.ClientOrderID,
+			Side:          toGlobalSideType(binance.SideType(o.Side)),
+			Type:          toGlobalFuturesOrderType(futures.OrderType(o.OrderType), futures.TimeInForceType(o.TimeInForce)),
+			Quantity:      fixedpoint.MustNewFromString(o.OrderQuantity),
+			Price:         fixedpoint.MustNewFromString(o.OrderPrice),
+			TimeInForce:   o.TimeInForce,
+		},
+		OrderID:          uint64(o.OrderID),
+		Status:           status,
+		ExecutedQuantity: fixedpoint.MustNewFromString(o.CumulativeFilledQuantity),
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		UpdateTime:       datatype.Time(millisecondTime(e.TransactionTime)),
+	}, nil
+}
+
+func (e *FuturesOrderTradeUpdateEvent) Trade() (*types.Trade, error) {
+	if e.OrderUpdate.CurrentExecutionType != "TRADE" {
+		return nil, errors.New("futures order trade update is not a trade")
+	}
+
+	o := e.OrderUpdate
+	price := fixedpoint.MustNewFromString(o.LastFilledPrice)
+	quantity := fixedpoint.MustNewFromString(o.LastFilledQuantity)
+	return &types.Trade{
+		ID:            o.TradeID,
+		Exchange:      string(types.ExchangeBinance),
+		Symbol:        o.Symbol,
+		OrderID:       uint64(o.OrderID),
+		Side:          toGlobalSideType(binance.SideType(o.Side)),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		IsBuyer:       o.Side == "BUY",
+		IsMaker:       o.IsMaker,
+		Time:          datatype.Time(millisecondTime(o.TradeTime)),
+		Fee:           fixedpoint.MustNewFromString(o.CommissionAmount),
+		FeeCurrency:   o.CommissionAsset,
+	}, nil
+}
@@ -3,8 +3,12 @@ package binance
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
 var jsCommentTrimmer = regexp.MustCompile("(?m)//.*$")
@@ -183,3 +187,106 @@ func TestParseOrderUpdate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, orderUpdate)
 }
+
+func TestParseMarketTrade(t *testing.T) {
+	payload := `{
+  "e": "trade",     // Event type
+  "E": 123456789,   // Event time
+  "s": "BNBBTC",    // Symbol
+  "t": 12345,       // Trade ID
+  "p": "0.001",     // Price
+  "q": "100",       // Quantity
+  "b": 88,          // Buyer order ID
+  "a": 50,          // Seller order ID
+  "T": 123456785,   // Trade time
+  "m": true,        // Is the buyer the market maker?
+  "M": true         // Ignore
+}`
+
+	payload = jsCommentTrimmer.ReplaceAllLiteralString(payload, "")
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	tradeEvent, ok := event.(*MarketTradeEvent)
+	assert.True(t, ok)
+
+	trade, err := tradeEvent.Trade()
+	assert.NoError(t, err)
+	assert.Equal(t, "BNBBTC", trade.Symbol)
+	assert.Equal(t, int64(12345), trade.ID)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.001), trade.Price)
+	assert.Equal(t, fixedpoint.NewFromFloat(100.0), trade.Quantity)
+	// buyer is the maker, so the taker side is sell
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.Equal(t, int64(123456785), trade.Time.Time().UnixNano()/int64(time.Millisecond))
+}
+
+func TestParseAggTrade(t *testing.T) {
+	payload := `{
+  "e": "aggTrade",  // Event type
+  "E": 123456789,   // Event time
+  "s": "BNBBTC",    // Symbol
+  "a": 12345,       // Aggregate trade ID
+  "p": "0.001",     // Price
+  "q": "100",       // Quantity
+  "f": 100,         // First trade ID
+  "l": 105,         // Last trade ID
+  "T": 123456785,   // Trade time
+  "m": false,       // Is the buyer the market maker?
+  "M": true         // Ignore
+}`
+
+	payload = jsCommentTrimmer.ReplaceAllLiteralString(payload, "")
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	aggTradeEvent, ok := event.(*AggTradeEvent)
+	assert.True(t, ok)
+
+	trade, err := aggTradeEvent.Trade()
+	assert.NoError(t, err)
+	assert.Equal(t, types.SideTypeBuy, trade.Side)
+	assert.Equal(t, int64(12345), trade.ID)
+}
+
+func TestParseBookTicker(t *testing.T) {
+	payload := `{
+  "u":400900217,     // order book updateId
+  "s":"BNBUSDT",     // symbol
+  "b":"25.35190000", // best bid price
+  "B":"31.21000000", // best bid qty
+  "a":"25.36520000", // best ask price
+  "A":"40.66000000"  // best ask qty
+}`
+
+	payload = jsCommentTrimmer.ReplaceAllLiteralString(payload, "")
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	bookTickerEvent, ok := event.(*BookTickerEvent)
+	assert.True(t, ok)
+
+	bookTicker, err := bookTickerEvent.BookTicker()
+	assert.NoError(t, err)
+	assert.Equal(t, types.BookTicker{
+		Symbol:   "BNBUSDT",
+		Buy:      25.3519,
+		BuySize:  31.21,
+		Sell:     25.3652,
+		SellSize: 40.66,
+	}, bookTicker)
+}
+
+func TestParseBookTicker_Malformed(t *testing.T) {
+	event, err := ParseEvent(`{"u":400900217,"s":"BNBUSDT","b":"25.35190000","B":"","a":"25.36520000","A":"40.66000000"}`)
+	assert.NoError(t, err)
+
+	bookTickerEvent, ok := event.(*BookTickerEvent)
+	if assert.True(t, ok) {
+		_, err = bookTickerEvent.BookTicker()
+		assert.Error(t, err)
+	}
+}
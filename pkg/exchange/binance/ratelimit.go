@@ -0,0 +1,117 @@
+package binance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/exchange/ratelimit"
+)
+
+// the request weight limits of the spot and the futures api, binance reports the used weight in the response header
+var (
+	spotLimits = ratelimit.Limits{
+		Weight:           1200,
+		Interval:         time.Minute,
+		UsedWeightHeader: "X-MBX-USED-WEIGHT-1M",
+	}
+
+	futuresLimits = ratelimit.Limits{
+		Weight:           2400,
+		Interval:         time.Minute,
+		UsedWeightHeader: "X-MBX-USED-WEIGHT-1M",
+	}
+)
+
+// newHTTPClient returns the http client sharing the rate limiter of the api key
+func newHTTPClient(name, key string, limits ratelimit.Limits) *http.Client {
+	return &http.Client{
+		Transport: ratelimit.NewTransport(name, key, limits, requestWeight),
+	}
+}
+
+// requestWeight returns the weight of the endpoint, the weights of the endpoints not listed are 1.
+// see https://binance-docs.github.io/apidocs/spot/en/#limits and https://binance-docs.github.io/apidocs/futures/en/#limits
+func requestWeight(req *http.Request) int {
+	query := req.URL.Query()
+	hasSymbol := len(query.Get("symbol")) > 0
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	path := req.URL.Path
+	if strings.HasPrefix(path, "/fapi/") {
+		// the default limit of the depth and the kline endpoints is 500
+		if limit == 0 {
+			limit = 500
+		}
+
+		switch path {
+		case "/fapi/v1/depth":
+			switch {
+			case limit <= 50:
+				return 2
+			case limit <= 100:
+				return 5
+			case limit <= 500:
+				return 10
+			default:
+				return 20
+			}
+
+		case "/fapi/v1/klines":
+			switch {
+			case limit < 100:
+				return 1
+			case limit < 500:
+				return 2
+			case limit <= 1000:
+				return 5
+			default:
+				return 10
+			}
+
+		case "/fapi/v1/ticker/24hr", "/fapi/v1/openOrders":
+			if hasSymbol {
+				return 1
+			}
+			return 40
+
+		case "/fapi/v1/allOrders", "/fapi/v1/userTrades", "/fapi/v1/account", "/fapi/v2/account",
+			"/fapi/v1/positionRisk", "/fapi/v2/positionRisk":
+			return 5
+		}
+
+		return 1
+	}
+
+	switch path {
+	case "/api/v3/depth":
+		switch {
+		case limit <= 100:
+			return 1
+		case limit <= 500:
+			return 5
+		case limit <= 1000:
+			return 10
+		default:
+			return 50
+		}
+
+	case "/api/v3/ticker/24hr":
+		if hasSymbol {
+			return 1
+		}
+		return 40
+
+	case "/api/v3/openOrders":
+		if hasSymbol {
+			return 3
+		}
+		return 40
+
+	case "/api/v3/allOrders", "/api/v3/myTrades", "/api/v3/account", "/api/v3/exchangeInfo":
+		return 10
+	}
+
+	return 1
+}
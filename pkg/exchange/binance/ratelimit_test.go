@@ -0,0 +1,49 @@
+package binance
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/exchange/ratelimit"
+)
+
+func Test_requestWeight(t *testing.T) {
+	tests := map[string]int{
+		"/api/v3/order":                     1,
+		"/api/v3/depth?symbol=BTCUSDT":      1,
+		"/api/v3/depth?limit=1000":          10,
+		"/api/v3/openOrders?symbol=BTCUSDT": 3,
+		"/api/v3/openOrders":                40,
+		"/api/v3/myTrades?symbol=BTCUSDT":   10,
+		"/fapi/v1/depth?limit=1000":         20,
+		"/fapi/v1/depth?limit=5":            2,
+		"/fapi/v1/klines?limit=1500":        10,
+		"/fapi/v1/klines":                   5,
+		"/fapi/v1/openOrders":               40,
+		"/fapi/v1/userTrades":               5,
+		"/fapi/v1/premiumIndex":             1,
+	}
+
+	for endpoint, weight := range tests {
+		u, err := url.Parse("https://api.binance.com" + endpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, weight, requestWeight(&http.Request{URL: u}), endpoint)
+	}
+}
+
+func TestNew_SharedLimiter(t *testing.T) {
+	a := New("shared-key", "secret")
+	b := New("shared-key", "secret")
+
+	limiter := func(client *http.Client) *ratelimit.Limiter {
+		return client.Transport.(*ratelimit.Transport).Limiter
+	}
+
+	// the sessions of the same api key share the limiter, the spot and the futures api have their own limits
+	assert.Same(t, limiter(a.Client.HTTPClient), limiter(b.Client.HTTPClient))
+	assert.Same(t, limiter(a.FuturesClient.HTTPClient), limiter(b.FuturesClient.HTTPClient))
+	assert.NotSame(t, limiter(a.Client.HTTPClient), limiter(a.FuturesClient.HTTPClient))
+}
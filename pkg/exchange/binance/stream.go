@@ -8,12 +8,15 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/gorilla/websocket"
 
 	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/service"
 
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
@@ -37,6 +40,62 @@ func init() {
 	}
 }
 
+// loadDepthBook replaces the local book of the symbol with the snapshot
+func (s *Stream) loadDepthBook(symbol string, snapshot types.OrderBook) {
+	book := &types.OrderBook{Symbol: symbol}
+	book.Load(snapshot)
+
+	s.depthBooksMutex.Lock()
+	s.depthBooks[symbol] = book
+	s.depthBooksMutex.Unlock()
+}
+
+// applyDepthUpdate applies the update to the local book of the frame, it returns false if the update crosses the book.
+// The crossed book is dropped and the frame is reset, so the snapshot is reloaded with the next depth event.
+func (s *Stream) applyDepthUpdate(f *DepthFrame, update types.OrderBook) bool {
+	s.depthBooksMutex.Lock()
+	book, ok := s.depthBooks[f.Symbol]
+	if !ok {
+		s.depthBooksMutex.Unlock()
+		return true
+	}
+
+	book.Update(update)
+
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	crossed := hasBid && hasAsk && bid.Price >= ask.Price
+	if crossed {
+		delete(s.depthBooks, f.Symbol)
+	}
+	s.depthBooksMutex.Unlock()
+
+	if crossed {
+		log.Warnf("%s book is crossed, bid %f >= ask %f, reloading the snapshot", f.Symbol, bid.Price.Float64(), ask.Price.Float64())
+		f.reset()
+		s.EmitBookInvalidated(f.Symbol, types.BookInvalidReasonCrossed)
+		return false
+	}
+
+	return true
+}
+
+// maxStreamsPerConnection is the max number of the streams of a websocket connection. The documented limit is 1024,
+// but a connection with that many streams is hard to keep up with, so the shard size is kept as small as the futures.
+// https://binance-docs.github.io/apidocs/spot/en/#websocket-market-streams
+const maxStreamsPerConnection = 200
+
+// maxFuturesStreamsPerConnection is the max number of the streams of a futures websocket connection
+// https://binance-docs.github.io/apidocs/futures/en/#websocket-market-streams
+const maxFuturesStreamsPerConnection = 200
+
+const spotWebsocketURL = "wss://stream.binance.com:9443/ws"
+
+const futuresWebsocketURL = "wss://fstream.binance.com/ws"
+
+// AggTradeChannel is the binance aggregate trade channel, the aggregate trades are also emitted as market trades.
+var AggTradeChannel = types.Channel("aggTrade")
+
 type StreamRequest struct {
 	// request ID is required
 	ID     int      `json:"id"`
@@ -47,13 +106,23 @@ type StreamRequest struct {
 //go:generate callbackgen -type Stream -interface
 type Stream struct {
 	types.MarginSettings
+	types.FuturesSettings
 
 	types.StandardStream
 
 	Client    *binance.Client
 	ListenKey string
-	Conn      *websocket.Conn
-	connLock  sync.Mutex
+
+	// FuturesClient is used to request the futures listen key and the futures depth snapshot in the futures mode
+	FuturesClient *futures.Client
+
+	ws *service.WebsocketClientBase
+
+	// subscriptionMutex serializes the subscription changes, so that the connect-time request is updated in order
+	subscriptionMutex sync.Mutex
+
+	// requestID is the last ID of the live subscribe/unsubscribe requests, the ID 1 is used by the connect-time request
+	requestID int32
 
 	publicOnly bool
 
@@ -61,21 +130,57 @@ type Stream struct {
 	depthEventCallbacks       []func(e *DepthEvent)
 	kLineEventCallbacks       []func(e *KLineEvent)
 	kLineClosedEventCallbacks []func(e *KLineEvent)
+	marketTradeEventCallbacks []func(e *MarketTradeEvent)
+	aggTradeEventCallbacks    []func(e *AggTradeEvent)
+	bookTickerEventCallbacks  []func(e *BookTickerEvent)
 
 	balanceUpdateEventCallbacks           []func(event *BalanceUpdateEvent)
 	outboundAccountInfoEventCallbacks     []func(event *OutboundAccountInfoEvent)
 	outboundAccountPositionEventCallbacks []func(event *OutboundAccountPositionEvent)
 	executionReportEventCallbacks         []func(event *ExecutionReportEvent)
 
+	futuresAccountUpdateEventCallbacks    []func(event *FuturesAccountUpdateEvent)
+	futuresOrderTradeUpdateEventCallbacks []func(event *FuturesOrderTradeUpdateEvent)
+
 	depthFrames map[string]*DepthFrame
+
+	// depthBooksMutex protects depthBooks, the snapshots are also loaded by the periodic snapshot updater of the frames
+	depthBooksMutex sync.Mutex
+
+	// depthBooks are the local books of the depth frames, they are used to detect the books crossed by the updates
+	depthBooks map[string]*types.OrderBook
 }
 
 func NewStream(client *binance.Client) *Stream {
 	stream := &Stream{
 		Client:      client,
 		depthFrames: make(map[string]*DepthFrame),
+		depthBooks:  make(map[string]*types.OrderBook),
+		requestID:   1,
 	}
 
+	stream.ws = service.NewWebsocketClientBase("", service.DefaultWebsocketClientOptions)
+	stream.ws.SetURLProvider(stream.streamURL)
+	stream.ws.OnConnected(func(conn *websocket.Conn) {
+		log.Infof("websocket connected")
+
+		// use the default ping handler
+		conn.SetPingHandler(nil)
+
+		stream.EmitConnect()
+	})
+	stream.ws.OnDisconnected(func(conn *websocket.Conn) {
+		stream.EmitDisconnect()
+	})
+	stream.ws.OnError(func(err error) {
+		if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+			log.WithError(err).Errorf("read error: %s", err.Error())
+		}
+
+		stream.EmitError(err)
+	})
+	stream.ws.OnMessage(stream.handleMessage)
+
 	stream.OnDepthEvent(func(e *DepthEvent) {
 		f, ok := stream.depthFrames[e.Symbol]
 		if !ok {
@@ -85,6 +190,10 @@ func NewStream(client *binance.Client) *Stream {
 				Symbol:  e.Symbol,
 			}
 
+			if stream.IsFutures {
+				f.futuresClient = stream.FuturesClient
+			}
+
 			stream.depthFrames[e.Symbol] = f
 
 			f.OnReady(func(e DepthEvent, bufEvents []DepthEvent) {
@@ -96,8 +205,17 @@ func NewStream(client *binance.Client) *Stream {
 
 				if valid, err := snapshot.IsValid(); !valid {
 					log.Warnf("depth snapshot is invalid, event: %+v, error: %v", e, err)
+					bid, hasBid := snapshot.BestBid()
+					ask, hasAsk := snapshot.BestAsk()
+					if hasBid && hasAsk && bid.Price > ask.Price {
+						// reload the snapshot with the next depth event
+						f.reset()
+						stream.EmitBookInvalidated(snapshot.Symbol, types.BookInvalidReasonCrossed)
+						return
+					}
 				}
 
+				stream.loadDepthBook(f.Symbol, snapshot)
 				stream.EmitBookSnapshot(snapshot)
 
 				for _, e := range bufEvents {
@@ -107,10 +225,18 @@ func NewStream(client *binance.Client) *Stream {
 						return
 					}
 
+					if !stream.applyDepthUpdate(f, book) {
+						return
+					}
+
 					stream.EmitBookUpdate(book)
 				}
 			})
 
+			f.OnInvalid(func(reason types.BookInvalidReason) {
+				stream.EmitBookInvalidated(f.Symbol, reason)
+			})
+
 			f.OnPush(func(e DepthEvent) {
 				book, err := e.OrderBook()
 				if err != nil {
@@ -118,6 +244,10 @@ func NewStream(client *binance.Client) *Stream {
 					return
 				}
 
+				if !stream.applyDepthUpdate(f, book) {
+					return
+				}
+
 				stream.EmitBookUpdate(book)
 			})
 		} else {
@@ -149,6 +279,36 @@ func NewStream(client *binance.Client) *Stream {
 		}
 	})
 
+	stream.OnMarketTradeEvent(func(e *MarketTradeEvent) {
+		trade, err := e.Trade()
+		if err != nil {
+			log.WithError(err).Error("market trade convert error")
+			return
+		}
+
+		stream.EmitMarketTrade(*trade)
+	})
+
+	stream.OnAggTradeEvent(func(e *AggTradeEvent) {
+		trade, err := e.Trade()
+		if err != nil {
+			log.WithError(err).Error("aggregate trade convert error")
+			return
+		}
+
+		stream.EmitMarketTrade(*trade)
+	})
+
+	stream.OnBookTickerEvent(func(e *BookTickerEvent) {
+		bookTicker, err := e.BookTicker()
+		if err != nil {
+			log.WithError(err).Error("book ticker convert error")
+			return
+		}
+
+		stream.EmitBookTicker(bookTicker)
+	})
+
 	stream.OnExecutionReportEvent(func(e *ExecutionReportEvent) {
 		switch e.CurrentExecutionType {
 
@@ -172,31 +332,47 @@ func NewStream(client *binance.Client) *Stream {
 		}
 	})
 
-	stream.OnConnect(func() {
-		// reset the previous frames
-		for _, f := range stream.depthFrames {
-			f.reset()
-			f.loadDepthSnapshot()
+	stream.OnFuturesAccountUpdateEvent(func(e *FuturesAccountUpdateEvent) {
+		if len(e.Account.Balances) == 0 {
+			return
 		}
 
-		var params []string
-		for _, subscription := range stream.Subscriptions {
-			params = append(params, convertSubscription(subscription))
+		snapshot := types.BalanceMap{}
+		for _, balance := range e.Account.Balances {
+			snapshot[balance.Asset] = toGlobalFuturesBalance(balance.Asset, balance.WalletBalance)
 		}
+		stream.EmitBalanceSnapshot(snapshot)
+	})
 
-		if len(params) == 0 {
-			return
-		}
+	stream.OnFuturesOrderTradeUpdateEvent(func(e *FuturesOrderTradeUpdateEvent) {
+		switch e.OrderUpdate.CurrentExecutionType {
 
-		log.Infof("subscribing channels: %+v", params)
-		err := stream.Conn.WriteJSON(StreamRequest{
-			Method: "SUBSCRIBE",
-			Params: params,
-			ID:     1,
-		})
+		case "NEW", "CANCELED", "CALCULATED", "EXPIRED", "AMENDMENT":
+			order, err := e.Order()
+			if err != nil {
+				log.WithError(err).Error("futures order convert error")
+				return
+			}
 
-		if err != nil {
-			log.WithError(err).Error("subscribe error")
+			stream.EmitOrderUpdate(*order)
+
+		case "TRADE":
+			trade, err := e.Trade()
+			if err != nil {
+				log.WithError(err).Error("futures trade convert error")
+				return
+			}
+
+			stream.EmitTradeUpdate(*trade)
+		}
+	})
+
+	stream.OnConnect(func() {
+		// reset the previous frames, the updates might be lost while reconnecting
+		for _, f := range stream.depthFrames {
+			f.reset()
+			stream.EmitBookInvalidated(f.Symbol, types.BookInvalidReasonReconnect)
+			f.loadDepthSnapshot()
 		}
 	})
 
@@ -207,26 +383,12 @@ func (s *Stream) SetPublicOnly() {
 	s.publicOnly = true
 }
 
-func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
-	var url string
-	if s.publicOnly {
-		url = "wss://stream.binance.com:9443/ws"
-	} else {
-		url = "wss://stream.binance.com:9443/ws/" + listenKey
-	}
-
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-	if err != nil {
-		return nil, err
+func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
+	if s.IsFutures {
+		log.Infof("futures mode is enabled, requesting futures user stream listen key...")
+		return s.FuturesClient.NewStartUserStreamService().Do(ctx)
 	}
 
-	// use the default ping handler
-	conn.SetPingHandler(nil)
-
-	return conn, nil
-}
-
-func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
 	if s.IsMargin {
 		if s.IsIsolatedMargin {
 			log.Infof("isolated margin %s is enabled, requesting margin user stream listen key...", s.IsolatedMarginSymbol)
@@ -244,6 +406,10 @@ func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
 }
 
 func (s *Stream) keepaliveListenKey(ctx context.Context, listenKey string) error {
+	if s.IsFutures {
+		return s.FuturesClient.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+	}
+
 	if s.IsMargin {
 		if s.IsIsolatedMargin {
 			req := s.Client.NewKeepaliveIsolatedMarginUserStreamService().ListenKey(listenKey)
@@ -258,96 +424,172 @@ func (s *Stream) keepaliveListenKey(ctx context.Context, listenKey string) error
 	return s.Client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
 }
 
-func (s *Stream) connect(ctx context.Context) error {
+// websocketURL returns the base url of the spot stream or the futures (fstream) stream
+func (s *Stream) websocketURL() string {
+	if s.IsFutures {
+		return futuresWebsocketURL
+	}
+
+	return spotWebsocketURL
+}
+
+// streamURL returns the url of the stream, a new listen key is requested for each connection of the user data stream.
+func (s *Stream) streamURL(ctx context.Context) (string, error) {
 	if s.publicOnly {
 		log.Infof("stream is set to public only mode")
-	} else {
-		log.Infof("request listen key for creating user data stream...")
+		return s.websocketURL(), nil
+	}
 
-		listenKey, err := s.fetchListenKey(ctx)
-		if err != nil {
-			return err
+	if len(s.ListenKey) > 0 {
+		if err := s.invalidateListenKey(ctx, s.ListenKey); err != nil {
+			log.WithError(err).Error("invalidate listen key error")
 		}
-
-		s.ListenKey = listenKey
-		log.Infof("user data stream created. listenKey: %s", maskListenKey(s.ListenKey))
 	}
 
-	conn, err := s.dial(s.ListenKey)
+	log.Infof("request listen key for creating user data stream...")
+
+	listenKey, err := s.fetchListenKey(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	log.Infof("websocket connected")
-
-	s.connLock.Lock()
-	s.Conn = conn
-	s.connLock.Unlock()
-
-	s.EmitConnect()
-	return nil
+	s.ListenKey = listenKey
+	log.Infof("user data stream created. listenKey: %s", maskListenKey(s.ListenKey))
+	return s.websocketURL() + "/" + listenKey, nil
 }
 
 func convertSubscription(s types.Subscription) string {
 	// binance uses lower case symbol name,
 	// for kline, it's "<symbol>@kline_<interval>"
 	// for depth, it's "<symbol>@depth OR <symbol>@depth@100ms"
+	// for market trade, it's "<symbol>@trade", and "<symbol>@aggTrade" for the aggregate trades
 	switch s.Channel {
 	case types.KLineChannel:
 		return fmt.Sprintf("%s@%s_%s", strings.ToLower(s.Symbol), s.Channel, s.Options.String())
 
 	case types.BookChannel:
 		return fmt.Sprintf("%s@depth", strings.ToLower(s.Symbol))
+
+	case types.MarketTradeChannel:
+		return fmt.Sprintf("%s@trade", strings.ToLower(s.Symbol))
+
+	case AggTradeChannel:
+		return fmt.Sprintf("%s@aggTrade", strings.ToLower(s.Symbol))
+
+	case types.BookTickerChannel:
+		return fmt.Sprintf("%s@bookTicker", strings.ToLower(s.Symbol))
 	}
 
 	return fmt.Sprintf("%s@%s", strings.ToLower(s.Symbol), s.Channel)
 }
 
+// convertFuturesSubscription converts the subscription of the futures stream, the futures stream doesn't have the raw
+// trade channel, so the market trades are subscribed from the aggregate trade channel.
+func convertFuturesSubscription(s types.Subscription) string {
+	if s.Channel == types.MarketTradeChannel {
+		return fmt.Sprintf("%s@aggTrade", strings.ToLower(s.Symbol))
+	}
+
+	return convertSubscription(s)
+}
+
+func (s *Stream) streamName(subscription types.Subscription) string {
+	if s.IsFutures {
+		return convertFuturesSubscription(subscription)
+	}
+
+	return convertSubscription(subscription)
+}
+
 func (s *Stream) Connect(ctx context.Context) error {
-	err := s.connect(ctx)
-	if err != nil {
+	s.updateSubscriptions()
+
+	if err := s.ws.Connect(ctx); err != nil {
 		return err
 	}
 
-	go s.read(ctx)
+	if !s.publicOnly {
+		go s.keepalive(ctx)
+	}
 
 	s.EmitStart()
 	return nil
 }
 
-func (s *Stream) read(ctx context.Context) {
+// Subscribe adds the subscription, the subscribe request is sent immediately if the stream is connected.
+func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.subscriptionMutex.Lock()
+	defer s.subscriptionMutex.Unlock()
+
+	s.StandardStream.Subscribe(channel, symbol, options)
+	s.updateSubscriptions()
+	s.sendStreamRequest("SUBSCRIBE", s.streamName(types.Subscription{
+		Channel: channel,
+		Symbol:  symbol,
+		Options: options,
+	}))
+}
 
-	pingTicker := time.NewTicker(10 * time.Second)
-	defer pingTicker.Stop()
+// Unsubscribe removes the subscriptions of the channel and the symbol, the unsubscribe request is sent immediately if the stream is connected.
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string) {
+	s.subscriptionMutex.Lock()
+	defer s.subscriptionMutex.Unlock()
 
-	keepAliveTicker := time.NewTicker(5 * time.Minute)
-	defer keepAliveTicker.Stop()
+	var params []string
+	for _, sub := range s.GetSubscriptions() {
+		if sub.Channel == channel && sub.Symbol == symbol {
+			params = append(params, s.streamName(sub))
+		}
+	}
 
-	go func() {
-		for {
-			select {
+	s.StandardStream.Unsubscribe(channel, symbol)
+	s.updateSubscriptions()
+	s.sendStreamRequest("UNSUBSCRIBE", params...)
+}
 
-			case <-ctx.Done():
-				return
+// updateSubscriptions batches all the subscriptions into one request, the request is sent after every (re)connection
+func (s *Stream) updateSubscriptions() {
+	var params []string
+	for _, subscription := range s.GetSubscriptions() {
+		params = append(params, s.streamName(subscription))
+	}
 
-			case <-pingTicker.C:
-				s.connLock.Lock()
-				if err := s.Conn.WriteControl(websocket.PingMessage, []byte("hb"), time.Now().Add(3*time.Second)); err != nil {
-					log.WithError(err).Error("ping error", err)
-				}
+	if len(params) == 0 {
+		s.ws.SetSubscriptions()
+		return
+	}
+
+	log.Debugf("subscription channels: %+v", params)
+	s.ws.SetSubscriptions(StreamRequest{
+		Method: "SUBSCRIBE",
+		Params: params,
+		ID:     1,
+	})
+}
 
-				s.connLock.Unlock()
+func (s *Stream) sendStreamRequest(method string, params ...string) {
+	if len(params) == 0 {
+		return
+	}
 
-			case <-keepAliveTicker.C:
-				if !s.publicOnly {
-					if err := s.keepaliveListenKey(ctx, s.ListenKey); err != nil {
-						log.WithError(err).Errorf("listen key keep-alive error: %v key: %s", err, maskListenKey(s.ListenKey))
-					}
-				}
+	err := s.ws.WriteJSON(StreamRequest{
+		Method: method,
+		Params: params,
+		ID:     int(atomic.AddInt32(&s.requestID, 1)),
+	})
 
-			}
-		}
-	}()
+	switch err {
+	case nil, service.ErrWebsocketNotConnected:
+		// the subscriptions will be sent after connecting
+
+	default:
+		log.WithError(err).Errorf("%s %v error", strings.ToLower(method), params)
+	}
+}
+
+func (s *Stream) keepalive(ctx context.Context) {
+	keepAliveTicker := time.NewTicker(5 * time.Minute)
+	defer keepAliveTicker.Stop()
 
 	for {
 		select {
@@ -355,81 +597,66 @@ func (s *Stream) read(ctx context.Context) {
 		case <-ctx.Done():
 			return
 
-		default:
-			if err := s.Conn.SetReadDeadline(time.Now().Add(3 * time.Minute)); err != nil {
-				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
+		case <-keepAliveTicker.C:
+			if err := s.keepaliveListenKey(ctx, s.ListenKey); err != nil {
+				log.WithError(err).Errorf("listen key keep-alive error: %v key: %s", err, maskListenKey(s.ListenKey))
 			}
+		}
+	}
+}
 
-			mt, message, err := s.Conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
-					log.WithError(err).Errorf("read error: %s", err.Error())
-				} else {
-					log.Info("websocket connection closed, going away")
-				}
-
-				s.EmitDisconnect()
+func (s *Stream) handleMessage(message []byte) {
+	s.EmitMessage()
 
-				// reconnect
-				for err != nil {
-					select {
-					case <-ctx.Done():
-						return
+	log.Debug(string(message))
 
-					default:
-						if !s.publicOnly {
-							if err := s.invalidateListenKey(ctx, s.ListenKey); err != nil {
-								log.WithError(err).Error("invalidate listen key error")
-							}
-						}
+	e, err := ParseEvent(string(message))
+	if err != nil {
+		log.WithError(err).Errorf("[binance] event parse error")
+		return
+	}
 
-						err = s.connect(ctx)
-						time.Sleep(5 * time.Second)
-					}
-				}
+	// log.NotifyTo("[binance] event: %+v", e)
+	switch e := e.(type) {
 
-				continue
-			}
+	case *OutboundAccountPositionEvent:
+		log.Info(e.Event, " ", e.Balances)
+		s.EmitOutboundAccountPositionEvent(e)
 
-			// skip non-text messages
-			if mt != websocket.TextMessage {
-				continue
-			}
+	case *OutboundAccountInfoEvent:
+		log.Info(e.Event, " ", e.Balances)
+		s.EmitOutboundAccountInfoEvent(e)
 
-			log.Debug(string(message))
+	case *BalanceUpdateEvent:
+		log.Info(e.Event, " ", e.Asset, " ", e.Delta)
+		s.EmitBalanceUpdateEvent(e)
 
-			e, err := ParseEvent(string(message))
-			if err != nil {
-				log.WithError(err).Errorf("[binance] event parse error")
-				continue
-			}
+	case *KLineEvent:
+		s.EmitKLineEvent(e)
 
-			// log.NotifyTo("[binance] event: %+v", e)
-			switch e := e.(type) {
+	case *DepthEvent:
+		s.EmitDepthEvent(e)
 
-			case *OutboundAccountPositionEvent:
-				log.Info(e.Event, " ", e.Balances)
-				s.EmitOutboundAccountPositionEvent(e)
+	case *MarketTradeEvent:
+		s.EmitMarketTradeEvent(e)
 
-			case *OutboundAccountInfoEvent:
-				log.Info(e.Event, " ", e.Balances)
-				s.EmitOutboundAccountInfoEvent(e)
+	case *AggTradeEvent:
+		s.EmitAggTradeEvent(e)
 
-			case *BalanceUpdateEvent:
-				log.Info(e.Event, " ", e.Asset, " ", e.Delta)
-				s.EmitBalanceUpdateEvent(e)
+	case *BookTickerEvent:
+		s.EmitBookTickerEvent(e)
 
-			case *KLineEvent:
-				s.EmitKLineEvent(e)
+	case *ExecutionReportEvent:
+		log.Info(e.Event, " ", e)
+		s.EmitExecutionReportEvent(e)
 
-			case *DepthEvent:
-				s.EmitDepthEvent(e)
+	case *FuturesAccountUpdateEvent:
+		log.Info(e.Event, " ", e.Account.Reason, " ", e.Account.Balances)
+		s.EmitFuturesAccountUpdateEvent(e)
 
-			case *ExecutionReportEvent:
-				log.Info(e.Event, " ", e)
-				s.EmitExecutionReportEvent(e)
-			}
-		}
+	case *FuturesOrderTradeUpdateEvent:
+		log.Info(e.Event, " ", e.OrderUpdate)
+		s.EmitFuturesOrderTradeUpdateEvent(e)
 	}
 }
 
@@ -437,7 +664,9 @@ func (s *Stream) invalidateListenKey(ctx context.Context, listenKey string) (err
 	// should use background context to invalidate the user stream
 	log.Info("closing listen key")
 
-	if s.IsMargin {
+	if s.IsFutures {
+		err = s.FuturesClient.NewCloseUserStreamService().ListenKey(listenKey).Do(ctx)
+	} else if s.IsMargin {
 		if s.IsIsolatedMargin {
 			req := s.Client.NewCloseIsolatedMarginUserStreamService().ListenKey(listenKey)
 			req.Symbol(s.IsolatedMarginSymbol)
@@ -469,10 +698,7 @@ func (s *Stream) Close() error {
 		log.Infof("user data stream closed")
 	}
 
-	s.connLock.Lock()
-	defer s.connLock.Unlock()
-
-	return s.Conn.Close()
+	return s.ws.Close()
 }
 
 func maskListenKey(listenKey string) string {
@@ -34,6 +34,36 @@ func (s *Stream) EmitKLineClosedEvent(e *KLineEvent) {
 	}
 }
 
+func (s *Stream) OnMarketTradeEvent(cb func(e *MarketTradeEvent)) {
+	s.marketTradeEventCallbacks = append(s.marketTradeEventCallbacks, cb)
+}
+
+func (s *Stream) EmitMarketTradeEvent(e *MarketTradeEvent) {
+	for _, cb := range s.marketTradeEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnAggTradeEvent(cb func(e *AggTradeEvent)) {
+	s.aggTradeEventCallbacks = append(s.aggTradeEventCallbacks, cb)
+}
+
+func (s *Stream) EmitAggTradeEvent(e *AggTradeEvent) {
+	for _, cb := range s.aggTradeEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnBookTickerEvent(cb func(e *BookTickerEvent)) {
+	s.bookTickerEventCallbacks = append(s.bookTickerEventCallbacks, cb)
+}
+
+func (s *Stream) EmitBookTickerEvent(e *BookTickerEvent) {
+	for _, cb := range s.bookTickerEventCallbacks {
+		cb(e)
+	}
+}
+
 func (s *Stream) OnBalanceUpdateEvent(cb func(event *BalanceUpdateEvent)) {
 	s.balanceUpdateEventCallbacks = append(s.balanceUpdateEventCallbacks, cb)
 }
@@ -74,6 +104,26 @@ func (s *Stream) EmitExecutionReportEvent(event *ExecutionReportEvent) {
 	}
 }
 
+func (s *Stream) OnFuturesAccountUpdateEvent(cb func(event *FuturesAccountUpdateEvent)) {
+	s.futuresAccountUpdateEventCallbacks = append(s.futuresAccountUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitFuturesAccountUpdateEvent(event *FuturesAccountUpdateEvent) {
+	for _, cb := range s.futuresAccountUpdateEventCallbacks {
+		cb(event)
+	}
+}
+
+func (s *Stream) OnFuturesOrderTradeUpdateEvent(cb func(event *FuturesOrderTradeUpdateEvent)) {
+	s.futuresOrderTradeUpdateEventCallbacks = append(s.futuresOrderTradeUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitFuturesOrderTradeUpdateEvent(event *FuturesOrderTradeUpdateEvent) {
+	for _, cb := range s.futuresOrderTradeUpdateEventCallbacks {
+		cb(event)
+	}
+}
+
 type StreamEventHub interface {
 	OnDepthEvent(cb func(e *DepthEvent))
 
@@ -81,6 +131,12 @@ type StreamEventHub interface {
 
 	OnKLineClosedEvent(cb func(e *KLineEvent))
 
+	OnMarketTradeEvent(cb func(e *MarketTradeEvent))
+
+	OnAggTradeEvent(cb func(e *AggTradeEvent))
+
+	OnBookTickerEvent(cb func(e *BookTickerEvent))
+
 	OnBalanceUpdateEvent(cb func(event *BalanceUpdateEvent))
 
 	OnOutboundAccountInfoEvent(cb func(event *OutboundAccountInfoEvent))
@@ -88,4 +144,8 @@ type StreamEventHub interface {
 	OnOutboundAccountPositionEvent(cb func(event *OutboundAccountPositionEvent))
 
 	OnExecutionReportEvent(cb func(event *ExecutionReportEvent))
+
+	OnFuturesAccountUpdateEvent(cb func(event *FuturesAccountUpdateEvent))
+
+	OnFuturesOrderTradeUpdateEvent(cb func(event *FuturesOrderTradeUpdateEvent))
 }
@@ -0,0 +1,190 @@
+package binance
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func readFixture(t *testing.T, path string) []byte {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	return data
+}
+
+func newTestFuturesStream(t *testing.T) (*Stream, func()) {
+	ex, _, closeServer := newTestFuturesExchange(t)
+	stream := NewStream(ex.Client)
+	stream.FuturesSettings = ex.FuturesSettings
+	stream.FuturesClient = ex.FuturesClient
+	return stream, closeServer
+}
+
+func TestStream_FuturesStreamURL(t *testing.T) {
+	stream := NewStream(nil)
+	stream.SetPublicOnly()
+
+	url, err := stream.streamURL(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "wss://stream.binance.com:9443/ws", url)
+
+	stream.UseFutures()
+	url, err = stream.streamURL(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "wss://fstream.binance.com/ws", url)
+
+	// the futures stream doesn't have the raw trade channel
+	assert.Equal(t, "btcusdt@aggTrade", stream.streamName(types.Subscription{Channel: types.MarketTradeChannel, Symbol: "BTCUSDT"}))
+	assert.Equal(t, "btcusdt@depth", stream.streamName(types.Subscription{Channel: types.BookChannel, Symbol: "BTCUSDT"}))
+	assert.Equal(t, "btcusdt@kline_1m", stream.streamName(types.Subscription{
+		Channel: types.KLineChannel,
+		Symbol:  "BTCUSDT",
+		Options: types.SubscribeOptions{Interval: "1m"},
+	}))
+}
+
+func TestStream_FuturesKLine(t *testing.T) {
+	stream, closeServer := newTestFuturesStream(t)
+	defer closeServer()
+
+	var klines []types.KLine
+	stream.OnKLineClosed(func(kline types.KLine) {
+		klines = append(klines, kline)
+	})
+
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_kline.json"))
+	if assert.Len(t, klines, 1) {
+		assert.Equal(t, "BTCUSDT", klines[0].Symbol)
+		assert.Equal(t, types.Interval1m, klines[0].Interval)
+		assert.Equal(t, 35012.34, klines[0].Close)
+		assert.Equal(t, uint64(2345), klines[0].NumberOfTrades)
+	}
+}
+
+func TestStream_FuturesDepth(t *testing.T) {
+	stream, closeServer := newTestFuturesStream(t)
+	defer closeServer()
+
+	var snapshots, updates []types.OrderBook
+	var invalidated []types.BookInvalidReason
+	stream.OnBookSnapshot(func(book types.OrderBook) {
+		snapshots = append(snapshots, book)
+	})
+	stream.OnBookUpdate(func(book types.OrderBook) {
+		updates = append(updates, book)
+	})
+	stream.OnBookInvalidated(func(symbol string, reason types.BookInvalidReason) {
+		invalidated = append(invalidated, reason)
+	})
+
+	// the first event creates the depth frame, the snapshot is loaded when the second event is buffered, and the
+	// buffered event covers the last update ID 1027024 of the snapshot
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_depth_update.json"))
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_depth_update.json"))
+
+	if assert.Len(t, snapshots, 1) {
+		bid, ok := snapshots[0].BestBid()
+		assert.True(t, ok)
+		assert.Equal(t, fixedpoint.NewFromInt(35010), bid.Price)
+		assert.Equal(t, fixedpoint.MustNewFromString("1.5"), bid.Volume)
+	}
+
+	if assert.Len(t, updates, 1) {
+		assert.Len(t, updates[0].Asks, 2)
+	}
+
+	// the events are chained by the previous update ID
+	stream.handleMessage([]byte(`{"e":"depthUpdate","E":1625097600300,"T":1625097600298,"s":"BTCUSDT","U":1027035,"u":1027040,"pu":1027030,"b":[["35010.00","1.000"]],"a":[]}`))
+	assert.Len(t, updates, 2)
+	assert.Empty(t, invalidated)
+
+	stream.handleMessage([]byte(`{"e":"depthUpdate","E":1625097600400,"T":1625097600398,"s":"BTCUSDT","U":1027050,"u":1027060,"pu":1027045,"b":[["35010.00","2.000"]],"a":[]}`))
+	assert.Len(t, updates, 2)
+	assert.Equal(t, []types.BookInvalidReason{types.BookInvalidReasonSequenceGap}, invalidated)
+}
+
+func TestStream_FuturesDepthCrossed(t *testing.T) {
+	stream, closeServer := newTestFuturesStream(t)
+	defer closeServer()
+
+	var snapshots, updates int
+	var invalidated []types.BookInvalidReason
+	stream.OnBookSnapshot(func(book types.OrderBook) {
+		snapshots++
+	})
+	stream.OnBookUpdate(func(book types.OrderBook) {
+		updates++
+	})
+	stream.OnBookInvalidated(func(symbol string, reason types.BookInvalidReason) {
+		assert.Equal(t, "BTCUSDT", symbol)
+		invalidated = append(invalidated, reason)
+	})
+
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_depth_update.json"))
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_depth_update.json"))
+	assert.Equal(t, 1, snapshots)
+	assert.Equal(t, 1, updates)
+
+	// the bid 35012.5 crosses the best ask 35012
+	stream.handleMessage([]byte(`{"e":"depthUpdate","E":1625097600300,"T":1625097600298,"s":"BTCUSDT","U":1027035,"u":1027040,"pu":1027030,"b":[["35012.50","1.000"]],"a":[]}`))
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, []types.BookInvalidReason{types.BookInvalidReasonCrossed}, invalidated)
+
+	// the snapshot is reloaded with the next depth event
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_depth_update.json"))
+	assert.Equal(t, 2, snapshots)
+}
+
+func TestStream_FuturesUserData(t *testing.T) {
+	stream, closeServer := newTestFuturesStream(t)
+	defer closeServer()
+
+	var trades []types.Trade
+	var balances []types.BalanceMap
+	stream.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+	stream.OnBalanceSnapshot(func(snapshot types.BalanceMap) {
+		balances = append(balances, snapshot)
+	})
+
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_order_trade_update.json"))
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(698759), trades[0].ID)
+		assert.Equal(t, uint64(8389765498), trades[0].OrderID)
+		assert.Equal(t, types.SideTypeBuy, trades[0].Side)
+		assert.Equal(t, "34800", trades[0].Price.String())
+		assert.Equal(t, "0.01", trades[0].Quantity.String())
+		assert.Equal(t, "348", trades[0].QuoteQuantity.String())
+		assert.Equal(t, "0.0696", trades[0].Fee.String())
+		assert.True(t, trades[0].IsMaker)
+	}
+
+	stream.handleMessage(readFixture(t, "testdata/futures/ws_account_update.json"))
+	assert.Equal(t, []types.BalanceMap{
+		{
+			"USDT": {
+				Currency:  "USDT",
+				Available: fixedpoint.MustNewFromString("9999.9304"),
+			},
+		},
+	}, balances)
+
+	var orders []types.Order
+	stream.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+
+	stream.handleMessage([]byte(`{"e":"ORDER_TRADE_UPDATE","E":1625097610001,"T":1625097610000,"o":{"s":"BTCUSDT","c":"maker-1","S":"BUY","o":"LIMIT","f":"GTX","q":"0.050","p":"34800.00","ap":"34800.00000","sp":"0","x":"CANCELED","X":"CANCELED","i":8389765498,"l":"0","z":"0.010","L":"0","N":"USDT","n":"0","T":1625097610000,"t":0,"m":false,"R":false,"ps":"BOTH","AP":"0","rp":"0"}}`))
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, types.OrderStatusCanceled, orders[0].Status)
+		assert.Equal(t, types.OrderTypeLimitMaker, orders[0].Type)
+		assert.Equal(t, "0.01", orders[0].ExecutedQuantity.String())
+		assert.False(t, orders[0].IsWorking)
+	}
+}
@@ -2,6 +2,7 @@ package ftx
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,15 +40,15 @@ func toGlobalOrder(r order) (types.Order, error) {
 			Side:          types.SideType(TrimUpperString(r.Side)),
 			// order type definition: https://github.com/ftexchange/ftx/blob/master/rest/client.py#L122
 			Type:        types.OrderType(TrimUpperString(r.Type)),
-			Quantity:    r.Size,
-			Price:       r.Price,
+			Quantity:    fixedpoint.NewFromFloat(r.Size),
+			Price:       fixedpoint.NewFromFloat(r.Price),
 			TimeInForce: "GTC",
 		},
 		Exchange:         types.ExchangeFTX.String(),
 		IsWorking:        r.Status == "open",
 		OrderID:          uint64(r.ID),
 		Status:           "",
-		ExecutedQuantity: r.FilledSize,
+		ExecutedQuantity: fixedpoint.NewFromFloat(r.FilledSize),
 		CreationTime:     datatype.Time(r.CreatedAt.Time),
 		UpdateTime:       datatype.Time(r.CreatedAt.Time),
 	}
@@ -57,14 +58,14 @@ func toGlobalOrder(r order) (types.Order, error) {
 	case "new":
 		o.Status = types.OrderStatusNew
 	case "open":
-		if fixedpoint.NewFromFloat(o.ExecutedQuantity) != fixedpoint.NewFromInt(0) {
+		if o.ExecutedQuantity != 0 {
 			o.Status = types.OrderStatusPartiallyFilled
 		} else {
 			o.Status = types.OrderStatusNew
 		}
 	case "closed":
 		// filled or canceled
-		if fixedpoint.NewFromFloat(o.Quantity) == fixedpoint.NewFromFloat(o.ExecutedQuantity) {
+		if o.Quantity == o.ExecutedQuantity {
 			o.Status = types.OrderStatusFilled
 		} else {
 			// can't distinguish it's canceled or rejected from order response, so always set to canceled
@@ -109,27 +110,115 @@ func toGlobalDepositStatus(input string) (types.DepositStatus, error) {
 	return "", fmt.Errorf("unsupported status %s", input)
 }
 
+func toGlobalWithdraw(input withdrawHistory) types.Withdraw {
+	return types.Withdraw{
+		Exchange:               types.ExchangeFTX,
+		Asset:                  toGlobalCurrency(input.Coin),
+		Amount:                 input.Size,
+		Address:                input.Address,
+		AddressTag:             input.Tag,
+		Status:                 toGlobalWithdrawStatus(input.Status),
+		TransactionID:          input.TxID,
+		TransactionFee:         input.Fee,
+		TransactionFeeCurrency: toGlobalCurrency(input.Coin),
+		WithdrawOrderID:        strconv.FormatInt(input.ID, 10),
+		ApplyTime:              datatype.Time(input.Time.Time),
+	}
+}
+
+// toGlobalWithdrawStatus converts the status to the binance style status, so that the completed withdraws are counted
+// by the transfer history
+func toGlobalWithdrawStatus(input string) string {
+	switch input {
+	case "requested":
+		return "awaiting_approval"
+	case "complete":
+		return "completed"
+	}
+	// processing, cancelled
+	return input
+}
+
+func toGlobalReward(input stakingReward) types.Reward {
+	return types.Reward{
+		UUID:      strconv.FormatInt(input.ID, 10),
+		Exchange:  types.ExchangeFTX,
+		Type:      types.RewardHolding,
+		Currency:  toGlobalCurrency(input.Coin),
+		Quantity:  fixedpoint.NewFromFloat(input.Size),
+		State:     input.Status,
+		Note:      "staking",
+		CreatedAt: datatype.Time(input.Time.Time),
+	}
+}
+
+// toGlobalTicker converts the market to the ticker, /markets doesn't return the open, high, low and base volume,
+// so the open price is derived from the 24h change and the base volume is derived from the 24h quote volume.
+func toGlobalTicker(m market) types.Ticker {
+	ticker := types.Ticker{
+		Time: time.Now(),
+		Last: m.Last,
+		Buy:  m.Bid,
+		Sell: m.Ask,
+	}
+
+	if m.Change24h > -1 {
+		ticker.Open = m.Last / (1 + m.Change24h)
+	}
+	// the markets api only returns the 24h quote volume, the base volume is approximated by the last price
+	if m.Last > 0 {
+		ticker.Volume = m.QuoteVolume24h / m.Last
+	}
+
+	return ticker
+}
+
 func toGlobalTrade(f fill) (types.Trade, error) {
 	return types.Trade{
 		ID:            f.TradeId,
 		GID:           0,
 		OrderID:       f.OrderId,
 		Exchange:      types.ExchangeFTX.String(),
-		Price:         f.Price,
-		Quantity:      f.Size,
-		QuoteQuantity: f.Price * f.Size,
+		Price:         fixedpoint.NewFromFloat(f.Price),
+		Quantity:      fixedpoint.NewFromFloat(f.Size),
+		QuoteQuantity: fixedpoint.NewFromFloat(f.Price).Mul(fixedpoint.NewFromFloat(f.Size)),
 		Symbol:        toGlobalSymbol(f.Market),
 		Side:          f.Side,
 		IsBuyer:       f.Side == types.SideTypeBuy,
 		IsMaker:       f.Liquidity == "maker",
 		Time:          datatype.Time(f.Time.Time),
-		Fee:           f.Fee,
+		Fee:           fixedpoint.NewFromFloat(f.Fee),
 		FeeCurrency:   f.FeeCurrency,
 		IsMargin:      false,
 		IsIsolated:    false,
 	}, nil
 }
 
+// toGlobalMarketTrade converts the public trade, the symbol keeps the market name format like the order book channel
+func toGlobalMarketTrade(market string, t publicTrade) types.Trade {
+	return types.Trade{
+		ID:            t.ID,
+		Exchange:      types.ExchangeFTX.String(),
+		Price:         fixedpoint.NewFromFloat(t.Price),
+		Quantity:      fixedpoint.NewFromFloat(t.Size),
+		QuoteQuantity: fixedpoint.NewFromFloat(t.Price).Mul(fixedpoint.NewFromFloat(t.Size)),
+		Symbol:        TrimUpperString(market),
+		Side:          t.Side,
+		IsBuyer:       t.Side == types.SideTypeBuy,
+		Time:          datatype.Time(t.Time.Time),
+	}
+}
+
+func toGlobalBookTicker(r tickerResponse) types.BookTicker {
+	return types.BookTicker{
+		Symbol:   TrimUpperString(r.Market),
+		Buy:      r.Bid,
+		BuySize:  r.BidSize,
+		Sell:     r.Ask,
+		SellSize: r.AskSize,
+	}
+}
+
 func toGlobalKLine(symbol string, interval types.Interval, h Candle) (types.KLine, error) {
 	return types.KLine{
 		Exchange:  types.ExchangeFTX.String(),
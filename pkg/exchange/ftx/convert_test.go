@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -40,14 +41,14 @@ func Test_toGlobalOrderFromOpenOrder(t *testing.T) {
 	assert.Equal(t, "XRP-PERP", o.Symbol)
 	assert.Equal(t, types.SideTypeSell, o.Side)
 	assert.Equal(t, types.OrderTypeLimit, o.Type)
-	assert.Equal(t, float64(31431), o.Quantity)
-	assert.Equal(t, 0.306525, o.Price)
+	assert.Equal(t, fixedpoint.NewFromFloat(31431), o.Quantity)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.306525), o.Price)
 	assert.Equal(t, "GTC", o.TimeInForce)
 	assert.Equal(t, types.ExchangeFTX.String(), o.Exchange)
 	assert.True(t, o.IsWorking)
 	assert.Equal(t, uint64(9596912), o.OrderID)
 	assert.Equal(t, types.OrderStatusPartiallyFilled, o.Status)
-	assert.Equal(t, float64(10), o.ExecutedQuantity)
+	assert.Equal(t, fixedpoint.NewFromFloat(10), o.ExecutedQuantity)
 }
 
 func TestTrimLowerString(t *testing.T) {
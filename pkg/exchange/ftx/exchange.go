@@ -11,6 +11,8 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/ycdesu/spreaddog/pkg/exchange"
+	"github.com/ycdesu/spreaddog/pkg/exchange/ratelimit"
 	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
@@ -18,14 +20,48 @@ import (
 const (
 	restEndpoint       = "https://ftx.com"
 	defaultHTTPTimeout = 15 * time.Second
+
+	// withdrawHistoryLimit is the page size of the withdraw history
+	withdrawHistoryLimit = 200
 )
 
 var logger = logrus.WithField("exchange", "ftx")
 
+// rateLimits is the request limit of an account, ftx rejects the requests with 429 if it's exceeded
+var rateLimits = ratelimit.Limits{
+	Weight:   30,
+	Interval: time.Second,
+}
+
+var capabilities = types.ExchangeCapabilities{
+	Transfer:    true,
+	Rewards:     true,
+	Channels:    []types.Channel{types.BookChannel, types.KLineChannel, types.MarketTradeChannel, types.BookTickerChannel},
+	OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeMarket},
+	TimeInForce: []string{"GTC"},
+}
+
+func init() {
+	_ = types.Exchange(&Exchange{})
+	_ = types.ExchangeOrderQueryService(&Exchange{})
+	_ = types.ExchangeCancelAllService(&Exchange{})
+	_ = types.ExchangeOrderAmendService(&Exchange{})
+
+	exchange.Register(exchange.Registration{
+		Name: types.ExchangeFTX,
+		New: func(c exchange.Credentials) types.Exchange {
+			return NewExchange(c.Key, c.Secret, c.SubAccount)
+		},
+		UseSubAccount: true,
+		Capabilities:  capabilities,
+	})
+}
+
 type Exchange struct {
 	key, secret  string
 	subAccount   string
 	restEndpoint *url.URL
+	client       *http.Client
 }
 
 func NewExchange(key, secret string, subAccount string) *Exchange {
@@ -38,11 +74,15 @@ func NewExchange(key, secret string, subAccount string) *Exchange {
 		key:          key,
 		secret:       secret,
 		subAccount:   subAccount,
+		client: &http.Client{
+			Timeout:   defaultHTTPTimeout,
+			Transport: ratelimit.NewTransport(types.ExchangeFTX.String(), key, rateLimits, nil),
+		},
 	}
 }
 
 func (e *Exchange) newRest() *restRequest {
-	r := newRestRequest(&http.Client{Timeout: defaultHTTPTimeout}, e.restEndpoint).Auth(e.key, e.secret)
+	r := newRestRequest(e.client, e.restEndpoint).Auth(e.key, e.secret)
 	if len(e.subAccount) > 0 {
 		r.SubAccount(e.subAccount)
 	}
@@ -57,8 +97,14 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("FTT")
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return capabilities
+}
+
 func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.key, e.secret)
+	s := NewStream(e.key, e.secret)
+	s.klineQuerier = e
+	return s
 }
 
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
@@ -293,6 +339,82 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 	return
 }
 
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	if until == (time.Time{}) {
+		until = time.Now()
+	}
+	if since.After(until) {
+		return nil, fmt.Errorf("invalid query withdraw history time range, since: %+v, until: %+v", since, until)
+	}
+	asset = TrimUpperString(asset)
+
+	// ftx returns the latest withdraws first, so we paginate by moving the end time to the oldest withdraw of the
+	// page. The end time is in seconds, the withdraws of the same second are returned again and they are skipped by id.
+	withdrawIDs := make(map[int64]struct{})
+	endTime := until
+	for {
+		resp, err := e.newRest().WithdrawHistory(ctx, since, endTime, withdrawHistoryLimit)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success {
+			return nil, fmt.Errorf("ftx returns failure")
+		}
+
+		newWithdraws := 0
+		for _, r := range resp.Result {
+			if _, ok := withdrawIDs[r.ID]; ok {
+				continue
+			}
+			withdrawIDs[r.ID] = struct{}{}
+			newWithdraws++
+
+			if r.Time.Before(endTime) {
+				endTime = r.Time.Time
+			}
+
+			w := toGlobalWithdraw(r)
+			if len(asset) > 0 && w.Asset != asset {
+				continue
+			}
+			if !since.After(w.ApplyTime.Time()) && !until.Before(w.ApplyTime.Time()) {
+				allWithdraws = append(allWithdraws, w)
+			}
+		}
+
+		if len(resp.Result) < withdrawHistoryLimit || newWithdraws == 0 {
+			break
+		}
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Time().Before(allWithdraws[j].ApplyTime.Time())
+	})
+	return allWithdraws, nil
+}
+
+// QueryRewards returns the staking rewards since the start time, ftx only returns the whole staking reward history
+func (e *Exchange) QueryRewards(ctx context.Context, startTime time.Time) ([]types.Reward, error) {
+	resp, err := e.newRest().StakingRewards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("ftx returns querying staking rewards failure")
+	}
+
+	var rewards []types.Reward
+	for _, r := range resp.Result {
+		if r.Time.Before(startTime) {
+			continue
+		}
+		rewards = append(rewards, toGlobalReward(r))
+	}
+
+	sort.Sort(types.RewardSliceByCreationTime(rewards))
+	return rewards, nil
+}
+
 func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
 	var createdOrders types.OrderSlice
 	// TODO: currently only support limit and market order
@@ -310,7 +432,7 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			ReduceOnly: false,
 			IOC:        false,
 			PostOnly:   false,
-			ClientID:   so.ClientOrderID,
+			ClientID:   newClientOrderID(so),
 		})
 		if err != nil {
 			return createdOrders, fmt.Errorf("failed to place order %+v: %w", so, err)
@@ -413,10 +535,150 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) erro
 	return nil
 }
 
+// newClientOrderID returns the client order id of the submit order. ftx doesn't have the order group, the orders of a
+// group are tagged by the client order id prefix, so that they can be canceled by CancelOrdersByGroupID
+func newClientOrderID(so types.SubmitOrder) string {
+	if len(so.ClientOrderID) == 0 && so.GroupID > 0 {
+		return types.NewGroupClientOrderID(so.GroupID)
+	}
+	return so.ClientOrderID
+}
+
+// AmendOrder modifies the price and the size of the order by the native modify order api. ftx replaces the order with
+// a new order id, the order group of the original order is kept by the new client order id.
+func (e *Exchange) AmendOrder(ctx context.Context, order types.Order, price, quantity fixedpoint.Value) (*types.Order, error) {
+	payload := ModifyOrderPayload{
+		Price:    price,
+		Size:     quantity,
+		ClientID: exchange.NewReplaceSubmitOrder(order, price, quantity).ClientOrderID,
+	}
+
+	var resp orderResponse
+	var err error
+	if order.OrderID > 0 {
+		resp, err = e.newRest().ModifyOrder(ctx, order.OrderID, payload)
+	} else {
+		resp, err = e.newRest().ModifyOrderByClientID(ctx, order.ClientOrderID, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("ftx returns modifying order failure")
+	}
+
+	o, err := toGlobalOrder(resp.Result)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// QueryOrder queries the order by the order id, or by the client order id if the order id is not given
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	var resp orderResponse
+	var err error
+	if q.OrderID > 0 {
+		resp, err = e.newRest().OrderStatus(ctx, q.OrderID)
+	} else {
+		resp, err = e.newRest().OrderStatusByClientID(ctx, q.ClientOrderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("ftx returns querying order failure")
+	}
+
+	o, err := toGlobalOrder(resp.Result)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// CancelAllOrders cancels the open orders of all the markets, it returns the open orders before the cancellation
+func (e *Exchange) CancelAllOrders(ctx context.Context) ([]types.Order, error) {
+	return e.CancelOrdersBySymbol(ctx, "")
+}
+
+// CancelOrdersBySymbol cancels the open orders of the market, it returns the open orders before the cancellation
+func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	market := TrimUpperString(symbol)
+	openOrders, err := e.QueryOpenOrders(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.newRest().CancelAllOrders(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("ftx returns canceling all orders failure")
+	}
+
+	return types.OrderSlice(openOrders).Canceled(), nil
+}
+
+// CancelOrdersByGroupID cancels the open orders submitted with the group id
+func (e *Exchange) CancelOrdersByGroupID(ctx context.Context, groupID uint32) ([]types.Order, error) {
+	openOrders, err := e.QueryOpenOrders(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var groupOrders types.OrderSlice
+	for _, o := range openOrders {
+		if types.IsGroupClientOrderID(o.ClientOrderID, groupID) {
+			groupOrders = append(groupOrders, o)
+		}
+	}
+
+	if err := e.CancelOrders(ctx, groupOrders...); err != nil {
+		return nil, err
+	}
+
+	return groupOrders.Canceled(), nil
+}
+
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
-	panic("implement me")
+	tickers, err := e.QueryTickers(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, ok := tickers[toGlobalSymbol(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("ticker of %s is not found", symbol)
+	}
+	return &ticker, nil
 }
 
+// QueryTickers returns the tickers of the given symbols, or all the markets if no symbol is given. The symbols can be
+// either the global symbol (BTCUSD) or the ftx market name (BTC/USD).
 func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
-	panic("implement me")
+	resp, err := e.newRest().Markets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("ftx returns querying markets failure")
+	}
+
+	wanted := make(map[string]struct{}, len(symbol))
+	for _, s := range symbol {
+		wanted[toGlobalSymbol(s)] = struct{}{}
+	}
+
+	tickers := make(map[string]types.Ticker)
+	for _, m := range resp.Result {
+		s := toGlobalSymbol(m.Name)
+		if _, ok := wanted[s]; len(wanted) > 0 && !ok {
+			continue
+		}
+		tickers[s] = toGlobalTicker(m)
+	}
+
+	return tickers, nil
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -487,6 +488,180 @@ func TestExchange_QueryDepositHistory(t *testing.T) {
 	assert.Len(t, dh, 0)
 }
 
+func TestExchange_QueryWithdrawHistory(t *testing.T) {
+	respJSON := `
+{
+  "success": true,
+  "result": [
+    {
+      "coin": "TUSD",
+      "address": "0x83a127952d266A6eA306c40Ac62A4a70668FE3BE",
+      "tag": "test-tag",
+      "fee": 0.5,
+      "id": 1,
+      "size": 20.0,
+      "status": "complete",
+      "time": "2019-03-05T09:56:55.728933+00:00",
+      "txid": "0x8078356ae4b06a036d64747546c274af19581f1c78c510b60505798a7ffcaf1"
+    }
+  ]
+}
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/wallet/withdrawals", r.URL.Path)
+		fmt.Fprintln(w, respJSON)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	ctx := context.Background()
+	applyTime, err := parseDatetime("2019-03-05T09:56:55.728933+00:00")
+	assert.NoError(t, err)
+	wh, err := ex.QueryWithdrawHistory(ctx, "TUSD", applyTime.Add(-1*time.Hour), applyTime.Add(1*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, wh, 1)
+	assert.Equal(t, types.Withdraw{
+		Exchange:               types.ExchangeFTX,
+		Asset:                  "TUSD",
+		Amount:                 20.0,
+		Address:                "0x83a127952d266A6eA306c40Ac62A4a70668FE3BE",
+		AddressTag:             "test-tag",
+		Status:                 "completed",
+		TransactionID:          "0x8078356ae4b06a036d64747546c274af19581f1c78c510b60505798a7ffcaf1",
+		TransactionFee:         0.5,
+		TransactionFeeCurrency: "TUSD",
+		WithdrawOrderID:        "1",
+		ApplyTime:              datatype.Time(applyTime),
+	}, wh[0])
+
+	// not in the time range
+	wh, err = ex.QueryWithdrawHistory(ctx, "TUSD", applyTime.Add(1*time.Hour), applyTime.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, wh, 0)
+
+	// exclude by asset
+	wh, err = ex.QueryWithdrawHistory(ctx, "BTC", applyTime.Add(-1*time.Hour), applyTime.Add(1*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, wh, 0)
+}
+
+func TestExchange_QueryWithdrawHistoryPagination(t *testing.T) {
+	latest := time.Date(2021, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	// the latest withdraws are returned first, the withdraws of the end time second are included
+	var withdraws []withdrawHistory
+	for i := 0; i < withdrawHistoryLimit+50; i++ {
+		withdraws = append(withdraws, withdrawHistory{
+			ID:     int64(i + 1),
+			Coin:   "TUSD",
+			Size:   1,
+			Status: "complete",
+			Time:   datetime{Time: latest.Add(-time.Duration(i) * time.Minute)},
+		})
+	}
+
+	var endTimes []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/wallet/withdrawals", r.URL.Path)
+		assert.Equal(t, strconv.Itoa(withdrawHistoryLimit), r.URL.Query().Get("limit"))
+		endTimes = append(endTimes, r.URL.Query().Get("end_time"))
+
+		endTime, err := strconv.ParseInt(r.URL.Query().Get("end_time"), 10, 64)
+		assert.NoError(t, err)
+
+		var page []withdrawHistory
+		for _, w := range withdraws {
+			if w.Time.Unix() <= endTime && len(page) < withdrawHistoryLimit {
+				page = append(page, w)
+			}
+		}
+
+		assert.NoError(t, json.NewEncoder(w).Encode(withdrawHistoryResponse{Success: true, Result: page}))
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	wh, err := ex.QueryWithdrawHistory(context.Background(), "TUSD", latest.Add(-24*time.Hour), latest)
+	assert.NoError(t, err)
+	assert.Len(t, wh, withdrawHistoryLimit+50)
+	assert.Len(t, endTimes, 2)
+
+	// sorted by the apply time
+	assert.Equal(t, "250", wh[0].WithdrawOrderID)
+	assert.Equal(t, "1", wh[len(wh)-1].WithdrawOrderID)
+}
+
+func TestExchange_QueryTickers(t *testing.T) {
+	respJSON := `{
+"success": true,
+"result": [
+  {
+    "name": "BTC/USD",
+    "last": 59000.0,
+    "bid": 58990.0,
+    "ask": 59010.0,
+    "price": 59000.0,
+    "type": "spot",
+    "baseCurrency": "BTC",
+    "quoteCurrency": "USD",
+    "change24h": 0.18,
+    "quoteVolume24h": 5900000.0
+  },
+  {
+    "name": "ETH/USD",
+    "last": 2000.0,
+    "bid": 1999.0,
+    "ask": 2001.0,
+    "price": 2000.0,
+    "type": "spot",
+    "baseCurrency": "ETH",
+    "quoteCurrency": "USD",
+    "change24h": 0,
+    "quoteVolume24h": 0
+  }
+]
+}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/markets", r.URL.Path)
+		fmt.Fprintln(w, respJSON)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	ctx := context.Background()
+	tickers, err := ex.QueryTickers(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tickers, 2)
+
+	tickers, err = ex.QueryTickers(ctx, "BTC/USD")
+	assert.NoError(t, err)
+	assert.Len(t, tickers, 1)
+
+	ticker, err := ex.QueryTicker(ctx, "BTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, 59000.0, ticker.Last)
+	assert.Equal(t, 58990.0, ticker.Buy)
+	assert.Equal(t, 59010.0, ticker.Sell)
+	assert.InDelta(t, 50000.0, ticker.Open, 1e-8)
+	assert.InDelta(t, 100.0, ticker.Volume, 1e-8)
+
+	_, err = ex.QueryTicker(ctx, "LTCUSD")
+	assert.Error(t, err)
+}
+
 func TestExchange_QueryTrades(t *testing.T) {
 	t.Run("empty response", func(t *testing.T) {
 		respJSON := `
@@ -603,15 +778,15 @@ func TestExchange_QueryTrades(t *testing.T) {
 			ID:            789,
 			OrderID:       456,
 			Exchange:      types.ExchangeFTX.String(),
-			Price:         672.5,
-			Quantity:      1.0,
-			QuoteQuantity: 672.5 * 1.0,
+			Price:         fixedpoint.NewFromFloat(672.5),
+			Quantity:      fixedpoint.NewFromFloat(1.0),
+			QuoteQuantity: fixedpoint.NewFromFloat(672.5 * 1.0),
 			Symbol:        "TSLAUSD",
 			Side:          types.SideTypeSell,
 			IsBuyer:       false,
 			IsMaker:       true,
 			Time:          datatype.Time(actualConfirmedTime),
-			Fee:           -0.0033625,
+			Fee:           fixedpoint.NewFromFloat(-0.0033625),
 			FeeCurrency:   "USD",
 			IsMargin:      false,
 			IsIsolated:    false,
@@ -636,3 +811,136 @@ func Test_isIntervalSupportedInKLine(t *testing.T) {
 	assert.False(t, isIntervalSupportedInKLine(types.Interval30m))
 	assert.False(t, isIntervalSupportedInKLine(types.Interval3d))
 }
+
+func TestExchange_QueryOrder(t *testing.T) {
+	successResp := `
+{
+  "success": true,
+  "result": {
+    "createdAt": "2019-03-05T09:56:55.728933+00:00",
+    "filledSize": 10,
+    "future": "XRP-PERP",
+    "id": 9596912,
+    "market": "XRP-PERP",
+    "price": 0.306525,
+    "avgFillPrice": 0.306526,
+    "remainingSize": 31421,
+    "side": "sell",
+    "size": 31431,
+    "status": "open",
+    "type": "limit",
+    "reduceOnly": false,
+    "ioc": false,
+    "postOnly": false,
+    "clientId": "g1-abc"
+  }
+}
+`
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		fmt.Fprintln(w, successResp)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	o, err := ex.QueryOrder(context.Background(), types.OrderQuery{OrderID: 9596912})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9596912), o.OrderID)
+	assert.Equal(t, "g1-abc", o.ClientOrderID)
+
+	_, err = ex.QueryOrder(context.Background(), types.OrderQuery{ClientOrderID: "g1-abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/api/orders/9596912", "/api/orders/by_client_id/g1-abc"}, paths)
+}
+
+func TestExchange_CancelOrdersByGroupID(t *testing.T) {
+	openOrdersResp := `
+{
+  "success": true,
+  "result": [
+    {"createdAt": "2019-03-05T09:56:55.728933+00:00", "id": 1, "market": "XRP-PERP", "price": 0.3, "side": "sell", "size": 1, "remainingSize": 1, "status": "open", "type": "limit", "clientId": "g1-abc"},
+    {"createdAt": "2019-03-05T09:56:55.728933+00:00", "id": 2, "market": "XRP-PERP", "price": 0.3, "side": "sell", "size": 1, "remainingSize": 1, "status": "open", "type": "limit", "clientId": "g12-abc"},
+    {"createdAt": "2019-03-05T09:56:55.728933+00:00", "id": 3, "market": "XRP-PERP", "price": 0.3, "side": "sell", "size": 1, "remainingSize": 1, "status": "open", "type": "limit", "clientId": null}
+  ]
+}
+`
+	var canceled []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			var p map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			canceled = append(canceled, p["client_order_id"].(string))
+			fmt.Fprintln(w, `{"success": true, "result": "Order queued for cancellation"}`)
+			return
+		}
+		fmt.Fprintln(w, openOrdersResp)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	orders, err := ex.CancelOrdersByGroupID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, uint64(1), orders[0].OrderID)
+	assert.Equal(t, types.OrderStatusCanceled, orders[0].Status)
+	assert.Equal(t, []string{"g1-abc"}, canceled)
+}
+
+func TestExchange_CancelOrdersBySymbol(t *testing.T) {
+	var cancelPayload map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			assert.Equal(t, "/api/orders", r.URL.Path)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&cancelPayload))
+			fmt.Fprintln(w, `{"success": true, "result": "Orders queued for cancellation"}`)
+			return
+		}
+		fmt.Fprintln(w, `{"success": true, "result": [{"createdAt": "2019-03-05T09:56:55.728933+00:00", "id": 1, "market": "XRP-PERP", "price": 0.3, "side": "sell", "size": 1, "remainingSize": 1, "status": "open", "type": "limit"}]}`)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	orders, err := ex.CancelOrdersBySymbol(context.Background(), "xrp-perp")
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, map[string]interface{}{"market": "XRP-PERP"}, cancelPayload)
+}
+
+func TestExchange_AmendOrder(t *testing.T) {
+	var path string
+	var payload map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.Equal(t, "POST", r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		fmt.Fprintln(w, `{"success": true, "result": {"createdAt": "2019-03-05T09:56:55.728933+00:00", "id": 2, "market": "XRP-PERP", "price": 0.31, "side": "sell", "size": 2, "remainingSize": 2, "status": "new", "type": "limit", "clientId": "g1-def"}}`)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("", "", "")
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	order := types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: "g1-abc", Symbol: "XRP-PERP"}, OrderID: 1}
+	newOrder, err := ex.AmendOrder(context.Background(), order, fixedpoint.NewFromFloat(0.31), fixedpoint.NewFromFloat(2))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), newOrder.OrderID)
+	assert.Equal(t, "/api/orders/1/modify", path)
+	assert.Equal(t, 0.31, payload["price"])
+	assert.Equal(t, 2.0, payload["size"])
+	assert.True(t, types.IsGroupClientOrderID(payload["clientId"].(string), 1))
+}
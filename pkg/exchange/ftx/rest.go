@@ -11,10 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/ycdesu/spreaddog/pkg/types"
 	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
@@ -181,9 +183,12 @@ func (r *restRequest) sendRequest(req *http.Request) (*util.Response, error) {
 	if response.IsError() {
 		errorResponse, err := toErrorResponse(response)
 		if err != nil {
+			if response.IsRateLimited() {
+				return response, types.NewRateLimitError(response.RetryAfter(), err)
+			}
 			return response, err
 		}
-		return response, errorResponse
+		return response, toGlobalError(errorResponse)
 	}
 
 	return response, nil
@@ -206,6 +211,40 @@ func (r *ErrorResponse) Error() string {
 	)
 }
 
+// toGlobalError maps the error response to the error types by the status code and the error message, the unknown errors
+// are returned as they are
+func toGlobalError(r *ErrorResponse) error {
+	if r.IsRateLimited() {
+		return types.NewRateLimitError(r.RetryAfter(), r)
+	}
+
+	message := strings.ToLower(r.ErrorString)
+	switch {
+	case r.StatusCode == http.StatusUnauthorized, strings.HasPrefix(message, "not logged in"):
+		return types.NewExchangeError(types.ErrAuthentication, r)
+
+	case strings.Contains(message, "please slow down"), strings.Contains(message, "do not send more than"):
+		return types.NewRateLimitError(r.RetryAfter(), r)
+
+	case strings.Contains(message, "not enough balances"), strings.Contains(message, "account does not have enough margin"):
+		return types.NewExchangeError(types.ErrInsufficientBalance, r)
+
+	case strings.Contains(message, "order not found"), strings.Contains(message, "order already closed"):
+		return types.NewExchangeError(types.ErrOrderNotFound, r)
+
+	case strings.Contains(message, "invalid price"), strings.Contains(message, "invalid size"), strings.Contains(message, "size too small"):
+		return types.NewExchangeError(types.ErrInvalidPrecision, r)
+
+	case strings.Contains(message, "no such market"):
+		return types.NewExchangeError(types.ErrInvalidSymbol, r)
+
+	case strings.Contains(message, "market is closed"), strings.Contains(message, "market is not open"):
+		return types.NewExchangeError(types.ErrMarketClosed, r)
+	}
+
+	return r
+}
+
 func toErrorResponse(response *util.Response) (*ErrorResponse, error) {
 	errorResponse := &ErrorResponse{Response: response}
 
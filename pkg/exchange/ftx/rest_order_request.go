@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 )
 
 type orderRequest struct {
@@ -28,9 +31,9 @@ type orderRequest struct {
 type PlaceOrderPayload struct {
 	Market     string
 	Side       string
-	Price      float64
+	Price      fixedpoint.Value
 	Type       string
-	Size       float64
+	Size       fixedpoint.Value
 	ReduceOnly bool
 	IOC        bool
 	PostOnly   bool
@@ -64,6 +67,47 @@ func (r *orderRequest) PlaceOrder(ctx context.Context, p PlaceOrderPayload) (ord
 
 	return o, nil
 }
+// ModifyOrderPayload is the payload of the modify order api, ftx cancels the order and places a new order with a new
+// order id, so the client id of the new order should be different from the original one
+type ModifyOrderPayload struct {
+	Price    fixedpoint.Value
+	Size     fixedpoint.Value
+	ClientID string
+}
+
+func (r *orderRequest) ModifyOrder(ctx context.Context, orderID uint64, p ModifyOrderPayload) (orderResponse, error) {
+	return r.modifyOrder(ctx, "api/orders/"+strconv.FormatUint(orderID, 10)+"/modify", p)
+}
+
+func (r *orderRequest) ModifyOrderByClientID(ctx context.Context, clientID string, p ModifyOrderPayload) (orderResponse, error) {
+	return r.modifyOrder(ctx, "api/orders/by_client_id/"+url.PathEscape(clientID)+"/modify", p)
+}
+
+func (r *orderRequest) modifyOrder(ctx context.Context, refURL string, p ModifyOrderPayload) (orderResponse, error) {
+	payloads := map[string]interface{}{
+		"price": p.Price,
+		"size":  p.Size,
+	}
+	if len(p.ClientID) > 0 {
+		payloads["clientId"] = p.ClientID
+	}
+
+	resp, err := r.
+		Method("POST").
+		ReferenceURL(refURL).
+		Payloads(payloads).
+		DoAuthenticatedRequest(ctx)
+	if err != nil {
+		return orderResponse{}, err
+	}
+
+	var o orderResponse
+	if err := json.Unmarshal(resp.Body, &o); err != nil {
+		return orderResponse{}, fmt.Errorf("failed to unmarshal modify order response body to json: %w", err)
+	}
+	return o, nil
+}
+
 func (r *orderRequest) CancelOrderByOrderID(ctx context.Context, orderID uint64) (cancelOrderResponse, error) {
 	resp, err := r.
 		Method("DELETE").
@@ -98,6 +142,53 @@ func (r *orderRequest) CancelOrderByClientID(ctx context.Context, clientID strin
 	return co, nil
 }
 
+// CancelAllOrders cancels all the open orders of the market, or all the open orders of the account if market is empty
+func (r *orderRequest) CancelAllOrders(ctx context.Context, market string) (cancelOrderResponse, error) {
+	payloads := make(map[string]interface{})
+	if len(market) > 0 {
+		payloads["market"] = market
+	}
+
+	resp, err := r.
+		Method("DELETE").
+		ReferenceURL("api/orders").
+		Payloads(payloads).
+		DoAuthenticatedRequest(ctx)
+	if err != nil {
+		return cancelOrderResponse{}, err
+	}
+
+	var co cancelOrderResponse
+	if err := json.Unmarshal(resp.Body, &co); err != nil {
+		return cancelOrderResponse{}, fmt.Errorf("failed to unmarshal cancel all orders response body to json: %w", err)
+	}
+	return co, nil
+}
+
+func (r *orderRequest) OrderStatus(ctx context.Context, orderID uint64) (orderResponse, error) {
+	return r.orderStatus(ctx, "api/orders/"+strconv.FormatUint(orderID, 10))
+}
+
+func (r *orderRequest) OrderStatusByClientID(ctx context.Context, clientID string) (orderResponse, error) {
+	return r.orderStatus(ctx, "api/orders/by_client_id/"+url.PathEscape(clientID))
+}
+
+func (r *orderRequest) orderStatus(ctx context.Context, refURL string) (orderResponse, error) {
+	resp, err := r.
+		Method("GET").
+		ReferenceURL(refURL).
+		DoAuthenticatedRequest(ctx)
+	if err != nil {
+		return orderResponse{}, err
+	}
+
+	var o orderResponse
+	if err := json.Unmarshal(resp.Body, &o); err != nil {
+		return orderResponse{}, fmt.Errorf("failed to unmarshal order status response body to json: %w", err)
+	}
+	return o, nil
+}
+
 func (r *orderRequest) OpenOrders(ctx context.Context, market string) (ordersResponse, error) {
 	resp, err := r.
 		Method("GET").
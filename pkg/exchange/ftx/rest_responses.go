@@ -310,6 +310,69 @@ type depositHistory struct {
 	Notes         string   `json:"notes"`
 }
 
+/*
+{
+  "success": true,
+  "result": [
+    {
+      "coin": "TUSD",
+      "address": "0x83a127952d266A6eA306c40Ac62A4a70668FE3BE",
+      "tag": null,
+      "fee": 0,
+      "id": 1,
+      "size": 20.0,
+      "status": "complete",
+      "time": "2019-03-05T09:56:55.728933+00:00",
+      "txid": "0x8078356ae4b06a036d64747546c274af19581f1c78c510b60505798a7ffcaf1"
+    }
+  ]
+}
+*/
+type withdrawHistoryResponse struct {
+	Success bool              `json:"success"`
+	Result  []withdrawHistory `json:"result"`
+}
+
+type withdrawHistory struct {
+	ID      int64    `json:"id"`
+	Coin    string   `json:"coin"`
+	Address string   `json:"address"`
+	Tag     string   `json:"tag"`
+	Fee     float64  `json:"fee"`
+	Size    float64  `json:"size"`
+	Status  string   `json:"status"`
+	Time    datetime `json:"time"`
+	TxID    string   `json:"txid"`
+	Notes   string   `json:"notes"`
+}
+
+/*
+{
+  "success": true,
+  "result": [
+    {
+      "coin": "SRM",
+      "id": 1,
+      "size": 0.01,
+      "status": "complete",
+      "time": "2020-09-13T11:09:31.227431+00:00"
+    }
+  ]
+}
+*/
+type stakingRewardsResponse struct {
+	Success bool            `json:"success"`
+	Result  []stakingReward `json:"result"`
+}
+
+type stakingReward struct {
+	ID     int64    `json:"id"`
+	Coin   string   `json:"coin"`
+	Size   float64  `json:"size"`
+	Status string   `json:"status"`
+	Time   datetime `json:"time"`
+}
+
 /**
 {
 	"address": "test123",
@@ -2,12 +2,16 @@ package ftx
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ycdesu/spreaddog/pkg/types"
 	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
@@ -32,3 +36,47 @@ func Test_toErrorResponse(t *testing.T) {
 	assert.False(t, errResp.IsSuccess)
 	assert.Equal(t, "Not logged in", errResp.ErrorString)
 }
+
+func Test_toGlobalError(t *testing.T) {
+	newErrorResponse := func(statusCode int, message string) *ErrorResponse {
+		return &ErrorResponse{
+			Response: &util.Response{Response: &http.Response{
+				StatusCode: statusCode,
+				Header:     http.Header{"Retry-After": []string{"2"}},
+				Request:    &http.Request{Method: "POST", URL: &url.URL{Path: "/api/orders"}},
+			}},
+			ErrorString: message,
+		}
+	}
+
+	tests := []struct {
+		statusCode int
+		message    string
+		kind       error
+	}{
+		{429, "Do not send more than 2 orders on this market per 200ms", types.ErrRateLimited},
+		{400, "Please slow down", types.ErrRateLimited},
+		{401, "Not logged in: Invalid API key", types.ErrAuthentication},
+		{400, "Not enough balances", types.ErrInsufficientBalance},
+		{404, "Order not found", types.ErrOrderNotFound},
+		{400, "Order already closed", types.ErrOrderNotFound},
+		{400, "Size too small", types.ErrInvalidPrecision},
+		{404, "No such market: BTCUSD", types.ErrInvalidSymbol},
+	}
+
+	for _, test := range tests {
+		errResp := newErrorResponse(test.statusCode, test.message)
+		err := toGlobalError(errResp)
+		assert.True(t, errors.Is(err, test.kind), test.message)
+
+		var origin *ErrorResponse
+		assert.True(t, errors.As(err, &origin))
+	}
+
+	retryAfter, ok := types.RetryAfter(toGlobalError(newErrorResponse(429, "Please slow down")))
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	unknown := newErrorResponse(500, "Something went wrong")
+	assert.Equal(t, error(unknown), toGlobalError(unknown))
+}
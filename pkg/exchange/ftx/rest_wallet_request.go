@@ -43,6 +43,55 @@ func (r *walletRequest) DepositHistory(ctx context.Context, since time.Time, unt
 	return d, nil
 }
 
+func (r *walletRequest) WithdrawHistory(ctx context.Context, since time.Time, until time.Time, limit int) (withdrawHistoryResponse, error) {
+	q := make(map[string]string)
+	if limit > 0 {
+		q["limit"] = strconv.Itoa(limit)
+	}
+
+	if since != (time.Time{}) {
+		q["start_time"] = strconv.FormatInt(since.Unix(), 10)
+	}
+	if until != (time.Time{}) {
+		q["end_time"] = strconv.FormatInt(until.Unix(), 10)
+	}
+
+	resp, err := r.
+		Method("GET").
+		ReferenceURL("api/wallet/withdrawals").
+		Query(q).
+		DoAuthenticatedRequest(ctx)
+
+	if err != nil {
+		return withdrawHistoryResponse{}, err
+	}
+
+	var w withdrawHistoryResponse
+	if err := json.Unmarshal(resp.Body, &w); err != nil {
+		return withdrawHistoryResponse{}, fmt.Errorf("failed to unmarshal withdraw history response body to json: %w", err)
+	}
+
+	return w, nil
+}
+
+func (r *walletRequest) StakingRewards(ctx context.Context) (stakingRewardsResponse, error) {
+	resp, err := r.
+		Method("GET").
+		ReferenceURL("api/staking/staking_rewards").
+		DoAuthenticatedRequest(ctx)
+
+	if err != nil {
+		return stakingRewardsResponse{}, err
+	}
+
+	var s stakingRewardsResponse
+	if err := json.Unmarshal(resp.Body, &s); err != nil {
+		return stakingRewardsResponse{}, fmt.Errorf("failed to unmarshal staking rewards response body to json: %w", err)
+	}
+
+	return s, nil
+}
+
 func (r *walletRequest) Balances(ctx context.Context) (balances, error) {
 	resp, err := r.
 		Method("GET").
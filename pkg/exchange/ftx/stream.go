@@ -3,6 +3,7 @@ package ftx
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,26 +26,72 @@ type Stream struct {
 	key    string
 	secret string
 
-	// subscriptions are only accessed in single goroutine environment, so I don't use mutex to protect them
+	// mu protects ctx, subscriptions, marketTradeMarkets and klineBuilders, they can be changed after connecting
+	mu sync.Mutex
+
+	// ctx is the context of Connect, the kline builders added after connecting are seeded with it
+	ctx context.Context
+
+	// klineBuildersOnce starts the kline builder ticker and seeds the builders once, even if Connect is called again
+	klineBuildersOnce sync.Once
+
 	subscriptions []websocketRequest
+
+	// marketTradeMarkets are the markets subscribed by the market trade channel, the trades channel is also
+	// subscribed for the kline channel, so it's unsubscribed only if both channels are unsubscribed.
+	marketTradeMarkets map[string]struct{}
+
+	// FTX doesn't provide the kline channel, the klines are aggregated from the public trades.
+	klineBuilders []*types.KLineBuilder
+
+	// klineQuerier is used to seed the kline builders, it's optional
+	klineQuerier klineQuerier
+}
+
+type klineQuerier interface {
+	QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error)
 }
 
 func NewStream(key, secret string) *Stream {
 	s := &Stream{
-		key:            key,
-		secret:         secret,
-		StandardStream: &types.StandardStream{},
-		ws:             service.NewWebsocketClientBase(endpoint, 3*time.Second),
+		key:                key,
+		secret:             secret,
+		StandardStream:     &types.StandardStream{},
+		marketTradeMarkets: make(map[string]struct{}),
+		ws:                 service.NewWebsocketClientBase(endpoint, service.DefaultWebsocketClientOptions),
 	}
 
-	s.ws.OnMessage((&messageHandler{StandardStream: s.StandardStream}).handleMessage)
+	// https://docs.ftx.com/?javascript#request-process
+	s.ws.SetPingFunc(func(conn *websocket.Conn) error {
+		return conn.WriteJSON(websocketRequest{Operation: ping})
+	})
+
+	h := &messageHandler{
+		StandardStream:  s.StandardStream,
+		resyncOrderBook: s.resubscribeOrderBook,
+	}
+	s.ws.OnMessage(func(message []byte) {
+		s.EmitMessage()
+	})
+	s.ws.OnMessage(h.handleMessage)
+	s.ws.OnError(s.EmitError)
+	s.ws.OnDisconnected(func(conn *websocket.Conn) {
+		s.EmitDisconnect()
+	})
 	s.ws.OnConnected(func(conn *websocket.Conn) {
-		subs := []websocketRequest{newLoginRequest(s.key, s.secret, time.Now())}
-		subs = append(subs, s.subscriptions...)
-		for _, sub := range subs {
-			if err := conn.WriteJSON(sub); err != nil {
-				s.ws.EmitError(fmt.Errorf("failed to send subscription: %+v", sub))
-			}
+		// the updates might be lost while reconnecting, the books will be loaded from the new partial snapshots
+		h.resetOrderBooks(types.BookInvalidReasonReconnect)
+
+		// the subscriptions are replayed by the websocket client after the login
+		if err := s.ws.WriteJSON(newLoginRequest(s.key, s.secret, time.Now())); err != nil {
+			s.ws.EmitError(fmt.Errorf("failed to send login request: %w", err))
+		}
+
+		s.EmitConnect()
+	})
+	s.OnMarketTrade(func(trade types.Trade) {
+		for _, builder := range s.getKLineBuilders() {
+			builder.AddTrade(trade)
 		}
 	})
 
@@ -57,30 +104,61 @@ func (s *Stream) Connect(ctx context.Context) error {
 		s.subscribePrivateEvents()
 	}
 
-	if err := s.ws.Connect(ctx); err != nil {
-		return err
-	}
-
-	go func() {
-		// https://docs.ftx.com/?javascript#request-process
-		tk := time.NewTicker(15 * time.Second)
-		defer tk.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				if err := ctx.Err(); err != nil {
-					logger.WithError(err).Errorf("websocket ping goroutine is terminated")
-				}
-			case <-tk.C:
-				if err := s.ws.Conn().WriteJSON(websocketRequest{
-					Operation: ping,
-				}); err != nil {
-					logger.WithError(err).Warnf("failed to ping, try in next tick")
-				}
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	// the kline builders added after connecting are seeded when they are subscribed
+	s.klineBuildersOnce.Do(func() {
+		for _, builder := range s.getKLineBuilders() {
+			s.seedKLineBuilder(ctx, builder)
+		}
+		go s.tickKLineBuilders(ctx)
+	})
+
+	return s.ws.Connect(ctx)
+}
+
+// seedKLineBuilder loads the current kline of the builder, so that the first closed kline is not a partial one.
+func (s *Stream) seedKLineBuilder(ctx context.Context, builder *types.KLineBuilder) {
+	if s.klineQuerier == nil || !isIntervalSupportedInKLine(builder.Interval) {
+		return
+	}
+
+	now := time.Now()
+	startTime := now.Truncate(builder.Interval.Duration())
+	klines, err := s.klineQuerier.QueryKLines(ctx, builder.Symbol, builder.Interval, types.KLineQueryOptions{
+		StartTime: &startTime,
+		EndTime:   &now,
+	})
+	if err != nil {
+		logger.WithError(err).Warnf("failed to seed %s %s kline, the first kline might be partial", builder.Symbol, builder.Interval)
+		return
+	}
+
+	builder.Seed(klines)
+}
+
+// tickKLineBuilders closes the klines at the end of the interval even if there is no trade.
+func (s *Stream) tickKLineBuilders(ctx context.Context) {
+	tk := time.NewTicker(time.Second)
+	defer tk.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-tk.C:
+			for _, builder := range s.getKLineBuilders() {
+				builder.Tick(now)
 			}
 		}
-	}()
-	return nil
+	}
+}
+
+func (s *Stream) getKLineBuilders() []*types.KLineBuilder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.klineBuilders
 }
 
 func (s *Stream) subscribePrivateEvents() {
@@ -94,29 +172,178 @@ func (s *Stream) subscribePrivateEvents() {
 	})
 }
 
+// addSubscription adds the subscription request, the request is sent immediately if the stream is connected.
+// The caller must hold the lock.
 func (s *Stream) addSubscription(request websocketRequest) {
 	s.subscriptions = append(s.subscriptions, request)
+	if err := s.ws.AddSubscription(request); err != nil {
+		s.ws.EmitError(fmt.Errorf("failed to subscribe %s %s: %w", request.Channel, request.Market, err))
+	}
 }
 
-func (s *Stream) SetPublicOnly() {
-	atomic.StoreInt32(&s.publicOnly, 1)
+// removeSubscription removes the subscription of the channel and the market, the unsubscribe request is sent
+// immediately if the stream is connected. The caller must hold the lock.
+func (s *Stream) removeSubscription(channel channel, market string) {
+	var subscriptions []websocketRequest
+	for _, sub := range s.subscriptions {
+		if sub.Channel == channel && sub.Market == market {
+			if err := s.ws.RemoveSubscription(sub, websocketRequest{
+				Operation: unsubscribe,
+				Channel:   channel,
+				Market:    market,
+			}); err != nil {
+				s.ws.EmitError(fmt.Errorf("failed to unsubscribe %s %s: %w", channel, market, err))
+			}
+			continue
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	s.subscriptions = subscriptions
+}
+
+// resubscribeOrderBook unsubscribes and subscribes the orderbook channel again, so that FTX sends a new partial snapshot
+func (s *Stream) resubscribeOrderBook(market string) {
+	for _, op := range []operation{unsubscribe, subscribe} {
+		if err := s.ws.WriteJSON(websocketRequest{
+			Operation: op,
+			Channel:   orderBookChannel,
+			Market:    market,
+		}); err != nil {
+			s.ws.EmitError(fmt.Errorf("failed to %s orderbook of %s: %w", op, market, err))
+			return
+		}
+	}
 }
 
-func (s *Stream) Subscribe(channel types.Channel, symbol string, _ types.SubscribeOptions) {
-	if channel != types.BookChannel {
-		panic("only support book channel now")
+// subscribeMarketTrades subscribes the trades channel once even if both the market trade and kline channels are subscribed.
+// The caller must hold the lock.
+func (s *Stream) subscribeMarketTrades(market string) {
+	for _, sub := range s.subscriptions {
+		if sub.Channel == tradesChannel && sub.Market == market {
+			return
+		}
 	}
+
 	s.addSubscription(websocketRequest{
 		Operation: subscribe,
-		Channel:   orderBookChannel,
-		Market:    TrimUpperString(symbol),
+		Channel:   tradesChannel,
+		Market:    market,
 	})
 }
 
+func (s *Stream) SetPublicOnly() {
+	atomic.StoreInt32(&s.publicOnly, 1)
+}
+
+// Subscribe adds the subscription, the subscribe request is sent immediately if the stream is connected.
+func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	if channel == types.KLineChannel {
+		s.subscribeKLine(symbol, options)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch channel {
+	case types.BookChannel:
+		s.addSubscription(websocketRequest{
+			Operation: subscribe,
+			Channel:   orderBookChannel,
+			Market:    TrimUpperString(symbol),
+		})
+	case types.MarketTradeChannel:
+		s.marketTradeMarkets[TrimUpperString(symbol)] = struct{}{}
+		s.subscribeMarketTrades(TrimUpperString(symbol))
+	case types.BookTickerChannel:
+		s.addSubscription(websocketRequest{
+			Operation: subscribe,
+			Channel:   tickerChannel,
+			Market:    TrimUpperString(symbol),
+		})
+	default:
+		panic("only support book, book ticker, market trade and kline channels now")
+	}
+}
+
+// subscribeKLine adds the kline builder of the interval, the builder added after connecting is seeded before it
+// receives the trades.
+func (s *Stream) subscribeKLine(symbol string, options types.SubscribeOptions) {
+	interval := types.Interval(options.Interval)
+	if _, ok := types.SupportedIntervals[interval]; !ok {
+		panic(fmt.Sprintf("unsupported kline interval: %q", options.Interval))
+	}
+
+	builder := types.NewKLineBuilder(types.ExchangeFTX.String(), TrimUpperString(symbol), interval)
+	builder.OnKLine(s.EmitKLine)
+	builder.OnKLineClosed(s.EmitKLineClosed)
+
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	// the builders added before connecting are seeded by Connect
+	if ctx != nil {
+		s.seedKLineBuilder(ctx, builder)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.klineBuilders = append(s.klineBuilders, builder)
+	s.subscribeMarketTrades(builder.Symbol)
+}
+
+// Unsubscribe removes the subscriptions of the channel and the symbol, the unsubscribe request is sent immediately
+// if the stream is connected. The kline builders of all the intervals of the symbol are removed.
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	market := TrimUpperString(symbol)
+	switch channel {
+	case types.BookChannel:
+		s.removeSubscription(orderBookChannel, market)
+	case types.BookTickerChannel:
+		s.removeSubscription(tickerChannel, market)
+	case types.MarketTradeChannel:
+		delete(s.marketTradeMarkets, market)
+		s.unsubscribeMarketTrades(market)
+	case types.KLineChannel:
+		var builders []*types.KLineBuilder
+		for _, builder := range s.klineBuilders {
+			if builder.Symbol != market {
+				builders = append(builders, builder)
+			}
+		}
+		s.klineBuilders = builders
+		s.unsubscribeMarketTrades(market)
+	}
+}
+
+// unsubscribeMarketTrades unsubscribes the trades channel if neither the market trade nor the kline channel needs it.
+// The caller must hold the lock.
+func (s *Stream) unsubscribeMarketTrades(market string) {
+	if _, ok := s.marketTradeMarkets[market]; ok {
+		return
+	}
+
+	for _, builder := range s.klineBuilders {
+		if builder.Symbol == market {
+			return
+		}
+	}
+
+	s.removeSubscription(tradesChannel, market)
+}
+
 func (s *Stream) Close() error {
+	s.mu.Lock()
 	s.subscriptions = nil
+	s.mu.Unlock()
+
 	if s.ws != nil {
-		return s.ws.Conn().Close()
+		return s.ws.Close()
 	}
 	return nil
 }
@@ -2,12 +2,23 @@ package ftx
 
 import (
 	"encoding/json"
+	"expvar"
+	"strings"
 
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
+// orderBookResyncs counts the order book resyncs by market, it's published through expvar
+var orderBookResyncs = expvar.NewMap("ftx_orderbook_resyncs")
+
 type messageHandler struct {
 	*types.StandardStream
+
+	// books are the local order books of the markets, every update is applied to the local book to verify the checksum
+	books map[string]*orderBookResponse
+
+	// resyncOrderBook is called when the checksum of the local book doesn't match, it should request a new partial snapshot
+	resyncOrderBook func(market string)
 }
 
 func (h *messageHandler) handleMessage(message []byte) {
@@ -29,6 +40,10 @@ func (h *messageHandler) handleMessage(message []byte) {
 	switch r.Channel {
 	case orderBookChannel:
 		h.handleOrderBook(r)
+	case tradesChannel:
+		h.handleMarketTrades(r)
+	case tickerChannel:
+		h.handleTicker(r)
 	case privateOrdersChannel:
 		h.handlePrivateOrders(r)
 	case privateTradesChannel:
@@ -53,6 +68,11 @@ func (h *messageHandler) handleOrderBook(response websocketResponse) {
 		h.handleSubscribedMessage(response)
 		return
 	}
+	if response.Type == unsubscribedRespType {
+		// drop the local book, if it's unsubscribed for the resync, the partial snapshot comes after subscribing again
+		delete(h.books, response.Market)
+		return
+	}
 	r, err := response.toPublicOrderBookResponse()
 	if err != nil {
 		logger.WithError(err).Errorf("failed to convert the public orderbook")
@@ -69,10 +89,35 @@ func (h *messageHandler) handleOrderBook(response websocketResponse) {
 	case partialRespType:
 		if err := r.verifyChecksum(); err != nil {
 			logger.WithError(err).Errorf("invalid orderbook snapshot")
+			h.resync(r.Market, types.BookInvalidReasonChecksum, err)
 			return
 		}
+
+		if h.books == nil {
+			h.books = make(map[string]*orderBookResponse)
+		}
+		h.books[r.Market] = &r
 		h.EmitBookSnapshot(globalOrderBook)
 	case updateRespType:
+		book, ok := h.books[r.Market]
+		if !ok {
+			// the update is dropped until the partial snapshot comes
+			logger.Warnf("orderbook update of %s is dropped, the snapshot is not received yet", r.Market)
+			return
+		}
+
+		book.update(r)
+		if err := book.verifyChecksum(); err != nil {
+			logger.WithError(err).Errorf("invalid orderbook update")
+			h.resync(r.Market, types.BookInvalidReasonChecksum, err)
+			return
+		}
+
+		if err := book.verifyNotCrossed(); err != nil {
+			h.resync(r.Market, types.BookInvalidReasonCrossed, err)
+			return
+		}
+
 		// emit updates, not the whole orderbook
 		h.EmitBookUpdate(globalOrderBook)
 	default:
@@ -81,6 +126,59 @@ func (h *messageHandler) handleOrderBook(response websocketResponse) {
 	}
 }
 
+// resync drops the local book of the market and requests a new partial snapshot
+func (h *messageHandler) resync(market string, reason types.BookInvalidReason, err error) {
+	delete(h.books, market)
+	orderBookResyncs.Add(market, 1)
+	logger.WithError(err).WithField("market", market).Warnf("orderbook of %s is inconsistent (%s), resubscribing", market, reason)
+
+	h.EmitBookInvalidated(strings.ToUpper(market), reason)
+
+	if h.resyncOrderBook != nil {
+		h.resyncOrderBook(market)
+	}
+}
+
+// resetOrderBooks drops all the local books, the partial snapshots will be sent after subscribing again
+func (h *messageHandler) resetOrderBooks(reason types.BookInvalidReason) {
+	for market := range h.books {
+		delete(h.books, market)
+		h.EmitBookInvalidated(strings.ToUpper(market), reason)
+	}
+}
+
+func (h *messageHandler) handleMarketTrades(response websocketResponse) {
+	if response.Type == subscribedRespType {
+		h.handleSubscribedMessage(response)
+		return
+	}
+
+	r, err := response.toPublicTradesResponse()
+	if err != nil {
+		logger.WithError(err).Errorf("failed to convert the public trades")
+		return
+	}
+
+	for _, t := range r.Data {
+		h.EmitMarketTrade(toGlobalMarketTrade(r.Market, t))
+	}
+}
+
+func (h *messageHandler) handleTicker(response websocketResponse) {
+	if response.Type == subscribedRespType {
+		h.handleSubscribedMessage(response)
+		return
+	}
+
+	r, err := response.toTickerResponse()
+	if err != nil {
+		logger.WithError(err).Errorf("failed to convert the ticker")
+		return
+	}
+
+	h.EmitBookTicker(toGlobalBookTicker(r))
+}
+
 func (h *messageHandler) handlePrivateOrders(response websocketResponse) {
 	if response.Type == subscribedRespType {
 		h.handleSubscribedMessage(response)
@@ -2,11 +2,14 @@ package ftx
 
 import (
 	"database/sql"
+	"fmt"
+	"hash/crc32"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -47,14 +50,14 @@ func Test_messageHandler_handleMessage(t *testing.T) {
 					Symbol:        "OXY-PERP",
 					Side:          types.SideTypeSell,
 					Type:          types.OrderTypeLimit,
-					Quantity:      1.0,
-					Price:         2.7185,
+					Quantity:      fixedpoint.NewFromFloat(1.0),
+					Price:         fixedpoint.NewFromFloat(2.7185),
 					TimeInForce:   "GTC",
 				},
 				Exchange:         types.ExchangeFTX.String(),
 				OrderID:          36379,
 				Status:           types.OrderStatusFilled,
-				ExecutedQuantity: 1.0,
+				ExecutedQuantity: fixedpoint.NewFromFloat(1.0),
 				CreationTime:     datatype.Time(mustParseDatetime("2021-03-28T06:12:50.991447+00:00")),
 				UpdateTime:       datatype.Time(mustParseDatetime("2021-03-28T06:12:50.991447+00:00")),
 			}, order)
@@ -97,15 +100,15 @@ func Test_messageHandler_handleMessage(t *testing.T) {
 				ID:            6276431,
 				OrderID:       323789,
 				Exchange:      types.ExchangeFTX.String(),
-				Price:         2.723,
-				Quantity:      1.0,
-				QuoteQuantity: 2.723 * 1.0,
+				Price:         fixedpoint.NewFromFloat(2.723),
+				Quantity:      fixedpoint.NewFromFloat(1.0),
+				QuoteQuantity: fixedpoint.NewFromFloat(2.723 * 1.0),
 				Symbol:        "OXY-PERP",
 				Side:          types.SideTypeBuy,
 				IsBuyer:       true,
 				IsMaker:       false,
 				Time:          datatype.Time(mustParseDatetime("2021-03-28T06:12:34.702926+00:00")),
-				Fee:           0.00153917575,
+				Fee:           fixedpoint.NewFromFloat(0.00153917575),
 				FeeCurrency:   "USD",
 				IsMargin:      false,
 				IsIsolated:    false,
@@ -116,4 +119,124 @@ func Test_messageHandler_handleMessage(t *testing.T) {
 		h.handleMessage(input)
 		assert.Equal(t, 1, i)
 	})
+
+	t.Run("handle market trades", func(t *testing.T) {
+		input := []byte(`
+{
+  "channel": "trades",
+  "market": "BTC-PERP",
+  "type": "update",
+  "data": [
+    {"id": 44200173, "price": 9761.0, "size": 0.5, "side": "buy", "liquidation": false, "time": "2020-05-29T11:59:23.431216+00:00"},
+    {"id": 44200174, "price": 9760.5, "size": 0.1, "side": "sell", "liquidation": false, "time": "2020-05-29T11:59:23.431216+00:00"}
+  ]
+}
+`)
+		h := &messageHandler{StandardStream: &types.StandardStream{}}
+		var trades []types.Trade
+		h.OnMarketTrade(func(trade types.Trade) {
+			trades = append(trades, trade)
+		})
+		h.handleMessage(input)
+		assert.Len(t, trades, 2)
+		assert.Equal(t, types.Trade{
+			ID:            44200173,
+			Exchange:      types.ExchangeFTX.String(),
+			Price:         fixedpoint.NewFromFloat(9761.0),
+			Quantity:      fixedpoint.NewFromFloat(0.5),
+			QuoteQuantity: fixedpoint.NewFromFloat(9761.0 * 0.5),
+			Symbol:        "BTC-PERP",
+			Side:          types.SideTypeBuy,
+			IsBuyer:       true,
+			Time:          datatype.Time(mustParseDatetime("2020-05-29T11:59:23.431216+00:00")),
+		}, trades[0])
+		assert.Equal(t, types.SideTypeSell, trades[1].Side)
+	})
+
+	t.Run("handle ticker", func(t *testing.T) {
+		input := []byte(`
+{
+  "channel": "ticker",
+  "market": "BTC/USD",
+  "type": "update",
+  "data": {"bid": 9772.5, "ask": 9773.0, "bidSize": 0.3, "askSize": 0.0028, "last": 9772.5, "time": 1591081869.4567513}
+}
+`)
+		h := &messageHandler{StandardStream: &types.StandardStream{}}
+		i := 0
+		h.OnBookTicker(func(bookTicker types.BookTicker) {
+			i++
+			assert.Equal(t, types.BookTicker{
+				Symbol:   "BTC/USD",
+				Buy:      9772.5,
+				BuySize:  0.3,
+				Sell:     9773.0,
+				SellSize: 0.0028,
+			}, bookTicker)
+		})
+		h.handleMessage(input)
+		assert.Equal(t, 1, i)
+	})
+
+	t.Run("handle orderbook updates", func(t *testing.T) {
+		checksum := func(s string) uint32 {
+			return crc32.ChecksumIEEE([]byte(s))
+		}
+		message := func(typ string, sum uint32, bids, asks string) []byte {
+			return []byte(fmt.Sprintf(`{"channel": "orderbook", "market": "BTC/USDT", "type": "%s", "data": {"time": 1614520368.9313016, "checksum": %d, "bids": %s, "asks": %s, "action": "%s"}}`,
+				typ, sum, bids, asks, typ))
+		}
+
+		var resyncs []string
+		h := &messageHandler{
+			StandardStream: &types.StandardStream{},
+			resyncOrderBook: func(market string) {
+				resyncs = append(resyncs, market)
+			},
+		}
+		snapshots, updates := 0, 0
+		h.OnBookSnapshot(func(book types.OrderBook) { snapshots++ })
+		h.OnBookUpdate(func(book types.OrderBook) { updates++ })
+		var invalidReasons []types.BookInvalidReason
+		h.OnBookInvalidated(func(symbol string, reason types.BookInvalidReason) {
+			assert.Equal(t, "BTC/USDT", symbol)
+			invalidReasons = append(invalidReasons, reason)
+		})
+
+		// the update before the snapshot is dropped
+		h.handleMessage(message("update", 0, `[[100.0, 1.0]]`, `[]`))
+		assert.Equal(t, 0, updates)
+
+		h.handleMessage(message("partial", checksum("100.0:1.0:101.0:2.0"), `[[100.0, 1.0]]`, `[[101.0, 2.0]]`))
+		assert.Equal(t, 1, snapshots)
+
+		h.handleMessage(message("update", checksum("100.5:3.0:101.0:2.0:100.0:1.0"), `[[100.5, 3.0]]`, `[]`))
+		assert.Equal(t, 1, updates)
+		assert.Empty(t, resyncs)
+
+		// ftx sends the removed level with the size 0.0
+		h.handleMessage(message("update", checksum("100.0:1.0:101.0:2.0"), `[[100.5, 0.0]]`, `[]`))
+		assert.Equal(t, 2, updates)
+		assert.Empty(t, resyncs)
+
+		// the update with an unmatched checksum triggers the resync
+		h.handleMessage(message("update", 1234, `[[100.5, 0.0]]`, `[]`))
+		assert.Equal(t, 2, updates)
+		assert.Equal(t, []string{"BTC/USDT"}, resyncs)
+		assert.Equal(t, []types.BookInvalidReason{types.BookInvalidReasonChecksum}, invalidReasons)
+
+		// the updates are dropped until the next snapshot
+		h.handleMessage(message("update", checksum("100.0:1.0:101.0:2.0"), `[[100.5, 0.0]]`, `[]`))
+		assert.Equal(t, 2, updates)
+
+		// the crossed book triggers the resync
+		h.handleMessage(message("partial", checksum("100.0:1.0:101.0:2.0"), `[[100.0, 1.0]]`, `[[101.0, 2.0]]`))
+		h.handleMessage(message("update", checksum("102.0:1.0:101.0:2.0:100.0:1.0"), `[[102.0, 1.0]]`, `[]`))
+		assert.Equal(t, 2, updates)
+		assert.Equal(t, []types.BookInvalidReason{types.BookInvalidReasonChecksum, types.BookInvalidReasonCrossed}, invalidReasons)
+
+		h.handleMessage(message("partial", checksum("100.0:1.0:101.0:2.0"), `[[100.0, 1.0]]`, `[[101.0, 2.0]]`))
+		h.resetOrderBooks(types.BookInvalidReasonReconnect)
+		assert.Equal(t, types.BookInvalidReasonReconnect, invalidReasons[len(invalidReasons)-1])
+	})
 }
@@ -0,0 +1,100 @@
+package ftx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestStream_SubscribeKLine(t *testing.T) {
+	s := NewStream("", "")
+	s.Subscribe(types.MarketTradeChannel, "btc/usdt", types.SubscribeOptions{})
+	s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "1m"})
+	s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "5m"})
+
+	// the trades channel should be subscribed once
+	assert.Equal(t, []websocketRequest{
+		{Operation: subscribe, Channel: tradesChannel, Market: "BTC/USDT"},
+	}, s.subscriptions)
+
+	var closed []types.KLine
+	s.OnKLineClosed(func(kline types.KLine) {
+		closed = append(closed, kline)
+	})
+
+	startTime := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	s.EmitMarketTrade(types.Trade{Symbol: "BTC/USDT", Price: fixedpoint.NewFromFloat(100), Quantity: fixedpoint.NewFromFloat(1), Time: datatype.Time(startTime)})
+	s.EmitMarketTrade(types.Trade{Symbol: "BTC/USDT", Price: fixedpoint.NewFromFloat(101), Quantity: fixedpoint.NewFromFloat(1), Time: datatype.Time(startTime.Add(time.Minute))})
+
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, "BTC/USDT", closed[0].Symbol)
+		assert.Equal(t, types.Interval1m, closed[0].Interval)
+		assert.Equal(t, 100.0, closed[0].Close)
+	}
+
+	assert.Panics(t, func() {
+		s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "2m"})
+	})
+}
+
+func TestStream_Unsubscribe(t *testing.T) {
+	s := NewStream("", "")
+	s.Subscribe(types.BookChannel, "btc/usdt", types.SubscribeOptions{})
+	s.Subscribe(types.MarketTradeChannel, "btc/usdt", types.SubscribeOptions{})
+	s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "1m"})
+	s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "5m"})
+
+	s.Unsubscribe(types.BookChannel, "btc/usdt")
+	assert.Equal(t, []websocketRequest{
+		{Operation: subscribe, Channel: tradesChannel, Market: "BTC/USDT"},
+	}, s.subscriptions)
+
+	// the trades channel is still used by the kline channel
+	s.Unsubscribe(types.MarketTradeChannel, "btc/usdt")
+	assert.Len(t, s.subscriptions, 1)
+
+	// the klines of all the intervals are unsubscribed
+	s.Unsubscribe(types.KLineChannel, "btc/usdt")
+	assert.Empty(t, s.subscriptions)
+	assert.Empty(t, s.klineBuilders)
+}
+
+type mockKLineQuerier struct {
+	intervals []types.Interval
+}
+
+func (m *mockKLineQuerier) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	m.intervals = append(m.intervals, interval)
+	return []types.KLine{{
+		Exchange:  types.ExchangeFTX.String(),
+		Symbol:    symbol,
+		Interval:  interval,
+		StartTime: options.StartTime.Truncate(interval.Duration()),
+		EndTime:   options.StartTime.Truncate(interval.Duration()).Add(interval.Duration()),
+		Open:      100,
+		High:      100,
+		Low:       100,
+		Close:     100,
+	}}, nil
+}
+
+func TestStream_SubscribeKLineAfterConnect(t *testing.T) {
+	querier := &mockKLineQuerier{}
+	s := NewStream("", "")
+	s.klineQuerier = querier
+
+	// the builders added before connecting are seeded by Connect
+	s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "1m"})
+	assert.Empty(t, querier.intervals)
+
+	s.ctx = context.Background()
+	s.Subscribe(types.KLineChannel, "btc/usdt", types.SubscribeOptions{Interval: "5m"})
+	assert.Equal(t, []types.Interval{types.Interval5m}, querier.intervals)
+	assert.Len(t, s.klineBuilders, 2)
+}
@@ -23,6 +23,8 @@ const unsubscribe operation = "unsubscribe"
 type channel string
 
 const orderBookChannel channel = "orderbook"
+const tradesChannel channel = "trades"
+const tickerChannel channel = "ticker"
 const privateOrdersChannel channel = "orders"
 const privateTradesChannel channel = "fills"
 
@@ -145,6 +147,85 @@ func (r websocketResponse) toTradeUpdateResponse() (tradeUpdateResponse, error)
 	return t, nil
 }
 
+/*
+{
+  "channel": "trades",
+  "market": "BTC-PERP",
+  "type": "update",
+  "data": [{"id": 44200173, "price": 9761.0, "size": 0.0008, "side": "buy", "liquidation": false, "time": "2020-05-29T11:59:23.431216+00:00"}]
+}
+*/
+type publicTrade struct {
+	ID          int64          `json:"id"`
+	Price       float64        `json:"price"`
+	Size        float64        `json:"size"`
+	Side        types.SideType `json:"side"`
+	Liquidation bool           `json:"liquidation"`
+	Time        datetime       `json:"time"`
+}
+
+type publicTradesResponse struct {
+	mandatoryFields
+
+	Market string `json:"market"`
+
+	Data []publicTrade `json:"data"`
+}
+
+func (r websocketResponse) toPublicTradesResponse() (publicTradesResponse, error) {
+	if r.Channel != tradesChannel {
+		return publicTradesResponse{}, fmt.Errorf("type %s, channel %s: %w", r.Type, r.Channel, errUnsupportedConversion)
+	}
+
+	var t publicTradesResponse
+	if err := json.Unmarshal(r.Data, &t.Data); err != nil {
+		return publicTradesResponse{}, err
+	}
+
+	t.mandatoryFields = r.mandatoryFields
+	t.Market = r.Market
+	return t, nil
+}
+
+/*
+{
+  "channel": "ticker",
+  "market": "BTC/USD",
+  "type": "update",
+  "data": {"bid": 9772.5, "ask": 9773.0, "bidSize": 0.3, "askSize": 0.0028, "last": 9772.5, "time": 1591081869.4567513}
+}
+*/
+type tickerResponse struct {
+	mandatoryFields
+
+	Market string `json:"market"`
+
+	Bid     float64 `json:"bid"`
+	Ask     float64 `json:"ask"`
+	BidSize float64 `json:"bidSize"`
+	AskSize float64 `json:"askSize"`
+	Last    float64 `json:"last"`
+	Time    float64 `json:"time"`
+
+	Timestamp time.Time
+}
+
+func (r websocketResponse) toTickerResponse() (tickerResponse, error) {
+	if r.Channel != tickerChannel {
+		return tickerResponse{}, fmt.Errorf("type %s, channel %s: %w", r.Type, r.Channel, errUnsupportedConversion)
+	}
+
+	var t tickerResponse
+	if err := json.Unmarshal(r.Data, &t); err != nil {
+		return tickerResponse{}, err
+	}
+
+	t.mandatoryFields = r.mandatoryFields
+	t.Market = r.Market
+	t.Timestamp = nanoToTime(t.Time)
+	return t, nil
+}
+
 /*
 Private:
 	order: {"type": "subscribed", "channel": "orders"}
@@ -246,7 +327,7 @@ func (r *orderBookResponse) updateAsks(asks [][]json.Number) {
 		return dst < src
 	}
 	for _, o := range asks {
-		if remove := o[1] == "0"; remove {
+		if isZeroSize(o[1]) {
 			r.Asks = removePrice(r.Asks, o[0])
 		} else {
 			r.Asks = upsertPriceVolume(r.Asks, o, higherPrice)
@@ -259,7 +340,7 @@ func (r *orderBookResponse) updateBids(bids [][]json.Number) {
 		return dst > src
 	}
 	for _, o := range bids {
-		if remove := o[1] == "0"; remove {
+		if isZeroSize(o[1]) {
 			r.Bids = removePrice(r.Bids, o[0])
 		} else {
 			r.Bids = upsertPriceVolume(r.Bids, o, lessPrice)
@@ -267,6 +348,16 @@ func (r *orderBookResponse) updateBids(bids [][]json.Number) {
 	}
 }
 
+// isZeroSize returns true if the level is removed, ftx sends the removed levels with the size 0.0
+func isZeroSize(size json.Number) bool {
+	f, err := size.Float64()
+	if err != nil {
+		logger.WithError(err).Errorf("unexpected size %s", size)
+		return false
+	}
+	return f == 0
+}
+
 func upsertPriceVolume(dst [][]json.Number, src []json.Number, priceComparator func(dst float64, src float64) bool) [][]json.Number {
 	for i, pv := range dst {
 		dstPrice := pv[0]
@@ -323,7 +414,30 @@ func (r orderBookResponse) verifyChecksum() error {
 	return nil
 }
 
-// <best_bid_price>:<best_bid_size>:<best_ask_price>:<best_ask_size>...
+func (r orderBookResponse) verifyNotCrossed() error {
+	if len(r.Bids) == 0 || len(r.Asks) == 0 {
+		return nil
+	}
+
+	bid, err := r.Bids[0][0].Float64()
+	if err != nil {
+		return err
+	}
+	ask, err := r.Asks[0][0].Float64()
+	if err != nil {
+		return err
+	}
+
+	if bid >= ask {
+		return fmt.Errorf("best bid %f >= best ask %f: %w", bid, ask, errCrossedOrderBook)
+	}
+	return nil
+}
+
+// checksumDepth is the number of the levels per side covered by the checksum
+const checksumDepth = 100
+
+// <best_bid_price>:<best_bid_size>:<best_ask_price>:<best_ask_size>... of the best 100 levels per side
 func checksumString(bids, asks [][]json.Number) string {
 	sb := strings.Builder{}
 	appendNumber := func(pv []json.Number) {
@@ -336,7 +450,13 @@ func checksumString(bids, asks [][]json.Number) string {
 	}
 
 	bidsLen := len(bids)
+	if bidsLen > checksumDepth {
+		bidsLen = checksumDepth
+	}
 	asksLen := len(asks)
+	if asksLen > checksumDepth {
+		asksLen = checksumDepth
+	}
 	for i := 0; i < bidsLen || i < asksLen; i++ {
 		if i < bidsLen {
 			appendNumber(bids[i])
@@ -350,6 +470,8 @@ func checksumString(bids, asks [][]json.Number) string {
 
 var errUnmatchedChecksum = fmt.Errorf("unmatched checksum")
 
+var errCrossedOrderBook = fmt.Errorf("crossed orderbook")
+
 func toGlobalOrderBook(r orderBookResponse) (types.OrderBook, error) {
 	bids, err := toPriceVolumeSlice(r.Bids)
 	if err != nil {
@@ -3,6 +3,7 @@ package ftx
 import (
 	"encoding/json"
 	"io/ioutil"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -111,6 +112,14 @@ func Test_checksumString(t *testing.T) {
 			},
 			want: "5000.5:10:5001.0:6:4995.0:5:5002.0:7",
 		},
+		{
+			name: "only the best 100 levels per side",
+			args: args{
+				bids: levels(101, 5000, -1),
+				asks: levels(101, 5001, 1),
+			},
+			want: checksumLevels(levels(100, 5000, -1), levels(100, 5001, 1)),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -121,6 +130,21 @@ func Test_checksumString(t *testing.T) {
 	}
 }
 
+func levels(n int, price, step int) (pvs [][]json.Number) {
+	for i := 0; i < n; i++ {
+		pvs = append(pvs, []json.Number{json.Number(strconv.Itoa(price + i*step)), "1.0"})
+	}
+	return pvs
+}
+
+func checksumLevels(bids, asks [][]json.Number) string {
+	var s []string
+	for i := range bids {
+		s = append(s, string(bids[i][0]), string(bids[i][1]), string(asks[i][0]), string(asks[i][1]))
+	}
+	return strings.Join(s, ":")
+}
+
 func Test_orderBookResponse_verifyChecksum(t *testing.T) {
 	for _, file := range []string{"./orderbook_snapshot.json"} {
 		f, err := ioutil.ReadFile(file)
@@ -146,7 +170,7 @@ func Test_orderBookResponse_update(t *testing.T) {
 	ob := &orderBookResponse{Bids: nil, Asks: nil}
 
 	ob.update(orderBookResponse{
-		Bids: [][]json.Number{{"1.0", "0"}, {"10.0", "1"}, {"11.0", "1"}},
+		Bids: [][]json.Number{{"1.0", "0.0"}, {"10.0", "1"}, {"11.0", "1"}},
 		Asks: [][]json.Number{{"1.0", "1"}},
 	})
 	assert.Equal(t, [][]json.Number{{"11.0", "1"}, {"10.0", "1"}}, ob.Bids)
@@ -160,7 +184,7 @@ func Test_orderBookResponse_update(t *testing.T) {
 
 	// remove them
 	ob.update(orderBookResponse{
-		Bids: [][]json.Number{{"9.0", "0"}, {"12.0", "0"}, {"10.5", "0"}},
+		Bids: [][]json.Number{{"9.0", "0.0"}, {"12.0", "0"}, {"10.5", "0.00000000"}},
 		Asks: [][]json.Number{{"9.0", "1"}, {"12.0", "1"}, {"10.5", "1"}},
 	})
 	assert.Equal(t, [][]json.Number{{"11.0", "1"}, {"10.0", "1"}}, ob.Bids)
@@ -0,0 +1,425 @@
+package kraken
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// legacyAssets are the kraken asset names that are different from the global currencies
+var legacyAssets = map[string]string{
+	"XBT":  "BTC",
+	"XXBT": "BTC",
+	"XDG":  "DOGE",
+	"XXDG": "DOGE",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XXRP": "XRP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XZEC": "ZEC",
+	"XREP": "REP",
+	"XMLN": "MLN",
+	"ZUSD": "USD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZJPY": "JPY",
+	"ZCAD": "CAD",
+	"ZAUD": "AUD",
+}
+
+// quoteCurrencies are used to split the global symbol if the markets are not queried yet, the longer currencies
+// should go first, e.g., USDT before USD.
+var quoteCurrencies = []string{"USDT", "USDC", "DAI", "USD", "EUR", "GBP", "JPY", "CAD", "AUD", "CHF", "BTC", "ETH", "DOT"}
+
+// pairs maps the global symbol to the asset pair, and pairSymbols maps the pair name, the altname and the wsname of
+// the pair to the global symbol, they are filled by QueryMarkets.
+var pairs sync.Map
+var pairSymbols sync.Map
+
+func registerPair(name string, p assetPair) string {
+	symbol := toGlobalSymbol(p.WSName)
+	pairs.Store(symbol, p)
+	for _, n := range []string{name, p.Altname, p.WSName} {
+		pairSymbols.Store(n, symbol)
+	}
+	return symbol
+}
+
+func toGlobalCurrency(asset string) string {
+	asset = strings.ToUpper(asset)
+	if currency, ok := legacyAssets[asset]; ok {
+		return currency
+	}
+	return asset
+}
+
+func toLocalCurrency(currency string) string {
+	switch strings.ToUpper(currency) {
+	case "BTC":
+		return "XBT"
+	case "DOGE":
+		return "XDG"
+	}
+	return strings.ToUpper(currency)
+}
+
+// toGlobalSymbol converts the pair name, the altname or the wsname to the global symbol, e.g., XBT/USD -> BTCUSD
+func toGlobalSymbol(pair string) string {
+	if symbol, ok := pairSymbols.Load(pair); ok {
+		return symbol.(string)
+	}
+
+	if i := strings.IndexByte(pair, '/'); i >= 0 {
+		return toGlobalCurrency(pair[:i]) + toGlobalCurrency(pair[i+1:])
+	}
+	return strings.ToUpper(pair)
+}
+
+// toLocalPair converts the global symbol to the wsname of the pair, e.g., BTCUSD -> XBT/USD
+func toLocalPair(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if p, ok := pairs.Load(symbol); ok {
+		return p.(assetPair).WSName
+	}
+
+	if i := strings.IndexByte(symbol, '/'); i >= 0 {
+		return toLocalCurrency(toGlobalCurrency(symbol[:i])) + "/" + toLocalCurrency(toGlobalCurrency(symbol[i+1:]))
+	}
+
+	for _, quote := range quoteCurrencies {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return toLocalCurrency(strings.TrimSuffix(symbol, quote)) + "/" + toLocalCurrency(quote)
+		}
+	}
+	return symbol
+}
+
+// toRestPair converts the global symbol to the altname of the pair used by the rest api, e.g., BTCUSD -> XBTUSD
+func toRestPair(symbol string) string {
+	if p, ok := pairs.Load(strings.ToUpper(strings.TrimSpace(symbol))); ok {
+		return p.(assetPair).Altname
+	}
+	return strings.ReplaceAll(toLocalPair(symbol), "/", "")
+}
+
+// quoteCurrencyOf returns the quote currency of the global symbol
+func quoteCurrencyOf(symbol string) string {
+	if p, ok := pairs.Load(symbol); ok {
+		return toGlobalCurrency(p.(assetPair).Quote)
+	}
+
+	wsname := toLocalPair(symbol)
+	if i := strings.IndexByte(wsname, '/'); i >= 0 {
+		return toGlobalCurrency(wsname[i+1:])
+	}
+	return ""
+}
+
+// intervals are the supported intervals in minutes
+var intervals = map[types.Interval]int{
+	types.Interval1m:  1,
+	types.Interval5m:  5,
+	types.Interval15m: 15,
+	types.Interval30m: 30,
+	types.Interval1h:  60,
+	types.Interval4h:  240,
+	types.Interval1d:  1440,
+}
+
+func toLocalInterval(interval types.Interval) (int, error) {
+	minutes, ok := intervals[interval]
+	if !ok {
+		return 0, fmt.Errorf("unsupported interval %s", interval)
+	}
+	return minutes, nil
+}
+
+func toGlobalInterval(minutes int) (types.Interval, bool) {
+	for interval, m := range intervals {
+		if m == minutes {
+			return interval, true
+		}
+	}
+	return "", false
+}
+
+func toGlobalMarket(p assetPair) types.Market {
+	tickSize := p.TickSize.Float64()
+	if tickSize == 0 {
+		tickSize = math.Pow10(-p.PairDecimals)
+	}
+
+	return types.Market{
+		Symbol:          toGlobalSymbol(p.WSName),
+		PricePrecision:  p.PairDecimals,
+		VolumePrecision: p.LotDecimals,
+		QuoteCurrency:   toGlobalCurrency(p.Quote),
+		BaseCurrency:    toGlobalCurrency(p.Base),
+		MinNotional:     p.CostMin.Float64(),
+		MinQuantity:     p.OrderMin.Float64(),
+		StepSize:        math.Pow10(-p.LotDecimals),
+		TickSize:        tickSize,
+	}
+}
+
+func at(numbers []number, i int) number {
+	if i < len(numbers) {
+		return numbers[i]
+	}
+	return ""
+}
+
+func toGlobalTicker(t tickerInfo) types.Ticker {
+	return types.Ticker{
+		Time:   time.Now(),
+		Volume: at(t.Volume, 1).Float64(),
+		Last:   at(t.Close, 0).Float64(),
+		Open:   t.Open.Float64(),
+		High:   at(t.High, 1).Float64(),
+		Low:    at(t.Low, 1).Float64(),
+		Buy:    at(t.Bid, 0).Float64(),
+		Sell:   at(t.Ask, 0).Float64(),
+	}
+}
+
+// toGlobalKLine converts the rest ohlc [time, open, high, low, close, vwap, volume, count] to the kline
+func toGlobalKLine(symbol string, interval types.Interval, c ohlc) (types.KLine, error) {
+	if len(c) < 8 {
+		return types.KLine{}, fmt.Errorf("invalid ohlc %v", c)
+	}
+
+	startTime, err := parseUnixTime(c[0].String())
+	if err != nil {
+		return types.KLine{}, err
+	}
+
+	numbers := make([]number, len(c))
+	for i, n := range c {
+		numbers[i] = number(n.String())
+	}
+
+	volume := numbers[6].Float64()
+	count := numbers[7].Float64()
+	return types.KLine{
+		Exchange:       types.ExchangeKraken.String(),
+		Symbol:         symbol,
+		StartTime:      startTime,
+		EndTime:        startTime.Add(interval.Duration() - time.Millisecond),
+		Interval:       interval,
+		Open:           numbers[1].Float64(),
+		High:           numbers[2].Float64(),
+		Low:            numbers[3].Float64(),
+		Close:          numbers[4].Float64(),
+		Volume:         volume,
+		QuoteVolume:    volume * numbers[5].Float64(),
+		NumberOfTrades: uint64(count),
+	}, nil
+}
+
+func toGlobalBalances(balances map[string]balanceEx) types.BalanceMap {
+	global := make(types.BalanceMap)
+	for asset, b := range balances {
+		// the staked or the opt-in rewards assets have the suffix, e.g., XBT.M or ETH.F
+		if i := strings.IndexByte(asset, '.'); i >= 0 {
+			asset = asset[:i]
+		}
+		currency := toGlobalCurrency(asset)
+		total := b.Balance.Value()
+		locked := b.HoldTrade.Value()
+
+		// the assets of the same currency are merged
+		if balance, ok := global[currency]; ok {
+			total = total.Add(balance.Available).Add(balance.Locked)
+			locked = locked.Add(balance.Locked)
+		}
+
+		global[currency] = types.Balance{
+			Currency:  currency,
+			Available: total.Sub(locked),
+			Locked:    locked,
+		}
+	}
+	return global
+}
+
+// txIDHash hashes the kraken txid, e.g., OQCLML-BW3P3-BUCMWZ, to the numeric id since the txid is not numeric
+func txIDHash(txID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(txID))
+	return h.Sum64()
+}
+
+// orderTxIDs maps the order id to the txid of the order, it's used to cancel the order by the order id
+var orderTxIDs sync.Map
+
+// toGlobalOrderID returns the numeric id of the order txid and remembers the txid
+func toGlobalOrderID(txID string) uint64 {
+	id := txIDHash(txID)
+	orderTxIDs.Store(id, txID)
+	return id
+}
+
+func toLocalOrderTxID(orderID uint64) (string, bool) {
+	txID, ok := orderTxIDs.Load(orderID)
+	if !ok {
+		return "", false
+	}
+	return txID.(string), true
+}
+
+// toGlobalTradeID returns the positive numeric id of the trade txid
+func toGlobalTradeID(txID string) int64 {
+	return int64(txIDHash(txID) >> 1)
+}
+
+func toLocalSide(side types.SideType) string {
+	return strings.ToLower(string(side))
+}
+
+func toGlobalSide(side string) types.SideType {
+	switch side {
+	case "b":
+		return types.SideTypeBuy
+	case "s":
+		return types.SideTypeSell
+	}
+	return types.SideType(strings.ToUpper(side))
+}
+
+// toLocalOrderType returns the order type, the order flags and the time in force of the order
+func toLocalOrderType(orderType types.OrderType, timeInForce string) (string, string, string, error) {
+	switch orderType {
+	case types.OrderTypeLimit:
+		switch strings.ToUpper(timeInForce) {
+		case "", "GTC":
+			return "limit", "", "", nil
+		case "IOC":
+			return "limit", "", "IOC", nil
+		}
+		return "", "", "", fmt.Errorf("unsupported time in force %s", timeInForce)
+
+	case types.OrderTypeLimitMaker:
+		return "limit", "post", "", nil
+
+	case types.OrderTypeMarket:
+		return "market", "", "", nil
+	}
+
+	return "", "", "", fmt.Errorf("unsupported order type %s", orderType)
+}
+
+// toGlobalOrderType returns the order type of the kraken order type and the order flags
+func toGlobalOrderType(orderType, oflags string) (types.OrderType, error) {
+	switch orderType {
+	case "limit":
+		for _, flag := range strings.Split(oflags, ",") {
+			if flag == "post" {
+				return types.OrderTypeLimitMaker, nil
+			}
+		}
+		return types.OrderTypeLimit, nil
+	case "market":
+		return types.OrderTypeMarket, nil
+	}
+
+	return "", fmt.Errorf("unsupported order type %s", orderType)
+}
+
+func toGlobalOrderStatus(o orderInfo) (types.OrderStatus, bool, error) {
+	switch o.Status {
+	case "pending", "open":
+		if o.VolumeExecuted.Value() > 0 {
+			return types.OrderStatusPartiallyFilled, true, nil
+		}
+		return types.OrderStatusNew, true, nil
+	case "closed":
+		return types.OrderStatusFilled, false, nil
+	case "canceled", "expired":
+		return types.OrderStatusCanceled, false, nil
+	}
+
+	return "", false, fmt.Errorf("unsupported order status %s", o.Status)
+}
+
+func toGlobalOrder(txID string, o orderInfo) (types.Order, error) {
+	orderType, err := toGlobalOrderType(o.Description.OrderType, o.OFlags)
+	if err != nil {
+		return types.Order{}, err
+	}
+
+	status, isWorking, err := toGlobalOrderStatus(o)
+	if err != nil {
+		return types.Order{}, err
+	}
+
+	timeInForce := strings.ToUpper(o.TimeInForce)
+	if orderType == types.OrderTypeMarket {
+		timeInForce = ""
+	} else if timeInForce == "" {
+		timeInForce = "GTC"
+	}
+
+	updateTime := o.LastUpdated.Time()
+	if closeTime := o.CloseTime.Time(); closeTime.After(updateTime) {
+		updateTime = closeTime
+	}
+	if updateTime.Before(o.OpenTime.Time()) {
+		updateTime = o.OpenTime.Time()
+	}
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        toGlobalSymbol(o.Description.Pair),
+			Side:          toGlobalSide(o.Description.Type),
+			Type:          orderType,
+			Quantity:      o.Volume.Value(),
+			Price:         o.Description.Price.Value(),
+			TimeInForce:   timeInForce,
+		},
+		Exchange:         types.ExchangeKraken.String(),
+		OrderID:          toGlobalOrderID(txID),
+		Status:           status,
+		ExecutedQuantity: o.VolumeExecuted.Value(),
+		IsWorking:        isWorking,
+		CreationTime:     datatype.Time(o.OpenTime.Time()),
+		UpdateTime:       datatype.Time(updateTime),
+	}, nil
+}
+
+// toGlobalTrade converts the trade, the fee is charged in the quote currency by default
+func toGlobalTrade(txID string, t tradeInfo) types.Trade {
+	symbol := toGlobalSymbol(t.Pair)
+	side := toGlobalSide(t.Type)
+	return types.Trade{
+		ID:            toGlobalTradeID(txID),
+		OrderID:       toGlobalOrderID(t.OrderTxID),
+		Exchange:      types.ExchangeKraken.String(),
+		Price:         t.Price.Value(),
+		Quantity:      t.Volume.Value(),
+		QuoteQuantity: t.Cost.Value(),
+		Symbol:        symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       t.Maker,
+		Time:          datatype.Time(t.Time.Time()),
+		Fee:           t.Fee.Value(),
+		FeeCurrency:   quoteCurrencyOf(symbol),
+	}
+}
+
+// sortTrades sorts the trades in the ascending order of the time since the trade ids are hashes
+func sortTrades(trades []types.Trade) {
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Time().Before(trades[j].Time.Time())
+	})
+}
@@ -0,0 +1,456 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/exchange"
+	"github.com/ycdesu/spreaddog/pkg/exchange/ratelimit"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+const (
+	restEndpoint       = "https://api.kraken.com"
+	defaultHTTPTimeout = 15 * time.Second
+
+	// feeTierPair is the pair of the fee tier in the account, the spot pairs share the same fee schedule
+	feeTierPair = "XBTUSD"
+)
+
+var logger = logrus.WithField("exchange", "kraken")
+
+// rateLimits is the api counter of an account, the counter of the starter tier is 15 and decreases by 0.33 per second,
+// so it's a bucket of 15 refilled in 45 seconds
+var rateLimits = ratelimit.Limits{
+	Weight:   15,
+	Interval: 45 * time.Second,
+	Burst:    15,
+}
+
+var capabilities = types.ExchangeCapabilities{
+	Channels:    []types.Channel{types.BookChannel, types.KLineChannel, types.MarketTradeChannel, types.BookTickerChannel},
+	OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeMarket},
+	TimeInForce: []string{"GTC", "IOC"},
+}
+
+func init() {
+	exchange.Register(exchange.Registration{
+		Name: types.ExchangeKraken,
+		New: func(c exchange.Credentials) types.Exchange {
+			return NewExchange(c.Key, c.Secret)
+		},
+		Capabilities: capabilities,
+	})
+}
+
+type Exchange struct {
+	key, secret  string
+	restEndpoint *url.URL
+	client       *http.Client
+}
+
+func NewExchange(key, secret string) *Exchange {
+	u, err := url.Parse(restEndpoint)
+	if err != nil {
+		panic(err)
+	}
+	return &Exchange{
+		restEndpoint: u,
+		key:          key,
+		secret:       secret,
+		client: &http.Client{
+			Timeout:   defaultHTTPTimeout,
+			Transport: ratelimit.NewTransport(types.ExchangeKraken.String(), key, rateLimits, requestWeight),
+		},
+	}
+}
+
+// requestWeight returns the api counter cost of the endpoint, the ledger and the trade history queries cost 2
+func requestWeight(req *http.Request) int {
+	switch req.URL.Path {
+	case "/0/private/Ledgers", "/0/private/QueryLedgers", "/0/private/TradesHistory", "/0/private/QueryTrades":
+		return 2
+	}
+	return 1
+}
+
+func (e *Exchange) newRest() *restRequest {
+	return newRestRequest(e.client, e.restEndpoint).Auth(e.key, e.secret)
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeKraken
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return capabilities
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	s := NewStream()
+	s.tokenProvider = e.queryWebsocketToken
+	return s
+}
+
+func (e *Exchange) queryWebsocketToken(ctx context.Context) (string, error) {
+	token, err := e.newRest().WebsocketsToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
+
+// loadPairs queries the markets if they are not loaded, the pair names in the private responses can only be
+// converted to the global symbols after the markets are loaded.
+func (e *Exchange) loadPairs(ctx context.Context) error {
+	loaded := false
+	pairs.Range(func(key, value interface{}) bool {
+		loaded = true
+		return false
+	})
+	if loaded {
+		return nil
+	}
+
+	_, err := e.QueryMarkets(ctx)
+	return err
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	assetPairs, err := e.newRest().AssetPairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for name, p := range assetPairs {
+		// the dark pool pairs, e.g., XXBTZUSD.d, have no wsname and are not supported
+		if len(p.WSName) == 0 {
+			continue
+		}
+
+		registerPair(name, p)
+		market := toGlobalMarket(p)
+		markets[market.Symbol] = market
+	}
+	return markets, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	tickers, err := e.newRest().Ticker(ctx, toRestPair(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	// the ticker is keyed by the pair name which might be different from the requested pair
+	for _, t := range tickers {
+		ticker := toGlobalTicker(t)
+		return &ticker, nil
+	}
+	return nil, fmt.Errorf("ticker of %s is not found", symbol)
+}
+
+// QueryTickers returns the tickers of the given symbols, or all the pairs if no symbol is given
+func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	var restPairs []string
+	for _, s := range symbol {
+		restPairs = append(restPairs, toRestPair(s))
+	}
+
+	resp, err := e.newRest().Ticker(ctx, restPairs...)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make(map[string]types.Ticker)
+	for pair, t := range resp {
+		tickers[toGlobalSymbol(pair)] = toGlobalTicker(t)
+	}
+	return tickers, nil
+}
+
+// QueryKLines returns the klines in the ascending order, kraken only returns the most recent 720 klines, so the
+// klines before it can't be queried.
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	minutes, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	if options.StartTime != nil {
+		// since is exclusive
+		since = options.StartTime.Add(-time.Second)
+	}
+
+	candles, err := e.newRest().OHLC(ctx, toRestPair(symbol), minutes, since)
+	if err != nil {
+		return nil, err
+	}
+
+	globalSymbol := toGlobalSymbol(toLocalPair(symbol))
+	var klines []types.KLine
+	for _, c := range candles {
+		k, err := toGlobalKLine(globalSymbol, interval, c)
+		if err != nil {
+			return nil, err
+		}
+
+		if options.StartTime != nil && k.StartTime.Before(*options.StartTime) {
+			continue
+		}
+		if options.EndTime != nil && k.StartTime.After(*options.EndTime) {
+			continue
+		}
+		klines = append(klines, k)
+	}
+
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime)
+	})
+
+	// the klines closest to the end time are returned if there are more than the limit
+	if options.Limit > 0 && len(klines) > options.Limit {
+		klines = klines[len(klines)-options.Limit:]
+	}
+	return klines, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	volume, err := e.newRest().TradeVolume(ctx, feeTierPair)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fees are percentages
+	a := &types.Account{}
+	for _, f := range volume.FeesMaker {
+		a.MakerCommission = f.Fee.Value().Div(fixedpoint.NewFromInt(100))
+	}
+	for _, f := range volume.Fees {
+		a.TakerCommission = f.Fee.Value().Div(fixedpoint.NewFromInt(100))
+	}
+
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.UpdateBalances(balances)
+
+	return a, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	balances, err := e.newRest().BalanceEx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalances(balances), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+	for _, so := range orders {
+		orderType, oflags, timeInForce, err := toLocalOrderType(so.Type, so.TimeInForce)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		payload := AddOrderPayload{
+			Pair:          toRestPair(so.Symbol),
+			Type:          toLocalSide(so.Side),
+			OrderType:     orderType,
+			Volume:        so.Quantity.String(),
+			OFlags:        oflags,
+			TimeInForce:   timeInForce,
+			ClientOrderID: so.ClientOrderID,
+		}
+		if so.Type != types.OrderTypeMarket {
+			payload.Price = so.Price.String()
+		}
+
+		result, err := e.newRest().AddOrder(ctx, payload)
+		if err != nil {
+			return createdOrders, fmt.Errorf("failed to place order %+v: %w", so, err)
+		}
+
+		now := time.Now()
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder:  so,
+			Exchange:     types.ExchangeKraken.String(),
+			OrderID:      toGlobalOrderID(result.TxIDs[0]),
+			Status:       types.OrderStatusNew,
+			IsWorking:    true,
+			CreationTime: datatype.Time(now),
+			UpdateTime:   datatype.Time(now),
+		})
+	}
+	return createdOrders, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if err := e.loadPairs(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.newRest().OpenOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	globalSymbol := toGlobalSymbol(toLocalPair(symbol))
+	for txID, r := range resp {
+		o, err := toGlobalOrder(txID, r)
+		if err != nil {
+			return nil, err
+		}
+
+		// kraken returns the open orders of all the pairs
+		if o.Symbol != globalSymbol {
+			continue
+		}
+		orders = append(orders, o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Time().Before(orders[j].CreationTime.Time())
+	})
+	return orders, nil
+}
+
+// QueryClosedOrders returns the closed orders in the ascending order of the creation time. The order ids are the hashes
+// of the kraken txids, so the orders are not ordered by the id, the orders before and at the order of lastOrderID are
+// dropped.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if until == (time.Time{}) {
+		until = time.Now()
+	}
+	if since.After(until) {
+		return nil, fmt.Errorf("invalid query closed orders time range, since: %+v, until: %+v", since, until)
+	}
+
+	if err := e.loadPairs(ctx); err != nil {
+		return nil, err
+	}
+
+	globalSymbol := toGlobalSymbol(toLocalPair(symbol))
+	for ofs := 0; ; ofs += historyPageSize {
+		resp, err := e.newRest().ClosedOrders(ctx, since, until, ofs)
+		if err != nil {
+			return nil, err
+		}
+
+		for txID, r := range resp.Closed {
+			o, err := toGlobalOrder(txID, r)
+			if err != nil {
+				return nil, err
+			}
+
+			if o.Symbol != globalSymbol {
+				continue
+			}
+			orders = append(orders, o)
+		}
+
+		if len(resp.Closed) < historyPageSize || ofs+historyPageSize >= resp.Count {
+			break
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Time().Before(orders[j].CreationTime.Time())
+	})
+
+	for i, o := range orders {
+		if lastOrderID > 0 && o.OrderID == lastOrderID {
+			return orders[i+1:], nil
+		}
+	}
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, o := range orders {
+		txID, ok := toLocalOrderTxID(o.OrderID)
+		if !ok && len(o.ClientOrderID) == 0 {
+			return fmt.Errorf("the txid of the order %d is unknown, query the order before canceling it", o.OrderID)
+		}
+
+		if _, err := e.newRest().CancelOrder(ctx, txID, o.ClientOrderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryTrades returns the trades in the ascending order of the time. The trade ids are the hashes of the kraken txids,
+// so the trades before and at the trade of LastTradeID are dropped instead of comparing the ids.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	var since, until time.Time
+	if options.StartTime != nil {
+		since = *options.StartTime
+	}
+	if options.EndTime != nil {
+		until = *options.EndTime
+	} else {
+		until = time.Now()
+	}
+
+	if since.After(until) {
+		return nil, fmt.Errorf("invalid query trades time range, since: %+v, until: %+v", since, until)
+	}
+
+	if err := e.loadPairs(ctx); err != nil {
+		return nil, err
+	}
+
+	globalSymbol := toGlobalSymbol(toLocalPair(symbol))
+	var trades []types.Trade
+	for ofs := 0; ; ofs += historyPageSize {
+		resp, err := e.newRest().TradesHistory(ctx, since, until, ofs)
+		if err != nil {
+			return nil, err
+		}
+
+		for txID, r := range resp.Trades {
+			t := toGlobalTrade(txID, r)
+			if t.Symbol != globalSymbol {
+				continue
+			}
+			trades = append(trades, t)
+		}
+
+		if len(resp.Trades) < historyPageSize || ofs+historyPageSize >= resp.Count {
+			break
+		}
+	}
+
+	sortTrades(trades)
+
+	for i, t := range trades {
+		if options.LastTradeID > 0 && t.ID == options.LastTradeID {
+			trades = trades[i+1:]
+			break
+		}
+	}
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+	return trades, nil
+}
+
+var _ types.Exchange = &Exchange{}
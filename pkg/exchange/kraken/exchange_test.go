@@ -0,0 +1,286 @@
+package kraken
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+	"github.com/ycdesu/spreaddog/pkg/util"
+)
+
+var testSecret = base64.StdEncoding.EncodeToString([]byte("secret"))
+
+// fixtures are the recorded responses by the request path
+var fixtures = map[string]string{
+	"/0/public/AssetPairs":     "testdata/asset_pairs.json",
+	"/0/public/OHLC":           "testdata/ohlc.json",
+	"/0/private/BalanceEx":     "testdata/balance_ex.json",
+	"/0/private/OpenOrders":    "testdata/open_orders.json",
+	"/0/private/TradesHistory": "testdata/trades_history.json",
+}
+
+// newTestExchange returns the exchange of the test server, the server serves the fixtures and verifies the signatures
+// of the private requests.
+func newTestExchange(t *testing.T) (*Exchange, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			body, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			form, err := url.ParseQuery(string(body))
+			assert.NoError(t, err)
+
+			signature, err := sign(testSecret, r.URL.Path, form.Get("nonce"), string(body))
+			assert.NoError(t, err)
+			assert.Equal(t, "key", r.Header.Get("API-Key"))
+			assert.Equal(t, signature, r.Header.Get("API-Sign"))
+		}
+
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			fmt.Fprintln(w, `{"error":["EGeneral:Unknown method"]}`)
+			return
+		}
+
+		resp, err := ioutil.ReadFile(fixture)
+		assert.NoError(t, err)
+		_, _ = w.Write(resp)
+	}))
+
+	ex := NewExchange("key", testSecret)
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+	return ex, ts.Close
+}
+
+func TestExchange_QueryMarkets(t *testing.T) {
+	ex, closeServer := newTestExchange(t)
+	defer closeServer()
+
+	markets, err := ex.QueryMarkets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, types.MarketMap{
+		"BTCUSD": {
+			Symbol:          "BTCUSD",
+			PricePrecision:  1,
+			VolumePrecision: 8,
+			QuoteCurrency:   "USD",
+			BaseCurrency:    "BTC",
+			MinNotional:     0.5,
+			MinQuantity:     0.0001,
+			StepSize:        0.00000001,
+			TickSize:        0.1,
+		},
+		"ETHUSDT": {
+			Symbol:          "ETHUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 8,
+			QuoteCurrency:   "USDT",
+			BaseCurrency:    "ETH",
+			MinNotional:     0.5,
+			MinQuantity:     0.002,
+			StepSize:        0.00000001,
+			TickSize:        0.01,
+		},
+	}, markets)
+
+	// the symbols are converted by the registered pairs
+	assert.Equal(t, "XBT/USD", toLocalPair("BTCUSD"))
+	assert.Equal(t, "XBTUSD", toRestPair("BTCUSD"))
+	assert.Equal(t, "BTCUSD", toGlobalSymbol("XXBTZUSD"))
+	assert.Equal(t, "ETHUSDT", toGlobalSymbol("ETH/USDT"))
+}
+
+func TestExchange_QueryKLines(t *testing.T) {
+	ex, closeServer := newTestExchange(t)
+	defer closeServer()
+
+	startTime := time.Unix(1688671260, 0)
+	klines, err := ex.QueryKLines(context.Background(), "BTCUSD", types.Interval1m, types.KLineQueryOptions{
+		StartTime: &startTime,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, klines, 2) {
+		assert.Equal(t, "BTCUSD", klines[0].Symbol)
+		assert.Equal(t, startTime, klines[0].StartTime)
+		assert.Equal(t, startTime.Add(time.Minute-time.Millisecond), klines[0].EndTime)
+		assert.Equal(t, 30304.5, klines[0].Open)
+		assert.Equal(t, 30300.0, klines[0].Close)
+		assert.Equal(t, 4.42996871, klines[0].Volume)
+		assert.Equal(t, uint64(18), klines[0].NumberOfTrades)
+		assert.Equal(t, 30291.4, klines[1].Close)
+	}
+
+	klines, err = ex.QueryKLines(context.Background(), "BTCUSD", types.Interval1m, types.KLineQueryOptions{Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, klines, 1) {
+		assert.Equal(t, time.Unix(1688671320, 0), klines[0].StartTime)
+	}
+}
+
+func TestExchange_QueryAccountBalances(t *testing.T) {
+	ex, closeServer := newTestExchange(t)
+	defer closeServer()
+
+	balances, err := ex.QueryAccountBalances(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, types.BalanceMap{
+		"BTC": {
+			Currency:  "BTC",
+			Available: fixedpoint.MustNewFromString("1.7"),
+			Locked:    fixedpoint.MustNewFromString("0.3"),
+		},
+		"USD": {
+			Currency:  "USD",
+			Available: fixedpoint.NewFromInt(10000),
+			Locked:    0,
+		},
+	}, balances)
+}
+
+func TestExchange_QueryOpenOrders(t *testing.T) {
+	ex, closeServer := newTestExchange(t)
+	defer closeServer()
+
+	orders, err := ex.QueryOpenOrders(context.Background(), "BTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Order{
+		{
+			SubmitOrder: types.SubmitOrder{
+				ClientOrderID: "b1",
+				Symbol:        "BTCUSD",
+				Side:          types.SideTypeBuy,
+				Type:          types.OrderTypeLimitMaker,
+				Quantity:      fixedpoint.MustNewFromString("1.25"),
+				Price:         fixedpoint.NewFromInt(30010),
+				TimeInForce:   "GTC",
+			},
+			Exchange:         types.ExchangeKraken.String(),
+			OrderID:          txIDHash("OQCLML-BW3P3-BUCMWZ"),
+			Status:           types.OrderStatusPartiallyFilled,
+			ExecutedQuantity: fixedpoint.MustNewFromString("0.375"),
+			IsWorking:        true,
+			CreationTime:     orders[0].CreationTime,
+			UpdateTime:       orders[0].UpdateTime,
+		},
+	}, orders)
+	assert.Equal(t, time.Unix(1688666559, 897400000), orders[0].CreationTime.Time())
+
+	// the txid is remembered for canceling the order
+	txID, ok := toLocalOrderTxID(orders[0].OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, "OQCLML-BW3P3-BUCMWZ", txID)
+}
+
+func TestExchange_QueryTrades(t *testing.T) {
+	ex, closeServer := newTestExchange(t)
+	defer closeServer()
+
+	trades, err := ex.QueryTrades(context.Background(), "BTCUSD", &types.TradeQueryOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		// the trades are in the ascending order of the time
+		assert.Equal(t, toGlobalTradeID("TCWJEG-FL4SZ-3FKGH6"), trades[0].ID)
+		assert.Equal(t, toGlobalTradeID("THVRQM-33VKH-UCI7BS"), trades[1].ID)
+
+		assert.Equal(t, txIDHash("OQCLML-BW3P3-BUCMWZ"), trades[0].OrderID)
+		assert.Equal(t, "BTCUSD", trades[0].Symbol)
+		assert.Equal(t, types.SideTypeBuy, trades[0].Side)
+		assert.True(t, trades[0].IsBuyer)
+		assert.True(t, trades[0].IsMaker)
+		assert.Equal(t, "30010", trades[0].Price.String())
+		assert.Equal(t, "0.01", trades[0].Quantity.String())
+		assert.Equal(t, "300.1", trades[0].QuoteQuantity.String())
+		assert.Equal(t, "0.48016", trades[0].Fee.String())
+		assert.Equal(t, "USD", trades[0].FeeCurrency)
+	}
+
+	// the trades before and at the last trade are dropped
+	trades, err = ex.QueryTrades(context.Background(), "BTCUSD", &types.TradeQueryOptions{
+		LastTradeID: toGlobalTradeID("TCWJEG-FL4SZ-3FKGH6"),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, toGlobalTradeID("THVRQM-33VKH-UCI7BS"), trades[0].ID)
+	}
+}
+
+func TestExchange_ErrorResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"error":["EOrder:Insufficient funds"]}`)
+	}))
+	defer ts.Close()
+
+	ex := NewExchange("key", testSecret)
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+
+	_, err = ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSD",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromInt(1),
+		Price:    fixedpoint.NewFromInt(30000),
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "EOrder:Insufficient funds")
+	assert.True(t, errors.Is(err, types.ErrInsufficientBalance))
+
+	var errResp *ErrorResponse
+	if assert.True(t, errors.As(err, &errResp)) {
+		assert.Equal(t, []string{"EOrder:Insufficient funds"}, errResp.Errors)
+	}
+}
+
+func Test_toGlobalError(t *testing.T) {
+	tests := map[string]error{
+		"EAPI:Rate limit exceeded":            types.ErrRateLimited,
+		"EOrder:Rate limit exceeded":          types.ErrRateLimited,
+		"EAPI:Invalid key":                    types.ErrAuthentication,
+		"EAPI:Invalid nonce":                  types.ErrAuthentication,
+		"EOrder:Unknown order":                types.ErrOrderNotFound,
+		"EQuery:Unknown asset pair":           types.ErrInvalidSymbol,
+		"EOrder:Order minimum not met":        types.ErrInvalidPrecision,
+		"EService:Market in cancel_only mode": types.ErrMarketClosed,
+	}
+
+	for e, kind := range tests {
+		errResp := &ErrorResponse{
+			Response: &util.Response{Response: &http.Response{StatusCode: 200}},
+			Errors:   []string{e},
+		}
+		assert.True(t, errors.Is(toGlobalError(errResp), kind), e)
+	}
+
+	unknown := &ErrorResponse{
+		Response: &util.Response{Response: &http.Response{StatusCode: 200}},
+		Errors:   []string{"EService:Unavailable"},
+	}
+	assert.Equal(t, error(unknown), toGlobalError(unknown))
+}
+
+func TestSign(t *testing.T) {
+	// the example of https://docs.kraken.com/rest/#section/Authentication/Headers-and-Signature
+	signature, err := sign(
+		"kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg==",
+		"/0/private/AddOrder",
+		"1616492376594",
+		"nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ==", signature)
+}
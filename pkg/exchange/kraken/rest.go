@@ -0,0 +1,275 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+	"github.com/ycdesu/spreaddog/pkg/util"
+)
+
+type restRequest struct {
+	*marketRequest
+	*accountRequest
+	*tradeRequest
+
+	key, secret string
+
+	c       *http.Client
+	baseURL *url.URL
+	refURL  string
+	// http method, e.g., GET or POST
+	m string
+
+	// query string of the public requests
+	q map[string]string
+
+	// form payload of the private requests
+	p map[string]string
+}
+
+func newRestRequest(c *http.Client, baseURL *url.URL) *restRequest {
+	r := &restRequest{
+		c:       c,
+		baseURL: baseURL,
+		q:       make(map[string]string),
+		p:       make(map[string]string),
+	}
+
+	r.marketRequest = &marketRequest{restRequest: r}
+	r.accountRequest = &accountRequest{restRequest: r}
+	r.tradeRequest = &tradeRequest{restRequest: r}
+	return r
+}
+
+func (r *restRequest) Auth(key, secret string) *restRequest {
+	r.key = key
+	r.secret = secret
+	return r
+}
+
+func (r *restRequest) Method(method string) *restRequest {
+	r.m = method
+	return r
+}
+
+func (r *restRequest) ReferenceURL(refURL string) *restRequest {
+	r.refURL = refURL
+	return r
+}
+
+func (r *restRequest) buildURL() (*url.URL, error) {
+	refURL, err := url.Parse(r.refURL)
+	if err != nil {
+		return nil, err
+	}
+	return r.baseURL.ResolveReference(refURL), nil
+}
+
+// Payloads sets the form payload, the empty values are skipped
+func (r *restRequest) Payloads(payloads map[string]string) *restRequest {
+	for k, v := range payloads {
+		if len(v) > 0 {
+			r.p[k] = v
+		}
+	}
+	return r
+}
+
+// Query sets the query string, the empty values are skipped
+func (r *restRequest) Query(query map[string]string) *restRequest {
+	for k, v := range query {
+		if len(v) > 0 {
+			r.q[k] = v
+		}
+	}
+	return r
+}
+
+// DoPublicRequest sends the GET request of the public endpoints and decodes the result into v
+func (r *restRequest) DoPublicRequest(ctx context.Context, v interface{}) error {
+	u, err := r.buildURL()
+	if err != nil {
+		return err
+	}
+
+	rq := u.Query()
+	for k, val := range r.q {
+		rq.Set(k, val)
+	}
+	u.RawQuery = rq.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	return r.send(req, v)
+}
+
+// DoAuthenticatedRequest sends the signed POST request of the private endpoints and decodes the result into v
+func (r *restRequest) DoAuthenticatedRequest(ctx context.Context, v interface{}) error {
+	u, err := r.buildURL()
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	for k, val := range r.p {
+		form.Set(k, val)
+	}
+	nonce := nextNonce()
+	form.Set("nonce", nonce)
+	body := form.Encode()
+
+	signature, err := sign(r.secret, u.Path, nonce, body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.m, u.String(), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", r.key)
+	req.Header.Set("API-Sign", signature)
+
+	return r.send(req, v)
+}
+
+// lastNonce is the last nonce of all the private requests, the nonce must be increasing for the same key
+var lastNonce int64
+
+// nextNonce returns the increasing nonce, it's the unix time in milliseconds unless the requests are sent faster
+func nextNonce() string {
+	for {
+		last := atomic.LoadInt64(&lastNonce)
+		nonce := time.Now().UnixNano() / int64(time.Millisecond)
+		if nonce <= last {
+			nonce = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&lastNonce, last, nonce) {
+			return strconv.FormatInt(nonce, 10)
+		}
+	}
+}
+
+// sign returns base64(hmac-sha512(path + sha256(nonce + body), base64 decoded secret))
+func sign(secret, path, nonce, body string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid api secret: %w", err)
+	}
+
+	sha := sha256.Sum256([]byte(nonce + body))
+	mac := hmac.New(sha512.New, key)
+	mac.Write(append([]byte(path), sha[:]...))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (r *restRequest) send(req *http.Request, v interface{}) error {
+	resp, err := r.c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	// newResponse reads the response body and return a new Response object
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	var ar apiResponse
+	if err := response.DecodeJSON(&ar); err != nil {
+		if response.IsRateLimited() {
+			return types.NewRateLimitError(response.RetryAfter(), err)
+		}
+		return errors.Wrapf(err, "failed to decode json for response: %d %s", response.StatusCode, string(response.Body))
+	}
+
+	if response.IsError() || len(ar.Errors) > 0 {
+		return toGlobalError(&ErrorResponse{Response: response, Errors: ar.Errors})
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(ar.Result, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response result %s: %w", string(ar.Result), err)
+	}
+	return nil
+}
+
+// apiResponse is the envelope of all the kraken responses, the request succeeds if there is no error
+type apiResponse struct {
+	Errors []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+type ErrorResponse struct {
+	*util.Response
+
+	// Errors are in the format of <severity><category>:<message>, e.g., EOrder:Insufficient funds
+	Errors []string `json:"error"`
+}
+
+// toGlobalError maps the error response to the error types by the first error, the unknown errors are returned as they
+// are. see https://docs.kraken.com/rest/#section/General-Usage/Common-Error-Messages
+func toGlobalError(r *ErrorResponse) error {
+	if r.IsRateLimited() {
+		return types.NewRateLimitError(r.RetryAfter(), r)
+	}
+
+	for _, e := range r.Errors {
+		switch {
+		case strings.HasSuffix(e, ":Rate limit exceeded"), e == "EGeneral:Too many requests":
+			return types.NewRateLimitError(r.RetryAfter(), r)
+
+		case e == "EAPI:Invalid key", e == "EAPI:Invalid signature", e == "EAPI:Invalid nonce",
+			e == "EGeneral:Permission denied":
+			return types.NewExchangeError(types.ErrAuthentication, r)
+
+		case e == "EOrder:Insufficient funds", e == "EOrder:Insufficient margin":
+			return types.NewExchangeError(types.ErrInsufficientBalance, r)
+
+		case e == "EOrder:Unknown order":
+			return types.NewExchangeError(types.ErrOrderNotFound, r)
+
+		case e == "EQuery:Unknown asset pair":
+			return types.NewExchangeError(types.ErrInvalidSymbol, r)
+
+		case e == "EOrder:Order minimum not met", strings.HasPrefix(e, "EGeneral:Invalid arguments:volume"),
+			strings.HasPrefix(e, "EGeneral:Invalid arguments:price"):
+			return types.NewExchangeError(types.ErrInvalidPrecision, r)
+
+		case e == "EService:Market in cancel_only mode":
+			return types.NewExchangeError(types.ErrMarketClosed, r)
+		}
+	}
+
+	return r
+}
+
+func (r *ErrorResponse) Error() string {
+	return fmt.Sprintf("%s %s %d, errors: %s",
+		r.Response.Request.Method,
+		r.Response.Request.URL.String(),
+		r.Response.StatusCode,
+		strings.Join(r.Errors, ", "),
+	)
+}
@@ -0,0 +1,40 @@
+package kraken
+
+import (
+	"context"
+)
+
+type accountRequest struct {
+	*restRequest
+}
+
+// BalanceEx returns the balances and the amounts held by the open orders by the asset, e.g., XXBT
+func (r *accountRequest) BalanceEx(ctx context.Context) (map[string]balanceEx, error) {
+	var balances map[string]balanceEx
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/BalanceEx").
+		DoAuthenticatedRequest(ctx, &balances)
+	return balances, err
+}
+
+// TradeVolume returns the 30-day volume and the fee tiers of the pair
+func (r *accountRequest) TradeVolume(ctx context.Context, pair string) (tradeVolume, error) {
+	var v tradeVolume
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/TradeVolume").
+		Payloads(map[string]string{"pair": pair}).
+		DoAuthenticatedRequest(ctx, &v)
+	return v, err
+}
+
+// WebsocketsToken returns the token of the private websocket, it must be used within 15 minutes
+func (r *accountRequest) WebsocketsToken(ctx context.Context) (websocketsToken, error) {
+	var token websocketsToken
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/GetWebSocketsToken").
+		DoAuthenticatedRequest(ctx, &token)
+	return token, err
+}
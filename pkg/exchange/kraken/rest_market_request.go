@@ -0,0 +1,73 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type marketRequest struct {
+	*restRequest
+}
+
+// AssetPairs returns the tradable pairs by the pair name, e.g., XXBTZUSD
+func (r *marketRequest) AssetPairs(ctx context.Context) (map[string]assetPair, error) {
+	var pairs map[string]assetPair
+	err := r.
+		Method("GET").
+		ReferenceURL("0/public/AssetPairs").
+		DoPublicRequest(ctx, &pairs)
+	return pairs, err
+}
+
+// Ticker returns the tickers by the pair name, all the pairs are returned if no pair is given
+func (r *marketRequest) Ticker(ctx context.Context, pairs ...string) (map[string]tickerInfo, error) {
+	var tickers map[string]tickerInfo
+	err := r.
+		Method("GET").
+		ReferenceURL("0/public/Ticker").
+		Query(map[string]string{"pair": strings.Join(pairs, ",")}).
+		DoPublicRequest(ctx, &tickers)
+	return tickers, err
+}
+
+/*
+OHLC returns at most 720 candles of the pair in the ascending order, the candles after since are returned if it's
+given, otherwise the most recent ones are returned. The interval is in minutes.
+doc: https://docs.kraken.com/rest/#operation/getOHLCData
+*/
+func (r *marketRequest) OHLC(ctx context.Context, pair string, interval int, since time.Time) ([]ohlc, error) {
+	q := map[string]string{
+		"pair":     pair,
+		"interval": strconv.Itoa(interval),
+	}
+	if since != (time.Time{}) {
+		q["since"] = strconv.FormatInt(since.Unix(), 10)
+	}
+
+	// the result is {"<pair name>": [...], "last": <id>}
+	var result map[string]json.RawMessage
+	if err := r.
+		Method("GET").
+		ReferenceURL("0/public/OHLC").
+		Query(q).
+		DoPublicRequest(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	for k, v := range result {
+		if k == "last" {
+			continue
+		}
+
+		var candles []ohlc
+		if err := json.Unmarshal(v, &candles); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ohlc %s: %w", string(v), err)
+		}
+		return candles, nil
+	}
+	return nil, nil
+}
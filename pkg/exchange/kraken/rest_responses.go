@@ -0,0 +1,288 @@
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+// number is the numeric string of kraken, the empty string means zero
+type number string
+
+func (n number) Value() fixedpoint.Value {
+	if n == "" {
+		return 0
+	}
+
+	v, err := fixedpoint.NewFromString(string(n))
+	if err != nil {
+		logger.WithError(err).Warnf("invalid number %q", string(n))
+		return 0
+	}
+	return v
+}
+
+func (n number) Float64() float64 {
+	if n == "" {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		logger.WithError(err).Warnf("invalid number %q", string(n))
+		return 0
+	}
+	return f
+}
+
+// unixTime is the unix time in seconds with the fractional part, it's a number in the rest responses and a string
+// in the websocket messages, e.g., 1560516023.070651 or "1560516023.070651".
+type unixTime time.Time
+
+func (t *unixTime) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*t = unixTime{}
+		return nil
+	}
+
+	tt, err := parseUnixTime(s)
+	if err != nil {
+		return err
+	}
+	*t = unixTime(tt)
+	return nil
+}
+
+func (t unixTime) Time() time.Time {
+	return time.Time(t)
+}
+
+// parseUnixTime parses the decimal seconds exactly, the digits beyond nanoseconds are truncated
+func parseUnixTime(s string) (time.Time, error) {
+	sec, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		sec, frac = s[:i], s[i+1:]
+	}
+
+	seconds, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid unix time %s: %w", s, err)
+	}
+
+	var nanos int64
+	if len(frac) > 0 {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nanos, err = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix time %s: %w", s, err)
+		}
+	}
+	return time.Unix(seconds, nanos), nil
+}
+
+/*
+{
+  "altname": "XBTUSD",
+  "wsname": "XBT/USD",
+  "base": "XXBT",
+  "quote": "ZUSD",
+  "pair_decimals": 1,
+  "lot_decimals": 8,
+  "ordermin": "0.0001",
+  "costmin": "0.5",
+  "tick_size": "0.1",
+  "status": "online"
+}
+*/
+type assetPair struct {
+	Altname      string `json:"altname"`
+	WSName       string `json:"wsname"`
+	Base         string `json:"base"`
+	Quote        string `json:"quote"`
+	PairDecimals int    `json:"pair_decimals"`
+	LotDecimals  int    `json:"lot_decimals"`
+	OrderMin     number `json:"ordermin"`
+	CostMin      number `json:"costmin"`
+	TickSize     number `json:"tick_size"`
+	Status       string `json:"status"`
+}
+
+/*
+{
+  "a": ["30300.10000", "1", "1.000"],
+  "b": ["30300.00000", "1", "1.000"],
+  "c": ["30303.20000", "0.00067643"],
+  "v": ["4083.67001100", "4412.73601799"],
+  "p": ["30706.77771", "30689.13205"],
+  "t": [34619, 38907],
+  "l": ["29868.30000", "29868.30000"],
+  "h": ["31631.00000", "31631.00000"],
+  "o": "30502.80000"
+}
+
+The arrays of v, p, t, l and h are the values of today and the last 24 hours.
+*/
+type tickerInfo struct {
+	Ask    []number `json:"a"`
+	Bid    []number `json:"b"`
+	Close  []number `json:"c"`
+	Volume []number `json:"v"`
+	VWAP   []number `json:"p"`
+	Trades []int    `json:"t"`
+	Low    []number `json:"l"`
+	High   []number `json:"h"`
+	Open   number   `json:"o"`
+}
+
+// ohlc is [time, open, high, low, close, vwap, volume, count], the time and the count are numbers and the others
+// are strings
+type ohlc []json.Number
+
+/*
+{
+  "XXBT": {"balance": "1.5", "hold_trade": "0.3"},
+  "ZUSD": {"balance": "100.0000", "hold_trade": "0.0000"}
+}
+*/
+type balanceEx struct {
+	Balance   number `json:"balance"`
+	HoldTrade number `json:"hold_trade"`
+}
+
+// the fees are percentages, e.g., 0.26 means 0.26%
+type tradeVolume struct {
+	Currency  string                `json:"currency"`
+	Volume    number                `json:"volume"`
+	Fees      map[string]feeTierInfo `json:"fees"`
+	FeesMaker map[string]feeTierInfo `json:"fees_maker"`
+}
+
+type feeTierInfo struct {
+	Fee number `json:"fee"`
+}
+
+type websocketsToken struct {
+	Token   string `json:"token"`
+	Expires int    `json:"expires"`
+}
+
+/*
+{
+  "descr": {"order": "buy 1.25000000 XBTUSD @ limit 27500.0"},
+  "txid": ["OU22CG-KLAF2-FWUDD7"]
+}
+*/
+type addOrderResult struct {
+	Description struct {
+		Order string `json:"order"`
+	} `json:"descr"`
+	TxIDs []string `json:"txid"`
+}
+
+type cancelOrderResult struct {
+	Count int `json:"count"`
+}
+
+/*
+{
+  "refid": null,
+  "userref": 0,
+  "cl_ord_id": "b1",
+  "status": "open",
+  "opentm": 1688666559.8974,
+  "closetm": 0,
+  "descr": {
+    "pair": "XBTUSD",
+    "type": "buy",
+    "ordertype": "limit",
+    "price": "30010.0",
+    "price2": "0",
+    "order": "buy 1.25000000 XBTUSD @ limit 30010.0"
+  },
+  "vol": "1.25000000",
+  "vol_exec": "0.37500000",
+  "cost": "11253.7",
+  "fee": "0.00000",
+  "price": "30010.0",
+  "oflags": "fciq"
+}
+
+The order of the websocket openOrders channel might only contain the changed fields.
+*/
+type orderInfo struct {
+	UserRef        int64       `json:"userref"`
+	ClientOrderID  string      `json:"cl_ord_id"`
+	Status         string      `json:"status"`
+	OpenTime       unixTime    `json:"opentm"`
+	CloseTime      unixTime    `json:"closetm"`
+	LastUpdated    unixTime    `json:"lastupdated"`
+	Description    orderDescr  `json:"descr"`
+	Volume         number      `json:"vol"`
+	VolumeExecuted number      `json:"vol_exec"`
+	Cost           number      `json:"cost"`
+	Fee            number      `json:"fee"`
+	AveragePrice   number      `json:"price"`
+	OFlags         string      `json:"oflags"`
+	TimeInForce    string      `json:"timeinforce"`
+}
+
+type orderDescr struct {
+	Pair      string `json:"pair"`
+	Type      string `json:"type"`
+	OrderType string `json:"ordertype"`
+	Price     number `json:"price"`
+	Price2    number `json:"price2"`
+	Order     string `json:"order"`
+}
+
+type openOrdersResult struct {
+	Open map[string]orderInfo `json:"open"`
+}
+
+type closedOrdersResult struct {
+	Closed map[string]orderInfo `json:"closed"`
+	Count  int                  `json:"count"`
+}
+
+/*
+{
+  "ordertxid": "OQCLML-BW3P3-BUCMWZ",
+  "postxid": "TKH2SE-M7IF5-CFI7LT",
+  "pair": "XXBTZUSD",
+  "time": 1688667796.8802,
+  "type": "buy",
+  "ordertype": "limit",
+  "price": "30010.00000",
+  "cost": "600.20000",
+  "fee": "0.00000",
+  "vol": "0.02000000",
+  "margin": "0.00000",
+  "maker": true
+}
+*/
+type tradeInfo struct {
+	OrderTxID string   `json:"ordertxid"`
+	PosTxID   string   `json:"postxid"`
+	Pair      string   `json:"pair"`
+	Time      unixTime `json:"time"`
+	Type      string   `json:"type"`
+	OrderType string   `json:"ordertype"`
+	Price     number   `json:"price"`
+	Cost      number   `json:"cost"`
+	Fee       number   `json:"fee"`
+	Volume    number   `json:"vol"`
+	Margin    number   `json:"margin"`
+	Maker     bool     `json:"maker"`
+}
+
+type tradesHistoryResult struct {
+	Trades map[string]tradeInfo `json:"trades"`
+	Count  int                  `json:"count"`
+}
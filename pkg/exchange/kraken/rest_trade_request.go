@@ -0,0 +1,115 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// the max number of the closed orders and the trades of a page
+const historyPageSize = 50
+
+type tradeRequest struct {
+	*restRequest
+}
+
+type AddOrderPayload struct {
+	Pair          string
+	Type          string
+	OrderType     string
+	Price         string
+	Volume        string
+	OFlags        string
+	TimeInForce   string
+	ClientOrderID string
+}
+
+func (r *tradeRequest) AddOrder(ctx context.Context, p AddOrderPayload) (addOrderResult, error) {
+	var result addOrderResult
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/AddOrder").
+		Payloads(map[string]string{
+			"pair":        p.Pair,
+			"type":        p.Type,
+			"ordertype":   p.OrderType,
+			"price":       p.Price,
+			"volume":      p.Volume,
+			"oflags":      p.OFlags,
+			"timeinforce": p.TimeInForce,
+			"cl_ord_id":   p.ClientOrderID,
+		}).
+		DoAuthenticatedRequest(ctx, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if len(result.TxIDs) == 0 {
+		return result, fmt.Errorf("empty txid of the order %s", result.Description.Order)
+	}
+	return result, nil
+}
+
+// CancelOrder cancels the order by the txid, or by the client order id if the txid is empty
+func (r *tradeRequest) CancelOrder(ctx context.Context, txID, clientOrderID string) (cancelOrderResult, error) {
+	payload := map[string]string{"txid": txID}
+	if len(txID) == 0 {
+		payload["cl_ord_id"] = clientOrderID
+	}
+
+	var result cancelOrderResult
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/CancelOrder").
+		Payloads(payload).
+		DoAuthenticatedRequest(ctx, &result)
+	return result, err
+}
+
+// OpenOrders returns the open orders of all the pairs by the txid
+func (r *tradeRequest) OpenOrders(ctx context.Context) (map[string]orderInfo, error) {
+	var result openOrdersResult
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/OpenOrders").
+		DoAuthenticatedRequest(ctx, &result)
+	return result.Open, err
+}
+
+// ClosedOrders returns a page of the closed orders of all the pairs in the time range, the orders are in the
+// descending order of the time and ofs is the offset of the page.
+func (r *tradeRequest) ClosedOrders(ctx context.Context, start, end time.Time, ofs int) (closedOrdersResult, error) {
+	var result closedOrdersResult
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/ClosedOrders").
+		Payloads(timeRangePayload(start, end, ofs)).
+		DoAuthenticatedRequest(ctx, &result)
+	return result, err
+}
+
+// TradesHistory returns a page of the trades of all the pairs in the time range, the trades are in the descending
+// order of the time and ofs is the offset of the page.
+func (r *tradeRequest) TradesHistory(ctx context.Context, start, end time.Time, ofs int) (tradesHistoryResult, error) {
+	var result tradesHistoryResult
+	err := r.
+		Method("POST").
+		ReferenceURL("0/private/TradesHistory").
+		Payloads(timeRangePayload(start, end, ofs)).
+		DoAuthenticatedRequest(ctx, &result)
+	return result, err
+}
+
+func timeRangePayload(start, end time.Time, ofs int) map[string]string {
+	payload := map[string]string{
+		"ofs": strconv.Itoa(ofs),
+	}
+	if start != (time.Time{}) {
+		payload["start"] = strconv.FormatInt(start.Unix(), 10)
+	}
+	if end != (time.Time{}) {
+		payload["end"] = strconv.FormatInt(end.Unix(), 10)
+	}
+	return payload
+}
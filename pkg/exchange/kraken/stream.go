@@ -0,0 +1,229 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ycdesu/spreaddog/pkg/service"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+const publicEndpoint = "wss://ws.kraken.com"
+const privateEndpoint = "wss://ws-auth.kraken.com"
+
+// tokenTimeout is the timeout of querying the websocket token for every private connection
+const tokenTimeout = 10 * time.Second
+
+// Stream connects the public and the private endpoints separately, the private connection is only made if the stream
+// is not public only. The connect and disconnect events are emitted for both connections.
+type Stream struct {
+	*types.StandardStream
+
+	public, private *service.WebsocketClientBase
+
+	// publicOnly can only be configured before connecting
+	publicOnly int32
+
+	// tokenProvider returns the token of the private channels, a new token is queried for every private connection
+	tokenProvider func(ctx context.Context) (string, error)
+
+	// mu protects subscriptions, they can be changed after connecting
+	mu sync.Mutex
+
+	subscriptions []websocketRequest
+}
+
+func NewStream() *Stream {
+	return newStream(publicEndpoint, privateEndpoint)
+}
+
+func newStream(publicURL, privateURL string) *Stream {
+	s := &Stream{
+		StandardStream: &types.StandardStream{},
+		public:         service.NewWebsocketClientBase(publicURL, service.DefaultWebsocketClientOptions),
+		private:        service.NewWebsocketClientBase(privateURL, service.DefaultWebsocketClientOptions),
+	}
+
+	publicHandler := newMessageHandler(s.StandardStream)
+	publicHandler.resyncOrderBook = s.resubscribeOrderBook
+	s.setupClient(s.public, publicHandler)
+	s.public.OnConnected(func(conn *websocket.Conn) {
+		// the updates might be lost while reconnecting, the books will be loaded from the new snapshots
+		publicHandler.resetOrderBooks(types.BookInvalidReasonReconnect)
+		s.EmitConnect()
+	})
+
+	privateHandler := newMessageHandler(s.StandardStream)
+	s.setupClient(s.private, privateHandler)
+	s.private.OnConnected(func(conn *websocket.Conn) {
+		// the open orders are sent again after subscribing
+		privateHandler.orders = make(map[string]orderInfo)
+		s.subscribePrivateChannels()
+		s.EmitConnect()
+	})
+
+	return s
+}
+
+func (s *Stream) setupClient(client *service.WebsocketClientBase, h *messageHandler) {
+	// https://docs.kraken.com/websockets/#message-ping
+	client.SetPingFunc(func(conn *websocket.Conn) error {
+		return conn.WriteJSON(websocketRequest{Event: pingEvent})
+	})
+	client.OnMessage(func(message []byte) {
+		s.EmitMessage()
+	})
+	client.OnMessage(h.handleMessage)
+	client.OnError(s.EmitError)
+	client.OnDisconnected(func(conn *websocket.Conn) {
+		s.EmitDisconnect()
+	})
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.public.Connect(ctx); err != nil {
+		return err
+	}
+
+	// If it's not public only, let's do the authentication.
+	if atomic.LoadInt32(&s.publicOnly) == 0 {
+		if s.tokenProvider == nil {
+			return fmt.Errorf("the private channels of kraken need the websocket token, create the stream from the exchange")
+		}
+		return s.private.Connect(ctx)
+	}
+	return nil
+}
+
+// subscribePrivateChannels queries a new token and subscribes the private channels, the token can't be replayed by the
+// websocket client since it might have expired.
+func (s *Stream) subscribePrivateChannels() {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenTimeout)
+	defer cancel()
+
+	token, err := s.tokenProvider(ctx)
+	if err != nil {
+		s.private.EmitError(fmt.Errorf("failed to query the websocket token: %w", err))
+		return
+	}
+
+	noSnapshot := false
+	for _, sub := range []subscription{
+		{Name: openOrdersChannel, Token: token},
+		// the recent trades are queried through the rest api
+		{Name: ownTradesChannel, Token: token, Snapshot: &noSnapshot},
+	} {
+		sub := sub
+		if err := s.private.WriteJSON(websocketRequest{Event: subscribeEvent, Subscription: &sub}); err != nil {
+			s.private.EmitError(fmt.Errorf("failed to subscribe %s: %w", sub.Name, err))
+		}
+	}
+}
+
+// addSubscription adds the subscription of the public channel, the request is sent immediately if the stream is
+// connected. The caller must hold the lock.
+func (s *Stream) addSubscription(pair string, sub subscription) {
+	request := websocketRequest{Event: subscribeEvent, Pairs: []string{pair}, Subscription: &sub}
+	s.subscriptions = append(s.subscriptions, request)
+	if err := s.public.AddSubscription(request); err != nil {
+		s.public.EmitError(fmt.Errorf("failed to subscribe %s %s: %w", sub.Name, pair, err))
+	}
+}
+
+// removeSubscription removes the subscriptions of the channel and the pair, the unsubscribe request is sent
+// immediately if the stream is connected. The caller must hold the lock.
+func (s *Stream) removeSubscription(name, pair string) {
+	var subscriptions []websocketRequest
+	for _, request := range s.subscriptions {
+		if request.Subscription.Name == name && request.Pairs[0] == pair {
+			if err := s.public.RemoveSubscription(request, websocketRequest{
+				Event:        unsubscribeEvent,
+				Pairs:        request.Pairs,
+				Subscription: request.Subscription,
+			}); err != nil {
+				s.public.EmitError(fmt.Errorf("failed to unsubscribe %s %s: %w", name, pair, err))
+			}
+			continue
+		}
+		subscriptions = append(subscriptions, request)
+	}
+	s.subscriptions = subscriptions
+}
+
+// resubscribeOrderBook unsubscribes and subscribes the book channel again, so that kraken sends a new snapshot
+func (s *Stream) resubscribeOrderBook(pair string) {
+	for _, event := range []string{unsubscribeEvent, subscribeEvent} {
+		if err := s.public.WriteJSON(websocketRequest{
+			Event:        event,
+			Pairs:        []string{pair},
+			Subscription: &subscription{Name: bookChannel, Depth: bookDepth},
+		}); err != nil {
+			s.public.EmitError(fmt.Errorf("failed to %s orderbook of %s: %w", event, pair, err))
+			return
+		}
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	atomic.StoreInt32(&s.publicOnly, 1)
+}
+
+// Subscribe adds the subscription, the subscribe request is sent immediately if the stream is connected.
+func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pair := toLocalPair(symbol)
+	switch channel {
+	case types.BookChannel:
+		s.addSubscription(pair, subscription{Name: bookChannel, Depth: bookDepth})
+	case types.MarketTradeChannel:
+		s.addSubscription(pair, subscription{Name: tradeChannel})
+	case types.BookTickerChannel:
+		s.addSubscription(pair, subscription{Name: spreadChannel})
+	case types.KLineChannel:
+		minutes, err := toLocalInterval(types.Interval(options.Interval))
+		if err != nil {
+			panic(fmt.Sprintf("unsupported kline interval: %q", options.Interval))
+		}
+		s.addSubscription(pair, subscription{Name: ohlcChannel, Interval: minutes})
+	default:
+		panic("only support book, book ticker, market trade and kline channels now")
+	}
+}
+
+// Unsubscribe removes the subscriptions of the channel and the symbol, the unsubscribe request is sent immediately
+// if the stream is connected.
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pair := toLocalPair(symbol)
+	switch channel {
+	case types.BookChannel:
+		s.removeSubscription(bookChannel, pair)
+	case types.MarketTradeChannel:
+		s.removeSubscription(tradeChannel, pair)
+	case types.BookTickerChannel:
+		s.removeSubscription(spreadChannel, pair)
+	case types.KLineChannel:
+		// the klines of all the intervals are unsubscribed
+		s.removeSubscription(ohlcChannel, pair)
+	}
+}
+
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	s.subscriptions = nil
+	s.mu.Unlock()
+
+	if err := s.private.Close(); err != nil {
+		logger.WithError(err).Warn("failed to close the private connection")
+	}
+	return s.public.Close()
+}
@@ -0,0 +1,326 @@
+package kraken
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// orderBookResyncs counts the order book resyncs by pair, it's published through expvar
+var orderBookResyncs = expvar.NewMap("kraken_orderbook_resyncs")
+
+// klineKey is the ohlc subscription of a pair
+type klineKey struct {
+	pair     string
+	interval types.Interval
+}
+
+// messageHandler handles the messages of a connection, the public and the private connections have their own
+// handlers since the messages of different connections are handled in different goroutines.
+type messageHandler struct {
+	*types.StandardStream
+
+	// books are the local order books of the pairs, every update is applied to the local book to verify the checksum
+	books map[string]*localOrderBook
+
+	// klines are the last klines of the ohlc subscriptions, they are closed when the next kline starts
+	klines map[klineKey]types.KLine
+
+	// orders are the open orders by the txid, the order updates only contain the changed fields
+	orders map[string]orderInfo
+
+	// resyncOrderBook is called when the checksum of the local book doesn't match, it should request a new snapshot
+	resyncOrderBook func(pair string)
+}
+
+func newMessageHandler(stream *types.StandardStream) *messageHandler {
+	return &messageHandler{
+		StandardStream: stream,
+		books:          make(map[string]*localOrderBook),
+		klines:         make(map[klineKey]types.KLine),
+		orders:         make(map[string]orderInfo),
+	}
+}
+
+func (h *messageHandler) handleMessage(message []byte) {
+	message = bytes.TrimSpace(message)
+	if len(message) == 0 {
+		return
+	}
+
+	if message[0] == '{' {
+		h.handleEvent(message)
+		return
+	}
+
+	var fields []json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal resp: %s", string(message))
+		return
+	}
+
+	if len(fields) < 3 {
+		logger.Warnf("unsupported message: %s", string(message))
+		return
+	}
+
+	// the private message is [payload, channel name, {"sequence": n}]
+	if bytes.HasPrefix(fields[0], []byte("[")) {
+		h.handlePrivateMessage(rawString(fields[1]), fields[0])
+		return
+	}
+
+	// the public message is [channel id, payload..., channel name, pair]
+	channelName := rawString(fields[len(fields)-2])
+	pair := rawString(fields[len(fields)-1])
+	payloads := fields[1 : len(fields)-2]
+
+	channel, param := channelOf(channelName)
+	switch channel {
+	case bookChannel:
+		h.handleOrderBook(pair, param, payloads)
+	case tradeChannel:
+		h.handleMarketTrades(pair, payloads)
+	case ohlcChannel:
+		h.handleOHLC(pair, param, payloads)
+	case spreadChannel:
+		h.handleSpread(pair, payloads)
+	default:
+		logger.Warnf("unsupported channel: %s", channelName)
+	}
+}
+
+func (h *messageHandler) handleEvent(message []byte) {
+	var e websocketEvent
+	if err := json.Unmarshal(message, &e); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal event: %s", string(message))
+		return
+	}
+
+	switch e.Event {
+	case pongEvent, heartbeatEvent:
+	case systemStatusEvent:
+		logger.Infof("system status: %s", e.Status)
+	case subscriptionStatusEvent:
+		switch e.Status {
+		case subscribedStatus:
+			logger.Infof("subscribed %s %s", e.ChannelName, e.Pair)
+		case unsubscribedStatus:
+			// drop the local book, if it's unsubscribed for the resync, the snapshot comes after subscribing again
+			if e.Subscription.Name == bookChannel {
+				delete(h.books, e.Pair)
+			}
+			logger.Infof("unsubscribed %s %s", e.ChannelName, e.Pair)
+		case errorStatus:
+			logger.Errorf("subscription error of %s %s: %s", e.Subscription.Name, e.Pair, e.ErrorMessage)
+		}
+	default:
+		logger.Warnf("unsupported event: %s", string(message))
+	}
+}
+
+func (h *messageHandler) handleOrderBook(pair string, depth int, payloads []json.RawMessage) {
+	var bids, asks []bookLevel
+	var checksum string
+	snapshot := false
+	for _, raw := range payloads {
+		var p bookPayload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			logger.WithError(err).Errorf("failed to unmarshal the book: %s", string(raw))
+			return
+		}
+
+		if p.AsksSnapshot != nil || p.BidsSnapshot != nil {
+			snapshot = true
+			bids = append(bids, p.BidsSnapshot...)
+			asks = append(asks, p.AsksSnapshot...)
+			continue
+		}
+
+		bids = append(bids, p.Bids...)
+		asks = append(asks, p.Asks...)
+		if len(p.Checksum) > 0 {
+			checksum = p.Checksum
+		}
+	}
+
+	symbol := toGlobalSymbol(pair)
+	if snapshot {
+		book := newLocalOrderBook(depth, bids, asks)
+		h.books[pair] = book
+		h.EmitBookSnapshot(toGlobalOrderBook(symbol, book.bids, book.asks))
+		return
+	}
+
+	book, ok := h.books[pair]
+	if !ok {
+		// the update is dropped until the snapshot comes
+		logger.Warnf("orderbook update of %s is dropped, the snapshot is not received yet", pair)
+		return
+	}
+
+	removedBids, removedAsks := book.update(bids, asks)
+	if err := book.verifyChecksum(checksum); err != nil {
+		h.resync(pair, types.BookInvalidReasonChecksum, err)
+		return
+	}
+
+	// emit updates, not the whole orderbook. The removals of the levels beyond the depth come after the updates, so
+	// the downstream books keep the same levels as the local book.
+	h.EmitBookUpdate(toGlobalOrderBook(symbol, append(bids, removedBids...), append(asks, removedAsks...)))
+}
+
+// resync drops the local book of the pair and requests a new snapshot
+func (h *messageHandler) resync(pair string, reason types.BookInvalidReason, err error) {
+	delete(h.books, pair)
+	orderBookResyncs.Add(pair, 1)
+	logger.WithError(err).Warnf("orderbook of %s is inconsistent (%s), resubscribing", pair, reason)
+
+	h.EmitBookInvalidated(toGlobalSymbol(pair), reason)
+
+	if h.resyncOrderBook != nil {
+		h.resyncOrderBook(pair)
+	}
+}
+
+// resetOrderBooks drops all the local books, the snapshots will be sent after subscribing again
+func (h *messageHandler) resetOrderBooks(reason types.BookInvalidReason) {
+	for pair := range h.books {
+		delete(h.books, pair)
+		h.EmitBookInvalidated(toGlobalSymbol(pair), reason)
+	}
+}
+
+func (h *messageHandler) handleMarketTrades(pair string, payloads []json.RawMessage) {
+	symbol := toGlobalSymbol(pair)
+	for _, raw := range payloads {
+		var trades [][]json.RawMessage
+		if err := json.Unmarshal(raw, &trades); err != nil {
+			logger.WithError(err).Errorf("failed to unmarshal the trades: %s", string(raw))
+			return
+		}
+
+		for _, fields := range trades {
+			trade, err := toGlobalMarketTrade(symbol, fields)
+			if err != nil {
+				logger.WithError(err).Errorf("failed to convert the market trade")
+				continue
+			}
+			h.EmitMarketTrade(trade)
+		}
+	}
+}
+
+// handleOHLC emits the kline of every push, the kline is closed when the next kline starts since kraken doesn't
+// push the closed kline.
+func (h *messageHandler) handleOHLC(pair string, minutes int, payloads []json.RawMessage) {
+	interval, ok := toGlobalInterval(minutes)
+	if !ok {
+		logger.Warnf("unsupported ohlc interval %d", minutes)
+		return
+	}
+
+	symbol := toGlobalSymbol(pair)
+	key := klineKey{pair: pair, interval: interval}
+	for _, raw := range payloads {
+		var fields []json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			logger.WithError(err).Errorf("failed to unmarshal the ohlc: %s", string(raw))
+			return
+		}
+
+		k, err := toGlobalWebsocketKLine(symbol, interval, fields)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to convert the ohlc")
+			continue
+		}
+
+		if last, ok := h.klines[key]; ok {
+			if k.StartTime.Before(last.StartTime) {
+				continue
+			}
+
+			if k.StartTime.After(last.StartTime) {
+				last.Closed = true
+				h.EmitKLineClosed(last)
+			}
+		}
+
+		h.klines[key] = k
+		h.EmitKLine(k)
+	}
+}
+
+func (h *messageHandler) handleSpread(pair string, payloads []json.RawMessage) {
+	for _, raw := range payloads {
+		var fields []json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			logger.WithError(err).Errorf("failed to unmarshal the spread: %s", string(raw))
+			return
+		}
+
+		bookTicker, err := toGlobalBookTicker(toGlobalSymbol(pair), fields)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to convert the spread")
+			continue
+		}
+		h.EmitBookTicker(bookTicker)
+	}
+}
+
+// handlePrivateMessage handles the payload [{txid: {...}}, ...] of the private channels
+func (h *messageHandler) handlePrivateMessage(channelName string, payload json.RawMessage) {
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &items); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the %s: %s", channelName, string(payload))
+		return
+	}
+
+	for _, item := range items {
+		for txID, raw := range item {
+			switch channelName {
+			case openOrdersChannel:
+				h.handleOrder(txID, raw)
+			case ownTradesChannel:
+				h.handleTrade(txID, raw)
+			default:
+				logger.Warnf("unsupported channel: %s", channelName)
+				return
+			}
+		}
+	}
+}
+
+// handleOrder merges the changed fields into the cached order and emits the order update
+func (h *messageHandler) handleOrder(txID string, raw json.RawMessage) {
+	o := h.orders[txID]
+	if err := json.Unmarshal(raw, &o); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the order: %s", string(raw))
+		return
+	}
+
+	globalOrder, err := toGlobalOrder(txID, o)
+	if err != nil {
+		logger.WithError(err).Errorf("failed to convert order update to global order")
+		return
+	}
+
+	if globalOrder.IsWorking {
+		h.orders[txID] = o
+	} else {
+		delete(h.orders, txID)
+	}
+	h.EmitOrderUpdate(globalOrder)
+}
+
+func (h *messageHandler) handleTrade(txID string, raw json.RawMessage) {
+	var t tradeInfo
+	if err := json.Unmarshal(raw, &t); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the trade: %s", string(raw))
+		return
+	}
+
+	h.EmitTradeUpdate(toGlobalTrade(txID, t))
+}
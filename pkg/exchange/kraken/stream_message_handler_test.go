@@ -0,0 +1,208 @@
+package kraken
+
+import (
+	"hash/crc32"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestMessageHandler_OrderBook(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var snapshots, updates []types.OrderBook
+	h.OnBookSnapshot(func(book types.OrderBook) {
+		snapshots = append(snapshots, book)
+	})
+	h.OnBookUpdate(func(book types.OrderBook) {
+		updates = append(updates, book)
+	})
+
+	var resyncs []string
+	h.resyncOrderBook = func(pair string) {
+		resyncs = append(resyncs, pair)
+	}
+
+	h.handleMessage(readFixture(t, "book_snapshot.json"))
+	if assert.Len(t, snapshots, 1) {
+		assert.Equal(t, "BTCUSD", snapshots[0].Symbol)
+		assert.Len(t, snapshots[0].Bids, 3)
+		assert.Len(t, snapshots[0].Asks, 3)
+		assert.Equal(t, "5541.3", snapshots[0].Asks[0].Price.String())
+	}
+
+	h.handleMessage(readFixture(t, "book_update.json"))
+	assert.Empty(t, resyncs)
+	if assert.Len(t, updates, 1) {
+		// the update contains the changed levels only
+		assert.Equal(t, types.OrderBook{
+			Symbol: "BTCUSD",
+			Bids: types.PriceVolumeSlice{
+				{Price: number("5541.2").Value(), Volume: number("1").Value()},
+			},
+			Asks: types.PriceVolumeSlice{
+				{Price: number("5541.3").Value(), Volume: 0},
+				{Price: number("5542.5").Value(), Volume: number("0.401").Value()},
+			},
+		}, updates[0])
+	}
+}
+
+func TestMessageHandler_OrderBookChecksumMismatch(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var invalidated []types.BookInvalidReason
+	h.OnBookInvalidated(func(symbol string, reason types.BookInvalidReason) {
+		assert.Equal(t, "BTCUSD", symbol)
+		invalidated = append(invalidated, reason)
+	})
+
+	var updates int
+	h.OnBookUpdate(func(book types.OrderBook) {
+		updates++
+	})
+
+	var resyncs []string
+	h.resyncOrderBook = func(pair string) {
+		resyncs = append(resyncs, pair)
+	}
+
+	h.handleMessage(readFixture(t, "book_snapshot.json"))
+	h.handleMessage([]byte(`[1234,{"a":[["5541.30000","1.00000000","1534614335.345903"]],"c":"3776436635"},"book-25","XBT/USD"]`))
+
+	assert.Equal(t, 0, updates)
+	assert.Equal(t, []types.BookInvalidReason{types.BookInvalidReasonChecksum}, invalidated)
+	assert.Equal(t, []string{"XBT/USD"}, resyncs)
+
+	// the updates are dropped until the new snapshot comes
+	h.handleMessage(readFixture(t, "book_update.json"))
+	assert.Equal(t, 0, updates)
+	assert.Len(t, resyncs, 1)
+}
+
+func TestMessageHandler_OrderBookDepth(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var snapshots []types.OrderBook
+	h.OnBookSnapshot(func(book types.OrderBook) {
+		snapshots = append(snapshots, book)
+	})
+
+	var updates []types.OrderBook
+	h.OnBookUpdate(func(book types.OrderBook) {
+		updates = append(updates, book)
+	})
+
+	// the snapshot is truncated to the subscribed depth
+	h.handleMessage([]byte(`[1234,{"as":[["5541.30000","2.50700000","1534614248.123678"],["5541.80000","0.33000000","1534614098.345543"],["5542.70000","0.64700000","1534614244.654432"]],"bs":[["5541.20000","1.52900000","1534614248.765567"],["5539.90000","0.30000000","1534614241.769870"]]},"book-2","XBT/USD"]`))
+	if assert.Len(t, snapshots, 1) {
+		assert.Len(t, snapshots[0].Asks, 2)
+		assert.Len(t, snapshots[0].Bids, 2)
+	}
+
+	// the new best ask pushes the worst ask out of the depth, the removal of it is emitted with the update
+	expected := newLocalOrderBook(2,
+		[]bookLevel{{"5541.20000", "1.52900000"}, {"5539.90000", "0.30000000"}},
+		[]bookLevel{{"5541.25000", "1.00000000"}, {"5541.30000", "2.50700000"}},
+	)
+	checksum := strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(expected.checksumString()))), 10)
+	h.handleMessage([]byte(`[1234,{"a":[["5541.25000","1.00000000","1534614335.345903"]],"c":"` + checksum + `"},"book-2","XBT/USD"]`))
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, types.PriceVolumeSlice{
+			{Price: number("5541.25").Value(), Volume: number("1").Value()},
+			{Price: number("5541.8").Value(), Volume: 0},
+		}, updates[0].Asks)
+	}
+}
+
+func TestMessageHandler_OHLC(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var klines, closed []types.KLine
+	h.OnKLine(func(kline types.KLine) {
+		klines = append(klines, kline)
+	})
+	h.OnKLineClosed(func(kline types.KLine) {
+		closed = append(closed, kline)
+	})
+
+	h.handleMessage([]byte(`[42,["1542057314.748456","1542057360.000000","3586.70000","3586.70000","3586.60000","3586.60000","3586.68894","0.03373000",2],"ohlc-1","XBT/USD"]`))
+	h.handleMessage([]byte(`[42,["1542057321.748456","1542057360.000000","3586.70000","3586.80000","3586.60000","3586.80000","3586.70000","0.04373000",3],"ohlc-1","XBT/USD"]`))
+	assert.Len(t, klines, 2)
+	assert.Empty(t, closed)
+
+	h.handleMessage([]byte(`[42,["1542057362.748456","1542057420.000000","3586.80000","3586.80000","3586.80000","3586.80000","3586.80000","0.01000000",1],"ohlc-1","XBT/USD"]`))
+	assert.Len(t, klines, 3)
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, "BTCUSD", closed[0].Symbol)
+		assert.Equal(t, types.Interval1m, closed[0].Interval)
+		assert.Equal(t, time.Unix(1542057300, 0), closed[0].StartTime)
+		assert.Equal(t, 3586.8, closed[0].Close)
+		assert.Equal(t, uint64(3), closed[0].NumberOfTrades)
+		assert.True(t, closed[0].Closed)
+	}
+}
+
+func TestMessageHandler_OpenOrders(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var orders []types.Order
+	h.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+
+	h.handleMessage([]byte(`[[{"OGTT3Y-C6I3P-XRI6HX":{"status":"open","cl_ord_id":"b2","opentm":"1616665496.7808","vol":"1.00000000","vol_exec":"0.00000000","oflags":"fcib","descr":{"pair":"XBT/USD","type":"sell","ordertype":"limit","price":"34000.00000","price2":"0.00000"}}}],"openOrders",{"sequence":1}]`))
+	// the updates only contain the changed fields
+	h.handleMessage([]byte(`[[{"OGTT3Y-C6I3P-XRI6HX":{"vol_exec":"0.40000000","lastupdated":"1616665500.1234"}}],"openOrders",{"sequence":2}]`))
+	h.handleMessage([]byte(`[[{"OGTT3Y-C6I3P-XRI6HX":{"status":"closed","vol_exec":"1.00000000"}}],"openOrders",{"sequence":3}]`))
+
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, types.OrderStatusNew, orders[0].Status)
+		assert.Equal(t, "BTCUSD", orders[0].Symbol)
+		assert.Equal(t, "b2", orders[0].ClientOrderID)
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+
+		assert.Equal(t, types.OrderStatusPartiallyFilled, orders[1].Status)
+		assert.Equal(t, "b2", orders[1].ClientOrderID)
+		assert.Equal(t, "34000", orders[1].Price.String())
+		assert.Equal(t, "0.4", orders[1].ExecutedQuantity.String())
+		assert.True(t, orders[1].IsWorking)
+
+		assert.Equal(t, types.OrderStatusFilled, orders[2].Status)
+		assert.Equal(t, "1", orders[2].ExecutedQuantity.String())
+		assert.False(t, orders[2].IsWorking)
+	}
+	assert.Empty(t, h.orders)
+}
+
+func TestMessageHandler_OwnTrades(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var trades []types.Trade
+	h.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	h.handleMessage([]byte(`[[{"TDLH43-DVQXD-2KHVYY":{"cost":"1000000.00000","fee":"1600.00000","margin":"0.00000","ordertxid":"TDLH43-DVQXD-2KHVYY","ordertype":"limit","pair":"XBT/EUR","postxid":"OGTT3Y-C6I3P-XRI6HX","price":"100000.00000","time":"1560516023.070651","type":"sell","vol":"10.00000000"}}],"ownTrades",{"sequence":2948}]`))
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "BTCEUR", trades[0].Symbol)
+		assert.Equal(t, toGlobalTradeID("TDLH43-DVQXD-2KHVYY"), trades[0].ID)
+		assert.Equal(t, types.SideTypeSell, trades[0].Side)
+		assert.Equal(t, "100000", trades[0].Price.String())
+		assert.Equal(t, "10", trades[0].Quantity.String())
+		assert.Equal(t, "1600", trades[0].Fee.String())
+		assert.Equal(t, "EUR", trades[0].FeeCurrency)
+	}
+}
@@ -0,0 +1,199 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// testWebsocketServer forwards the received messages to the messages channel, the test replies through the connections
+type testWebsocketServer struct {
+	*httptest.Server
+
+	messages chan string
+	conns    chan *websocket.Conn
+}
+
+func newTestWebsocketServer() *testWebsocketServer {
+	s := &testWebsocketServer{
+		messages: make(chan string, 16),
+		conns:    make(chan *websocket.Conn, 2),
+	}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.conns <- conn
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			s.messages <- string(msg)
+		}
+	}))
+	return s
+}
+
+func (s *testWebsocketServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.Server.URL, "http")
+}
+
+func (s *testWebsocketServer) nextMessage(t *testing.T) map[string]interface{} {
+	select {
+	case msg := <-s.messages:
+		var m map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(msg), &m))
+		return m
+	case <-time.After(3 * time.Second):
+		t.Fatal("no message is received")
+	}
+	return nil
+}
+
+func TestStream_Subscribe(t *testing.T) {
+	s := NewStream()
+	s.Subscribe(types.BookChannel, "BTCUSD", types.SubscribeOptions{})
+	s.Subscribe(types.KLineChannel, "BTCUSD", types.SubscribeOptions{Interval: "1h"})
+	s.Subscribe(types.MarketTradeChannel, "ETHUSDT", types.SubscribeOptions{})
+
+	assert.Equal(t, []websocketRequest{
+		{Event: subscribeEvent, Pairs: []string{"XBT/USD"}, Subscription: &subscription{Name: bookChannel, Depth: bookDepth}},
+		{Event: subscribeEvent, Pairs: []string{"XBT/USD"}, Subscription: &subscription{Name: ohlcChannel, Interval: 60}},
+		{Event: subscribeEvent, Pairs: []string{"ETH/USDT"}, Subscription: &subscription{Name: tradeChannel}},
+	}, s.subscriptions)
+
+	s.Unsubscribe(types.KLineChannel, "BTCUSD")
+	assert.Equal(t, []websocketRequest{
+		{Event: subscribeEvent, Pairs: []string{"XBT/USD"}, Subscription: &subscription{Name: bookChannel, Depth: bookDepth}},
+		{Event: subscribeEvent, Pairs: []string{"ETH/USDT"}, Subscription: &subscription{Name: tradeChannel}},
+	}, s.subscriptions)
+
+	assert.Panics(t, func() {
+		s.Subscribe(types.KLineChannel, "BTCUSD", types.SubscribeOptions{Interval: "3d"})
+	})
+}
+
+func TestStream_Connect(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	// the private channels can't be subscribed without the token
+	s := newStream(server.URL(), server.URL())
+	err := s.Connect(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "token")
+	assert.NoError(t, s.Close())
+}
+
+func TestStream_Public(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	s := newStream(server.URL(), server.URL())
+	s.SetPublicOnly()
+	s.Subscribe(types.BookChannel, "BTCUSD", types.SubscribeOptions{})
+
+	snapshotC := make(chan types.OrderBook, 1)
+	s.OnBookSnapshot(func(book types.OrderBook) {
+		snapshotC <- book
+	})
+	updateC := make(chan types.OrderBook, 1)
+	s.OnBookUpdate(func(book types.OrderBook) {
+		updateC <- book
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.Connect(ctx))
+	defer s.Close()
+
+	assert.Equal(t, map[string]interface{}{
+		"event":        "subscribe",
+		"pair":         []interface{}{"XBT/USD"},
+		"subscription": map[string]interface{}{"name": "book", "depth": float64(25)},
+	}, server.nextMessage(t))
+
+	conn := <-server.conns
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"channelID":1234,"channelName":"book-25","event":"subscriptionStatus","pair":"XBT/USD","status":"subscribed","subscription":{"depth":25,"name":"book"}}`)))
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, readFixture(t, "book_snapshot.json")))
+
+	select {
+	case book := <-snapshotC:
+		assert.Equal(t, "BTCUSD", book.Symbol)
+		assert.Equal(t, "5541.2", book.Bids[0].Price.String())
+	case <-time.After(3 * time.Second):
+		t.Fatal("the snapshot is not emitted")
+	}
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, readFixture(t, "book_update.json")))
+
+	select {
+	case book := <-updateC:
+		assert.Equal(t, "BTCUSD", book.Symbol)
+		assert.Len(t, book.Asks, 2)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the update is not emitted")
+	}
+
+	// the book is subscribed again for a new snapshot if the checksum doesn't match
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`[1234,{"b":[["5541.20000","2.00000000","1534614336.345903"]],"c":"1"},"book-25","XBT/USD"]`)))
+	assert.Equal(t, "unsubscribe", server.nextMessage(t)["event"])
+	assert.Equal(t, "subscribe", server.nextMessage(t)["event"])
+}
+
+func TestStream_Private(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	s := newStream(server.URL(), server.URL())
+	s.tokenProvider = func(ctx context.Context) (string, error) {
+		return "token", nil
+	}
+
+	orderC := make(chan types.Order, 1)
+	s.OnOrderUpdate(func(order types.Order) {
+		orderC <- order
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.Connect(ctx))
+	defer s.Close()
+
+	// the first connection is the public one which has no subscription
+	<-server.conns
+	conn := <-server.conns
+
+	assert.Equal(t, map[string]interface{}{
+		"event":        "subscribe",
+		"subscription": map[string]interface{}{"name": "openOrders", "token": "token"},
+	}, server.nextMessage(t))
+	assert.Equal(t, map[string]interface{}{
+		"event":        "subscribe",
+		"subscription": map[string]interface{}{"name": "ownTrades", "token": "token", "snapshot": false},
+	}, server.nextMessage(t))
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`[[{"OGTT3Y-C6I3P-XRI6HX":{"status":"open","opentm":"1616665496.7808","vol":"1.00000000","vol_exec":"0.00000000","descr":{"pair":"XBT/USD","type":"buy","ordertype":"limit","price":"34000.00000"}}}],"openOrders",{"sequence":1}]`)))
+
+	select {
+	case order := <-orderC:
+		assert.Equal(t, txIDHash("OGTT3Y-C6I3P-XRI6HX"), order.OrderID)
+		assert.Equal(t, "BTCUSD", order.Symbol)
+		assert.Equal(t, types.OrderStatusNew, order.Status)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the order update is not emitted")
+	}
+}
@@ -0,0 +1,313 @@
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+const subscribeEvent = "subscribe"
+const unsubscribeEvent = "unsubscribe"
+const pingEvent = "ping"
+const pongEvent = "pong"
+const heartbeatEvent = "heartbeat"
+const systemStatusEvent = "systemStatus"
+const subscriptionStatusEvent = "subscriptionStatus"
+
+const subscribedStatus = "subscribed"
+const unsubscribedStatus = "unsubscribed"
+const errorStatus = "error"
+
+const bookChannel = "book"
+const tradeChannel = "trade"
+const ohlcChannel = "ohlc"
+const spreadChannel = "spread"
+const openOrdersChannel = "openOrders"
+const ownTradesChannel = "ownTrades"
+
+// bookDepth is the depth of the book subscription, the levels beyond the depth are dropped from the local book
+const bookDepth = 25
+
+// checksumDepth is the number of the levels of each side in the checksum
+const checksumDepth = 10
+
+var errUnmatchedChecksum = fmt.Errorf("unmatched checksum")
+
+/*
+{"event": "subscribe", "pair": ["XBT/USD"], "subscription": {"name": "book", "depth": 25}}
+{"event": "subscribe", "subscription": {"name": "openOrders", "token": "<token>"}}
+*/
+type websocketRequest struct {
+	Event        string        `json:"event"`
+	Pairs        []string      `json:"pair,omitempty"`
+	Subscription *subscription `json:"subscription,omitempty"`
+}
+
+type subscription struct {
+	Name     string `json:"name"`
+	Depth    int    `json:"depth,omitempty"`
+	Interval int    `json:"interval,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	// Snapshot is only used by the ownTrades channel, the recent trades are sent after subscribing if it's not false
+	Snapshot *bool `json:"snapshot,omitempty"`
+}
+
+/*
+{
+  "event": "subscriptionStatus",
+  "status": "subscribed",
+  "pair": "XBT/USD",
+  "channelName": "book-25",
+  "subscription": {"name": "book", "depth": 25}
+}
+{"event": "subscriptionStatus", "status": "error", "errorMessage": "Subscription depth not supported"}
+*/
+type websocketEvent struct {
+	Event        string       `json:"event"`
+	Status       string       `json:"status"`
+	Pair         string       `json:"pair"`
+	ChannelName  string       `json:"channelName"`
+	ErrorMessage string       `json:"errorMessage"`
+	Subscription subscription `json:"subscription"`
+}
+
+// channelOf returns the channel and the parameter of the channel name, e.g., book-25 -> (book, 25)
+func channelOf(channelName string) (string, int) {
+	i := strings.IndexByte(channelName, '-')
+	if i < 0 {
+		return channelName, 0
+	}
+
+	param, err := strconv.Atoi(channelName[i+1:])
+	if err != nil {
+		return channelName[:i], 0
+	}
+	return channelName[:i], param
+}
+
+/*
+The snapshot: {"as": [["5541.30000", "2.50700000", "1534614248.123678"]], "bs": [...]}
+The update: {"a": [["5541.30000", "2.50700000", "1534614248.456738"]], "c": "974942666"}
+
+The asks and the bids of an update might be in two payloads, the checksum is in the last one.
+*/
+type bookPayload struct {
+	AsksSnapshot []bookLevel `json:"as"`
+	BidsSnapshot []bookLevel `json:"bs"`
+	Asks         []bookLevel `json:"a"`
+	Bids         []bookLevel `json:"b"`
+	Checksum     string      `json:"c"`
+}
+
+// bookLevel is [price, volume, timestamp, (update type)], the strings are kept to calculate the checksum
+type bookLevel []string
+
+// localOrderBook keeps the levels of a book in the string form, the checksum is calculated from the original strings
+type localOrderBook struct {
+	depth int
+
+	// bids are in the descending order and asks are in the ascending order of the price
+	bids, asks []bookLevel
+}
+
+func newLocalOrderBook(depth int, bids, asks []bookLevel) *localOrderBook {
+	b := &localOrderBook{depth: depth}
+	b.update(bids, asks)
+	return b
+}
+
+// update applies the updates and drops the levels beyond the depth. Kraken doesn't send the deletions of the levels
+// pushed out of the depth, so the zero volume levels of the dropped prices are returned for the downstream books.
+func (b *localOrderBook) update(bids, asks []bookLevel) (removedBids, removedAsks []bookLevel) {
+	b.bids, removedBids = truncateLevels(upsertLevels(b.bids, bids, func(a, b fixedpoint.Value) bool { return a > b }), b.depth)
+	b.asks, removedAsks = truncateLevels(upsertLevels(b.asks, asks, func(a, b fixedpoint.Value) bool { return a < b }), b.depth)
+	return removedBids, removedAsks
+}
+
+// truncateLevels drops the levels beyond the depth and returns the zero volume levels of the dropped prices
+func truncateLevels(levels []bookLevel, depth int) ([]bookLevel, []bookLevel) {
+	if depth <= 0 || len(levels) <= depth {
+		return levels, nil
+	}
+
+	var removed []bookLevel
+	for _, l := range levels[depth:] {
+		removed = append(removed, bookLevel{l[0], "0"})
+	}
+	return levels[:depth], removed
+}
+
+// upsertLevels applies the updates to the sorted levels, the level of the zero volume is removed
+func upsertLevels(levels, updates []bookLevel, before func(a, b fixedpoint.Value) bool) []bookLevel {
+	for _, u := range updates {
+		if len(u) < 2 {
+			continue
+		}
+
+		price := number(u[0]).Value()
+		i := sort.Search(len(levels), func(i int) bool {
+			return !before(number(levels[i][0]).Value(), price)
+		})
+
+		found := i < len(levels) && number(levels[i][0]).Value() == price
+		switch {
+		case number(u[1]).Value() == 0:
+			if found {
+				levels = append(levels[:i], levels[i+1:]...)
+			}
+		case found:
+			levels[i] = u
+		default:
+			levels = append(levels, nil)
+			copy(levels[i+1:], levels[i:])
+			levels[i] = u
+		}
+	}
+	return levels
+}
+
+// checksumString concatenates the prices and the volumes of the best 10 asks and then the best 10 bids, the decimal
+// points and the leading zeros are removed from the numbers.
+func (b *localOrderBook) checksumString() string {
+	var sb strings.Builder
+	for _, levels := range [][]bookLevel{b.asks, b.bids} {
+		for i := 0; i < checksumDepth && i < len(levels); i++ {
+			sb.WriteString(checksumNumber(levels[i][0]))
+			sb.WriteString(checksumNumber(levels[i][1]))
+		}
+	}
+	return sb.String()
+}
+
+func checksumNumber(s string) string {
+	return strings.TrimLeft(strings.Replace(s, ".", "", 1), "0")
+}
+
+func (b *localOrderBook) verifyChecksum(checksum string) error {
+	expected, err := strconv.ParseUint(checksum, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid checksum %s: %w", checksum, err)
+	}
+
+	if actual := crc32.ChecksumIEEE([]byte(b.checksumString())); uint64(actual) != expected {
+		return fmt.Errorf("expected checksum %d, actual checksum %d: %w", expected, actual, errUnmatchedChecksum)
+	}
+	return nil
+}
+
+func toGlobalOrderBook(symbol string, bids, asks []bookLevel) types.OrderBook {
+	return types.OrderBook{
+		Symbol: symbol,
+		Bids:   toPriceVolumeSlice(bids),
+		Asks:   toPriceVolumeSlice(asks),
+	}
+}
+
+func toPriceVolumeSlice(levels []bookLevel) types.PriceVolumeSlice {
+	var pvs types.PriceVolumeSlice
+	for _, l := range levels {
+		if len(l) < 2 {
+			continue
+		}
+		pvs = append(pvs, types.PriceVolume{
+			Price:  number(l[0]).Value(),
+			Volume: number(l[1]).Value(),
+		})
+	}
+	return pvs
+}
+
+// rawString returns the string of the json string or number
+func rawString(raw json.RawMessage) string {
+	return strings.Trim(string(raw), `"`)
+}
+
+// toGlobalMarketTrade converts [price, volume, time, side, order type, misc, (trade id)] to the trade, the hash of
+// the time, the price and the volume is used as the id if there is no trade id.
+func toGlobalMarketTrade(symbol string, fields []json.RawMessage) (types.Trade, error) {
+	if len(fields) < 4 {
+		return types.Trade{}, fmt.Errorf("invalid trade %s", fields)
+	}
+
+	price := number(rawString(fields[0])).Value()
+	quantity := number(rawString(fields[1])).Value()
+	tradeTime, err := parseUnixTime(rawString(fields[2]))
+	if err != nil {
+		return types.Trade{}, err
+	}
+
+	var id int64
+	if len(fields) > 6 {
+		id, _ = strconv.ParseInt(rawString(fields[6]), 10, 64)
+	}
+	if id == 0 {
+		id = toGlobalTradeID(rawString(fields[2]) + rawString(fields[0]) + rawString(fields[1]))
+	}
+
+	side := toGlobalSide(rawString(fields[3]))
+	return types.Trade{
+		ID:            id,
+		Exchange:      types.ExchangeKraken.String(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Symbol:        symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          datatype.Time(tradeTime),
+	}, nil
+}
+
+// toGlobalWebsocketKLine converts [time, etime, open, high, low, close, vwap, volume, count] to the kline, the start
+// time is derived from the end time of the interval.
+func toGlobalWebsocketKLine(symbol string, interval types.Interval, fields []json.RawMessage) (types.KLine, error) {
+	if len(fields) < 9 {
+		return types.KLine{}, fmt.Errorf("invalid ohlc %s", fields)
+	}
+
+	endTime, err := parseUnixTime(rawString(fields[1]))
+	if err != nil {
+		return types.KLine{}, err
+	}
+
+	volume := number(rawString(fields[7])).Float64()
+	count, _ := strconv.ParseUint(rawString(fields[8]), 10, 64)
+	return types.KLine{
+		Exchange:       types.ExchangeKraken.String(),
+		Symbol:         symbol,
+		StartTime:      endTime.Add(-interval.Duration()),
+		EndTime:        endTime.Add(-time.Millisecond),
+		Interval:       interval,
+		Open:           number(rawString(fields[2])).Float64(),
+		High:           number(rawString(fields[3])).Float64(),
+		Low:            number(rawString(fields[4])).Float64(),
+		Close:          number(rawString(fields[5])).Float64(),
+		Volume:         volume,
+		QuoteVolume:    volume * number(rawString(fields[6])).Float64(),
+		NumberOfTrades: count,
+	}, nil
+}
+
+// toGlobalBookTicker converts the spread [bid, ask, timestamp, bid volume, ask volume] to the book ticker
+func toGlobalBookTicker(symbol string, fields []json.RawMessage) (types.BookTicker, error) {
+	if len(fields) < 5 {
+		return types.BookTicker{}, fmt.Errorf("invalid spread %s", fields)
+	}
+
+	return types.BookTicker{
+		Symbol:   symbol,
+		Buy:      number(rawString(fields[0])).Float64(),
+		BuySize:  number(rawString(fields[3])).Float64(),
+		Sell:     number(rawString(fields[1])).Float64(),
+		SellSize: number(rawString(fields[4])).Float64(),
+	}, nil
+}
@@ -0,0 +1,111 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestChannelOf(t *testing.T) {
+	channel, param := channelOf("book-25")
+	assert.Equal(t, bookChannel, channel)
+	assert.Equal(t, 25, param)
+
+	channel, param = channelOf("ohlc-60")
+	assert.Equal(t, ohlcChannel, channel)
+	assert.Equal(t, 60, param)
+
+	channel, param = channelOf("trade")
+	assert.Equal(t, tradeChannel, channel)
+	assert.Equal(t, 0, param)
+}
+
+func TestParseUnixTime(t *testing.T) {
+	tt, err := parseUnixTime("1534614335.345903")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1534614335, 345903000), tt)
+
+	tt, err = parseUnixTime("1688671320")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1688671320, 0), tt)
+}
+
+func TestChecksumNumber(t *testing.T) {
+	assert.Equal(t, "554130000", checksumNumber("5541.30000"))
+	assert.Equal(t, "250700000", checksumNumber("2.50700000"))
+	assert.Equal(t, "500", checksumNumber("0.00000500"))
+	assert.Equal(t, "5005", checksumNumber("0.05005"))
+}
+
+func TestLocalOrderBook_Checksum(t *testing.T) {
+	// the example of https://docs.kraken.com/websockets/#book-checksum
+	book := newLocalOrderBook(10,
+		[]bookLevel{{"0.05000", "0.00000500", "1582905487.684110"}},
+		[]bookLevel{{"0.05005", "0.00000500", "1582905486.989018"}},
+	)
+	assert.Equal(t, "50055005000500", book.checksumString())
+	assert.NoError(t, book.verifyChecksum("3851508195"))
+}
+
+func TestLocalOrderBook_Update(t *testing.T) {
+	book := newLocalOrderBook(3,
+		[]bookLevel{
+			{"5541.20000", "1.52900000", "1534614248.765567"},
+			{"5539.90000", "0.30000000", "1534614241.769870"},
+			{"5539.50000", "5.00000000", "1534613831.243486"},
+		},
+		[]bookLevel{
+			{"5541.30000", "2.50700000", "1534614248.123678"},
+			{"5541.80000", "0.33000000", "1534614098.345543"},
+			{"5542.70000", "0.64700000", "1534614244.654432"},
+		},
+	)
+
+	book.update(
+		[]bookLevel{
+			{"5541.20000", "1.00000000", "1534614335.345903"},
+			// the level beyond the depth is dropped
+			{"5530.00000", "1.00000000", "1534614335.345903"},
+		},
+		[]bookLevel{
+			{"5541.30000", "0.00000000", "1534614335.345903"},
+			{"5542.50000", "0.40100000", "1534614335.345903", "r"},
+		},
+	)
+
+	assert.Equal(t, []bookLevel{
+		{"5541.80000", "0.33000000", "1534614098.345543"},
+		{"5542.50000", "0.40100000", "1534614335.345903", "r"},
+		{"5542.70000", "0.64700000", "1534614244.654432"},
+	}, book.asks)
+	assert.Equal(t, []bookLevel{
+		{"5541.20000", "1.00000000", "1534614335.345903"},
+		{"5539.90000", "0.30000000", "1534614241.769870"},
+		{"5539.50000", "5.00000000", "1534613831.243486"},
+	}, book.bids)
+
+	assert.NoError(t, book.verifyChecksum("3776436635"))
+
+	err := book.verifyChecksum("1")
+	assert.True(t, errors.Is(err, errUnmatchedChecksum))
+}
+
+func TestToGlobalMarketTrade(t *testing.T) {
+	var fields []json.RawMessage
+	assert.NoError(t, json.Unmarshal([]byte(`["5541.20000","0.15850568","1534614057.321597","s","l",""]`), &fields))
+
+	trade, err := toGlobalMarketTrade("BTCUSD", fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSD", trade.Symbol)
+	assert.Equal(t, "5541.2", trade.Price.String())
+	assert.Equal(t, "0.15850568", trade.Quantity.String())
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.False(t, trade.IsBuyer)
+	assert.Equal(t, time.Unix(1534614057, 321597000), trade.Time.Time())
+	assert.True(t, trade.ID > 0)
+}
@@ -2,7 +2,6 @@ package max
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +11,6 @@ import (
 	"github.com/ycdesu/spreaddog/pkg/exchange/max/maxapi"
 	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
-	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
 func toGlobalCurrency(currency string) string {
@@ -182,8 +180,8 @@ func toGlobalOrder(maxOrder max.Order) (*types.Order, error) {
 			Symbol:        toGlobalSymbol(maxOrder.Market),
 			Side:          toGlobalSideType(maxOrder.Side),
 			Type:          toGlobalOrderType(maxOrder.OrderType),
-			Quantity:      util.MustParseFloat(maxOrder.Volume),
-			Price:         util.MustParseFloat(maxOrder.Price),
+			Quantity:      fixedpoint.MustNewFromString(maxOrder.Volume),
+			Price:         fixedpoint.MustNewFromString(maxOrder.Price),
 			TimeInForce:   "GTC", // MAX only supports GTC
 			GroupID:       maxOrder.GroupID,
 		},
@@ -191,7 +189,7 @@ func toGlobalOrder(maxOrder max.Order) (*types.Order, error) {
 		IsWorking:        maxOrder.State == "wait",
 		OrderID:          maxOrder.ID,
 		Status:           toGlobalOrderStatus(maxOrder.State, executedVolume, remainingVolume),
-		ExecutedQuantity: executedVolume.Float64(),
+		ExecutedQuantity: executedVolume,
 		CreationTime:     datatype.Time(maxOrder.CreatedAt),
 		UpdateTime:       datatype.Time(maxOrder.CreatedAt),
 	}, nil
@@ -204,22 +202,22 @@ func toGlobalTrade(t max.Trade) (*types.Trade, error) {
 	// trade time
 	mts := time.Unix(0, t.CreatedAtMilliSeconds*int64(time.Millisecond))
 
-	price, err := strconv.ParseFloat(t.Price, 64)
+	price, err := fixedpoint.NewFromString(t.Price)
 	if err != nil {
 		return nil, err
 	}
 
-	quantity, err := strconv.ParseFloat(t.Volume, 64)
+	quantity, err := fixedpoint.NewFromString(t.Volume)
 	if err != nil {
 		return nil, err
 	}
 
-	quoteQuantity, err := strconv.ParseFloat(t.Funds, 64)
+	quoteQuantity, err := fixedpoint.NewFromString(t.Funds)
 	if err != nil {
 		return nil, err
 	}
 
-	fee, err := strconv.ParseFloat(t.Fee, 64)
+	fee, err := fixedpoint.NewFromString(t.Fee)
 	if err != nil {
 		return nil, err
 	}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/http"
 	"os"
 	"sort"
-	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,7 +15,9 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/exchange"
 	maxapi "github.com/ycdesu/spreaddog/pkg/exchange/max/maxapi"
+	"github.com/ycdesu/spreaddog/pkg/exchange/ratelimit"
 	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 	"github.com/ycdesu/spreaddog/pkg/util"
@@ -26,8 +28,39 @@ var tradeQueryLimiter = rate.NewLimiter(rate.Every(4*time.Second), 1)
 var accountQueryLimiter = rate.NewLimiter(rate.Every(5*time.Second), 1)
 var marketDataLimiter = rate.NewLimiter(rate.Every(5*time.Second), 1)
 
+// rateLimits is the request limit shared by the sessions of the same api key, the endpoint limiters above are applied
+// before it
+var rateLimits = ratelimit.Limits{
+	Weight:   1200,
+	Interval: time.Minute,
+}
+
+const defaultHTTPTimeout = 30 * time.Second
+
 var log = logrus.WithField("exchange", "max")
 
+var capabilities = types.ExchangeCapabilities{
+	Transfer:    true,
+	Rewards:     true,
+	Channels:    []types.Channel{types.BookChannel, types.KLineChannel, types.MarketTradeChannel, types.BookTickerChannel},
+	OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeMarket, types.OrderTypeStopLimit, types.OrderTypeStopMarket},
+	TimeInForce: []string{"GTC"},
+}
+
+func init() {
+	_ = types.Exchange(&Exchange{})
+	_ = types.ExchangeOrderQueryService(&Exchange{})
+	_ = types.ExchangeCancelAllService(&Exchange{})
+
+	exchange.Register(exchange.Registration{
+		Name: types.ExchangeMax,
+		New: func(c exchange.Credentials) types.Exchange {
+			return New(c.Key, c.Secret)
+		},
+		Capabilities: capabilities,
+	})
+}
+
 type Exchange struct {
 	client      *maxapi.RestClient
 	key, secret string
@@ -39,7 +72,10 @@ func New(key, secret string) *Exchange {
 		baseURL = override
 	}
 
-	client := maxapi.NewRestClient(baseURL)
+	client := maxapi.NewRestClientWithHttpClient(baseURL, &http.Client{
+		Timeout:   defaultHTTPTimeout,
+		Transport: ratelimit.NewTransport(types.ExchangeMax.String(), key, rateLimits, nil),
+	})
 	client.Auth(key, secret)
 	return &Exchange{
 		client: client,
@@ -226,6 +262,23 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 	return orders, err
 }
 
+// QueryOrder queries the order by the order id, or by the client order id if the order id is not given
+func (e *Exchange) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	var req = e.client.OrderService.NewOrderGetRequest()
+	if q.OrderID > 0 {
+		req.ID(q.OrderID)
+	} else {
+		req.ClientOrderID(q.ClientOrderID)
+	}
+
+	maxOrder, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalOrder(*maxOrder)
+}
+
 func (e *Exchange) CancelAllOrders(ctx context.Context) ([]types.Order, error) {
 	var req = e.client.OrderService.NewOrderCancelAllRequest()
 	var maxOrders, err = req.Do(ctx)
@@ -317,9 +370,9 @@ func toMaxSubmitOrder(o types.SubmitOrder) (*maxapi.Order, error) {
 	volumeInString := o.QuantityString
 	if len(volumeInString) == 0 {
 		if o.Market.Symbol != "" {
-			volumeInString = o.Market.FormatQuantity(o.Quantity)
+			volumeInString = o.Market.FormatQuantity(o.Quantity.Float64())
 		} else {
-			volumeInString = strconv.FormatFloat(o.Quantity, 'f', 8, 64)
+			volumeInString = o.Quantity.String()
 		}
 	}
 
@@ -338,9 +391,9 @@ func toMaxSubmitOrder(o types.SubmitOrder) (*maxapi.Order, error) {
 		priceInString := o.PriceString
 		if len(priceInString) == 0 {
 			if o.Market.Symbol != "" {
-				priceInString = o.Market.FormatPrice(o.Price)
+				priceInString = o.Market.FormatPrice(o.Price.Float64())
 			} else {
-				priceInString = strconv.FormatFloat(o.Price, 'f', 8, 64)
+				priceInString = o.Price.String()
 			}
 		}
 		maxOrder.Price = priceInString
@@ -356,9 +409,9 @@ func toMaxSubmitOrder(o types.SubmitOrder) (*maxapi.Order, error) {
 		priceInString := o.StopPriceString
 		if len(priceInString) == 0 {
 			if o.Market.Symbol != "" {
-				priceInString = o.Market.FormatPrice(o.StopPrice)
+				priceInString = o.Market.FormatPrice(o.StopPrice.Float64())
 			} else {
-				priceInString = strconv.FormatFloat(o.StopPrice, 'f', 8, 64)
+				priceInString = o.StopPrice.String()
 			}
 		}
 
@@ -457,6 +510,10 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("max")
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return capabilities
+}
+
 func (e *Exchange) getLaunchDate() (time.Time, error) {
 	// MAX launch date June 21th, 2018
 	loc, err := time.LoadLocation("Asia/Taipei")
@@ -368,6 +368,52 @@ func (s *OrderService) Get(orderID uint64) (*Order, error) {
 	return &order, nil
 }
 
+type OrderGetRequestParams struct {
+	*PrivateRequestParams
+
+	ID            uint64 `json:"id,omitempty"`
+	ClientOrderID string `json:"client_oid,omitempty"`
+}
+
+type OrderGetRequest struct {
+	client *RestClient
+
+	params OrderGetRequestParams
+}
+
+func (r *OrderGetRequest) ID(id uint64) *OrderGetRequest {
+	r.params.ID = id
+	return r
+}
+
+func (r *OrderGetRequest) ClientOrderID(id string) *OrderGetRequest {
+	r.params.ClientOrderID = id
+	return r
+}
+
+func (r *OrderGetRequest) Do(ctx context.Context) (*Order, error) {
+	req, err := r.client.newAuthenticatedRequest("GET", "v2/order", &r.params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var order = Order{}
+	if err := response.DecodeJSON(&order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (s *OrderService) NewOrderGetRequest() *OrderGetRequest {
+	return &OrderGetRequest{client: s.client}
+}
+
 type MultiOrderRequestParams struct {
 	*PrivateRequestParams
 
@@ -15,6 +15,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
+	"github.com/ycdesu/spreaddog/pkg/types"
 	"github.com/ycdesu/spreaddog/pkg/util"
 	"github.com/ycdesu/spreaddog/pkg/version"
 )
@@ -293,9 +295,12 @@ func (c *RestClient) sendRequest(req *http.Request) (*util.Response, error) {
 	if response.IsError() {
 		errorResponse, err := toErrorResponse(response)
 		if err != nil {
+			if response.IsRateLimited() {
+				return response, types.NewRateLimitError(response.RetryAfter(), err)
+			}
 			return response, err
 		}
-		return response, errorResponse
+		return response, toGlobalError(errorResponse)
 	}
 
 	return response, nil
@@ -375,6 +380,37 @@ func (r *ErrorResponse) Error() string {
 	)
 }
 
+// toGlobalError maps the error response to the error types by the status code and the error message, the unknown errors
+// are returned as they are
+func toGlobalError(r *ErrorResponse) error {
+	if r.IsRateLimited() {
+		return types.NewRateLimitError(r.RetryAfter(), r)
+	}
+
+	message := strings.ToLower(r.Err.Message)
+	switch {
+	case r.StatusCode == http.StatusUnauthorized, strings.Contains(message, "access key"), strings.Contains(message, "signature"):
+		return types.NewExchangeError(types.ErrAuthentication, r)
+
+	case strings.Contains(message, "insufficient"), strings.Contains(message, "not enough"):
+		return types.NewExchangeError(types.ErrInsufficientBalance, r)
+
+	case strings.Contains(message, "order") && strings.Contains(message, "not found"):
+		return types.NewExchangeError(types.ErrOrderNotFound, r)
+
+	case strings.Contains(message, "precision"), strings.Contains(message, "too small"):
+		return types.NewExchangeError(types.ErrInvalidPrecision, r)
+
+	case strings.Contains(message, "market") && (strings.Contains(message, "invalid") || strings.Contains(message, "not found")):
+		return types.NewExchangeError(types.ErrInvalidSymbol, r)
+
+	case strings.Contains(message, "market") && strings.Contains(message, "closed"):
+		return types.NewExchangeError(types.ErrMarketClosed, r)
+	}
+
+	return r
+}
+
 // toErrorResponse tries to convert/parse the server response to the standard Error interface object
 func toErrorResponse(response *util.Response) (errorResponse *ErrorResponse, err error) {
 	errorResponse = &ErrorResponse{Response: response}
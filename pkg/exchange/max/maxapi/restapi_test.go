@@ -0,0 +1,60 @@
+package max
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+	"github.com/ycdesu/spreaddog/pkg/util"
+)
+
+func Test_toGlobalError(t *testing.T) {
+	newResponse := func(statusCode int, body string) *util.Response {
+		response, err := util.NewResponse(&http.Response{
+			StatusCode: statusCode,
+			Header:     http.Header{"Content-Type": []string{"application/json"}, "Retry-After": []string{"5"}},
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			Request:    &http.Request{Method: "POST", URL: &url.URL{Path: "/api/v2/orders"}},
+		})
+		assert.NoError(t, err)
+		return response
+	}
+
+	tests := []struct {
+		statusCode int
+		body       string
+		kind       error
+	}{
+		{429, `{"error":{"code":2006,"message":"Too many requests"}}`, types.ErrRateLimited},
+		{401, `{"error":{"code":2004,"message":"The access key does not exist."}}`, types.ErrAuthentication},
+		{422, `{"error":{"code":2007,"message":"Insufficient balance"}}`, types.ErrInsufficientBalance},
+		{404, `{"error":{"code":2002,"message":"Order not found"}}`, types.ErrOrderNotFound},
+		{422, `{"error":{"code":2016,"message":"amount too small"}}`, types.ErrInvalidPrecision},
+		{422, `{"error":{"code":2018,"message":"Invalid market: btcusd"}}`, types.ErrInvalidSymbol},
+	}
+
+	for _, test := range tests {
+		errResp, err := toErrorResponse(newResponse(test.statusCode, test.body))
+		assert.NoError(t, err)
+
+		err = toGlobalError(errResp)
+		assert.True(t, errors.Is(err, test.kind), test.body)
+	}
+
+	errResp, err := toErrorResponse(newResponse(429, `{"error":{"code":2006,"message":"Too many requests"}}`))
+	assert.NoError(t, err)
+	retryAfter, ok := types.RetryAfter(toGlobalError(errResp))
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, retryAfter)
+
+	errResp, err = toErrorResponse(newResponse(500, `{"error":{"code":1000,"message":"internal server error"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, error(errResp), toGlobalError(errResp))
+}
@@ -9,7 +9,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+
+	"github.com/ycdesu/spreaddog/pkg/service"
 )
 
 var WebSocketURL = "wss://max-stream.maicoin.com/ws"
@@ -42,12 +43,12 @@ var UnsubscribeAction = "unsubscribe"
 
 //go:generate callbackgen -type WebSocketService
 type WebSocketService struct {
-	baseURL, key, secret string
+	key, secret string
 
-	mu   sync.Mutex
-	conn *websocket.Conn
+	client *service.WebsocketClientBase
 
-	reconnectC chan struct{}
+	// mu protects Subscriptions, they can be changed after connecting
+	mu sync.Mutex
 
 	// Subscriptions is the subscription request payloads that will be used for sending subscription request
 	Subscriptions []Subscription
@@ -73,29 +74,27 @@ type WebSocketService struct {
 }
 
 func NewWebSocketService(wsURL string, key, secret string) *WebSocketService {
-	return &WebSocketService{
-		key:        key,
-		secret:     secret,
-		reconnectC: make(chan struct{}, 1),
-		baseURL:    wsURL,
+	s := &WebSocketService{
+		key:    key,
+		secret: secret,
+		client: service.NewWebsocketClientBase(wsURL, service.DefaultWebsocketClientOptions),
 	}
-}
 
-func (s *WebSocketService) Connect(ctx context.Context) error {
-	s.OnConnect(func(c *websocket.Conn) {
-		if err := s.SendSubscriptionRequest(SubscribeAction); err != nil {
-			s.EmitError(err)
-			logger.WithError(err).Error("failed to subscribe")
-		}
+	s.client.OnConnected(s.EmitConnect)
+	s.client.OnDisconnected(func(conn *websocket.Conn) {
+		s.EmitDisconnect()
 	})
+	s.client.OnError(s.EmitError)
+	s.client.OnMessage(s.handleMessage)
+	return s
+}
 
-	// pre-allocate the websocket client, the websocket client can be used for reconnecting.
-	if err := s.connect(ctx); err != nil {
-		return err
-	}
+func (s *WebSocketService) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	s.updateSubscriptions()
+	s.mu.Unlock()
 
-	go s.reconnector(ctx)
-	return nil
+	return s.client.Connect(ctx)
 }
 
 func (s *WebSocketService) Auth() error {
@@ -107,88 +106,20 @@ func (s *WebSocketService) Auth() error {
 		Signature: signPayload(fmt.Sprintf("%d", nonce), s.secret),
 		ID:        uuid.New().String(),
 	}
-	return s.conn.WriteJSON(auth)
+	return s.client.WriteJSON(auth)
 }
 
-func (s *WebSocketService) connect(ctx context.Context) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *WebSocketService) handleMessage(msg []byte) {
+	s.EmitMessage(msg)
 
-	dialer := websocket.DefaultDialer
-	conn, _, err := dialer.DialContext(ctx, s.baseURL, nil)
+	m, err := ParseMessage(msg)
 	if err != nil {
-		return err
+		s.EmitError(errors.Wrapf(err, "failed to parse message: %s", msg))
+		return
 	}
 
-	s.conn = conn
-	s.EmitConnect(conn)
-
-	go s.read(ctx)
-
-	return nil
-}
-
-func (s *WebSocketService) emitReconnect() {
-	select {
-	case s.reconnectC <- struct{}{}:
-	default:
-	}
-}
-
-func (s *WebSocketService) reconnector(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-
-		case <-s.reconnectC:
-			time.Sleep(3 * time.Second)
-			if err := s.connect(ctx); err != nil {
-				s.emitReconnect()
-			}
-		}
-	}
-}
-
-func (s *WebSocketService) read(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-
-		default:
-			s.mu.Lock()
-			mt, msg, err := s.conn.ReadMessage()
-			s.mu.Unlock()
-
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure) {
-					s.EmitDisconnect()
-					// emit reconnect to start a new connection
-					s.emitReconnect()
-					return
-				}
-
-				log.WithError(err).Error("websocket error")
-				continue
-			}
-
-			if mt != websocket.TextMessage {
-				continue
-			}
-
-			s.EmitMessage(msg)
-
-			m, err := ParseMessage(msg)
-			if err != nil {
-				s.EmitError(errors.Wrapf(err, "failed to parse message: %s", msg))
-				continue
-			}
-
-			if m != nil {
-				s.dispatch(m)
-			}
-		}
+	if m != nil {
+		s.dispatch(m)
 	}
 }
 
@@ -234,12 +165,31 @@ func (s *WebSocketService) dispatch(msg interface{}) {
 }
 
 func (s *WebSocketService) ClearSubscriptions() {
+	s.mu.Lock()
 	s.Subscriptions = nil
+	s.updateSubscriptions()
+	s.mu.Unlock()
+}
+
+// updateSubscriptions batches the subscriptions into one command, the command is sent by the websocket client after
+// every (re)connection. The caller must hold the lock.
+func (s *WebSocketService) updateSubscriptions() {
+	if len(s.Subscriptions) == 0 {
+		s.client.SetSubscriptions()
+		return
+	}
+
+	subscriptions := make([]Subscription, len(s.Subscriptions))
+	copy(subscriptions, s.Subscriptions)
+	s.client.SetSubscriptions(WebsocketCommand{
+		Action:        SubscribeAction,
+		Subscriptions: subscriptions,
+	})
 }
 
 func (s *WebSocketService) Reconnect() {
 	logger.Info("reconnecting...")
-	s.emitReconnect()
+	s.client.Reconnect()
 }
 
 // Subscribe is a helper method for building subscription request from the internal mapping types.
@@ -254,34 +204,86 @@ func (s *WebSocketService) Subscribe(channel, market string, options SubscribeOp
 }
 
 // AddSubscription adds the subscription request to the buffer, these requests will be sent to the server right after connecting to the endpoint.
+// The subscription request is sent immediately if it's connected.
 func (s *WebSocketService) AddSubscription(subscription Subscription) {
+	s.mu.Lock()
 	s.Subscriptions = append(s.Subscriptions, subscription)
+	s.updateSubscriptions()
+	s.mu.Unlock()
+
+	s.sendCommand(SubscribeAction, []Subscription{subscription})
+}
+
+// Unsubscribe removes the subscriptions of the channel and the market, the unsubscription request is sent immediately if it's connected.
+func (s *WebSocketService) Unsubscribe(channel, market string) {
+	var subscriptions, removed []Subscription
+
+	s.mu.Lock()
+	for _, sub := range s.Subscriptions {
+		if sub.Channel == channel && sub.Market == market {
+			removed = append(removed, sub)
+			continue
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	s.Subscriptions = subscriptions
+	s.updateSubscriptions()
+	s.mu.Unlock()
+
+	if len(removed) > 0 {
+		s.sendCommand(UnsubscribeAction, removed)
+	}
 }
 
-func (s *WebSocketService) Resubscribe() {
-	// Calling Resubscribe() by websocket is not enough to refresh orderbook.
-	// We still need to get orderbook snapshot by rest client.
-	// Therefore Reconnect() is used to simplify implementation.
-	logger.Info("resubscribing all subscription...")
-	if err := s.SendSubscriptionRequest(UnsubscribeAction); err != nil {
-		logger.WithError(err).Error("failed to unsubscribe")
+func (s *WebSocketService) sendCommand(action string, subscriptions []Subscription) {
+	err := s.client.WriteJSON(WebsocketCommand{
+		Action:        action,
+		Subscriptions: subscriptions,
+	})
+
+	switch err {
+	case nil, service.ErrWebsocketNotConnected:
+		// the subscriptions will be sent after connecting
+
+	default:
+		s.EmitError(errors.Wrapf(err, "failed to %s %+v", action, subscriptions))
 	}
+}
+
+// Resubscribe unsubscribes and subscribes the channel of the market again, the other channels and markets are not
+// touched. It's used to get a new book snapshot of the market when the local book is inconsistent.
+func (s *WebSocketService) Resubscribe(channel, market string) {
+	var subscriptions []Subscription
 
-	if err := s.SendSubscriptionRequest(SubscribeAction); err != nil {
-		logger.WithError(err).Error("failed to unsubscribe")
+	s.mu.Lock()
+	for _, sub := range s.Subscriptions {
+		if sub.Channel == channel && sub.Market == market {
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(subscriptions) == 0 {
+		return
 	}
+
+	logger.Infof("resubscribing %s %s...", channel, market)
+	s.sendCommand(UnsubscribeAction, subscriptions)
+	s.sendCommand(SubscribeAction, subscriptions)
 }
 
 func (s *WebSocketService) SendSubscriptionRequest(action string) error {
+	s.mu.Lock()
 	request := WebsocketCommand{
 		Action:        action,
 		Subscriptions: s.Subscriptions,
 	}
+	s.mu.Unlock()
 
 	logger.Debugf("sending websocket subscription: %+v", request)
 
-	if err := s.conn.WriteJSON(request); err != nil {
-		return errors.Wrap(err, "Failed to send subscribe event")
+	if err := s.client.WriteJSON(request); err != nil {
+		return errors.Wrapf(err, "failed to send %s request", action)
 	}
 
 	return nil
@@ -289,5 +291,5 @@ func (s *WebSocketService) SendSubscriptionRequest(action string) error {
 
 // Close web socket connection
 func (s *WebSocketService) Close() error {
-	return s.conn.Close()
+	return s.client.Close()
 }
@@ -0,0 +1,73 @@
+package max
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketService_Resubscribe(t *testing.T) {
+	var mu sync.Mutex
+	var commands []WebsocketCommand
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var command WebsocketCommand
+			if err := conn.ReadJSON(&command); err != nil {
+				return
+			}
+
+			mu.Lock()
+			commands = append(commands, command)
+			mu.Unlock()
+		}
+	}))
+	defer server.Close()
+
+	s := NewWebSocketService("ws"+strings.TrimPrefix(server.URL, "http"), "", "")
+	s.Subscribe("book", "btcusdt", SubscribeOptions{Depth: 10})
+	s.Subscribe("book", "ethusdt", SubscribeOptions{Depth: 10})
+	s.Subscribe("trade", "btcusdt", SubscribeOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, s.Connect(ctx))
+	defer s.Close()
+
+	s.Resubscribe("book", "btcusdt")
+
+	getCommands := func() []WebsocketCommand {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]WebsocketCommand(nil), commands...)
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(getCommands()) == 3
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// the first command is the subscription replay after connecting, only the book of btcusdt is resubscribed
+	received := getCommands()
+	if assert.Len(t, received, 3) {
+		assert.Len(t, received[0].Subscriptions, 3)
+
+		book := []Subscription{{Channel: "book", Market: "btcusdt", Depth: 10}}
+		assert.Equal(t, WebsocketCommand{Action: UnsubscribeAction, Subscriptions: book}, received[1])
+		assert.Equal(t, WebsocketCommand{Action: SubscribeAction, Subscriptions: book}, received[2])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -12,7 +13,6 @@ import (
 	max "github.com/ycdesu/spreaddog/pkg/exchange/max/maxapi"
 	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
-	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
 var logger = log.WithField("exchange", "max")
@@ -23,6 +23,23 @@ type Stream struct {
 	websocketService *max.WebSocketService
 
 	publicOnly bool
+
+	// mu protects connected, bookMarkets and bookTickerMarkets, they can be changed after connecting
+	mu sync.Mutex
+
+	// connected is set when Connect is called, the subscriptions are sent to the websocket service directly after it
+	connected bool
+
+	// bookMarkets is the set of the markets subscribed by the book channel
+	bookMarkets map[string]struct{}
+
+	// bookTickerMarkets is the set of the markets subscribed by the book ticker channel. The MAX ticker channel
+	// does not provide the best bid and ask, so the book ticker is derived from the book channel.
+	bookTickerMarkets map[string]struct{}
+
+	// books are the local books loaded from the snapshots, they are used to derive the book ticker and to detect
+	// the crossed books. The book is removed when it's invalidated until the next snapshot comes.
+	books map[string]*types.OrderBook
 }
 
 func NewStream(key, secret string) *Stream {
@@ -34,6 +51,9 @@ func NewStream(key, secret string) *Stream {
 	wss := max.NewWebSocketService(url, key, secret)
 	stream := &Stream{
 		websocketService: wss,
+		bookMarkets:       make(map[string]struct{}),
+		bookTickerMarkets: make(map[string]struct{}),
+		books:             make(map[string]*types.OrderBook),
 	}
 
 	wss.OnConnect(func(conn *websocket.Conn) {
@@ -48,9 +68,17 @@ func NewStream(key, secret string) *Stream {
 	})
 
 	wss.OnDisconnect(stream.EmitDisconnect)
+	wss.OnDisconnect(func() {
+		// the updates might be lost while reconnecting, the books will be loaded from the new snapshots
+		for market, book := range stream.books {
+			delete(stream.books, market)
+			stream.EmitBookInvalidated(book.Symbol, types.BookInvalidReasonReconnect)
+		}
+	})
 
 	wss.OnMessage(func(message []byte) {
 		logger.Debugf("M: %s", message)
+		stream.EmitMessage()
 	})
 
 	wss.OnKLineEvent(func(e max.KLineEvent) {
@@ -97,6 +125,18 @@ func NewStream(key, secret string) *Stream {
 		}
 	})
 
+	wss.OnTradeEvent(func(e max.PublicTradeEvent) {
+		for _, tradeEntry := range e.Trades {
+			trade, err := convertWebSocketMarketTrade(e.Market, tradeEntry)
+			if err != nil {
+				logger.WithError(err).Error("websocket market trade convert error")
+				continue
+			}
+
+			stream.EmitMarketTrade(*trade)
+		}
+	})
+
 	wss.OnBookEvent(func(e max.BookEvent) {
 		newBook, err := e.OrderBook()
 		if err != nil {
@@ -106,6 +146,21 @@ func NewStream(key, secret string) *Stream {
 
 		newBook.Symbol = toGlobalSymbol(e.Market)
 
+		book, ok := stream.updateBook(e.Market, e.Event, newBook)
+		if !ok {
+			return
+		}
+
+		isBookMarket, isBookTickerMarket := stream.isSubscribedBy(e.Market)
+		if isBookTickerMarket {
+			stream.emitBookTicker(book)
+		}
+
+		// skip the book events of the markets that are only subscribed by the book ticker channel
+		if !isBookMarket {
+			return
+		}
+
 		switch e.Event {
 		case "snapshot":
 			stream.EmitBookSnapshot(newBook)
@@ -148,6 +203,7 @@ func NewStream(key, secret string) *Stream {
 
 	wss.OnError(func(err error) {
 		log.WithError(err).Error("websocket error")
+		stream.EmitError(err)
 	})
 
 	return stream
@@ -157,7 +213,32 @@ func (s *Stream) SetPublicOnly() {
 	s.publicOnly = true
 }
 
+// Subscribe adds the subscription, the subscription request is sent immediately if the stream is connected.
 func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	market := toLocalSymbol(symbol)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch channel {
+	case types.BookTickerChannel:
+		s.bookTickerMarkets[market] = struct{}{}
+
+		// the book subscription will be added when connecting
+		if _, ok := s.bookMarkets[market]; s.connected && !ok {
+			s.websocketService.Subscribe(string(types.BookChannel), market, max.SubscribeOptions{Depth: 1})
+		}
+		return
+
+	case types.BookChannel:
+		if _, ok := s.bookTickerMarkets[market]; s.connected && ok {
+			// replace the top level subscription of the book ticker
+			s.websocketService.Unsubscribe(string(types.BookChannel), market)
+		}
+
+		s.bookMarkets[market] = struct{}{}
+	}
+
 	opt := max.SubscribeOptions{}
 
 	if len(options.Depth) > 0 {
@@ -172,10 +253,114 @@ func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.S
 		opt.Resolution = options.Interval
 	}
 
-	s.websocketService.Subscribe(string(channel), toLocalSymbol(symbol), opt)
+	s.websocketService.Subscribe(string(channel), market, opt)
+}
+
+// Unsubscribe removes the subscriptions of the channel and the symbol, the unsubscription request is sent immediately
+// if the stream is connected.
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string) {
+	market := toLocalSymbol(symbol)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch channel {
+	case types.BookTickerChannel:
+		delete(s.bookTickerMarkets, market)
+
+		// the book subscription is still used by the book channel
+		if _, ok := s.bookMarkets[market]; ok {
+			return
+		}
+
+		s.websocketService.Unsubscribe(string(types.BookChannel), market)
+		return
+
+	case types.BookChannel:
+		delete(s.bookMarkets, market)
+
+		// keep the book subscription for the book ticker, the book events are skipped
+		if _, ok := s.bookTickerMarkets[market]; ok {
+			return
+		}
+	}
+
+	s.websocketService.Unsubscribe(string(channel), market)
+}
+
+// isSubscribedBy returns whether the market is subscribed by the book channel and the book ticker channel
+func (s *Stream) isSubscribedBy(market string) (book, bookTicker bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, book = s.bookMarkets[market]
+	_, bookTicker = s.bookTickerMarkets[market]
+	return book, bookTicker
+}
+
+// updateBook updates the local book of the market, it returns false if the book is not loaded or it's invalidated
+func (s *Stream) updateBook(market, event string, newBook types.OrderBook) (*types.OrderBook, bool) {
+	book, ok := s.books[market]
+	switch event {
+	case "snapshot":
+		if !ok {
+			book = &types.OrderBook{Symbol: newBook.Symbol}
+			s.books[market] = book
+		}
+		book.Load(newBook)
+
+	case "update":
+		if !ok {
+			// drop the updates until the next snapshot
+			return nil, false
+		}
+		book.Update(newBook)
+
+	default:
+		return nil, false
+	}
+
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	if hasBid && hasAsk && bid.Price >= ask.Price {
+		logger.Warnf("%s book is crossed, bid %f >= ask %f, resubscribing", book.Symbol, bid.Price.Float64(), ask.Price.Float64())
+		delete(s.books, market)
+		s.EmitBookInvalidated(book.Symbol, types.BookInvalidReasonCrossed)
+		s.websocketService.Resubscribe(string(types.BookChannel), market)
+		return nil, false
+	}
+
+	return book, true
+}
+
+// emitBookTicker emits the best bid and ask of the local book
+func (s *Stream) emitBookTicker(book *types.OrderBook) {
+	bid, hasBid := book.BestBid()
+	ask, hasAsk := book.BestAsk()
+	if !hasBid || !hasAsk {
+		return
+	}
+
+	s.EmitBookTicker(types.BookTicker{
+		Symbol:   book.Symbol,
+		Buy:      bid.Price.Float64(),
+		BuySize:  bid.Volume.Float64(),
+		Sell:     ask.Price.Float64(),
+		SellSize: ask.Volume.Float64(),
+	})
 }
 
 func (s *Stream) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	// subscribe the top level of the book for the book ticker markets that are not subscribed by the book channel
+	for market := range s.bookTickerMarkets {
+		if _, ok := s.bookMarkets[market]; !ok {
+			s.websocketService.Subscribe(string(types.BookChannel), market, max.SubscribeOptions{Depth: 1})
+		}
+	}
+	s.connected = true
+	s.mu.Unlock()
+
 	err := s.websocketService.Connect(ctx)
 	if err != nil {
 		return err
@@ -196,19 +381,19 @@ func convertWebSocketTrade(t max.TradeUpdate) (*types.Trade, error) {
 	// trade time
 	mts := time.Unix(0, t.Timestamp*int64(time.Millisecond))
 
-	price, err := strconv.ParseFloat(t.Price, 64)
+	price, err := fixedpoint.NewFromString(t.Price)
 	if err != nil {
 		return nil, err
 	}
 
-	quantity, err := strconv.ParseFloat(t.Volume, 64)
+	quantity, err := fixedpoint.NewFromString(t.Volume)
 	if err != nil {
 		return nil, err
 	}
 
-	quoteQuantity := price * quantity
+	quoteQuantity := price.Mul(quantity)
 
-	fee, err := strconv.ParseFloat(t.Fee, 64)
+	fee, err := fixedpoint.NewFromString(t.Fee)
 	if err != nil {
 		return nil, err
 	}
@@ -230,6 +415,35 @@ func convertWebSocketTrade(t max.TradeUpdate) (*types.Trade, error) {
 	}, nil
 }
 
+// convertWebSocketMarketTrade converts the public trade, the trend "up" means the taker is the buyer
+func convertWebSocketMarketTrade(market string, t max.TradeEntry) (*types.Trade, error) {
+	price, err := fixedpoint.NewFromString(t.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := fixedpoint.NewFromString(t.Volume)
+	if err != nil {
+		return nil, err
+	}
+
+	var side = types.SideTypeSell
+	if t.Trend == "up" {
+		side = types.SideTypeBuy
+	}
+
+	return &types.Trade{
+		Symbol:        toGlobalSymbol(market),
+		Exchange:      types.ExchangeMax.String(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          datatype.Time(t.Time()),
+	}, nil
+}
+
 func toGlobalOrderUpdate(u max.OrderUpdate) (*types.Order, error) {
 	executedVolume, err := fixedpoint.NewFromString(u.ExecutedVolume)
 	if err != nil {
@@ -247,16 +461,16 @@ func toGlobalOrderUpdate(u max.OrderUpdate) (*types.Order, error) {
 			Symbol:        toGlobalSymbol(u.Market),
 			Side:          toGlobalSideType(u.Side),
 			Type:          toGlobalOrderType(u.OrderType),
-			Quantity:      util.MustParseFloat(u.Volume),
-			Price:         util.MustParseFloat(u.Price),
-			StopPrice:     util.MustParseFloat(u.StopPrice),
+			Quantity:      fixedpoint.MustNewFromString(u.Volume),
+			Price:         fixedpoint.MustNewFromString(u.Price),
+			StopPrice:     fixedpoint.MustNewFromString(u.StopPrice),
 			TimeInForce:   "GTC", // MAX only supports GTC
 			GroupID:       u.GroupID,
 		},
 		Exchange:         types.ExchangeMax.String(),
 		OrderID:          u.ID,
 		Status:           toGlobalOrderStatus(u.State, executedVolume, remainingVolume),
-		ExecutedQuantity: executedVolume.Float64(),
+		ExecutedQuantity: executedVolume,
 		CreationTime:     datatype.Time(time.Unix(0, u.CreatedAtMs*int64(time.Millisecond))),
 	}, nil
 }
@@ -0,0 +1,322 @@
+package okex
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// instrumentIDs maps the global symbol to the okex instrument id, e.g., BTCUSDT -> BTC-USDT, it's filled by QueryMarkets
+var instrumentIDs sync.Map
+
+// quoteCurrencies are used to split the global symbol if the markets are not queried yet, the longer currencies
+// should go first, e.g., USDT before USD.
+var quoteCurrencies = []string{"USDT", "USDC", "USDK", "BTC", "ETH", "OKB", "DAI", "EUR", "USD"}
+
+func toGlobalSymbol(instrumentID string) string {
+	return strings.ReplaceAll(strings.ToUpper(instrumentID), "-", "")
+}
+
+// toLocalSymbol converts the global symbol to the okex instrument id, the instrument id is returned as it is
+func toLocalSymbol(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if strings.Contains(symbol, "-") {
+		return symbol
+	}
+
+	if id, ok := instrumentIDs.Load(symbol); ok {
+		return id.(string)
+	}
+
+	for _, quote := range quoteCurrencies {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return strings.TrimSuffix(symbol, quote) + "-" + quote
+		}
+	}
+
+	return symbol
+}
+
+func toGlobalCurrency(currency string) string {
+	return strings.ToUpper(currency)
+}
+
+var bars = map[types.Interval]string{
+	types.Interval1m:  "1m",
+	types.Interval5m:  "5m",
+	types.Interval15m: "15m",
+	types.Interval30m: "30m",
+	types.Interval1h:  "1H",
+	types.Interval2h:  "2H",
+	types.Interval4h:  "4H",
+	types.Interval6h:  "6H",
+	types.Interval12h: "12H",
+	types.Interval1d:  "1D",
+	types.Interval3d:  "3D",
+}
+
+func toLocalInterval(interval types.Interval) (string, error) {
+	bar, ok := bars[interval]
+	if !ok {
+		return "", fmt.Errorf("unsupported interval %s", interval)
+	}
+	return bar, nil
+}
+
+func toGlobalMarket(i instrument) types.Market {
+	return types.Market{
+		Symbol:          toGlobalSymbol(i.InstrumentID),
+		PricePrecision:  fixedpoint.NumFractionalDigits(i.TickSize.Value()),
+		VolumePrecision: fixedpoint.NumFractionalDigits(i.LotSize.Value()),
+		QuoteCurrency:   toGlobalCurrency(i.QuoteCurrency),
+		BaseCurrency:    toGlobalCurrency(i.BaseCurrency),
+		MinQuantity:     i.MinSize.Float64(),
+		StepSize:        i.LotSize.Float64(),
+		TickSize:        i.TickSize.Float64(),
+	}
+}
+
+func toGlobalTicker(t ticker) types.Ticker {
+	return types.Ticker{
+		Time:   t.Timestamp.Time(),
+		Volume: t.Volume24h.Float64(),
+		Last:   t.Last.Float64(),
+		Open:   t.Open24h.Float64(),
+		High:   t.High24h.Float64(),
+		Low:    t.Low24h.Float64(),
+		Buy:    t.BidPrice.Float64(),
+		Sell:   t.AskPrice.Float64(),
+	}
+}
+
+func toGlobalBookTicker(t ticker) types.BookTicker {
+	return types.BookTicker{
+		Symbol:   toGlobalSymbol(t.InstrumentID),
+		Buy:      t.BidPrice.Float64(),
+		BuySize:  t.BidSize.Float64(),
+		Sell:     t.AskPrice.Float64(),
+		SellSize: t.AskSize.Float64(),
+	}
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, c candle) (types.KLine, error) {
+	if len(c) < 7 {
+		return types.KLine{}, fmt.Errorf("invalid candle %v", c)
+	}
+
+	startTime := millisecond(c[0]).Time()
+	k := types.KLine{
+		Exchange:    types.ExchangeOKEx.String(),
+		Symbol:      symbol,
+		StartTime:   startTime,
+		EndTime:     startTime.Add(interval.Duration() - time.Millisecond),
+		Interval:    interval,
+		Open:        number(c[1]).Float64(),
+		High:        number(c[2]).Float64(),
+		Low:         number(c[3]).Float64(),
+		Close:       number(c[4]).Float64(),
+		Volume:      number(c[5]).Float64(),
+		QuoteVolume: number(c[6]).Float64(),
+	}
+
+	if len(c) > 8 {
+		k.Closed = c[8] == "1"
+	}
+	return k, nil
+}
+
+// toGlobalKLines converts the candles to the klines in the ascending order of the start time
+func toGlobalKLines(symbol string, interval types.Interval, candles []candle) ([]types.KLine, error) {
+	var klines []types.KLine
+	for _, c := range candles {
+		k, err := toGlobalKLine(symbol, interval, c)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, k)
+	}
+
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime)
+	})
+	return klines, nil
+}
+
+func toGlobalBalances(accounts []account) types.BalanceMap {
+	balances := make(types.BalanceMap)
+	for _, a := range accounts {
+		for _, d := range a.Details {
+			currency := toGlobalCurrency(d.Currency)
+			balances[currency] = types.Balance{
+				Currency:  currency,
+				Available: d.AvailableBalance.Value(),
+				Locked:    d.FrozenBalance.Value(),
+			}
+		}
+	}
+	return balances
+}
+
+func toLocalSide(side types.SideType) string {
+	return strings.ToLower(string(side))
+}
+
+func toGlobalSide(side string) types.SideType {
+	return types.SideType(strings.ToUpper(side))
+}
+
+// toLocalOrderType converts the order type and the time in force to the okex order type
+func toLocalOrderType(orderType types.OrderType, timeInForce string) (string, error) {
+	switch orderType {
+	case types.OrderTypeLimit:
+		switch strings.ToUpper(timeInForce) {
+		case "", "GTC":
+			return "limit", nil
+		case "IOC":
+			return "ioc", nil
+		case "FOK":
+			return "fok", nil
+		}
+		return "", fmt.Errorf("unsupported time in force %s", timeInForce)
+
+	case types.OrderTypeLimitMaker:
+		return "post_only", nil
+
+	case types.OrderTypeMarket:
+		return "market", nil
+	}
+
+	return "", fmt.Errorf("unsupported order type %s", orderType)
+}
+
+// toGlobalOrderType returns the order type and the time in force
+func toGlobalOrderType(orderType string) (types.OrderType, string, error) {
+	switch orderType {
+	case "limit":
+		return types.OrderTypeLimit, "GTC", nil
+	case "ioc":
+		return types.OrderTypeLimit, "IOC", nil
+	case "fok":
+		return types.OrderTypeLimit, "FOK", nil
+	case "post_only":
+		return types.OrderTypeLimitMaker, "GTC", nil
+	case "market":
+		return types.OrderTypeMarket, "", nil
+	}
+
+	return "", "", fmt.Errorf("unsupported order type %s", orderType)
+}
+
+func toGlobalOrderStatus(state string) (types.OrderStatus, bool, error) {
+	switch state {
+	case "live":
+		return types.OrderStatusNew, true, nil
+	case "partially_filled":
+		return types.OrderStatusPartiallyFilled, true, nil
+	case "filled":
+		return types.OrderStatusFilled, false, nil
+	case "canceled":
+		return types.OrderStatusCanceled, false, nil
+	}
+
+	return "", false, fmt.Errorf("unsupported order state %s", state)
+}
+
+func toGlobalOrder(o order) (types.Order, error) {
+	orderType, timeInForce, err := toGlobalOrderType(o.OrderType)
+	if err != nil {
+		return types.Order{}, err
+	}
+
+	status, isWorking, err := toGlobalOrderStatus(o.State)
+	if err != nil {
+		return types.Order{}, err
+	}
+
+	orderID, err := strconv.ParseUint(o.OrderID, 10, 64)
+	if err != nil {
+		return types.Order{}, fmt.Errorf("invalid order id %s: %w", o.OrderID, err)
+	}
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        toGlobalSymbol(o.InstrumentID),
+			Side:          toGlobalSide(o.Side),
+			Type:          orderType,
+			Quantity:      o.Size.Value(),
+			Price:         o.Price.Value(),
+			TimeInForce:   timeInForce,
+		},
+		Exchange:         types.ExchangeOKEx.String(),
+		OrderID:          orderID,
+		Status:           status,
+		ExecutedQuantity: o.AccumulatedSize.Value(),
+		IsWorking:        isWorking,
+		CreationTime:     datatype.Time(o.CreationTime.Time()),
+		UpdateTime:       datatype.Time(o.UpdateTime.Time()),
+	}, nil
+}
+
+func toGlobalTrade(f fill) (types.Trade, error) {
+	tradeID, err := strconv.ParseInt(f.TradeID, 10, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("invalid trade id %s: %w", f.TradeID, err)
+	}
+
+	orderID, err := strconv.ParseUint(f.OrderID, 10, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("invalid order id %s: %w", f.OrderID, err)
+	}
+
+	side := toGlobalSide(f.Side)
+	price := f.FillPrice.Value()
+	quantity := f.FillSize.Value()
+	return types.Trade{
+		ID:            tradeID,
+		OrderID:       orderID,
+		Exchange:      types.ExchangeOKEx.String(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Symbol:        toGlobalSymbol(f.InstrumentID),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       f.ExecutionType == "M",
+		Time:          datatype.Time(f.Timestamp.Time()),
+		// the fee is negative when it's charged, and positive when it's a rebate
+		Fee:         -f.Fee.Value(),
+		FeeCurrency: toGlobalCurrency(f.FeeCurrency),
+	}, nil
+}
+
+// toGlobalTradeFromOrder converts the last fill of the order update to the trade, it returns false if the update is
+// not caused by a fill.
+func toGlobalTradeFromOrder(o order) (types.Trade, bool, error) {
+	if len(o.TradeID) == 0 || o.FillSize.Value() == 0 {
+		return types.Trade{}, false, nil
+	}
+
+	t, err := toGlobalTrade(fill{
+		InstrumentType: o.InstrumentType,
+		InstrumentID:   o.InstrumentID,
+		TradeID:        o.TradeID,
+		OrderID:        o.OrderID,
+		ClientOrderID:  o.ClientOrderID,
+		Side:           o.Side,
+		FillPrice:      o.FillPrice,
+		FillSize:       o.FillSize,
+		Fee:            o.FillFee,
+		FeeCurrency:    o.FillFeeCcy,
+		ExecutionType:  o.ExecutionType,
+		Timestamp:      o.FillTime,
+	})
+	return t, err == nil, err
+}
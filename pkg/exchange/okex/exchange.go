@@ -0,0 +1,395 @@
+package okex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/exchange"
+	"github.com/ycdesu/spreaddog/pkg/exchange/ratelimit"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+const (
+	restEndpoint       = "https://www.okex.com"
+	defaultHTTPTimeout = 15 * time.Second
+
+	// the max limit of the candles, the fills and the orders of a page
+	candlesLimit = 100
+	fillsLimit   = 100
+	ordersLimit  = 100
+)
+
+var logger = logrus.WithField("exchange", "okex")
+
+// rateLimits is the request limit of an account, most of the endpoints allow 20 requests per 2 seconds
+var rateLimits = ratelimit.Limits{
+	Weight:   20,
+	Interval: 2 * time.Second,
+}
+
+var capabilities = types.ExchangeCapabilities{
+	Channels:    []types.Channel{types.BookChannel, types.KLineChannel, types.MarketTradeChannel, types.BookTickerChannel},
+	OrderTypes:  []types.OrderType{types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeMarket},
+	TimeInForce: []string{"GTC", "IOC", "FOK"},
+}
+
+func init() {
+	exchange.Register(exchange.Registration{
+		Name:    types.ExchangeOKEx,
+		Aliases: []string{"okx"},
+		New: func(c exchange.Credentials) types.Exchange {
+			return NewExchange(c.Key, c.Secret, c.Passphrase)
+		},
+		UsePassphrase: true,
+		Capabilities:  capabilities,
+	})
+}
+
+type Exchange struct {
+	key, secret, passphrase string
+	restEndpoint            *url.URL
+	client                  *http.Client
+}
+
+func NewExchange(key, secret, passphrase string) *Exchange {
+	u, err := url.Parse(restEndpoint)
+	if err != nil {
+		panic(err)
+	}
+	return &Exchange{
+		restEndpoint: u,
+		key:          key,
+		secret:       secret,
+		passphrase:   passphrase,
+		client: &http.Client{
+			Timeout:   defaultHTTPTimeout,
+			Transport: ratelimit.NewTransport(types.ExchangeOKEx.String(), key, rateLimits, nil),
+		},
+	}
+}
+
+func (e *Exchange) newRest() *restRequest {
+	return newRestRequest(e.client, e.restEndpoint).Auth(e.key, e.secret, e.passphrase)
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeOKEx
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return "OKB"
+}
+
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return capabilities
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e.key, e.secret, e.passphrase)
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	instruments, err := e.newRest().Instruments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, i := range instruments {
+		market := toGlobalMarket(i)
+		instrumentIDs.Store(market.Symbol, i.InstrumentID)
+		markets[market.Symbol] = market
+	}
+	return markets, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	tickers, err := e.newRest().Ticker(ctx, toLocalSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("ticker of %s is not found", symbol)
+	}
+
+	ticker := toGlobalTicker(tickers[0])
+	return &ticker, nil
+}
+
+// QueryTickers returns the tickers of the given symbols, or all the spot markets if no symbol is given
+func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	resp, err := e.newRest().Tickers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{}, len(symbol))
+	for _, s := range symbol {
+		wanted[toGlobalSymbol(toLocalSymbol(s))] = struct{}{}
+	}
+
+	tickers := make(map[string]types.Ticker)
+	for _, t := range resp {
+		s := toGlobalSymbol(t.InstrumentID)
+		if _, ok := wanted[s]; len(wanted) > 0 && !ok {
+			continue
+		}
+		tickers[s] = toGlobalTicker(t)
+	}
+	return tickers, nil
+}
+
+// QueryKLines returns the klines in the ascending order, okex returns at most 100 klines of a request, the klines
+// closest to the end time are returned if there are more than the limit.
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	bar, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := options.Limit
+	if limit <= 0 || limit > candlesLimit {
+		limit = candlesLimit
+	}
+
+	var after, before time.Time
+	if options.EndTime != nil {
+		// after is exclusive
+		after = options.EndTime.Add(time.Millisecond)
+	}
+	if options.StartTime != nil {
+		// before is exclusive
+		before = options.StartTime.Add(-time.Millisecond)
+	}
+
+	candles, err := e.newRest().Candles(ctx, toLocalSymbol(symbol), bar, after, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalKLines(toGlobalSymbol(toLocalSymbol(symbol)), interval, candles)
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	rest := e.newRest()
+	fees, err := rest.TradeFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{}
+	if len(fees) > 0 {
+		// the fee rates are negative when they are charged
+		a.MakerCommission = -fees[0].Maker.Value()
+		a.TakerCommission = -fees[0].Taker.Value()
+	}
+
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.UpdateBalances(balances)
+
+	return a, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	accounts, err := e.newRest().Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalances(accounts), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+	for _, so := range orders {
+		orderType, err := toLocalOrderType(so.Type, so.TimeInForce)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		payload := PlaceOrderPayload{
+			InstrumentID:  toLocalSymbol(so.Symbol),
+			Side:          toLocalSide(so.Side),
+			OrderType:     orderType,
+			Size:          so.Quantity.String(),
+			ClientOrderID: so.ClientOrderID,
+		}
+		if so.Type != types.OrderTypeMarket {
+			payload.Price = so.Price.String()
+		}
+
+		result, err := e.newRest().PlaceOrder(ctx, payload)
+		if err != nil {
+			return createdOrders, fmt.Errorf("failed to place order %+v: %w", so, err)
+		}
+
+		orderID, err := strconv.ParseUint(result.OrderID, 10, 64)
+		if err != nil {
+			return createdOrders, fmt.Errorf("invalid order id %s: %w", result.OrderID, err)
+		}
+
+		now := time.Now()
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder:  so,
+			Exchange:     types.ExchangeOKEx.String(),
+			OrderID:      orderID,
+			Status:       types.OrderStatusNew,
+			IsWorking:    true,
+			CreationTime: datatype.Time(now),
+			UpdateTime:   datatype.Time(now),
+		})
+	}
+	return createdOrders, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	var after string
+	for {
+		resp, err := e.newRest().PendingOrders(ctx, toLocalSymbol(symbol), after)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range resp {
+			o, err := toGlobalOrder(r)
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, o)
+		}
+
+		if len(resp) < ordersLimit {
+			return orders, nil
+		}
+		after = resp[len(resp)-1].OrderID
+	}
+}
+
+// QueryClosedOrders returns the filled and canceled orders of the last 7 days in the ascending order of the order id,
+// okex can only paginate by the order id, so the orders are paginated from the newest one and filtered by the time
+// range and the lastOrderID.
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if until == (time.Time{}) {
+		until = time.Now()
+	}
+	if since.After(until) {
+		return nil, fmt.Errorf("invalid query closed orders time range, since: %+v, until: %+v", since, until)
+	}
+
+	var after string
+	for {
+		resp, err := e.newRest().OrdersHistory(ctx, toLocalSymbol(symbol), after)
+		if err != nil {
+			return nil, err
+		}
+
+		done := len(resp) < ordersLimit
+		for _, r := range resp {
+			o, err := toGlobalOrder(r)
+			if err != nil {
+				return nil, err
+			}
+
+			if o.OrderID <= lastOrderID || o.CreationTime.Time().Before(since) {
+				// the rest orders are older
+				done = true
+				break
+			}
+
+			if o.CreationTime.Time().After(until) {
+				continue
+			}
+			orders = append(orders, o)
+		}
+
+		if done {
+			break
+		}
+		after = resp[len(resp)-1].OrderID
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, o := range orders {
+		var orderID string
+		if o.OrderID > 0 {
+			orderID = strconv.FormatUint(o.OrderID, 10)
+		}
+
+		if _, err := e.newRest().CancelOrder(ctx, toLocalSymbol(o.Symbol), orderID, o.ClientOrderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryTrades returns the trades of the last 3 months in the ascending order of the trade id. okex paginates the fills
+// from the newest one by the bill id, so all the trades in the time range are queried before the limit is applied.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	var since, until time.Time
+	if options.StartTime != nil {
+		since = *options.StartTime
+	}
+	if options.EndTime != nil {
+		until = *options.EndTime
+	} else {
+		until = time.Now()
+	}
+
+	if since.After(until) {
+		return nil, fmt.Errorf("invalid query trades time range, since: %+v, until: %+v", since, until)
+	}
+
+	var trades []types.Trade
+	var after string
+	for {
+		resp, err := e.newRest().FillsHistory(ctx, toLocalSymbol(symbol), after, since, until, fillsLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range resp {
+			t, err := toGlobalTrade(r)
+			if err != nil {
+				return nil, err
+			}
+
+			if t.ID <= options.LastTradeID || t.Time.Time().Before(since) || t.Time.Time().After(until) {
+				continue
+			}
+			trades = append(trades, t)
+		}
+
+		if len(resp) < fillsLimit {
+			break
+		}
+		after = resp[len(resp)-1].BillID
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].ID < trades[j].ID
+	})
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+	return trades, nil
+}
+
+var _ types.Exchange = &Exchange{}
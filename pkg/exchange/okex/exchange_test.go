@@ -0,0 +1,374 @@
+package okex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// newTestExchange returns the exchange of the test server, the handler serves the responses by the request path.
+func newTestExchange(t *testing.T, key, secret, passphrase string, handler http.HandlerFunc) (*Exchange, func()) {
+	ts := httptest.NewServer(handler)
+
+	ex := NewExchange(key, secret, passphrase)
+	serverURL, err := url.Parse(ts.URL)
+	assert.NoError(t, err)
+	ex.restEndpoint = serverURL
+	return ex, ts.Close
+}
+
+func TestExchange_QueryMarkets(t *testing.T) {
+	resp := `
+{
+  "code": "0",
+  "msg": "",
+  "data": [
+    {
+      "instType": "SPOT",
+      "instId": "BTC-USDT",
+      "baseCcy": "BTC",
+      "quoteCcy": "USDT",
+      "tickSz": "0.1",
+      "lotSz": "0.00000001",
+      "minSz": "0.00001",
+      "state": "live"
+    },
+    {
+      "instType": "SPOT",
+      "instId": "OKB-USDK",
+      "baseCcy": "OKB",
+      "quoteCcy": "USDK",
+      "tickSz": "0.001",
+      "lotSz": "0.0001",
+      "minSz": "0.1",
+      "state": "live"
+    }
+  ]
+}
+`
+	ex, closeServer := newTestExchange(t, "", "", "", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v5/public/instruments", r.URL.Path)
+		assert.Equal(t, "SPOT", r.URL.Query().Get("instType"))
+		// the public endpoints are not signed without the key
+		assert.Empty(t, r.Header.Get("OK-ACCESS-SIGN"))
+		fmt.Fprintln(w, resp)
+	})
+	defer closeServer()
+
+	markets, err := ex.QueryMarkets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, types.MarketMap{
+		"BTCUSDT": {
+			Symbol:          "BTCUSDT",
+			PricePrecision:  1,
+			VolumePrecision: 8,
+			QuoteCurrency:   "USDT",
+			BaseCurrency:    "BTC",
+			MinQuantity:     0.00001,
+			StepSize:        0.00000001,
+			TickSize:        0.1,
+		},
+		"OKBUSDK": {
+			Symbol:          "OKBUSDK",
+			PricePrecision:  3,
+			VolumePrecision: 4,
+			QuoteCurrency:   "USDK",
+			BaseCurrency:    "OKB",
+			MinQuantity:     0.1,
+			StepSize:        0.0001,
+			TickSize:        0.001,
+		},
+	}, markets)
+
+	assert.Equal(t, "OKB-USDK", toLocalSymbol("OKBUSDK"))
+}
+
+func TestExchange_QueryKLines(t *testing.T) {
+	// the candles are in the descending order
+	resp := `
+{
+  "code": "0",
+  "msg": "",
+  "data": [
+    ["1614556860000", "49000", "49100", "48900", "49050", "2.5", "122625"],
+    ["1614556800000", "48800", "49010", "48700", "49000", "1.5", "73350"]
+  ]
+}
+`
+	startTime := time.Unix(1614556800, 0)
+	endTime := time.Unix(1614556860, 0)
+	ex, closeServer := newTestExchange(t, "", "", "", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v5/market/candles", r.URL.Path)
+		assert.Equal(t, url.Values{
+			"instId": {"BTC-USDT"},
+			"bar":    {"1m"},
+			"after":  {"1614556860001"},
+			"before": {"1614556799999"},
+			"limit":  {"100"},
+		}, r.URL.Query())
+		fmt.Fprintln(w, resp)
+	})
+	defer closeServer()
+
+	klines, err := ex.QueryKLines(context.Background(), "BTCUSDT", types.Interval1m, types.KLineQueryOptions{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, klines, 2) {
+		assert.Equal(t, types.KLine{
+			Exchange:    "okex",
+			Symbol:      "BTCUSDT",
+			StartTime:   startTime,
+			EndTime:     startTime.Add(time.Minute - time.Millisecond),
+			Interval:    types.Interval1m,
+			Open:        48800,
+			High:        49010,
+			Low:         48700,
+			Close:       49000,
+			Volume:      1.5,
+			QuoteVolume: 73350,
+		}, klines[0])
+		assert.Equal(t, endTime, klines[1].StartTime)
+	}
+
+	_, err = ex.QueryKLines(context.Background(), "BTCUSDT", types.Interval("2m"), types.KLineQueryOptions{})
+	assert.Error(t, err)
+}
+
+func TestExchange_QueryAccount(t *testing.T) {
+	feeResp := `{"code": "0", "msg": "", "data": [{"instType": "SPOT", "maker": "-0.0008", "taker": "-0.001"}]}`
+	balanceResp := `
+{
+  "code": "0",
+  "msg": "",
+  "data": [
+    {
+      "totalEq": "91884.8502560037982",
+      "uTime": "1614846244194",
+      "details": [
+        {"ccy": "BTC", "availBal": "1.2", "frozenBal": "0.3", "cashBal": "1.5", "eq": "1.5", "uTime": "1614846244194"},
+        {"ccy": "USDT", "availBal": "100", "frozenBal": "", "cashBal": "100", "eq": "100", "uTime": "1614846244194"}
+      ]
+    }
+  ]
+}
+`
+	ex, closeServer := newTestExchange(t, "key", "secret", "passphrase", func(w http.ResponseWriter, r *http.Request) {
+		ts := r.Header.Get("OK-ACCESS-TIMESTAMP")
+		_, err := time.Parse("2006-01-02T15:04:05.000Z", ts)
+		assert.NoError(t, err)
+		assert.Equal(t, "key", r.Header.Get("OK-ACCESS-KEY"))
+		assert.Equal(t, "passphrase", r.Header.Get("OK-ACCESS-PASSPHRASE"))
+		assert.Equal(t, sign("secret", ts+r.Method+r.URL.RequestURI()), r.Header.Get("OK-ACCESS-SIGN"))
+
+		switch r.URL.Path {
+		case "/api/v5/account/trade-fee":
+			fmt.Fprintln(w, feeResp)
+		case "/api/v5/account/balance":
+			fmt.Fprintln(w, balanceResp)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	defer closeServer()
+
+	account, err := ex.QueryAccount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.0008"), account.MakerCommission)
+	assert.Equal(t, fixedpoint.MustNewFromString("0.001"), account.TakerCommission)
+
+	balances := account.Balances()
+	assert.Equal(t, types.Balance{
+		Currency:  "BTC",
+		Available: fixedpoint.MustNewFromString("1.2"),
+		Locked:    fixedpoint.MustNewFromString("0.3"),
+	}, balances["BTC"])
+	assert.Equal(t, types.Balance{
+		Currency:  "USDT",
+		Available: fixedpoint.MustNewFromString("100"),
+	}, balances["USDT"])
+}
+
+func TestExchange_SubmitOrders(t *testing.T) {
+	var payloads []map[string]interface{}
+	ex, closeServer := newTestExchange(t, "key", "secret", "passphrase", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v5/trade/order", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, sign("secret", r.Header.Get("OK-ACCESS-TIMESTAMP")+r.Method+r.URL.Path+string(body)), r.Header.Get("OK-ACCESS-SIGN"))
+
+		var p map[string]interface{}
+		assert.NoError(t, json.Unmarshal(body, &p))
+		payloads = append(payloads, p)
+
+		if len(payloads) == 1 {
+			fmt.Fprintln(w, `{"code": "0", "msg": "", "data": [{"clOrdId": "b1", "ordId": "312269865356374016", "sCode": "0", "sMsg": ""}]}`)
+			return
+		}
+		fmt.Fprintln(w, `{"code": "1", "msg": "Operation failed.", "data": [{"clOrdId": "", "ordId": "", "sCode": "51008", "sMsg": "Order placement failed due to insufficient balance"}]}`)
+	})
+	defer closeServer()
+
+	orders, err := ex.SubmitOrders(context.Background(), types.SubmitOrder{
+		ClientOrderID: "b1",
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		Type:          types.OrderTypeLimitMaker,
+		Quantity:      fixedpoint.MustNewFromString("0.5"),
+		Price:         fixedpoint.MustNewFromString("30000.1"),
+	}, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.MustNewFromString("1"),
+	})
+	assert.True(t, errors.Is(err, types.ErrInsufficientBalance))
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, uint64(312269865356374016), orders[0].OrderID)
+		assert.Equal(t, types.OrderStatusNew, orders[0].Status)
+		assert.Equal(t, "b1", orders[0].ClientOrderID)
+	}
+
+	if assert.Len(t, payloads, 2) {
+		assert.Equal(t, map[string]interface{}{
+			"instId":  "BTC-USDT",
+			"tdMode":  "cash",
+			"clOrdId": "b1",
+			"side":    "buy",
+			"ordType": "post_only",
+			"sz":      "0.5",
+			"px":      "30000.1",
+		}, payloads[0])
+
+		_, ok := payloads[1]["px"]
+		assert.False(t, ok, "the market order should not have the price")
+		assert.Equal(t, "market", payloads[1]["ordType"])
+	}
+}
+
+func TestExchange_QueryTrades(t *testing.T) {
+	// the fills are in the descending order of the bill id
+	resp := `
+{
+  "code": "0",
+  "msg": "",
+  "data": [
+    {
+      "instType": "SPOT",
+      "instId": "BTC-USDT",
+      "tradeId": "124",
+      "ordId": "312269865356374016",
+      "clOrdId": "b1",
+      "billId": "1112",
+      "side": "sell",
+      "fillPx": "30000",
+      "fillSz": "0.1",
+      "fee": "-3",
+      "feeCcy": "USDT",
+      "execType": "T",
+      "ts": "1614556860000"
+    },
+    {
+      "instType": "SPOT",
+      "instId": "BTC-USDT",
+      "tradeId": "123",
+      "ordId": "312269865356374015",
+      "clOrdId": "b0",
+      "billId": "1111",
+      "side": "buy",
+      "fillPx": "29000",
+      "fillSz": "0.5",
+      "fee": "-0.0004",
+      "feeCcy": "BTC",
+      "execType": "M",
+      "ts": "1614556800000"
+    }
+  ]
+}
+`
+	ex, closeServer := newTestExchange(t, "key", "secret", "passphrase", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v5/trade/fills-history", r.URL.Path)
+		assert.Equal(t, "BTC-USDT", r.URL.Query().Get("instId"))
+		fmt.Fprintln(w, resp)
+	})
+	defer closeServer()
+
+	since := time.Unix(1614556700, 0)
+	until := time.Unix(1614556900, 0)
+	trades, err := ex.QueryTrades(context.Background(), "BTCUSDT", &types.TradeQueryOptions{
+		StartTime: &since,
+		EndTime:   &until,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, int64(123), trades[0].ID)
+		assert.Equal(t, uint64(312269865356374015), trades[0].OrderID)
+		assert.Equal(t, fixedpoint.MustNewFromString("29000"), trades[0].Price)
+		assert.Equal(t, fixedpoint.MustNewFromString("14500"), trades[0].QuoteQuantity)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.0004"), trades[0].Fee)
+		assert.Equal(t, "BTC", trades[0].FeeCurrency)
+		assert.True(t, trades[0].IsBuyer)
+		assert.True(t, trades[0].IsMaker)
+
+		assert.Equal(t, int64(124), trades[1].ID)
+		assert.Equal(t, types.SideTypeSell, trades[1].Side)
+		assert.False(t, trades[1].IsMaker)
+	}
+
+	trades, err = ex.QueryTrades(context.Background(), "BTCUSDT", &types.TradeQueryOptions{
+		StartTime:   &since,
+		EndTime:     &until,
+		LastTradeID: 123,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+}
+
+func TestExchange_ErrorResponse(t *testing.T) {
+	ex, closeServer := newTestExchange(t, "key", "secret", "passphrase", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"code": "50113", "msg": "Invalid Sign", "data": []}`)
+	})
+	defer closeServer()
+
+	_, err := ex.QueryAccountBalances(context.Background())
+	if assert.Error(t, err) {
+		assert.True(t, errors.Is(err, types.ErrAuthentication))
+
+		var errResp *ErrorResponse
+		if assert.True(t, errors.As(err, &errResp)) {
+			assert.Equal(t, "50113", errResp.Code)
+			assert.Equal(t, "Invalid Sign", errResp.Message)
+		}
+	}
+}
+
+func TestExchange_RateLimitError(t *testing.T) {
+	// the limiter of the key backs off after the 429 response, use another key to not block the other tests
+	ex, closeServer := newTestExchange(t, "rate-limited-key", "secret", "passphrase", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintln(w, `{"code": "50011", "msg": "Requests too frequent."}`)
+	})
+	defer closeServer()
+
+	_, err := ex.QueryOpenOrders(context.Background(), "BTCUSDT")
+	retryAfter, ok := types.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+}
@@ -0,0 +1,304 @@
+package okex
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+	"github.com/ycdesu/spreaddog/pkg/util"
+)
+
+type restRequest struct {
+	*marketRequest
+	*accountRequest
+	*tradeRequest
+
+	key, secret, passphrase string
+
+	c       *http.Client
+	baseURL *url.URL
+	refURL  string
+	// http method, e.g., GET or POST
+	m string
+
+	// query string
+	q map[string]string
+
+	// payload
+	p map[string]interface{}
+}
+
+func newRestRequest(c *http.Client, baseURL *url.URL) *restRequest {
+	r := &restRequest{
+		c:       c,
+		baseURL: baseURL,
+		q:       make(map[string]string),
+		p:       make(map[string]interface{}),
+	}
+
+	r.marketRequest = &marketRequest{restRequest: r}
+	r.accountRequest = &accountRequest{restRequest: r}
+	r.tradeRequest = &tradeRequest{restRequest: r}
+	return r
+}
+
+func (r *restRequest) Auth(key, secret, passphrase string) *restRequest {
+	r.key = key
+	r.secret = secret
+	r.passphrase = passphrase
+	return r
+}
+
+func (r *restRequest) Method(method string) *restRequest {
+	r.m = method
+	return r
+}
+
+func (r *restRequest) ReferenceURL(refURL string) *restRequest {
+	r.refURL = refURL
+	return r
+}
+
+func (r *restRequest) buildURL() (*url.URL, error) {
+	refURL, err := url.Parse(r.refURL)
+	if err != nil {
+		return nil, err
+	}
+	return r.baseURL.ResolveReference(refURL), nil
+}
+
+func (r *restRequest) Payloads(payloads map[string]interface{}) *restRequest {
+	for k, v := range payloads {
+		r.p[k] = v
+	}
+	return r
+}
+
+func (r *restRequest) Query(query map[string]string) *restRequest {
+	for k, v := range query {
+		if len(v) > 0 {
+			r.q[k] = v
+		}
+	}
+	return r
+}
+
+// DoAuthenticatedRequest sends the request and decodes the data of the response into v. The public endpoints can be
+// requested without the api key, the request is signed only if the key is set.
+func (r *restRequest) DoAuthenticatedRequest(ctx context.Context, v interface{}) error {
+	req, err := r.newAuthenticatedRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.sendRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, v)
+}
+
+func (r *restRequest) newAuthenticatedRequest(ctx context.Context) (*http.Request, error) {
+	u, err := r.buildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonPayload []byte
+	if len(r.p) > 0 {
+		var err2 error
+		jsonPayload, err2 = json.Marshal(r.p)
+		if err2 != nil {
+			return nil, fmt.Errorf("can't marshal payload map to json: %w", err2)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.m, u.String(), bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
+
+	// the request path of the signature includes the query string
+	path := u.Path
+	if len(r.q) > 0 {
+		rq := u.Query()
+		for k, v := range r.q {
+			rq.Add(k, v)
+		}
+		req.URL.RawQuery = rq.Encode()
+		path += "?" + req.URL.RawQuery
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(r.key) > 0 {
+		ts := timestamp(time.Now())
+		req.Header.Set("OK-ACCESS-KEY", r.key)
+		req.Header.Set("OK-ACCESS-SIGN", sign(r.secret, ts+r.m+path+string(jsonPayload)))
+		req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", r.passphrase)
+	}
+
+	return req, nil
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// timestamp returns the ISO format timestamp in UTC with milliseconds, e.g., 2020-12-08T09:08:57.715Z
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func (r *restRequest) sendRequest(req *http.Request) (*util.Response, error) {
+	resp, err := r.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// newResponse reads the response body and return a new Response object
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return response, err
+	}
+
+	// Check error, if there is an error, return the ErrorResponse struct type
+	if response.IsError() {
+		errorResponse, err := toErrorResponse(response)
+		if err != nil {
+			if response.IsRateLimited() {
+				return response, types.NewRateLimitError(response.RetryAfter(), err)
+			}
+			return response, err
+		}
+		return response, toGlobalError(errorResponse)
+	}
+
+	return response, nil
+}
+
+// apiResponse is the envelope of all the okex responses, the code "0" means success
+type apiResponse struct {
+	Code    string          `json:"code"`
+	Message string          `json:"msg"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func decodeResponse(response *util.Response, v interface{}) error {
+	var r apiResponse
+	if err := response.DecodeJSON(&r); err != nil {
+		return errors.Wrapf(err, "failed to decode json for response: %d %s", response.StatusCode, string(response.Body))
+	}
+
+	if r.Code != "0" {
+		errorResponse := &ErrorResponse{Response: response, Code: r.Code, Message: r.Message}
+
+		// the order requests fail with code 1 and the reason of the order is in sCode and sMsg of the data
+		var results []orderResult
+		if err := json.Unmarshal(r.Data, &results); err == nil {
+			for _, result := range results {
+				if len(result.Code) > 0 && result.Code != "0" {
+					errorResponse.Code = result.Code
+					errorResponse.Message = result.Message
+					break
+				}
+			}
+		}
+
+		return toGlobalError(errorResponse)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response data %s: %w", string(r.Data), err)
+	}
+	return nil
+}
+
+type ErrorResponse struct {
+	*util.Response
+
+	Code    string `json:"code"`
+	Message string `json:"msg"`
+}
+
+func (r *ErrorResponse) Error() string {
+	return fmt.Sprintf("%s %s %d, code: %s, msg: %s",
+		r.Response.Request.Method,
+		r.Response.Request.URL.String(),
+		r.Response.StatusCode,
+		r.Code,
+		r.Message,
+	)
+}
+
+// toGlobalError maps the error response to the error types by the status code and the error code
+func toGlobalError(r *ErrorResponse) error {
+	if r.IsRateLimited() {
+		return types.NewRateLimitError(r.RetryAfter(), r)
+	}
+
+	return toGlobalErrorCode(r.Code, r.Message, r)
+}
+
+// toGlobalErrorCode maps the error code of okex to the error types, err is returned as it is if the code is unknown.
+// see https://www.okex.com/docs-v5/en/#error-code
+func toGlobalErrorCode(code, message string, err error) error {
+	switch code {
+	case "50011":
+		return types.NewRateLimitError(0, err)
+
+	case "50100", "50101", "50102", "50103", "50104", "50105", "50106", "50107", "50108", "50109", "50110", "50111",
+		"50112", "50113", "50114":
+		return types.NewExchangeError(types.ErrAuthentication, err)
+
+	case "51001":
+		return types.NewExchangeError(types.ErrInvalidSymbol, err)
+
+	case "51008":
+		return types.NewExchangeError(types.ErrInsufficientBalance, err)
+
+	case "51400", "51603":
+		return types.NewExchangeError(types.ErrOrderNotFound, err)
+
+	case "51020", "51121":
+		return types.NewExchangeError(types.ErrInvalidPrecision, err)
+	}
+
+	if strings.Contains(strings.ToLower(message), "suspended") {
+		return types.NewExchangeError(types.ErrMarketClosed, err)
+	}
+
+	return err
+}
+
+func toErrorResponse(response *util.Response) (*ErrorResponse, error) {
+	errorResponse := &ErrorResponse{Response: response}
+
+	if response.IsJSON() {
+		var err = response.DecodeJSON(errorResponse)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode json for response: %d %s", response.StatusCode, string(response.Body))
+		}
+		return errorResponse, nil
+	}
+
+	return errorResponse, fmt.Errorf("unexpected response content type %s", response.Header.Get("content-type"))
+}
@@ -0,0 +1,28 @@
+package okex
+
+import (
+	"context"
+)
+
+type accountRequest struct {
+	*restRequest
+}
+
+func (r *accountRequest) Balance(ctx context.Context) ([]account, error) {
+	var accounts []account
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/account/balance").
+		DoAuthenticatedRequest(ctx, &accounts)
+	return accounts, err
+}
+
+func (r *accountRequest) TradeFee(ctx context.Context) ([]tradeFee, error) {
+	var fees []tradeFee
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/account/trade-fee").
+		Query(map[string]string{"instType": instrumentTypeSpot}).
+		DoAuthenticatedRequest(ctx, &fees)
+	return fees, err
+}
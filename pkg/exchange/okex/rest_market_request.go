@@ -0,0 +1,77 @@
+package okex
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const instrumentTypeSpot = "SPOT"
+
+type marketRequest struct {
+	*restRequest
+}
+
+func (r *marketRequest) Instruments(ctx context.Context) ([]instrument, error) {
+	var instruments []instrument
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/public/instruments").
+		Query(map[string]string{"instType": instrumentTypeSpot}).
+		DoAuthenticatedRequest(ctx, &instruments)
+	return instruments, err
+}
+
+func (r *marketRequest) Tickers(ctx context.Context) ([]ticker, error) {
+	var tickers []ticker
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/market/tickers").
+		Query(map[string]string{"instType": instrumentTypeSpot}).
+		DoAuthenticatedRequest(ctx, &tickers)
+	return tickers, err
+}
+
+func (r *marketRequest) Ticker(ctx context.Context, instrumentID string) ([]ticker, error) {
+	var tickers []ticker
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/market/ticker").
+		Query(map[string]string{"instId": instrumentID}).
+		DoAuthenticatedRequest(ctx, &tickers)
+	return tickers, err
+}
+
+/*
+Candles returns the candles in the descending order, after returns the candles earlier than the time and before
+returns the candles newer than the time, the max limit is 100.
+doc: https://www.okex.com/docs-v5/en/#rest-api-market-data-get-candlesticks
+*/
+func (r *marketRequest) Candles(ctx context.Context, instrumentID string, bar string, after, before time.Time, limit int) ([]candle, error) {
+	q := map[string]string{
+		"instId": instrumentID,
+		"bar":    bar,
+	}
+
+	if limit > 0 {
+		q["limit"] = strconv.Itoa(limit)
+	}
+	if after != (time.Time{}) {
+		q["after"] = strconv.FormatInt(toMillisecond(after), 10)
+	}
+	if before != (time.Time{}) {
+		q["before"] = strconv.FormatInt(toMillisecond(before), 10)
+	}
+
+	var candles []candle
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/market/candles").
+		Query(q).
+		DoAuthenticatedRequest(ctx, &candles)
+	return candles, err
+}
+
+func toMillisecond(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,243 @@
+package okex
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+// number is the numeric string of okex, the empty string means zero
+type number string
+
+func (n number) Value() fixedpoint.Value {
+	if n == "" {
+		return 0
+	}
+
+	v, err := fixedpoint.NewFromString(string(n))
+	if err != nil {
+		logger.WithError(err).Warnf("invalid number %q", string(n))
+		return 0
+	}
+	return v
+}
+
+func (n number) Float64() float64 {
+	if n == "" {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		logger.WithError(err).Warnf("invalid number %q", string(n))
+		return 0
+	}
+	return f
+}
+
+// millisecond is the unix timestamp string in milliseconds
+type millisecond string
+
+func (m millisecond) Time() time.Time {
+	ms, err := strconv.ParseInt(string(m), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+/*
+{
+  "instType": "SPOT",
+  "instId": "BTC-USDT",
+  "baseCcy": "BTC",
+  "quoteCcy": "USDT",
+  "tickSz": "0.1",
+  "lotSz": "0.00000001",
+  "minSz": "0.00001",
+  "state": "live"
+}
+*/
+type instrument struct {
+	InstrumentType string `json:"instType"`
+	InstrumentID   string `json:"instId"`
+	BaseCurrency   string `json:"baseCcy"`
+	QuoteCurrency  string `json:"quoteCcy"`
+	TickSize       number `json:"tickSz"`
+	LotSize        number `json:"lotSz"`
+	MinSize        number `json:"minSz"`
+	State          string `json:"state"`
+}
+
+/*
+{
+  "instType": "SPOT",
+  "instId": "BTC-USDT",
+  "last": "9999.99",
+  "lastSz": "0.1",
+  "askPx": "9999.99",
+  "askSz": "11",
+  "bidPx": "8888.88",
+  "bidSz": "5",
+  "open24h": "9000",
+  "high24h": "10000",
+  "low24h": "8888.88",
+  "volCcy24h": "2222",
+  "vol24h": "2222",
+  "ts": "1597026383085"
+}
+*/
+type ticker struct {
+	InstrumentType string      `json:"instType"`
+	InstrumentID   string      `json:"instId"`
+	Last           number      `json:"last"`
+	LastSize       number      `json:"lastSz"`
+	AskPrice       number      `json:"askPx"`
+	AskSize        number      `json:"askSz"`
+	BidPrice       number      `json:"bidPx"`
+	BidSize        number      `json:"bidSz"`
+	Open24h        number      `json:"open24h"`
+	High24h        number      `json:"high24h"`
+	Low24h         number      `json:"low24h"`
+	QuoteVolume24h number      `json:"volCcy24h"`
+	Volume24h      number      `json:"vol24h"`
+	Timestamp      millisecond `json:"ts"`
+}
+
+// candle is [ts, open, high, low, close, volume, quote volume, (confirm)], the confirm field is only in the
+// websocket candles, "1" means the candle is closed.
+type candle []string
+
+/*
+{
+  "totalEq": "91884.8502560037982",
+  "uTime": "1614846244194",
+  "details": [
+    {
+      "ccy": "BTC",
+      "availBal": "1.2",
+      "frozenBal": "0.3",
+      "cashBal": "1.5",
+      "eq": "1.5",
+      "uTime": "1614846244194"
+    }
+  ]
+}
+*/
+type account struct {
+	TotalEquity number          `json:"totalEq"`
+	UpdateTime  millisecond     `json:"uTime"`
+	Details     []balanceDetail `json:"details"`
+}
+
+type balanceDetail struct {
+	Currency         string      `json:"ccy"`
+	AvailableBalance number      `json:"availBal"`
+	FrozenBalance    number      `json:"frozenBal"`
+	CashBalance      number      `json:"cashBal"`
+	Equity           number      `json:"eq"`
+	UpdateTime       millisecond `json:"uTime"`
+}
+
+// the fee rates are negative when they are charged
+type tradeFee struct {
+	InstrumentType string `json:"instType"`
+	Maker          number `json:"maker"`
+	Taker          number `json:"taker"`
+}
+
+/*
+{
+  "instType": "SPOT",
+  "instId": "BTC-USDT",
+  "ordId": "312269865356374016",
+  "clOrdId": "b1",
+  "px": "999",
+  "sz": "3",
+  "ordType": "limit",
+  "side": "buy",
+  "tdMode": "cash",
+  "accFillSz": "0",
+  "avgPx": "",
+  "state": "live",
+  "cTime": "1597026383085",
+  "uTime": "1597026383085"
+}
+
+The order of the websocket orders channel also contains the fill fields of the last fill.
+*/
+type order struct {
+	InstrumentType  string      `json:"instType"`
+	InstrumentID    string      `json:"instId"`
+	OrderID         string      `json:"ordId"`
+	ClientOrderID   string      `json:"clOrdId"`
+	Price           number      `json:"px"`
+	Size            number      `json:"sz"`
+	OrderType       string      `json:"ordType"`
+	Side            string      `json:"side"`
+	TradeMode       string      `json:"tdMode"`
+	AccumulatedSize number      `json:"accFillSz"`
+	AveragePrice    number      `json:"avgPx"`
+	State           string      `json:"state"`
+	CreationTime    millisecond `json:"cTime"`
+	UpdateTime      millisecond `json:"uTime"`
+
+	// the fields of the last fill, they are only in the websocket orders channel
+	TradeID       string      `json:"tradeId"`
+	FillPrice     number      `json:"fillPx"`
+	FillSize      number      `json:"fillSz"`
+	FillTime      millisecond `json:"fillTime"`
+	FillFee       number      `json:"fillFee"`
+	FillFeeCcy    string      `json:"fillFeeCcy"`
+	ExecutionType string      `json:"execType"`
+}
+
+/*
+{
+  "clOrdId": "oktswap6",
+  "ordId": "312269865356374016",
+  "tag": "",
+  "sCode": "0",
+  "sMsg": ""
+}
+*/
+type orderResult struct {
+	OrderID       string `json:"ordId"`
+	ClientOrderID string `json:"clOrdId"`
+	Code          string `json:"sCode"`
+	Message       string `json:"sMsg"`
+}
+
+/*
+{
+  "instType": "SPOT",
+  "instId": "BTC-USDT",
+  "tradeId": "123",
+  "ordId": "312269865356374016",
+  "clOrdId": "b16",
+  "billId": "1111",
+  "side": "buy",
+  "fillPx": "999",
+  "fillSz": "0.5",
+  "fee": "-0.0005",
+  "feeCcy": "BTC",
+  "execType": "M",
+  "ts": "1597026383085"
+}
+*/
+type fill struct {
+	InstrumentType string      `json:"instType"`
+	InstrumentID   string      `json:"instId"`
+	TradeID        string      `json:"tradeId"`
+	OrderID        string      `json:"ordId"`
+	ClientOrderID  string      `json:"clOrdId"`
+	BillID         string      `json:"billId"`
+	Side           string      `json:"side"`
+	FillPrice      number      `json:"fillPx"`
+	FillSize       number      `json:"fillSz"`
+	Fee            number      `json:"fee"`
+	FeeCurrency    string      `json:"feeCcy"`
+	ExecutionType  string      `json:"execType"`
+	Timestamp      millisecond `json:"ts"`
+}
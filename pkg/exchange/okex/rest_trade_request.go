@@ -0,0 +1,143 @@
+package okex
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type tradeRequest struct {
+	*restRequest
+}
+
+type PlaceOrderPayload struct {
+	InstrumentID  string
+	Side          string
+	OrderType     string
+	Price         string
+	Size          string
+	ClientOrderID string
+}
+
+func (r *tradeRequest) PlaceOrder(ctx context.Context, p PlaceOrderPayload) (orderResult, error) {
+	payload := map[string]interface{}{
+		"instId":  p.InstrumentID,
+		"tdMode":  "cash",
+		"side":    p.Side,
+		"ordType": p.OrderType,
+		"sz":      p.Size,
+	}
+	if len(p.Price) > 0 {
+		payload["px"] = p.Price
+	}
+	if len(p.ClientOrderID) > 0 {
+		payload["clOrdId"] = p.ClientOrderID
+	}
+
+	var results []orderResult
+	if err := r.
+		Method("POST").
+		ReferenceURL("api/v5/trade/order").
+		Payloads(payload).
+		DoAuthenticatedRequest(ctx, &results); err != nil {
+		return orderResult{}, err
+	}
+
+	return firstOrderResult(results)
+}
+
+// CancelOrder cancels the order by the order id, or by the client order id if the order id is empty
+func (r *tradeRequest) CancelOrder(ctx context.Context, instrumentID, orderID, clientOrderID string) (orderResult, error) {
+	payload := map[string]interface{}{
+		"instId": instrumentID,
+	}
+	if len(orderID) > 0 {
+		payload["ordId"] = orderID
+	} else {
+		payload["clOrdId"] = clientOrderID
+	}
+
+	var results []orderResult
+	if err := r.
+		Method("POST").
+		ReferenceURL("api/v5/trade/cancel-order").
+		Payloads(payload).
+		DoAuthenticatedRequest(ctx, &results); err != nil {
+		return orderResult{}, err
+	}
+
+	return firstOrderResult(results)
+}
+
+// firstOrderResult returns the result of the single order request, the request succeeds with the failed order result
+func firstOrderResult(results []orderResult) (orderResult, error) {
+	if len(results) == 0 {
+		return orderResult{}, fmt.Errorf("empty order result")
+	}
+
+	result := results[0]
+	if result.Code != "0" {
+		err := fmt.Errorf("order %s failed, code: %s, msg: %s", result.ClientOrderID, result.Code, result.Message)
+		return result, toGlobalErrorCode(result.Code, result.Message, err)
+	}
+	return result, nil
+}
+
+// PendingOrders returns the open orders in the descending order of the order id, after is the order id for the pagination
+func (r *tradeRequest) PendingOrders(ctx context.Context, instrumentID string, after string) ([]order, error) {
+	var orders []order
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/trade/orders-pending").
+		Query(map[string]string{
+			"instType": instrumentTypeSpot,
+			"instId":   instrumentID,
+			"after":    after,
+		}).
+		DoAuthenticatedRequest(ctx, &orders)
+	return orders, err
+}
+
+// OrdersHistory returns the completed orders of the last 7 days in the descending order of the order id, after is
+// the order id for the pagination
+func (r *tradeRequest) OrdersHistory(ctx context.Context, instrumentID string, after string) ([]order, error) {
+	var orders []order
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/trade/orders-history").
+		Query(map[string]string{
+			"instType": instrumentTypeSpot,
+			"instId":   instrumentID,
+			"after":    after,
+		}).
+		DoAuthenticatedRequest(ctx, &orders)
+	return orders, err
+}
+
+// FillsHistory returns the fills of the last 3 months in the descending order of the bill id, after is the bill id
+// for the pagination
+func (r *tradeRequest) FillsHistory(ctx context.Context, instrumentID string, after string, begin, end time.Time, limit int) ([]fill, error) {
+	q := map[string]string{
+		"instType": instrumentTypeSpot,
+		"instId":   instrumentID,
+		"after":    after,
+	}
+	if limit > 0 {
+		q["limit"] = strconv.Itoa(limit)
+	}
+	if begin != (time.Time{}) {
+		q["begin"] = strconv.FormatInt(toMillisecond(begin), 10)
+	}
+	if end != (time.Time{}) {
+		q["end"] = strconv.FormatInt(toMillisecond(end), 10)
+	}
+
+	var fills []fill
+	err := r.
+		Method("GET").
+		ReferenceURL("api/v5/trade/fills-history").
+		Query(q).
+		DoAuthenticatedRequest(ctx, &fills)
+	return fills, err
+}
@@ -0,0 +1,222 @@
+package okex
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ycdesu/spreaddog/pkg/service"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+const publicEndpoint = "wss://ws.okex.com:8443/ws/v5/public"
+const privateEndpoint = "wss://ws.okex.com:8443/ws/v5/private"
+
+// Stream connects the public and the private endpoints separately, the private connection is only made if the stream
+// is not public only. The connect and disconnect events are emitted for both connections.
+type Stream struct {
+	*types.StandardStream
+
+	public, private *service.WebsocketClientBase
+
+	// publicOnly can only be configured before connecting
+	publicOnly int32
+
+	key, secret, passphrase string
+
+	// mu protects subscriptions, they can be changed after connecting
+	mu sync.Mutex
+
+	subscriptions []websocketRequest
+}
+
+func NewStream(key, secret, passphrase string) *Stream {
+	return newStream(key, secret, passphrase, publicEndpoint, privateEndpoint)
+}
+
+func newStream(key, secret, passphrase, publicURL, privateURL string) *Stream {
+	s := &Stream{
+		StandardStream: &types.StandardStream{},
+		key:            key,
+		secret:         secret,
+		passphrase:     passphrase,
+		public:         service.NewWebsocketClientBase(publicURL, service.DefaultWebsocketClientOptions),
+		private:        service.NewWebsocketClientBase(privateURL, service.DefaultWebsocketClientOptions),
+	}
+
+	publicHandler := newMessageHandler(s.StandardStream)
+	publicHandler.resyncOrderBook = s.resubscribeOrderBook
+	s.setupClient(s.public, publicHandler)
+	s.public.OnConnected(func(conn *websocket.Conn) {
+		// the updates might be lost while reconnecting, the books will be loaded from the new snapshots
+		publicHandler.resetOrderBooks(types.BookInvalidReasonReconnect)
+		s.EmitConnect()
+	})
+
+	privateHandler := newMessageHandler(s.StandardStream)
+	privateHandler.onLogin = s.subscribePrivateChannels
+	s.setupClient(s.private, privateHandler)
+	s.private.OnConnected(func(conn *websocket.Conn) {
+		// the private channels are subscribed after the login succeeds
+		if err := s.private.WriteJSON(newLoginRequest(s.key, s.secret, s.passphrase, time.Now())); err != nil {
+			s.private.EmitError(fmt.Errorf("failed to send login request: %w", err))
+		}
+
+		s.EmitConnect()
+	})
+
+	return s
+}
+
+func (s *Stream) setupClient(client *service.WebsocketClientBase, h *messageHandler) {
+	// https://www.okex.com/docs-v5/en/#websocket-api-connect
+	client.SetPingFunc(func(conn *websocket.Conn) error {
+		return conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+	})
+	client.OnMessage(func(message []byte) {
+		s.EmitMessage()
+	})
+	client.OnMessage(h.handleMessage)
+	client.OnError(s.EmitError)
+	client.OnDisconnected(func(conn *websocket.Conn) {
+		s.EmitDisconnect()
+	})
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.public.Connect(ctx); err != nil {
+		return err
+	}
+
+	// If it's not public only, let's do the authentication.
+	if atomic.LoadInt32(&s.publicOnly) == 0 {
+		return s.private.Connect(ctx)
+	}
+	return nil
+}
+
+func (s *Stream) subscribePrivateChannels() {
+	if err := s.private.WriteJSON(websocketRequest{
+		Operation: subscribe,
+		Args: []websocketArg{
+			{Channel: ordersChannel, InstrumentType: instrumentTypeSpot},
+			{Channel: accountChannel},
+		},
+	}); err != nil {
+		s.private.EmitError(fmt.Errorf("failed to subscribe private channels: %w", err))
+	}
+}
+
+// addSubscription adds the subscription of the public channel, the request is sent immediately if the stream is
+// connected. The caller must hold the lock.
+func (s *Stream) addSubscription(arg websocketArg) {
+	request := websocketRequest{Operation: subscribe, Args: []websocketArg{arg}}
+	s.subscriptions = append(s.subscriptions, request)
+	if err := s.public.AddSubscription(request); err != nil {
+		s.public.EmitError(fmt.Errorf("failed to subscribe %s %s: %w", arg.Channel, arg.InstrumentID, err))
+	}
+}
+
+// removeSubscription removes the subscription of the channel and the instrument, the unsubscribe request is sent
+// immediately if the stream is connected. The caller must hold the lock.
+func (s *Stream) removeSubscription(match func(arg websocketArg) bool) {
+	var subscriptions []websocketRequest
+	for _, sub := range s.subscriptions {
+		if arg := sub.Args[0]; match(arg) {
+			if err := s.public.RemoveSubscription(sub, websocketRequest{
+				Operation: unsubscribe,
+				Args:      sub.Args,
+			}); err != nil {
+				s.public.EmitError(fmt.Errorf("failed to unsubscribe %s %s: %w", arg.Channel, arg.InstrumentID, err))
+			}
+			continue
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	s.subscriptions = subscriptions
+}
+
+// resubscribeOrderBook unsubscribes and subscribes the books channel again, so that okex sends a new snapshot
+func (s *Stream) resubscribeOrderBook(instrumentID string) {
+	for _, op := range []operation{unsubscribe, subscribe} {
+		if err := s.public.WriteJSON(websocketRequest{
+			Operation: op,
+			Args:      []websocketArg{{Channel: booksChannel, InstrumentID: instrumentID}},
+		}); err != nil {
+			s.public.EmitError(fmt.Errorf("failed to %s orderbook of %s: %w", op, instrumentID, err))
+			return
+		}
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	atomic.StoreInt32(&s.publicOnly, 1)
+}
+
+// Subscribe adds the subscription, the subscribe request is sent immediately if the stream is connected.
+func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instrumentID := toLocalSymbol(symbol)
+	switch channel {
+	case types.BookChannel:
+		s.addSubscription(websocketArg{Channel: booksChannel, InstrumentID: instrumentID})
+	case types.MarketTradeChannel:
+		s.addSubscription(websocketArg{Channel: tradesChannel, InstrumentID: instrumentID})
+	case types.BookTickerChannel:
+		s.addSubscription(websocketArg{Channel: tickersChannel, InstrumentID: instrumentID})
+	case types.KLineChannel:
+		candleChannel, err := toCandleChannel(types.Interval(options.Interval))
+		if err != nil {
+			panic(fmt.Sprintf("unsupported kline interval: %q", options.Interval))
+		}
+		s.addSubscription(websocketArg{Channel: candleChannel, InstrumentID: instrumentID})
+	default:
+		panic("only support book, book ticker, market trade and kline channels now")
+	}
+}
+
+// Unsubscribe removes the subscriptions of the channel and the symbol, the unsubscribe request is sent immediately
+// if the stream is connected.
+func (s *Stream) Unsubscribe(channel types.Channel, symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instrumentID := toLocalSymbol(symbol)
+	switch channel {
+	case types.BookChannel:
+		s.removeSubscription(func(arg websocketArg) bool {
+			return arg.Channel == booksChannel && arg.InstrumentID == instrumentID
+		})
+	case types.MarketTradeChannel:
+		s.removeSubscription(func(arg websocketArg) bool {
+			return arg.Channel == tradesChannel && arg.InstrumentID == instrumentID
+		})
+	case types.BookTickerChannel:
+		s.removeSubscription(func(arg websocketArg) bool {
+			return arg.Channel == tickersChannel && arg.InstrumentID == instrumentID
+		})
+	case types.KLineChannel:
+		// the klines of all the intervals are unsubscribed
+		s.removeSubscription(func(arg websocketArg) bool {
+			_, ok := toGlobalCandleInterval(arg.Channel)
+			return ok && arg.InstrumentID == instrumentID
+		})
+	}
+}
+
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	s.subscriptions = nil
+	s.mu.Unlock()
+
+	if err := s.private.Close(); err != nil {
+		logger.WithError(err).Warn("failed to close the private connection")
+	}
+	return s.public.Close()
+}
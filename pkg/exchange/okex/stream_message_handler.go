@@ -0,0 +1,278 @@
+package okex
+
+import (
+	"encoding/json"
+	"expvar"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// orderBookResyncs counts the order book resyncs by instrument, it's published through expvar
+var orderBookResyncs = expvar.NewMap("okex_orderbook_resyncs")
+
+// messageHandler handles the messages of a connection, the public and the private connections have their own
+// handlers since the messages of different connections are handled in different goroutines.
+type messageHandler struct {
+	*types.StandardStream
+
+	// books are the local order books of the instruments, every update is applied to the local book to verify the checksum
+	books map[string]*localOrderBook
+
+	// klines are the last klines of the candle subscriptions, they are used to close the kline when the next one starts
+	klines map[websocketArg]types.KLine
+
+	// balanceSnapshotReceived is false until the first push of the account channel which contains all the balances
+	balanceSnapshotReceived bool
+
+	// resyncOrderBook is called when the checksum of the local book doesn't match, it should request a new snapshot
+	resyncOrderBook func(instrumentID string)
+
+	// onLogin is called when the login succeeds, the private channels can only be subscribed after the login
+	onLogin func()
+}
+
+func newMessageHandler(stream *types.StandardStream) *messageHandler {
+	return &messageHandler{
+		StandardStream: stream,
+		books:          make(map[string]*localOrderBook),
+		klines:         make(map[websocketArg]types.KLine),
+	}
+}
+
+func (h *messageHandler) handleMessage(message []byte) {
+	if string(message) == pong {
+		return
+	}
+
+	var r websocketResponse
+	if err := json.Unmarshal(message, &r); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal resp: %s", string(message))
+		return
+	}
+
+	if len(r.Event) > 0 {
+		h.handleEvent(r)
+		return
+	}
+
+	switch r.Arg.Channel {
+	case booksChannel:
+		h.handleOrderBook(r)
+	case tradesChannel:
+		h.handleMarketTrades(r)
+	case tickersChannel:
+		h.handleTickers(r)
+	case ordersChannel:
+		h.handleOrders(r)
+	case accountChannel:
+		h.handleAccount(r)
+	default:
+		if interval, ok := toGlobalCandleInterval(r.Arg.Channel); ok {
+			h.handleCandles(r, interval)
+			return
+		}
+		logger.Warnf("unsupported channel: %+v", r.Arg)
+	}
+}
+
+func (h *messageHandler) handleEvent(r websocketResponse) {
+	switch r.Event {
+	case loginEvent:
+		if r.Code != "0" {
+			logger.Errorf("failed to login, code: %s, msg: %s", r.Code, r.Message)
+			return
+		}
+
+		logger.Info("websocket logged in")
+		h.balanceSnapshotReceived = false
+		if h.onLogin != nil {
+			h.onLogin()
+		}
+	case subscribeEvent:
+		logger.Infof("subscribed %+v", r.Arg)
+	case unsubscribeEvent:
+		// drop the local book, if it's unsubscribed for the resync, the snapshot comes after subscribing again
+		if r.Arg.Channel == booksChannel {
+			delete(h.books, r.Arg.InstrumentID)
+		}
+		logger.Infof("unsubscribed %+v", r.Arg)
+	case errorEvent:
+		logger.Errorf("receives err, code: %s, msg: %s", r.Code, r.Message)
+	default:
+		logger.Warnf("unsupported event: %s", r.Event)
+	}
+}
+
+func (h *messageHandler) handleOrderBook(r websocketResponse) {
+	var data []bookData
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the books: %s", string(r.Data))
+		return
+	}
+
+	instrumentID := r.Arg.InstrumentID
+	symbol := toGlobalSymbol(instrumentID)
+	for _, d := range data {
+		switch r.Action {
+		case snapshotAction:
+			book := newLocalOrderBook(d)
+			if err := book.verifyChecksum(d.Checksum); err != nil {
+				h.resync(instrumentID, types.BookInvalidReasonChecksum, err)
+				return
+			}
+
+			h.books[instrumentID] = book
+			h.EmitBookSnapshot(toGlobalOrderBook(symbol, d))
+		case updateAction:
+			book, ok := h.books[instrumentID]
+			if !ok {
+				// the update is dropped until the snapshot comes
+				logger.Warnf("orderbook update of %s is dropped, the snapshot is not received yet", instrumentID)
+				return
+			}
+
+			book.update(d)
+			if err := book.verifyChecksum(d.Checksum); err != nil {
+				h.resync(instrumentID, types.BookInvalidReasonChecksum, err)
+				return
+			}
+
+			// emit updates, not the whole orderbook
+			h.EmitBookUpdate(toGlobalOrderBook(symbol, d))
+		default:
+			logger.Errorf("unsupported order book action %s", r.Action)
+			return
+		}
+	}
+}
+
+// resync drops the local book of the instrument and requests a new snapshot
+func (h *messageHandler) resync(instrumentID string, reason types.BookInvalidReason, err error) {
+	delete(h.books, instrumentID)
+	orderBookResyncs.Add(instrumentID, 1)
+	logger.WithError(err).Warnf("orderbook of %s is inconsistent (%s), resubscribing", instrumentID, reason)
+
+	h.EmitBookInvalidated(toGlobalSymbol(instrumentID), reason)
+
+	if h.resyncOrderBook != nil {
+		h.resyncOrderBook(instrumentID)
+	}
+}
+
+// resetOrderBooks drops all the local books, the snapshots will be sent after subscribing again
+func (h *messageHandler) resetOrderBooks(reason types.BookInvalidReason) {
+	for instrumentID := range h.books {
+		delete(h.books, instrumentID)
+		h.EmitBookInvalidated(toGlobalSymbol(instrumentID), reason)
+	}
+}
+
+func (h *messageHandler) handleMarketTrades(r websocketResponse) {
+	var trades []publicTrade
+	if err := json.Unmarshal(r.Data, &trades); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the trades: %s", string(r.Data))
+		return
+	}
+
+	for _, t := range trades {
+		trade, err := toGlobalMarketTrade(t)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to convert the market trade")
+			continue
+		}
+		h.EmitMarketTrade(trade)
+	}
+}
+
+func (h *messageHandler) handleTickers(r websocketResponse) {
+	var tickers []ticker
+	if err := json.Unmarshal(r.Data, &tickers); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the tickers: %s", string(r.Data))
+		return
+	}
+
+	for _, t := range tickers {
+		h.EmitBookTicker(toGlobalBookTicker(t))
+	}
+}
+
+// handleCandles emits the kline of every push, the kline is closed when it's confirmed or the next kline starts.
+func (h *messageHandler) handleCandles(r websocketResponse, interval types.Interval) {
+	var candles []candle
+	if err := json.Unmarshal(r.Data, &candles); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the candles: %s", string(r.Data))
+		return
+	}
+
+	symbol := toGlobalSymbol(r.Arg.InstrumentID)
+	for _, c := range candles {
+		k, err := toGlobalKLine(symbol, interval, c)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to convert the candle")
+			continue
+		}
+
+		last, ok := h.klines[r.Arg]
+		if ok {
+			if k.StartTime.Before(last.StartTime) || (last.Closed && !k.StartTime.After(last.StartTime)) {
+				// the stale candle or the candle that has been closed
+				continue
+			}
+
+			if !last.Closed && k.StartTime.After(last.StartTime) {
+				last.Closed = true
+				h.EmitKLineClosed(last)
+			}
+		}
+
+		h.klines[r.Arg] = k
+		if k.Closed {
+			h.EmitKLineClosed(k)
+		} else {
+			h.EmitKLine(k)
+		}
+	}
+}
+
+func (h *messageHandler) handleOrders(r websocketResponse) {
+	var orders []order
+	if err := json.Unmarshal(r.Data, &orders); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the orders: %s", string(r.Data))
+		return
+	}
+
+	for _, o := range orders {
+		globalOrder, err := toGlobalOrder(o)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to convert order update to global order")
+			continue
+		}
+		h.EmitOrderUpdate(globalOrder)
+
+		trade, ok, err := toGlobalTradeFromOrder(o)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to convert the fill of the order update to global trade")
+			continue
+		}
+		if ok {
+			h.EmitTradeUpdate(trade)
+		}
+	}
+}
+
+// handleAccount emits the balance snapshot of the first push, and the balance updates of the changed currencies after it
+func (h *messageHandler) handleAccount(r websocketResponse) {
+	var accounts []account
+	if err := json.Unmarshal(r.Data, &accounts); err != nil {
+		logger.WithError(err).Errorf("failed to unmarshal the account: %s", string(r.Data))
+		return
+	}
+
+	balances := toGlobalBalances(accounts)
+	if !h.balanceSnapshotReceived {
+		h.balanceSnapshotReceived = true
+		h.EmitBalanceSnapshot(balances)
+		return
+	}
+	h.EmitBalanceUpdate(balances)
+}
@@ -0,0 +1,119 @@
+package okex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func Test_messageHandler_handleOrderBook(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var resyncs []string
+	h.resyncOrderBook = func(instrumentID string) {
+		resyncs = append(resyncs, instrumentID)
+	}
+
+	var invalidated []types.BookInvalidReason
+	h.OnBookInvalidated(func(symbol string, reason types.BookInvalidReason) {
+		assert.Equal(t, "BTCUSDT", symbol)
+		invalidated = append(invalidated, reason)
+	})
+
+	var snapshots, updates []types.OrderBook
+	h.OnBookSnapshot(func(book types.OrderBook) {
+		snapshots = append(snapshots, book)
+	})
+	h.OnBookUpdate(func(book types.OrderBook) {
+		updates = append(updates, book)
+	})
+
+	// the update is dropped before the snapshot
+	h.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[["8477","9","0","3"]],"bids":[],"ts":"1597026383085","checksum":1}]}`))
+	assert.Len(t, updates, 0)
+
+	h.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["8476.98","415","0","13"],["8477","7","0","2"]],"bids":[["8476.97","256","0","12"],["8475.55","101","0","1"]],"ts":"1597026383085","checksum":2123921068}]}`))
+	if assert.Len(t, snapshots, 1) {
+		assert.Equal(t, "BTCUSDT", snapshots[0].Symbol)
+		assert.Len(t, snapshots[0].Bids, 2)
+		assert.Len(t, snapshots[0].Asks, 2)
+	}
+
+	h.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[["8477","9","0","3"]],"bids":[["8476.97","0","0","0"],["8476.5","3","0","1"]],"ts":"1597026383086","checksum":168470733}]}`))
+	if assert.Len(t, updates, 1) {
+		assert.Len(t, updates[0].Bids, 2)
+		assert.Len(t, updates[0].Asks, 1)
+	}
+
+	// the update of the unmatched checksum invalidates the book and resubscribes it
+	h.handleMessage([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"update","data":[{"asks":[["8478","1","0","1"]],"bids":[],"ts":"1597026383087","checksum":168470733}]}`))
+	assert.Len(t, updates, 1)
+	assert.Equal(t, []types.BookInvalidReason{types.BookInvalidReasonChecksum}, invalidated)
+	assert.Equal(t, []string{"BTC-USDT"}, resyncs)
+	assert.NotContains(t, h.books, "BTC-USDT")
+}
+
+func Test_messageHandler_handleCandles(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var klines, closed []types.KLine
+	h.OnKLine(func(kline types.KLine) {
+		klines = append(klines, kline)
+	})
+	h.OnKLineClosed(func(kline types.KLine) {
+		closed = append(closed, kline)
+	})
+
+	h.handleMessage([]byte(`{"arg":{"channel":"candle1m","instId":"BTC-USDT"},"data":[["1614556800000","48800","49010","48700","48900","1","48900"]]}`))
+	h.handleMessage([]byte(`{"arg":{"channel":"candle1m","instId":"BTC-USDT"},"data":[["1614556800000","48800","49010","48700","49000","1.5","73350"]]}`))
+	assert.Len(t, klines, 2)
+	assert.Len(t, closed, 0)
+
+	// the kline is closed when the next one starts
+	h.handleMessage([]byte(`{"arg":{"channel":"candle1m","instId":"BTC-USDT"},"data":[["1614556860000","49000","49000","49000","49000","0.1","4900"]]}`))
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, "BTCUSDT", closed[0].Symbol)
+		assert.Equal(t, types.Interval1m, closed[0].Interval)
+		assert.Equal(t, 49000.0, closed[0].Close)
+		assert.True(t, closed[0].Closed)
+	}
+
+	// the confirmed kline is closed once
+	h.handleMessage([]byte(`{"arg":{"channel":"candle1m","instId":"BTC-USDT"},"data":[["1614556860000","49000","49100","49000","49100","0.2","9810","9810","1"]]}`))
+	h.handleMessage([]byte(`{"arg":{"channel":"candle1m","instId":"BTC-USDT"},"data":[["1614556920000","49100","49100","49100","49100","0.1","4910","4910","0"]]}`))
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, 49100.0, closed[1].Close)
+	}
+	assert.Len(t, klines, 4)
+}
+
+func Test_messageHandler_handleOrders(t *testing.T) {
+	h := newMessageHandler(&types.StandardStream{})
+
+	var orders []types.Order
+	h.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+	var trades []types.Trade
+	h.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	h.handleMessage([]byte(`{"arg":{"channel":"orders","instType":"SPOT"},"data":[{"instType":"SPOT","instId":"BTC-USDT","ordId":"312269865356374016","clOrdId":"b1","px":"30000","sz":"1","ordType":"limit","side":"buy","accFillSz":"0","state":"live","cTime":"1597026383085","uTime":"1597026383085","tradeId":"","fillPx":"","fillSz":"0"}]}`))
+	h.handleMessage([]byte(`{"arg":{"channel":"orders","instType":"SPOT"},"data":[{"instType":"SPOT","instId":"BTC-USDT","ordId":"312269865356374016","clOrdId":"b1","px":"30000","sz":"1","ordType":"limit","side":"buy","accFillSz":"0.4","state":"partially_filled","cTime":"1597026383085","uTime":"1597026383090","tradeId":"123","fillPx":"30000","fillSz":"0.4","fillTime":"1597026383090","fillFee":"-0.0004","fillFeeCcy":"BTC","execType":"M"}]}`))
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.OrderStatusNew, orders[0].Status)
+		assert.Equal(t, types.OrderStatusPartiallyFilled, orders[1].Status)
+		assert.Equal(t, "0.4", orders[1].ExecutedQuantity.String())
+	}
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(123), trades[0].ID)
+		assert.Equal(t, uint64(312269865356374016), trades[0].OrderID)
+		assert.Equal(t, "12000", trades[0].QuoteQuantity.String())
+		assert.Equal(t, "0.0004", trades[0].Fee.String())
+		assert.True(t, trades[0].IsMaker)
+	}
+}
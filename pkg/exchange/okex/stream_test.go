@@ -0,0 +1,174 @@
+package okex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// testWebsocketServer forwards the received messages to the messages channel, the test replies through the last connection
+type testWebsocketServer struct {
+	*httptest.Server
+
+	messages chan string
+	conns    chan *websocket.Conn
+}
+
+func newTestWebsocketServer() *testWebsocketServer {
+	s := &testWebsocketServer{
+		messages: make(chan string, 16),
+		conns:    make(chan *websocket.Conn, 1),
+	}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.conns <- conn
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			s.messages <- string(msg)
+		}
+	}))
+	return s
+}
+
+func (s *testWebsocketServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.Server.URL, "http")
+}
+
+func (s *testWebsocketServer) nextMessage(t *testing.T) map[string]interface{} {
+	select {
+	case msg := <-s.messages:
+		var m map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(msg), &m))
+		return m
+	case <-time.After(3 * time.Second):
+		t.Fatal("no message is received")
+	}
+	return nil
+}
+
+func TestStream_Subscribe(t *testing.T) {
+	s := NewStream("", "", "")
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+	s.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "1h"})
+	s.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "1m"})
+	s.Subscribe(types.MarketTradeChannel, "ETH-USDT", types.SubscribeOptions{})
+
+	assert.Equal(t, []websocketRequest{
+		{Operation: subscribe, Args: []websocketArg{{Channel: booksChannel, InstrumentID: "BTC-USDT"}}},
+		{Operation: subscribe, Args: []websocketArg{{Channel: "candle1H", InstrumentID: "BTC-USDT"}}},
+		{Operation: subscribe, Args: []websocketArg{{Channel: "candle1m", InstrumentID: "BTC-USDT"}}},
+		{Operation: subscribe, Args: []websocketArg{{Channel: tradesChannel, InstrumentID: "ETH-USDT"}}},
+	}, s.subscriptions)
+
+	s.Unsubscribe(types.KLineChannel, "BTCUSDT")
+	assert.Equal(t, []websocketRequest{
+		{Operation: subscribe, Args: []websocketArg{{Channel: booksChannel, InstrumentID: "BTC-USDT"}}},
+		{Operation: subscribe, Args: []websocketArg{{Channel: tradesChannel, InstrumentID: "ETH-USDT"}}},
+	}, s.subscriptions)
+
+	assert.Panics(t, func() {
+		s.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "1w"})
+	})
+}
+
+func TestStream_Public(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	s := newStream("", "", "", server.URL(), server.URL())
+	s.SetPublicOnly()
+	s.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+
+	snapshotC := make(chan types.OrderBook, 1)
+	s.OnBookSnapshot(func(book types.OrderBook) {
+		snapshotC <- book
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.Connect(ctx))
+	defer s.Close()
+
+	assert.Equal(t, map[string]interface{}{
+		"op":   "subscribe",
+		"args": []interface{}{map[string]interface{}{"channel": "books", "instId": "BTC-USDT"}},
+	}, server.nextMessage(t))
+
+	conn := <-server.conns
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["8476.98","415","0","13"],["8477","7","0","2"]],"bids":[["8476.97","256","0","12"],["8475.55","101","0","1"]],"ts":"1597026383085","checksum":2123921068}]}`)))
+
+	select {
+	case book := <-snapshotC:
+		assert.Equal(t, "BTCUSDT", book.Symbol)
+		assert.Equal(t, "8476.97", book.Bids[0].Price.String())
+	case <-time.After(3 * time.Second):
+		t.Fatal("the snapshot is not emitted")
+	}
+}
+
+func TestStream_Private(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	s := newStream("key", "secret", "passphrase", server.URL(), server.URL())
+
+	orderC := make(chan types.Order, 1)
+	s.OnOrderUpdate(func(order types.Order) {
+		orderC <- order
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.Connect(ctx))
+	defer s.Close()
+
+	// the first connection is the public one which has no subscription
+	<-server.conns
+	conn := <-server.conns
+
+	loginMessage := server.nextMessage(t)
+	assert.Equal(t, "login", loginMessage["op"])
+
+	// the private channels are subscribed after the login
+	select {
+	case msg := <-server.messages:
+		t.Fatalf("unexpected message before the login: %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"login","code":"0","msg":""}`)))
+	assert.Equal(t, map[string]interface{}{
+		"op": "subscribe",
+		"args": []interface{}{
+			map[string]interface{}{"channel": "orders", "instType": "SPOT"},
+			map[string]interface{}{"channel": "account"},
+		},
+	}, server.nextMessage(t))
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"arg":{"channel":"orders","instType":"SPOT"},"data":[{"instType":"SPOT","instId":"BTC-USDT","ordId":"312269865356374016","clOrdId":"b1","px":"30000","sz":"1","ordType":"limit","side":"buy","accFillSz":"0","state":"live","cTime":"1597026383085","uTime":"1597026383085"}]}`)))
+
+	select {
+	case order := <-orderC:
+		assert.Equal(t, uint64(312269865356374016), order.OrderID)
+		assert.Equal(t, "BTCUSDT", order.Symbol)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the order update is not emitted")
+	}
+}
@@ -0,0 +1,295 @@
+package okex
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+type operation string
+
+const login operation = "login"
+const subscribe operation = "subscribe"
+const unsubscribe operation = "unsubscribe"
+
+type channel string
+
+const booksChannel channel = "books"
+const tradesChannel channel = "trades"
+const tickersChannel channel = "tickers"
+const ordersChannel channel = "orders"
+const accountChannel channel = "account"
+
+// candleChannelPrefix is followed by the bar, e.g., candle1m
+const candleChannelPrefix = "candle"
+
+const loginEvent = "login"
+const subscribeEvent = "subscribe"
+const unsubscribeEvent = "unsubscribe"
+const errorEvent = "error"
+
+const snapshotAction = "snapshot"
+const updateAction = "update"
+
+// pong is the text reply of the text ping
+const pong = "pong"
+
+// checksumDepth is the number of the levels of each side in the checksum
+const checksumDepth = 25
+
+var errUnmatchedChecksum = fmt.Errorf("unmatched checksum")
+
+// websocketArg is the channel of the subscription, it's also attached to the pushed data
+type websocketArg struct {
+	Channel        channel `json:"channel"`
+	InstrumentType string  `json:"instType,omitempty"`
+	InstrumentID   string  `json:"instId,omitempty"`
+}
+
+/*
+{"op": "subscribe", "args": [{"channel": "books", "instId": "BTC-USDT"}]}
+*/
+type websocketRequest struct {
+	Operation operation      `json:"op"`
+	Args      []websocketArg `json:"args"`
+}
+
+/*
+{
+  "op": "login",
+  "args": [
+    {
+      "apiKey": "<api_key>",
+      "passphrase": "<passphrase>",
+      "timestamp": "1538054050",
+      "sign": "<signature>"
+    }
+  ]
+}
+*/
+type loginRequest struct {
+	Operation operation   `json:"op"`
+	Args      []loginArgs `json:"args"`
+}
+
+type loginArgs struct {
+	Key        string `json:"apiKey"`
+	Passphrase string `json:"passphrase"`
+	Timestamp  string `json:"timestamp"`
+	Signature  string `json:"sign"`
+}
+
+// newLoginRequest signs the login with the unix timestamp in seconds, unlike the rest requests
+func newLoginRequest(key, secret, passphrase string, t time.Time) loginRequest {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return loginRequest{
+		Operation: login,
+		Args: []loginArgs{{
+			Key:        key,
+			Passphrase: passphrase,
+			Timestamp:  ts,
+			Signature:  sign(secret, ts+"GET"+"/users/self/verify"),
+		}},
+	}
+}
+
+/*
+The event response:
+{"event": "subscribe", "arg": {"channel": "books", "instId": "BTC-USDT"}}
+{"event": "login", "code": "0", "msg": ""}
+{"event": "error", "code": "60012", "msg": "Invalid request"}
+
+The data response:
+{"arg": {"channel": "books", "instId": "BTC-USDT"}, "action": "snapshot", "data": [...]}
+*/
+type websocketResponse struct {
+	Event   string       `json:"event"`
+	Code    string       `json:"code"`
+	Message string       `json:"msg"`
+	Arg     websocketArg `json:"arg"`
+
+	// Action is only in the books channel, it's either snapshot or update
+	Action string          `json:"action"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// bookLevel is [price, size, deprecated, number of orders], the strings are kept to calculate the checksum
+type bookLevel []string
+
+/*
+{
+  "asks": [["8476.98", "415", "0", "13"]],
+  "bids": [["8476.97", "256", "0", "12"]],
+  "ts": "1597026383085",
+  "checksum": -855196043
+}
+*/
+type bookData struct {
+	Asks      []bookLevel `json:"asks"`
+	Bids      []bookLevel `json:"bids"`
+	Timestamp millisecond `json:"ts"`
+	Checksum  int32       `json:"checksum"`
+}
+
+/*
+{
+  "instId": "BTC-USDT",
+  "tradeId": "130639474",
+  "px": "42219.9",
+  "sz": "0.12060306",
+  "side": "buy",
+  "ts": "1630048897897"
+}
+*/
+type publicTrade struct {
+	InstrumentID string      `json:"instId"`
+	TradeID      string      `json:"tradeId"`
+	Price        number      `json:"px"`
+	Size         number      `json:"sz"`
+	Side         string      `json:"side"`
+	Timestamp    millisecond `json:"ts"`
+}
+
+// localOrderBook keeps the levels of a book in the string form, the checksum is calculated from the original strings
+type localOrderBook struct {
+	// bids are in the descending order and asks are in the ascending order of the price
+	bids, asks []bookLevel
+}
+
+func newLocalOrderBook(d bookData) *localOrderBook {
+	b := &localOrderBook{}
+	b.update(d)
+	return b
+}
+
+func (b *localOrderBook) update(d bookData) {
+	b.bids = upsertLevels(b.bids, d.Bids, func(a, b fixedpoint.Value) bool { return a > b })
+	b.asks = upsertLevels(b.asks, d.Asks, func(a, b fixedpoint.Value) bool { return a < b })
+}
+
+// upsertLevels applies the updates to the sorted levels, the level of the zero size is removed
+func upsertLevels(levels, updates []bookLevel, before func(a, b fixedpoint.Value) bool) []bookLevel {
+	for _, u := range updates {
+		if len(u) < 2 {
+			continue
+		}
+
+		price := number(u[0]).Value()
+		i := sort.Search(len(levels), func(i int) bool {
+			return !before(number(levels[i][0]).Value(), price)
+		})
+
+		found := i < len(levels) && number(levels[i][0]).Value() == price
+		switch {
+		case number(u[1]).Value() == 0:
+			if found {
+				levels = append(levels[:i], levels[i+1:]...)
+			}
+		case found:
+			levels[i] = u
+		default:
+			levels = append(levels, nil)
+			copy(levels[i+1:], levels[i:])
+			levels[i] = u
+		}
+	}
+	return levels
+}
+
+// checksumString returns <bid price>:<bid size>:<ask price>:<ask size>... of the best 25 levels, the missing
+// levels of the shorter side are skipped.
+func (b *localOrderBook) checksumString() string {
+	var fields []string
+	for i := 0; i < checksumDepth; i++ {
+		if i < len(b.bids) {
+			fields = append(fields, b.bids[i][0], b.bids[i][1])
+		}
+		if i < len(b.asks) {
+			fields = append(fields, b.asks[i][0], b.asks[i][1])
+		}
+	}
+	return strings.Join(fields, ":")
+}
+
+func (b *localOrderBook) verifyChecksum(checksum int32) error {
+	if actual := int32(crc32.ChecksumIEEE([]byte(b.checksumString()))); actual != checksum {
+		return fmt.Errorf("expected checksum %d, actual checksum %d: %w", checksum, actual, errUnmatchedChecksum)
+	}
+	return nil
+}
+
+func toGlobalOrderBook(symbol string, d bookData) types.OrderBook {
+	return types.OrderBook{
+		Symbol: symbol,
+		Bids:   toPriceVolumeSlice(d.Bids),
+		Asks:   toPriceVolumeSlice(d.Asks),
+	}
+}
+
+func toPriceVolumeSlice(levels []bookLevel) types.PriceVolumeSlice {
+	var pvs types.PriceVolumeSlice
+	for _, l := range levels {
+		if len(l) < 2 {
+			continue
+		}
+		pvs = append(pvs, types.PriceVolume{
+			Price:  number(l[0]).Value(),
+			Volume: number(l[1]).Value(),
+		})
+	}
+	return pvs
+}
+
+func toGlobalMarketTrade(t publicTrade) (types.Trade, error) {
+	tradeID, err := strconv.ParseInt(t.TradeID, 10, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("invalid trade id %s: %w", t.TradeID, err)
+	}
+
+	side := toGlobalSide(t.Side)
+	price := t.Price.Value()
+	quantity := t.Size.Value()
+	return types.Trade{
+		ID:            tradeID,
+		Exchange:      types.ExchangeOKEx.String(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Symbol:        toGlobalSymbol(t.InstrumentID),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          datatype.Time(t.Timestamp.Time()),
+	}, nil
+}
+
+// toCandleChannel returns the candle channel of the interval, e.g., candle1m
+func toCandleChannel(interval types.Interval) (channel, error) {
+	bar, err := toLocalInterval(interval)
+	if err != nil {
+		return "", err
+	}
+	return channel(candleChannelPrefix + bar), nil
+}
+
+// toGlobalCandleInterval returns the interval of the candle channel, false is returned if it's not a candle channel
+func toGlobalCandleInterval(c channel) (types.Interval, bool) {
+	if !strings.HasPrefix(string(c), candleChannelPrefix) {
+		return "", false
+	}
+
+	bar := strings.TrimPrefix(string(c), candleChannelPrefix)
+	for interval, b := range bars {
+		if b == bar {
+			return interval, true
+		}
+	}
+	return "", false
+}
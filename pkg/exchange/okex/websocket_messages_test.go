@@ -0,0 +1,51 @@
+package okex
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalOrderBook_Update(t *testing.T) {
+	book := newLocalOrderBook(bookData{
+		Bids: []bookLevel{{"8476.97", "256", "0", "12"}, {"8475.55", "101", "0", "1"}},
+		Asks: []bookLevel{{"8476.98", "415", "0", "13"}, {"8477", "7", "0", "2"}},
+	})
+	assert.Equal(t, "8476.97:256:8476.98:415:8475.55:101:8477:7", book.checksumString())
+	assert.NoError(t, book.verifyChecksum(2123921068))
+
+	book.update(bookData{
+		// remove the best bid and insert a new one
+		Bids: []bookLevel{{"8476.97", "0", "0", "0"}, {"8476.5", "3", "0", "1"}},
+		Asks: []bookLevel{{"8477", "9", "0", "3"}},
+	})
+	assert.Equal(t, "8476.5:3:8476.98:415:8475.55:101:8477:9", book.checksumString())
+	assert.NoError(t, book.verifyChecksum(168470733))
+	assert.True(t, errors.Is(book.verifyChecksum(2123921068), errUnmatchedChecksum))
+
+	// removing the missing level is ignored
+	book.update(bookData{Asks: []bookLevel{{"9000", "0", "0", "0"}}})
+	assert.Len(t, book.asks, 2)
+}
+
+func TestLocalOrderBook_checksumString(t *testing.T) {
+	// the missing levels of the shorter side are skipped
+	book := newLocalOrderBook(bookData{
+		Bids: []bookLevel{{"3366.1", "7", "0", "3"}, {"3366", "6", "0", "3"}},
+		Asks: []bookLevel{{"3366.8", "9", "10", "3"}},
+	})
+	assert.Equal(t, "3366.1:7:3366.8:9:3366:6", book.checksumString())
+}
+
+func TestNewLoginRequest(t *testing.T) {
+	r := newLoginRequest("key", "secret", "passphrase", time.Unix(1538054050, 0))
+	assert.Equal(t, login, r.Operation)
+	if assert.Len(t, r.Args, 1) {
+		assert.Equal(t, "key", r.Args[0].Key)
+		assert.Equal(t, "passphrase", r.Args[0].Passphrase)
+		assert.Equal(t, "1538054050", r.Args[0].Timestamp)
+		assert.Equal(t, sign("secret", "1538054050GET/users/self/verify"), r.Args[0].Signature)
+	}
+}
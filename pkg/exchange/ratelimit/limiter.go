@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// defaultBackOff is the back off duration when the exchange rejects the request without the Retry-After header
+const defaultBackOff = 10 * time.Second
+
+// Limits is the request weight limit of an exchange account
+type Limits struct {
+	// Weight is the max weight of the requests in an interval, the weight of most requests is 1
+	Weight int
+
+	Interval time.Duration
+
+	// Burst is the bucket size of the limiter, it's half of the weight if it's zero. The bucket is refilled with the
+	// weight of Burst in an interval.
+	Burst int
+
+	// UsedWeightHeader is the response header of the used weight in the current interval reported by the exchange,
+	// e.g., X-MBX-USED-WEIGHT-1M of binance
+	UsedWeightHeader string
+}
+
+// Stats is the usage of a limiter, it's exported in the metrics
+type Stats struct {
+	Requests     int64 `json:"requests"`
+	Weight       int64 `json:"weight"`
+	UsedWeight   int64 `json:"usedWeight"`
+	WeightLimit  int   `json:"weightLimit"`
+	Throttled    int64 `json:"throttled"`
+	RateLimited  int64 `json:"rateLimited"`
+	BackOffUntil int64 `json:"backOffUntil,omitempty"`
+}
+
+// Limiter is the weight-aware rate limiter of an exchange account. By default the bucket is half of the weight limit
+// and it's refilled with the other half in an interval, so that the weight of the requests in any interval doesn't
+// exceed the limit. The limiter backs off when the exchange rejects the requests or reports the used weight is over the
+// limit.
+type Limiter struct {
+	name    string
+	limits  Limits
+	limiter *rate.Limiter
+
+	mu           sync.Mutex
+	backOffUntil time.Time
+
+	requests, weight, usedWeight, throttled, rateLimited int64
+}
+
+func NewLimiter(name string, limits Limits) *Limiter {
+	burst := limits.Burst
+	if burst == 0 {
+		burst = limits.Weight / 2
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		name:    name,
+		limits:  limits,
+		limiter: rate.NewLimiter(rate.Every(limits.Interval/time.Duration(burst)), burst),
+	}
+}
+
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+// Wait blocks until the request of the weight is allowed or the context is done
+func (l *Limiter) Wait(ctx context.Context, weight int) error {
+	if delay := l.backOffDelay(); delay > 0 {
+		atomic.AddInt64(&l.throttled, 1)
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	// the weight of a request can't be more than the bucket size, e.g., the depth request of 5000 levels
+	if weight > l.limiter.Burst() {
+		weight = l.limiter.Burst()
+	} else if weight < 1 {
+		weight = 1
+	}
+
+	atomic.AddInt64(&l.requests, 1)
+	atomic.AddInt64(&l.weight, int64(weight))
+	return l.limiter.WaitN(ctx, weight)
+}
+
+// BackOff blocks the requests for the duration, the longer back off is kept if there is one
+func (l *Limiter) BackOff(d time.Duration) {
+	until := time.Now().Add(d)
+
+	l.mu.Lock()
+	if until.After(l.backOffUntil) {
+		l.backOffUntil = until
+	}
+	l.mu.Unlock()
+}
+
+func (l *Limiter) backOffDelay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Until(l.backOffUntil)
+}
+
+// UpdateUsedWeight updates the used weight reported by the exchange, the limiter backs off until the next interval if
+// the weight is used up, e.g., the other processes using the same api key
+func (l *Limiter) UpdateUsedWeight(used int) {
+	atomic.StoreInt64(&l.usedWeight, int64(used))
+
+	if used >= l.limits.Weight {
+		now := time.Now()
+		l.BackOff(now.Truncate(l.limits.Interval).Add(l.limits.Interval).Sub(now))
+	}
+}
+
+// Observe updates the limiter with the response, it backs off when the exchange rejects the request for the rate limit
+func (l *Limiter) Observe(resp *http.Response) {
+	if len(l.limits.UsedWeightHeader) > 0 {
+		if used, err := strconv.Atoi(resp.Header.Get(l.limits.UsedWeightHeader)); err == nil {
+			l.UpdateUsedWeight(used)
+		}
+	}
+
+	// binance returns 418 when the ip is banned after the repeated 429 responses
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		atomic.AddInt64(&l.rateLimited, 1)
+
+		backOff := defaultBackOff
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			backOff = time.Duration(seconds) * time.Second
+		}
+		l.BackOff(backOff)
+	}
+}
+
+func (l *Limiter) Stats() Stats {
+	stats := Stats{
+		Requests:    atomic.LoadInt64(&l.requests),
+		Weight:      atomic.LoadInt64(&l.weight),
+		UsedWeight:  atomic.LoadInt64(&l.usedWeight),
+		WeightLimit: l.limits.Weight,
+		Throttled:   atomic.LoadInt64(&l.throttled),
+		RateLimited: atomic.LoadInt64(&l.rateLimited),
+	}
+
+	l.mu.Lock()
+	if l.backOffUntil.After(time.Now()) {
+		stats.BackOffUntil = l.backOffUntil.Unix()
+	}
+	l.mu.Unlock()
+
+	return stats
+}
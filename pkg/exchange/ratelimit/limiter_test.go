@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShared(t *testing.T) {
+	limits := Limits{Weight: 1200, Interval: time.Minute}
+
+	a := Shared("test", "key", limits)
+	b := Shared("test", "key", limits)
+	c := Shared("test", "another key", limits)
+	assert.Same(t, a, b)
+	assert.NotSame(t, a, c)
+	assert.NotContains(t, a.Name(), "key")
+
+	assert.NoError(t, a.Wait(context.Background(), 10))
+
+	var stats Stats
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("exchange_rate_limits").(*expvar.Map).Get(a.Name()).String()), &stats))
+	assert.Equal(t, int64(1), stats.Requests)
+	assert.Equal(t, int64(10), stats.Weight)
+	assert.Equal(t, 1200, stats.WeightLimit)
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := NewLimiter("test", Limits{Weight: 4, Interval: time.Second})
+
+	// the bucket is half of the limit, the weight over the bucket size is capped
+	start := time.Now()
+	assert.NoError(t, l.Wait(context.Background(), 2))
+	assert.NoError(t, l.Wait(context.Background(), 10))
+	assert.True(t, time.Since(start) >= 900*time.Millisecond)
+
+	l.BackOff(time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, l.Wait(ctx, 1))
+	assert.Equal(t, int64(1), l.Stats().Throttled)
+	assert.NotZero(t, l.Stats().BackOffUntil)
+}
+
+func TestLimiter_UpdateUsedWeight(t *testing.T) {
+	l := NewLimiter("test", Limits{Weight: 1200, Interval: time.Minute})
+
+	l.UpdateUsedWeight(100)
+	assert.Equal(t, int64(100), l.Stats().UsedWeight)
+	assert.True(t, l.backOffDelay() <= 0)
+
+	// the weight is used up, backs off until the next minute
+	l.UpdateUsedWeight(1200)
+	assert.True(t, l.backOffDelay() > 0)
+	assert.True(t, l.backOffDelay() <= time.Minute)
+}
+
+func TestTransport(t *testing.T) {
+	var statusCode = http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "15")
+		if statusCode == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "30")
+		}
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	l := NewLimiter("test", Limits{Weight: 1200, Interval: time.Minute, UsedWeightHeader: "X-MBX-USED-WEIGHT-1M"})
+	client := &http.Client{Transport: &Transport{
+		Limiter: l,
+		Weight: func(req *http.Request) int {
+			return 5
+		},
+	}}
+
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, Stats{Requests: 1, Weight: 5, UsedWeight: 15, WeightLimit: 1200}, l.Stats())
+
+	statusCode = http.StatusTooManyRequests
+	resp, err = client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int64(1), l.Stats().RateLimited)
+	assert.InDelta(t, float64(30*time.Second), float64(l.backOffDelay()), float64(time.Second))
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"expvar"
+	"net/http"
+	"sync"
+)
+
+// metrics exports the stats of the shared limiters by the limiter name, see /api/metrics of the server
+var metrics = expvar.NewMap("exchange_rate_limits")
+
+var limiters = make(map[string]*Limiter)
+var limitersMutex sync.Mutex
+
+// Shared returns the limiter of the exchange account, the sessions using the same api key share the same limiter.
+// The limiter name is the exchange name with the fingerprint of the key, the key itself is not exposed in the metrics.
+func Shared(exchange string, key string, limits Limits) *Limiter {
+	name := exchange
+	if len(key) > 0 {
+		name += "-" + fingerprint(key)
+	}
+
+	limitersMutex.Lock()
+	defer limitersMutex.Unlock()
+
+	if l, ok := limiters[name]; ok {
+		return l
+	}
+
+	l := NewLimiter(name, limits)
+	limiters[name] = l
+	metrics.Set(name, expvar.Func(func() interface{} {
+		return l.Stats()
+	}))
+	return l
+}
+
+// NewTransport returns the transport of the shared limiter of the exchange account
+func NewTransport(exchange string, key string, limits Limits, weight WeightFunc) http.RoundTripper {
+	return &Transport{
+		Limiter: Shared(exchange, key, limits),
+		Weight:  weight,
+	}
+}
+
+func fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:4])
+}
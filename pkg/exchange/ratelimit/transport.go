@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"net/http"
+)
+
+// WeightFunc returns the weight of the request
+type WeightFunc func(req *http.Request) int
+
+// Transport is the http.RoundTripper which waits for the limiter before sending the request and updates the limiter
+// with the response
+type Transport struct {
+	Limiter *Limiter
+
+	// Weight is the weight function of the endpoints, every request weighs 1 if it's nil
+	Weight WeightFunc
+
+	// Base is the underlying transport, http.DefaultTransport is used if it's nil
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	weight := 1
+	if t.Weight != nil {
+		weight = t.Weight(req)
+	}
+
+	if err := t.Limiter.Wait(req.Context(), weight); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Limiter.Observe(resp)
+	return resp, nil
+}
@@ -0,0 +1,152 @@
+package exchange
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+// Credentials are the api credentials of an exchange session, the fields that the exchange doesn't use are ignored.
+// The credentials are empty for the public only sessions.
+type Credentials struct {
+	Key        string
+	Secret     string
+	Passphrase string
+	SubAccount string
+}
+
+// Constructor creates the exchange with the credentials
+type Constructor func(credentials Credentials) types.Exchange
+
+// Registration describes an exchange adapter, the adapters register themselves from init(), so an exchange can be
+// added by importing its package.
+type Registration struct {
+	Name types.ExchangeName
+
+	// Aliases are the other names accepted in the config and the command line, e.g., bn for binance
+	Aliases []string
+
+	New Constructor
+
+	// EnvVarPrefix is the default prefix of the credential env vars, e.g., BINANCE for BINANCE_API_KEY and
+	// BINANCE_API_SECRET. The upper case name is used if it's empty.
+	EnvVarPrefix string
+
+	// UsePassphrase is true if the exchange reads the passphrase from <PREFIX>_API_PASSPHRASE
+	UsePassphrase bool
+
+	// UseSubAccount is true if the exchange reads the sub-account from <PREFIX>_SUBACCOUNT
+	UseSubAccount bool
+
+	Capabilities types.ExchangeCapabilities
+}
+
+var registry = make(map[types.ExchangeName]Registration)
+var registryMutex sync.RWMutex
+
+// Register registers the exchange adapter, it panics if the name is registered twice.
+func Register(r Registration) {
+	if len(r.Name) == 0 || r.New == nil {
+		panic(fmt.Errorf("exchange registration %+v requires the name and the constructor", r))
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, ok := registry[r.Name]; ok {
+		panic(fmt.Errorf("exchange %s is already registered", r.Name))
+	}
+
+	registry[r.Name] = r
+	types.RegisterExchangeName(r.Name, r.Aliases...)
+}
+
+// Lookup returns the registration of the exchange name or the alias
+func Lookup(name string) (Registration, error) {
+	n, err := types.ValidExchangeName(name)
+	if err != nil {
+		return Registration{}, err
+	}
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	r, ok := registry[n]
+	if !ok {
+		return Registration{}, fmt.Errorf("unsupported exchange: %v", name)
+	}
+	return r, nil
+}
+
+// Registrations returns the registered exchanges in the alphabetical order of the names
+func Registrations() []Registration {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	var registrations []Registration
+	for _, r := range registry {
+		registrations = append(registrations, r)
+	}
+
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Name < registrations[j].Name
+	})
+	return registrations
+}
+
+// New creates the registered exchange with the credentials
+func New(name types.ExchangeName, credentials Credentials) (types.Exchange, error) {
+	r, err := Lookup(name.String())
+	if err != nil {
+		return nil, err
+	}
+	return r.New(credentials), nil
+}
+
+// NewFromEnv creates the registered exchange with the credentials of the env vars, the default prefix of the
+// exchange is used if varPrefix is empty.
+func NewFromEnv(name types.ExchangeName, varPrefix string) (types.Exchange, error) {
+	r, err := Lookup(name.String())
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := r.CredentialsFromEnv(varPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return r.New(credentials), nil
+}
+
+// CredentialsFromEnv reads the credentials from <PREFIX>_API_KEY, <PREFIX>_API_SECRET and the optional passphrase
+// and sub-account env vars.
+func (r Registration) CredentialsFromEnv(varPrefix string) (Credentials, error) {
+	if len(varPrefix) == 0 {
+		varPrefix = r.EnvVarPrefix
+	}
+	if len(varPrefix) == 0 {
+		varPrefix = r.Name.String()
+	}
+
+	varPrefix = strings.ToUpper(varPrefix)
+
+	credentials := Credentials{
+		Key:    os.Getenv(varPrefix + "_API_KEY"),
+		Secret: os.Getenv(varPrefix + "_API_SECRET"),
+	}
+	if len(credentials.Key) == 0 || len(credentials.Secret) == 0 {
+		return credentials, fmt.Errorf("can not initialize exchange %s: empty key or secret, env var prefix: %s", r.Name, varPrefix)
+	}
+
+	if r.UsePassphrase {
+		credentials.Passphrase = os.Getenv(varPrefix + "_API_PASSPHRASE")
+	}
+	if r.UseSubAccount {
+		credentials.SubAccount = os.Getenv(varPrefix + "_SUBACCOUNT")
+	}
+	return credentials, nil
+}
@@ -0,0 +1,92 @@
+package exchange
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func TestRegister(t *testing.T) {
+	var created []Credentials
+	Register(Registration{
+		Name:    "testex",
+		Aliases: []string{"tx"},
+		New: func(c Credentials) types.Exchange {
+			created = append(created, c)
+			return nil
+		},
+		UsePassphrase: true,
+		Capabilities: types.ExchangeCapabilities{
+			Rewards:  true,
+			Channels: []types.Channel{types.BookChannel},
+		},
+	})
+
+	assert.Panics(t, func() {
+		Register(Registration{Name: "testex", New: func(c Credentials) types.Exchange { return nil }})
+	})
+	assert.Panics(t, func() {
+		Register(Registration{Name: "testex2"})
+	})
+
+	// the aliases are case insensitive
+	r, err := Lookup("TX")
+	assert.NoError(t, err)
+	assert.Equal(t, types.ExchangeName("testex"), r.Name)
+	assert.True(t, r.Capabilities.Rewards)
+	assert.True(t, r.Capabilities.HasChannel(types.BookChannel))
+	assert.False(t, r.Capabilities.HasChannel(types.KLineChannel))
+
+	_, err = Lookup("unknown")
+	assert.Error(t, err)
+
+	assert.Contains(t, types.ExchangeNames(), types.ExchangeName("testex"))
+
+	var names []types.ExchangeName
+	for _, r := range Registrations() {
+		names = append(names, r.Name)
+	}
+	assert.Contains(t, names, types.ExchangeName("testex"))
+
+	// the names in the config are resolved by the registry
+	var n types.ExchangeName
+	assert.NoError(t, json.Unmarshal([]byte(`"tx"`), &n))
+	assert.Equal(t, types.ExchangeName("testex"), n)
+	assert.Error(t, json.Unmarshal([]byte(`"unknown"`), &n))
+
+	_, err = New("testex", Credentials{Key: "key", Secret: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Credentials{{Key: "key", Secret: "secret"}}, created)
+}
+
+func TestRegistration_CredentialsFromEnv(t *testing.T) {
+	r := Registration{Name: "testenv", UsePassphrase: true}
+
+	_, err := r.CredentialsFromEnv("")
+	assert.Error(t, err)
+
+	for k, v := range map[string]string{
+		"TESTENV_API_KEY":        "key",
+		"TESTENV_API_SECRET":     "secret",
+		"TESTENV_API_PASSPHRASE": "passphrase",
+		"TESTENV_SUBACCOUNT":     "sub",
+		"OTHER_API_KEY":          "other-key",
+		"OTHER_API_SECRET":       "other-secret",
+	} {
+		assert.NoError(t, os.Setenv(k, v))
+		defer os.Unsetenv(k)
+	}
+
+	// the sub-account is not read since the exchange doesn't use it
+	credentials, err := r.CredentialsFromEnv("")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Key: "key", Secret: "secret", Passphrase: "passphrase"}, credentials)
+
+	credentials, err = r.CredentialsFromEnv("other")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Key: "other-key", Secret: "other-secret"}, credentials)
+}
@@ -0,0 +1,125 @@
+package fixedpoint
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+var (
+	ErrOverflow       = errors.New("fixedpoint overflow")
+	ErrDivisionByZero = errors.New("fixedpoint division by zero")
+)
+
+// RoundingMode decides how the digits beyond the precision are rounded
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value, and the tie is rounded to the even value (banker's rounding)
+	RoundHalfEven RoundingMode = iota
+
+	// RoundDown rounds toward zero, i.e., truncates the digits
+	RoundDown
+
+	// RoundUp rounds away from zero
+	RoundUp
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half-even"
+	case RoundDown:
+		return "down"
+	case RoundUp:
+		return "up"
+	}
+	return "unknown"
+}
+
+// MulRound multiplies the values with the integer arithmetic, the digits beyond the precision are rounded by the mode.
+// It returns ErrOverflow if the product does not fit in the value.
+func (v Value) MulRound(v2 Value, mode RoundingMode) (Value, error) {
+	r, err := mulDiv(int64(v), int64(v2), DefaultPow, mode)
+	return Value(r), err
+}
+
+// DivRound divides the value by v2 with the integer arithmetic, the digits beyond the precision are rounded by the mode.
+// It returns ErrDivisionByZero if v2 is zero, and ErrOverflow if the quotient does not fit in the value.
+func (v Value) DivRound(v2 Value, mode RoundingMode) (Value, error) {
+	r, err := mulDiv(int64(v), DefaultPow, int64(v2), mode)
+	return Value(r), err
+}
+
+// RoundToStep rounds the value to a multiple of the step, such as the tick size or the lot size.
+// The value is returned as it is if the step is not positive.
+func (v Value) RoundToStep(step Value, mode RoundingMode) (Value, error) {
+	if step <= 0 {
+		return v, nil
+	}
+
+	n, err := mulDiv(int64(v), 1, int64(step), mode)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := mulDiv(n, int64(step), 1, RoundDown)
+	return Value(r), err
+}
+
+// mulDiv returns a * b / c, the product is kept in 128 bits, so nothing is lost before the division
+func mulDiv(a, b, c int64, mode RoundingMode) (int64, error) {
+	if c == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	negative := (a < 0) != (b < 0) != (c < 0)
+	ua, ub, uc := abs(a), abs(b), abs(c)
+
+	hi, lo := bits.Mul64(ua, ub)
+	if hi >= uc {
+		return 0, ErrOverflow
+	}
+
+	q, r := bits.Div64(hi, lo, uc)
+	if r > 0 && roundsAway(mode, q, r, uc) {
+		// q was math.MaxUint64
+		if q++; q == 0 {
+			return 0, ErrOverflow
+		}
+	}
+
+	if negative {
+		if q > 1<<63 {
+			return 0, ErrOverflow
+		}
+		return int64(-q), nil
+	}
+
+	if q > math.MaxInt64 {
+		return 0, ErrOverflow
+	}
+	return int64(q), nil
+}
+
+// roundsAway returns true if the quotient q with the remainder r of the divisor d should be rounded away from zero
+func roundsAway(mode RoundingMode, q, r, d uint64) bool {
+	switch mode {
+	case RoundUp:
+		return true
+
+	case RoundHalfEven:
+		// compare the remainder with the half of the divisor without overflowing
+		half := d - r
+		return r > half || (r == half && q&1 == 1)
+	}
+
+	return false
+}
+
+func abs(a int64) uint64 {
+	if a < 0 {
+		return -uint64(a)
+	}
+	return uint64(a)
+}
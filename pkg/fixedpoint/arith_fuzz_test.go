@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package fixedpoint
+
+import (
+	"testing"
+)
+
+func FuzzMulRound(f *testing.F) {
+	f.Add(int64(150000000), int64(250000000), 0)
+	f.Add(int64(-31), int64(10000000), 2)
+	f.Add(int64(9223372036854775807), int64(-100000000), 1)
+
+	f.Fuzz(func(t *testing.T, a, b int64, mode int) {
+		checkMulDiv(t, a, b, DefaultPow, RoundingMode(uint(mode)%3))
+	})
+}
+
+func FuzzDivRound(f *testing.F) {
+	f.Add(int64(200000000), int64(300000000), 0)
+	f.Add(int64(-100000000), int64(300000000), 2)
+	f.Add(int64(-9223372036854775808), int64(-1), 1)
+
+	f.Fuzz(func(t *testing.T, a, b int64, mode int) {
+		checkMulDiv(t, a, DefaultPow, b, RoundingMode(uint(mode)%3))
+	})
+}
@@ -0,0 +1,187 @@
+package fixedpoint
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bigMulDiv is the reference implementation of mulDiv with math/big
+func bigMulDiv(a, b, c int64, mode RoundingMode) (int64, error) {
+	if c == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	n := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	d := big.NewInt(c)
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+
+	if r.Sign() != 0 {
+		away := false
+		switch mode {
+		case RoundUp:
+			away = true
+		case RoundHalfEven:
+			cmp := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).CmpAbs(d)
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		}
+
+		if away {
+			if n.Sign()*d.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return q.Int64(), nil
+}
+
+func checkMulDiv(t *testing.T, a, b, c int64, mode RoundingMode) {
+	expected, expectedErr := bigMulDiv(a, b, c, mode)
+	actual, err := mulDiv(a, b, c, mode)
+	if expectedErr != nil {
+		assert.Equal(t, expectedErr, err, "%d * %d / %d (%s)", a, b, c, mode)
+		return
+	}
+
+	if assert.NoError(t, err, "%d * %d / %d (%s)", a, b, c, mode) {
+		assert.Equal(t, expected, actual, "%d * %d / %d (%s)", a, b, c, mode)
+	}
+}
+
+func TestValue_MulRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Value
+		mode     RoundingMode
+		expected Value
+		err      error
+	}{
+		{name: "exact", a: MustNewFromString("1.5"), b: MustNewFromString("2.5"), mode: RoundHalfEven, expected: MustNewFromString("3.75")},
+		{name: "half to even down", a: 25, b: MustNewFromString("0.1"), mode: RoundHalfEven, expected: 2},
+		{name: "half to even up", a: 35, b: MustNewFromString("0.1"), mode: RoundHalfEven, expected: 4},
+		{name: "down", a: 39, b: MustNewFromString("0.1"), mode: RoundDown, expected: 3},
+		{name: "up", a: 31, b: MustNewFromString("0.1"), mode: RoundUp, expected: 4},
+		{name: "negative up", a: -31, b: MustNewFromString("0.1"), mode: RoundUp, expected: -4},
+		{name: "negative down", a: -39, b: MustNewFromString("0.1"), mode: RoundDown, expected: -3},
+		// the float64 version loses the last digits of the notional
+		{name: "large notional", a: MustNewFromString("12345678.12345678"), b: MustNewFromString("3.00000001"), mode: RoundHalfEven, expected: MustNewFromString("37037034.49382712")},
+		{name: "overflow", a: NewFromInt64(100000000), b: NewFromInt64(100000000), mode: RoundHalfEven, err: ErrOverflow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.a.MulRound(test.b, test.mode)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestValue_DivRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Value
+		mode     RoundingMode
+		expected Value
+		err      error
+	}{
+		{name: "exact", a: MustNewFromString("3.75"), b: MustNewFromString("2.5"), mode: RoundHalfEven, expected: MustNewFromString("1.5")},
+		{name: "half even", a: NewFromInt(2), b: NewFromInt(3), mode: RoundHalfEven, expected: 66666667},
+		{name: "down", a: NewFromInt(2), b: NewFromInt(3), mode: RoundDown, expected: 66666666},
+		{name: "up", a: NewFromInt(1), b: NewFromInt(3), mode: RoundUp, expected: 33333334},
+		{name: "negative", a: NewFromInt(-2), b: NewFromInt(3), mode: RoundHalfEven, expected: -66666667},
+		{name: "division by zero", a: NewFromInt(1), b: 0, mode: RoundHalfEven, err: ErrDivisionByZero},
+		{name: "overflow", a: NewFromInt64(10000000000), b: 1, mode: RoundHalfEven, err: ErrOverflow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.a.DivRound(test.b, test.mode)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestValue_MulDiv_Saturate(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   func() Value
+		expected Value
+	}{
+		{name: "mul", actual: func() Value { return NewFromInt(2).Mul(MustNewFromString("1.5")) }, expected: NewFromInt(3)},
+		{name: "mul overflow", actual: func() Value { return NewFromInt64(100000000).Mul(NewFromInt64(100000000)) }, expected: math.MaxInt64},
+		{name: "mul negative overflow", actual: func() Value { return NewFromInt64(-100000000).Mul(NewFromInt64(100000000)) }, expected: math.MinInt64},
+		{name: "div", actual: func() Value { return NewFromInt(3).Div(MustNewFromString("1.5")) }, expected: NewFromInt(2)},
+		{name: "div by zero", actual: func() Value { return NewFromInt(1).Div(0) }, expected: math.MaxInt64},
+		{name: "negative div by zero", actual: func() Value { return NewFromInt(-1).Div(0) }, expected: math.MinInt64},
+		{name: "zero div by zero", actual: func() Value { return Value(0).Div(0) }, expected: 0},
+		{name: "div overflow", actual: func() Value { return NewFromInt64(10000000000).Div(1) }, expected: math.MaxInt64},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				assert.Equal(t, test.expected, test.actual())
+			})
+		})
+	}
+}
+
+func TestValue_RoundToStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		v, step  Value
+		mode     RoundingMode
+		expected Value
+	}{
+		{name: "tick down", v: MustNewFromString("26.288656"), step: MustNewFromString("0.001"), mode: RoundDown, expected: MustNewFromString("26.288")},
+		{name: "tick up", v: MustNewFromString("26.288256"), step: MustNewFromString("0.001"), mode: RoundUp, expected: MustNewFromString("26.289")},
+		{name: "tick half even", v: MustNewFromString("26.2885"), step: MustNewFromString("0.001"), mode: RoundHalfEven, expected: MustNewFromString("26.288")},
+		{name: "step size", v: MustNewFromString("0.12511"), step: MustNewFromString("0.05"), mode: RoundDown, expected: MustNewFromString("0.1")},
+		{name: "no step", v: MustNewFromString("0.12511"), step: 0, mode: RoundDown, expected: MustNewFromString("0.12511")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.v.RoundToStep(test.step, test.mode)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestMulDiv_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// mix the small values, the full range values and the edge values
+	randomValue := func() int64 {
+		switch r.Intn(4) {
+		case 0:
+			return r.Int63n(2000) - 1000
+		case 1:
+			return r.Int63n(1e12) - 5e11
+		case 2:
+			return []int64{0, 1, -1, math.MaxInt64, math.MinInt64, DefaultPow, -DefaultPow}[r.Intn(7)]
+		default:
+			return int64(r.Uint64())
+		}
+	}
+
+	for i := 0; i < 100000; i++ {
+		mode := RoundingMode(r.Intn(3))
+		a, b := randomValue(), randomValue()
+		checkMulDiv(t, a, b, DefaultPow, mode)
+		checkMulDiv(t, a, DefaultPow, b, mode)
+		checkMulDiv(t, a, 1, b, mode)
+	}
+}
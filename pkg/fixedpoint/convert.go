@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/bits"
 	"strconv"
+	"strings"
 	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
 )
 
 const MaxPrecision = 12
@@ -24,7 +28,8 @@ func (v Value) Value() (driver.Value, error) {
 func (v *Value) Scan(src interface{}) error {
 	switch d := src.(type) {
 	case int64:
-		*v = Value(d)
+		// the integral decimal columns are returned as int64 by sqlite
+		*v = NewFromInt64(d)
 		return nil
 
 	case float64:
@@ -50,20 +55,60 @@ func (v Value) Float64() float64 {
 	return float64(v) / DefaultPow
 }
 
+// String formats the value in decimal without the trailing zeros, it's exact since no float is involved
+func (v Value) String() string {
+	u := abs(int64(v))
+	s := strconv.FormatUint(u/DefaultPow, 10)
+	if frac := u % DefaultPow; frac > 0 {
+		f := strconv.FormatUint(frac+DefaultPow, 10)[1:]
+		s += "." + strings.TrimRight(f, "0")
+	}
+
+	if v < 0 {
+		return "-" + s
+	}
+	return s
+}
+
 func (v Value) Int64() int64 {
 	return int64(v)
 }
 
+// Mul multiplies the values exactly and rounds half to even. It never panics, the overflowed product is saturated to
+// the max or the min value, use MulRound to handle the error.
 func (v Value) Mul(v2 Value) Value {
-	return NewFromFloat(v.Float64() * v2.Float64())
+	r, err := v.MulRound(v2, RoundHalfEven)
+	if err != nil {
+		log.WithError(err).Warnf("fixedpoint %s * %s is saturated", v, v2)
+		return saturate((v < 0) != (v2 < 0))
+	}
+	return r
 }
 
 func (v Value) MulFloat64(v2 float64) Value {
 	return NewFromFloat(v.Float64() * v2)
 }
 
+// Div divides the values exactly and rounds half to even. It never panics, the overflowed quotient and the division
+// by zero are saturated to the max or the min value (zero divided by zero is zero), use DivRound to handle the error.
 func (v Value) Div(v2 Value) Value {
-	return NewFromFloat(v.Float64() / v2.Float64())
+	r, err := v.DivRound(v2, RoundHalfEven)
+	if err != nil {
+		log.WithError(err).Warnf("fixedpoint %s / %s is saturated", v, v2)
+		if v == 0 {
+			return 0
+		}
+		return saturate((v < 0) != (v2 < 0))
+	}
+	return r
+}
+
+// saturate returns the min value if negative is true, otherwise the max value
+func saturate(negative bool) Value {
+	if negative {
+		return Value(math.MinInt64)
+	}
+	return Value(math.MaxInt64)
 }
 
 func (v Value) Floor() Value {
@@ -215,7 +260,13 @@ func Parse(input string) (num int64, numDecimalPoints int, err error) {
 	return num, numDecimalPoints, nil
 }
 
+// NewFromString parses the plain decimal string exactly, the other formats such as the exponent notation and the
+// digits beyond the precision are parsed through float64.
 func NewFromString(input string) (Value, error) {
+	if v, ok := parseDecimal(input); ok {
+		return v, nil
+	}
+
 	v, err := strconv.ParseFloat(input, 64)
 	if err != nil {
 		return 0, err
@@ -224,7 +275,53 @@ func NewFromString(input string) (Value, error) {
 	return NewFromFloat(v), nil
 }
 
+// parseDecimal parses [-+]digits[.digits] with at most DefaultPrecision fractional digits
+func parseDecimal(input string) (Value, bool) {
+	s := input
+	negative := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	if len(integer)+len(fraction) == 0 || len(fraction) > DefaultPrecision {
+		return 0, false
+	}
+
+	var v uint64
+	for _, digits := range []string{integer, fraction + strings.Repeat("0", DefaultPrecision-len(fraction))} {
+		for i := 0; i < len(digits); i++ {
+			c := digits[i]
+			if c < '0' || c > '9' {
+				return 0, false
+			}
+
+			hi, lo := bits.Mul64(v, 10)
+			lo, carry := bits.Add64(lo, uint64(c-'0'), 0)
+			if hi != 0 || carry != 0 || lo > math.MaxInt64 {
+				return 0, false
+			}
+			v = lo
+		}
+	}
+
+	if negative {
+		return Value(-int64(v)), true
+	}
+	return Value(v), true
+}
+
+// MustNewFromString parses the string and panics on error, the empty string is parsed as zero
 func MustNewFromString(input string) Value {
+	if len(input) == 0 {
+		return 0
+	}
+
 	v, err := NewFromString(input)
 	if err != nil {
 		panic(fmt.Errorf("can not parse %s into fixedpoint, error: %s", input, err.Error()))
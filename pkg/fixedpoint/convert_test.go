@@ -117,3 +117,33 @@ func TestNumFractionalDigits(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFromString_Exact(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Value
+		str   string
+	}{
+		{input: "0.1", want: 10000000, str: "0.1"},
+		{input: "-0.00000001", want: -1, str: "-0.00000001"},
+		{input: "9100.05", want: 910005000000, str: "9100.05"},
+		{input: "+1.50", want: 150000000, str: "1.5"},
+		{input: ".5", want: 50000000, str: "0.5"},
+		{input: "92233720368", want: 9223372036800000000, str: "92233720368"},
+		{input: "0", want: 0, str: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NewFromString(tt.input)
+			if err != nil {
+				t.Fatalf("NewFromString() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NewFromString() = %d, want %d", got, tt.want)
+			}
+			if got.String() != tt.str {
+				t.Errorf("String() = %s, want %s", got.String(), tt.str)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -25,6 +26,9 @@ import (
 
 const DefaultBindAddress = "localhost:8080"
 
+// defaultStreamMaxIdle is the default max duration without any stream message for the health check
+const defaultStreamMaxIdle = 5 * time.Minute
+
 type Setup struct {
 	// Context is the trader context
 	Context context.Context
@@ -61,6 +65,10 @@ func (s *Server) newEngine() *gin.Engine {
 	}))
 
 	r.GET("/api/ping", s.ping)
+	r.GET("/api/health", s.health)
+
+	// the expvar metrics, e.g., the usage of the exchange rate limiters in exchange_rate_limits
+	r.GET("/api/metrics", gin.WrapH(expvar.Handler()))
 
 	if s.Setup != nil {
 		r.POST("/api/setup/test-db", s.setupTestDB)
@@ -214,6 +222,7 @@ func (s *Server) newEngine() *gin.Engine {
 	r.GET("/api/sessions/:session/account", s.getSessionAccount)
 	r.GET("/api/sessions/:session/account/balances", s.getSessionAccountBalance)
 	r.GET("/api/sessions/:session/symbols", s.listSessionSymbols)
+	r.GET("/api/sessions/:session/stream", s.getSessionStreamStatus)
 
 	r.GET("/api/sessions/:session/pnl", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
@@ -263,6 +272,27 @@ func (s *Server) ping(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "pong"})
 }
 
+// health checks the stream connections of the sessions, it's used by the liveness probe.
+// The maxIdle query parameter is the max duration without any stream message, for example: /api/health?maxIdle=10m
+func (s *Server) health(c *gin.Context) {
+	maxIdle := defaultStreamMaxIdle
+	if v, ok := c.GetQuery("maxIdle"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid maxIdle %q: %s", v, err.Error())})
+			return
+		}
+		maxIdle = d
+	}
+
+	if err := s.Environ.CheckStreamHealth(maxIdle); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"healthy": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"healthy": true})
+}
+
 func (s *Server) listClosedOrders(c *gin.Context) {
 	if s.Environ.OrderService == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
@@ -347,6 +377,18 @@ func (s *Server) listSessionSymbols(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"symbols": symbols})
 }
 
+func (s *Server) getSessionStreamStatus(c *gin.Context) {
+	sessionName := c.Param("session")
+	session, ok := s.Environ.Session(sessionName)
+
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("session %s not found", sessionName)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stream": session.StreamStatus()})
+}
+
 func (s *Server) listSessionTrades(c *gin.Context) {
 	sessionName := c.Param("session")
 	session, ok := s.Environ.Session(sessionName)
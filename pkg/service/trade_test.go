@@ -7,6 +7,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -27,9 +28,9 @@ func Test_tradeService(t *testing.T) {
 		ID:            1,
 		OrderID:       1,
 		Exchange:      "binance",
-		Price:         1000.0,
-		Quantity:      0.1,
-		QuoteQuantity: 1000.0 * 0.1,
+		Price:         fixedpoint.NewFromFloat(1000.0),
+		Quantity:      fixedpoint.NewFromFloat(0.1),
+		QuoteQuantity: fixedpoint.NewFromFloat(1000.0 * 0.1),
 		Symbol:        "BTCUSDT",
 		Side:          "BUY",
 		IsBuyer:       true,
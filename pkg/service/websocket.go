@@ -2,21 +2,86 @@ package service
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
 )
 
+const writeTimeout = 10 * time.Second
+
+var ErrWebsocketNotConnected = errors.New("websocket is not connected")
+
+type WebsocketClientOptions struct {
+	// ConnectTimeout is the timeout of the dial and the websocket handshake
+	ConnectTimeout time.Duration
+
+	// MinBackoff and MaxBackoff are the bounds of the exponential reconnect backoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// PingInterval is the interval of the heartbeat
+	PingInterval time.Duration
+
+	// ReadTimeout is the read deadline, the connection is considered dead if nothing (including the pong) is read in it
+	ReadTimeout time.Duration
+}
+
+var DefaultWebsocketClientOptions = WebsocketClientOptions{
+	ConnectTimeout: 10 * time.Second,
+	MinBackoff:     time.Second,
+	MaxBackoff:     time.Minute,
+	PingInterval:   15 * time.Second,
+	ReadTimeout:    time.Minute,
+}
+
+// backoff returns the reconnect delay of the given attempt, the delay is doubled for every attempt until MaxBackoff,
+// and a random jitter in [delay/2, delay] is applied to avoid all the clients reconnecting at the same time.
+func (o WebsocketClientOptions) backoff(attempt int) time.Duration {
+	d := o.MaxBackoff
+	if attempt < 32 {
+		if b := o.MinBackoff << uint(attempt); b > 0 && b < o.MaxBackoff {
+			d = b
+		}
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// WebsocketClientBase is a websocket client that reconnects with exponential backoff, keeps the connection alive
+// with heartbeats and read deadlines, and replays the subscriptions after every (re)connection.
 //go:generate callbackgen -type WebsocketClientBase
 type WebsocketClientBase struct {
 	baseURL string
+	options WebsocketClientOptions
+
+	// urlProvider returns the url of each connection, it's used when the url changes between connections, e.g., the listen key
+	urlProvider func(ctx context.Context) (string, error)
+
+	// pingFunc sends the heartbeat, the websocket ping control message is sent if it's not set
+	pingFunc func(conn *websocket.Conn) error
+
+	// mu protects conn, connectingConn and subscriptions
+	mu   sync.Mutex
+	conn *websocket.Conn
+
+	// connectingConn is the new connection before the connected callbacks and the subscription replay finish, it's
+	// only used by WriteJSON (e.g., the login), so that the subscriptions added meanwhile are not sent before the
+	// authentication or twice with the replay.
+	connectingConn *websocket.Conn
+
+	subscriptions []interface{}
 
-	// mu protects conn
-	mu                sync.Mutex
-	conn              *websocket.Conn
-	reconnectC        chan struct{}
-	reconnectDuration time.Duration
+	// writeMu serializes the writes to the connection
+	writeMu sync.Mutex
+
+	closeC    chan struct{}
+	closeOnce sync.Once
 
 	connectedCallbacks    []func(conn *websocket.Conn)
 	disconnectedCallbacks []func(conn *websocket.Conn)
@@ -24,12 +89,91 @@ type WebsocketClientBase struct {
 	errorCallbacks        []func(err error)
 }
 
-func NewWebsocketClientBase(baseURL string, reconnectDuration time.Duration) *WebsocketClientBase {
+func NewWebsocketClientBase(baseURL string, options WebsocketClientOptions) *WebsocketClientBase {
 	return &WebsocketClientBase{
-		baseURL:           baseURL,
-		reconnectC:        make(chan struct{}, 1),
-		reconnectDuration: reconnectDuration,
+		baseURL: baseURL,
+		options: options,
+		closeC:  make(chan struct{}),
+	}
+}
+
+func (s *WebsocketClientBase) SetURLProvider(provider func(ctx context.Context) (string, error)) {
+	s.urlProvider = provider
+}
+
+func (s *WebsocketClientBase) SetPingFunc(pingFunc func(conn *websocket.Conn) error) {
+	s.pingFunc = pingFunc
+}
+
+// AddSubscription adds the subscription request which is sent after every (re)connection,
+// the request is sent immediately if the client is connected.
+func (s *WebsocketClientBase) AddSubscription(request interface{}) error {
+	s.mu.Lock()
+	s.subscriptions = append(s.subscriptions, request)
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
 	}
+
+	return s.writeJSON(conn, request)
+}
+
+// RemoveSubscription removes the subscription request, the unsubscribe request is sent if the client is connected.
+func (s *WebsocketClientBase) RemoveSubscription(request, unsubscribeRequest interface{}) error {
+	s.mu.Lock()
+	var subscriptions []interface{}
+	for _, sub := range s.subscriptions {
+		if reflect.DeepEqual(sub, request) {
+			continue
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	s.subscriptions = subscriptions
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil || unsubscribeRequest == nil {
+		return nil
+	}
+
+	return s.writeJSON(conn, unsubscribeRequest)
+}
+
+// SetSubscriptions replaces the subscription requests sent after the next (re)connection, nothing is sent immediately.
+// It's used when the subscriptions are batched into one request.
+func (s *WebsocketClientBase) SetSubscriptions(requests ...interface{}) {
+	s.mu.Lock()
+	s.subscriptions = requests
+	s.mu.Unlock()
+}
+
+// WriteJSON sends the message to the current connection, the connection being set up is used in the connected callbacks
+func (s *WebsocketClientBase) WriteJSON(v interface{}) error {
+	s.mu.Lock()
+	conn := s.conn
+	if s.connectingConn != nil {
+		conn = s.connectingConn
+	}
+	s.mu.Unlock()
+
+	if conn == nil {
+		return ErrWebsocketNotConnected
+	}
+
+	return s.writeJSON(conn, v)
+}
+
+func (s *WebsocketClientBase) writeJSON(conn *websocket.Conn, v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+
+	return conn.WriteJSON(v)
 }
 
 func (s *WebsocketClientBase) Listen(ctx context.Context) {
@@ -37,26 +181,56 @@ func (s *WebsocketClientBase) Listen(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-s.reconnectC:
-			time.Sleep(s.reconnectDuration)
-			if err := s.connect(ctx); err != nil {
-				s.Reconnect()
-			}
+
+		case <-s.closeC:
+			return
+
 		default:
-			conn := s.Conn()
-			mt, msg, err := conn.ReadMessage()
+		}
+
+		conn := s.Conn()
+		if conn == nil {
+			return
+		}
 
-			if err != nil {
-				s.Reconnect()
-				continue
+		if err := conn.SetReadDeadline(time.Now().Add(s.options.ReadTimeout)); err != nil {
+			log.WithError(err).Warn("websocket set read deadline error")
+		}
+
+		mt, msg, err := conn.ReadMessage()
+		if err != nil {
+			if s.isClosed() {
+				return
 			}
 
-			if mt != websocket.TextMessage {
-				continue
+			_ = conn.Close()
+
+			// the subscriptions added while reconnecting are sent by the replay
+			s.mu.Lock()
+			if s.conn == conn {
+				s.conn = nil
 			}
+			s.mu.Unlock()
+
+			s.EmitError(err)
+			s.EmitDisconnected(conn)
+
+			if !s.reconnect(ctx) {
+				return
+			}
+			continue
+		}
 
-			s.EmitMessage(msg)
+		// any message proves the connection is alive
+		if err := conn.SetReadDeadline(time.Now().Add(s.options.ReadTimeout)); err != nil {
+			log.WithError(err).Warn("websocket set read deadline error")
 		}
+
+		if mt != websocket.TextMessage {
+			continue
+		}
+
+		s.EmitMessage(msg)
 	}
 }
 
@@ -68,29 +242,202 @@ func (s *WebsocketClientBase) Connect(ctx context.Context) error {
 	return nil
 }
 
+// Reconnect closes the current connection, the listener reconnects with backoff.
 func (s *WebsocketClientBase) Reconnect() {
+	if conn := s.Conn(); conn != nil {
+		_ = conn.Close()
+	}
+}
+
+// Close closes the connection and stops the reconnection.
+func (s *WebsocketClientBase) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closeC)
+	})
+
+	s.mu.Lock()
+	conn := s.conn
+	s.conn = nil
+	if conn == nil {
+		conn = s.connectingConn
+	}
+	s.connectingConn = nil
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	s.writeMu.Lock()
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeTimeout))
+	s.writeMu.Unlock()
+	return conn.Close()
+}
+
+func (s *WebsocketClientBase) isClosed() bool {
 	select {
-	case s.reconnectC <- struct{}{}:
+	case <-s.closeC:
+		return true
 	default:
+		return false
+	}
+}
+
+// reconnect retries the connection with exponential backoff until it's connected, the context is done or the client is closed.
+func (s *WebsocketClientBase) reconnect(ctx context.Context) bool {
+	for attempt := 0; ; attempt++ {
+		delay := s.options.backoff(attempt)
+		log.Infof("websocket reconnecting in %s (attempt %d)", delay, attempt+1)
+
+		select {
+		case <-ctx.Done():
+			return false
+
+		case <-s.closeC:
+			return false
+
+		case <-time.After(delay):
+		}
+
+		if err := s.connect(ctx); err != nil {
+			log.WithError(err).Warn("websocket reconnect error")
+			s.EmitError(err)
+			continue
+		}
+
+		return true
 	}
 }
 
 func (s *WebsocketClientBase) connect(ctx context.Context) error {
-	dialer := websocket.DefaultDialer
-	conn, _, err := dialer.DialContext(ctx, s.baseURL, nil)
+	url := s.baseURL
+	if s.urlProvider != nil {
+		var err error
+		url, err = s.urlProvider(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, s.options.ConnectTimeout)
+	defer cancel()
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = s.options.ConnectTimeout
+	conn, _, err := dialer.DialContext(dialCtx, url, nil)
 	if err != nil {
 		return err
 	}
 
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(s.options.ReadTimeout))
+	})
+
 	s.mu.Lock()
-	s.conn = conn
+	if s.isClosed() {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return ErrWebsocketNotConnected
+	}
+
+	s.connectingConn = conn
 	s.mu.Unlock()
 
+	// the connected callbacks send the authentication before the subscriptions
 	s.EmitConnected(conn)
 
+	// the connection is set after the replay, the subscriptions added during the replay are replayed in the next round
+	var replayed []interface{}
+	for {
+		s.mu.Lock()
+		pending := pendingSubscriptions(s.subscriptions, replayed)
+		if len(pending) == 0 {
+			closed := s.isClosed()
+			s.connectingConn = nil
+			if !closed {
+				s.conn = conn
+			}
+			s.mu.Unlock()
+
+			if closed {
+				_ = conn.Close()
+				return ErrWebsocketNotConnected
+			}
+			break
+		}
+		s.mu.Unlock()
+
+		for _, request := range pending {
+			if err := s.writeJSON(conn, request); err != nil {
+				s.EmitError(err)
+			}
+		}
+		replayed = append(replayed, pending...)
+	}
+
+	if s.options.PingInterval > 0 {
+		go s.ping(ctx, conn)
+	}
+
 	return nil
 }
 
+// pendingSubscriptions returns the subscriptions which are not replayed yet
+func pendingSubscriptions(subscriptions, replayed []interface{}) (pending []interface{}) {
+	for _, sub := range subscriptions {
+		found := false
+		for _, r := range replayed {
+			if reflect.DeepEqual(sub, r) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			pending = append(pending, sub)
+		}
+	}
+	return pending
+}
+
+func (s *WebsocketClientBase) ping(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(s.options.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-s.closeC:
+			return
+
+		case <-ticker.C:
+			if s.Conn() != conn {
+				// the connection has been replaced
+				return
+			}
+
+			if err := s.sendPing(conn); err != nil {
+				// the read deadline will close the dead connection
+				log.WithError(err).Warn("websocket ping error")
+				return
+			}
+		}
+	}
+}
+
+func (s *WebsocketClientBase) sendPing(conn *websocket.Conn) error {
+	if s.pingFunc == nil {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.pingFunc(conn)
+}
+
 func (s *WebsocketClientBase) Conn() *websocket.Conn {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebsocketClientOptions_backoff(t *testing.T) {
+	options := WebsocketClientOptions{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second} {
+		for i := 0; i < 100; i++ {
+			d := options.backoff(attempt)
+			assert.True(t, d >= expected/2 && d <= expected, "attempt %d: %s is not in [%s, %s]", attempt, d, expected/2, expected)
+		}
+	}
+
+	// the large attempt should not overflow
+	d := options.backoff(100)
+	assert.True(t, d >= 5*time.Second && d <= 10*time.Second)
+}
+
+type testWebsocketServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []string
+	conns    []*websocket.Conn
+}
+
+func newTestWebsocketServer() *testWebsocketServer {
+	s := &testWebsocketServer{}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.messages = append(s.messages, string(msg))
+			s.mu.Unlock()
+		}
+	}))
+	return s
+}
+
+func (s *testWebsocketServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.Server.URL, "http")
+}
+
+func (s *testWebsocketServer) Messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+// dropConnections closes the server side connections to simulate a network failure
+func (s *testWebsocketServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+}
+
+func TestWebsocketClientBase_Reconnect(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	client := NewWebsocketClientBase(server.URL(), WebsocketClientOptions{
+		ConnectTimeout: time.Second,
+		MinBackoff:     10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		PingInterval:   time.Second,
+		ReadTimeout:    5 * time.Second,
+	})
+
+	connectedC := make(chan struct{}, 2)
+	client.OnConnected(func(conn *websocket.Conn) {
+		assert.NoError(t, client.WriteJSON(map[string]string{"op": "login"}))
+		connectedC <- struct{}{}
+	})
+
+	var disconnected int32
+	client.OnDisconnected(func(conn *websocket.Conn) {
+		atomic.AddInt32(&disconnected, 1)
+	})
+
+	assert.NoError(t, client.AddSubscription(map[string]string{"op": "subscribe"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, client.Connect(ctx))
+	<-connectedC
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	server.dropConnections()
+
+	select {
+	case <-connectedC:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the client is not reconnected")
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) == 4
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// the login is sent before the subscriptions on every connection
+	assert.Equal(t, []string{
+		`{"op":"login"}`, `{"op":"subscribe"}`,
+		`{"op":"login"}`, `{"op":"subscribe"}`,
+	}, trimMessages(server.Messages()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&disconnected))
+
+	assert.NoError(t, client.Close())
+	assert.Equal(t, ErrWebsocketNotConnected, client.WriteJSON(map[string]string{"op": "ping"}))
+}
+
+func trimMessages(messages []string) (trimmed []string) {
+	for _, m := range messages {
+		trimmed = append(trimmed, strings.TrimSpace(m))
+	}
+	return trimmed
+}
+
+func TestWebsocketClientBase_Subscription(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	client := NewWebsocketClientBase(server.URL(), DefaultWebsocketClientOptions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, client.Connect(ctx))
+	defer client.Close()
+
+	// the subscription requests are sent immediately after connecting
+	assert.NoError(t, client.AddSubscription(map[string]string{"op": "subscribe", "channel": "trades"}))
+	assert.NoError(t, client.RemoveSubscription(
+		map[string]string{"op": "subscribe", "channel": "trades"},
+		map[string]string{"op": "unsubscribe", "channel": "trades"},
+	))
+
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{
+		`{"channel":"trades","op":"subscribe"}`,
+		`{"channel":"trades","op":"unsubscribe"}`,
+	}, trimMessages(server.Messages()))
+
+	client.mu.Lock()
+	assert.Empty(t, client.subscriptions)
+	client.mu.Unlock()
+}
+
+func TestWebsocketClientBase_SubscriptionWhileConnecting(t *testing.T) {
+	server := newTestWebsocketServer()
+	defer server.Close()
+
+	client := NewWebsocketClientBase(server.URL(), DefaultWebsocketClientOptions)
+	assert.NoError(t, client.AddSubscription(map[string]string{"op": "subscribe", "channel": "trades"}))
+
+	client.OnConnected(func(conn *websocket.Conn) {
+		// the subscription added before the login is done is not sent immediately
+		assert.NoError(t, client.AddSubscription(map[string]string{"op": "subscribe", "channel": "orders"}))
+		assert.NoError(t, client.WriteJSON(map[string]string{"op": "login"}))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, client.Connect(ctx))
+	defer client.Close()
+
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) == 3
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// the subscriptions are sent once after the login
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{
+		`{"op":"login"}`,
+		`{"channel":"trades","op":"subscribe"}`,
+		`{"channel":"orders","op":"subscribe"}`,
+	}, trimMessages(server.Messages()))
+}
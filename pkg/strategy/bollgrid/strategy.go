@@ -158,11 +158,11 @@ func (s *Strategy) generateGridBuyOrders(session *bbgo.ExchangeSession) ([]types
 			Side:        types.SideTypeBuy,
 			Type:        types.OrderTypeLimit,
 			Market:      s.Market,
-			Quantity:    s.Quantity,
-			Price:       price,
+			Quantity:    fixedpoint.NewFromFloat(s.Quantity),
+			Price:       fixedpoint.NewFromFloat(price),
 			TimeInForce: "GTC",
 		}
-		quoteQuantity := fixedpoint.NewFromFloat(order.Quantity).MulFloat64(price)
+		quoteQuantity := order.Quantity.MulFloat64(price)
 		if quoteBalance < quoteQuantity {
 			log.Infof("quote balance %f is not enough, stop generating buy orders", quoteBalance.Float64())
 			break
@@ -219,11 +219,11 @@ func (s *Strategy) generateGridSellOrders(session *bbgo.ExchangeSession) ([]type
 			Side:        types.SideTypeSell,
 			Type:        types.OrderTypeLimit,
 			Market:      s.Market,
-			Quantity:    s.Quantity,
-			Price:       price,
+			Quantity:    fixedpoint.NewFromFloat(s.Quantity),
+			Price:       fixedpoint.NewFromFloat(price),
 			TimeInForce: "GTC",
 		}
-		baseQuantity := fixedpoint.NewFromFloat(order.Quantity)
+		baseQuantity := order.Quantity
 		if baseBalance < baseQuantity {
 			log.Infof("base balance %f is not enough, stop generating sell orders", baseBalance.Float64())
 			break
@@ -278,10 +278,10 @@ func (s *Strategy) submitReverseOrder(order types.Order) {
 
 	switch side {
 	case types.SideTypeSell:
-		price += s.ProfitSpread.Float64()
+		price += s.ProfitSpread
 
 	case types.SideTypeBuy:
-		price -= s.ProfitSpread.Float64()
+		price -= s.ProfitSpread
 
 	}
 
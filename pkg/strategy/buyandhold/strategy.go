@@ -102,7 +102,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			Market:   market,
 			Side:     types.SideTypeBuy,
 			Type:     types.OrderTypeMarket,
-			Quantity: quantity,
+			Quantity: fixedpoint.NewFromFloat(quantity),
 		})
 		if err != nil {
 			log.WithError(err).Error("submit order error")
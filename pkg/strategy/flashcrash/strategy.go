@@ -9,6 +9,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/indicator"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
@@ -87,8 +88,8 @@ func (s *Strategy) updateBidOrders(orderExecutor bbgo.OrderExecutor, session *bb
 			Side:        types.SideTypeBuy,
 			Type:        types.OrderTypeLimit,
 			Market:      s.Market,
-			Quantity:    s.BaseQuantity,
-			Price:       startPrice,
+			Quantity:    fixedpoint.NewFromFloat(s.BaseQuantity),
+			Price:       fixedpoint.NewFromFloat(startPrice),
 			TimeInForce: "GTC",
 		})
 
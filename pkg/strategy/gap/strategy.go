@@ -114,8 +114,8 @@ func (s *Strategy) handleTradeUpdate(trade types.Trade) {
 		s.state.AccumulatedFees = make(map[string]fixedpoint.Value)
 	}
 
-	s.state.AccumulatedFees[trade.FeeCurrency] += fixedpoint.NewFromFloat(trade.Fee)
-	s.state.AccumulatedVolume += fixedpoint.NewFromFloat(trade.Quantity)
+	s.state.AccumulatedFees[trade.FeeCurrency] += trade.Fee
+	s.state.AccumulatedVolume += trade.Quantity
 	log.Infof("accumulated fee: %f %s", s.state.AccumulatedFees[trade.FeeCurrency].Float64(), trade.FeeCurrency)
 }
 
@@ -290,8 +290,8 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 					Symbol:      s.Symbol,
 					Side:        types.SideTypeBuy,
 					Type:        types.OrderTypeLimit,
-					Quantity:    quantity,
-					Price:       price,
+					Quantity:    fixedpoint.NewFromFloat(quantity),
+					Price:       fixedpoint.NewFromFloat(price),
 					Market:      s.tradingMarket,
 					TimeInForce: "GTC",
 					GroupID:     s.groupID,
@@ -299,8 +299,8 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 					Symbol:      s.Symbol,
 					Side:        types.SideTypeSell,
 					Type:        types.OrderTypeLimit,
-					Quantity:    quantity,
-					Price:       price,
+					Quantity:    fixedpoint.NewFromFloat(quantity),
+					Price:       fixedpoint.NewFromFloat(price),
 					Market:      s.tradingMarket,
 					TimeInForce: "GTC",
 					GroupID:     s.groupID,
@@ -202,8 +202,8 @@ func (s *Strategy) generateGridSellOrders(session *bbgo.ExchangeSession) ([]type
 			Side:        types.SideTypeSell,
 			Type:        types.OrderTypeLimit,
 			Market:      s.Market,
-			Quantity:    quantity.Float64(),
-			Price:       price.Float64(),
+			Quantity:    quantity,
+			Price:       price,
 			TimeInForce: "GTC",
 			GroupID:     s.groupID,
 		})
@@ -296,8 +296,8 @@ func (s *Strategy) generateGridBuyOrders(session *bbgo.ExchangeSession) ([]types
 			Side:        types.SideTypeBuy,
 			Type:        types.OrderTypeLimit,
 			Market:      s.Market,
-			Quantity:    quantity.Float64(),
-			Price:       price.Float64(),
+			Quantity:    quantity,
+			Price:       price,
 			TimeInForce: "GTC",
 			GroupID:     s.groupID,
 		})
@@ -404,18 +404,18 @@ func (s *Strategy) handleFilledOrder(filledOrder types.Order) {
 
 	switch side {
 	case types.SideTypeSell:
-		price += s.ProfitSpread.Float64()
+		price += s.ProfitSpread
 	case types.SideTypeBuy:
-		price -= s.ProfitSpread.Float64()
+		price -= s.ProfitSpread
 	}
 
 	if s.FixedAmount > 0 {
-		quantity = s.FixedAmount.Float64() / price
+		quantity = s.FixedAmount.Div(price)
 	} else if s.Long {
 		// long = use the same amount to buy more quantity back
 		// the original amount
-		var amount = filledOrder.Price * filledOrder.Quantity
-		quantity = amount / price
+		var amount = filledOrder.Price.Mul(filledOrder.Quantity)
+		quantity = amount.Div(price)
 	}
 
 	submitOrder := types.SubmitOrder{
@@ -452,9 +452,9 @@ func (s *Strategy) handleFilledOrder(filledOrder types.Order) {
 			if buyOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
 				// use base asset quantity here
 				baseProfit := buyOrder.Quantity - filledOrder.Quantity
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(baseProfit)
+				s.state.AccumulativeArbitrageProfit += baseProfit
 				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s", s.Symbol,
-					baseProfit, s.Market.BaseCurrency,
+					baseProfit.Float64(), s.Market.BaseCurrency,
 					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.BaseCurrency,
 				)
 			}
@@ -463,9 +463,9 @@ func (s *Strategy) handleFilledOrder(filledOrder types.Order) {
 			if sellOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
 				// use base asset quantity here
 				baseProfit := filledOrder.Quantity - sellOrder.Quantity
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(baseProfit)
+				s.state.AccumulativeArbitrageProfit += baseProfit
 				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s", s.Symbol,
-					baseProfit, s.Market.BaseCurrency,
+					baseProfit.Float64(), s.Market.BaseCurrency,
 					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.BaseCurrency,
 				)
 			}
@@ -475,20 +475,20 @@ func (s *Strategy) handleFilledOrder(filledOrder types.Order) {
 		case types.SideTypeSell:
 			if buyOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
 				// use base asset quantity here
-				quoteProfit := (filledOrder.Quantity * filledOrder.Price) - (buyOrder.Quantity * buyOrder.Price)
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(quoteProfit)
+				quoteProfit := filledOrder.Quantity.Mul(filledOrder.Price) - buyOrder.Quantity.Mul(buyOrder.Price)
+				s.state.AccumulativeArbitrageProfit += quoteProfit
 				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s", s.Symbol,
-					quoteProfit, s.Market.QuoteCurrency,
+					quoteProfit.Float64(), s.Market.QuoteCurrency,
 					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.QuoteCurrency,
 				)
 			}
 		case types.SideTypeBuy:
 			if sellOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
 				// use base asset quantity here
-				quoteProfit := (sellOrder.Quantity * sellOrder.Price) - (filledOrder.Quantity * filledOrder.Price)
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(quoteProfit)
+				quoteProfit := sellOrder.Quantity.Mul(sellOrder.Price) - filledOrder.Quantity.Mul(filledOrder.Price)
+				s.state.AccumulativeArbitrageProfit += quoteProfit
 				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s", s.Symbol,
-					quoteProfit, s.Market.QuoteCurrency,
+					quoteProfit.Float64(), s.Market.QuoteCurrency,
 					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.QuoteCurrency,
 				)
 			}
@@ -571,7 +571,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		}
 
 		log.Infof("canceling active orders...")
-		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
+		if err := bbgo.CancelOrdersByGroup(ctx, session.Exchange, s.groupID, s.activeOrders.Orders()...); err != nil {
 			log.WithError(err).Errorf("cancel order error")
 		}
 	})
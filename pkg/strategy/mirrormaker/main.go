@@ -74,6 +74,14 @@ func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
 	sourceSession.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
 }
 
+// CrossRequiredCapabilities requires the source book and the limit orders on the maker session
+func (s *Strategy) CrossRequiredCapabilities() map[string]types.ExchangeCapabilities {
+	return map[string]types.ExchangeCapabilities{
+		s.SourceExchange: {Channels: []types.Channel{types.BookChannel}},
+		s.MakerExchange:  {OrderTypes: []types.OrderType{types.OrderTypeLimit}},
+	}
+}
+
 func (s *Strategy) updateQuote(ctx context.Context) {
 	if err := s.makerSession.Exchange.CancelOrders(ctx, s.activeMakerOrders.Orders()...); err != nil {
 		log.WithError(err).Errorf("can not cancel orders")
@@ -83,6 +91,11 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 	// avoid unlock issue
 	time.Sleep(100 * time.Millisecond)
 
+	// the source book is invalidated, stop quoting until the next snapshot comes
+	if !s.book.IsReady() {
+		return
+	}
+
 	sourceBook := s.book.Get()
 	if len(sourceBook.Bids) == 0 || len(sourceBook.Asks) == 0 {
 		return
@@ -131,8 +144,8 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 				Symbol:      s.Symbol,
 				Type:        types.OrderTypeLimit,
 				Side:        types.SideTypeBuy,
-				Price:       bidPrice.Float64(),
-				Quantity:    bidQuantity.Float64(),
+				Price:       bidPrice,
+				Quantity:    bidQuantity,
 				TimeInForce: "GTC",
 			})
 
@@ -150,8 +163,8 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 				Symbol:      s.Symbol,
 				Type:        types.OrderTypeLimit,
 				Side:        types.SideTypeSell,
-				Price:       askPrice.Float64(),
-				Quantity:    askQuantity.Float64(),
+				Price:       askPrice,
+				Quantity:    askQuantity,
 				TimeInForce: "GTC",
 			})
 			makerQuota.Commit()
@@ -188,7 +201,7 @@ func (s *Strategy) handleTradeUpdate(trade types.Trade) {
 	if s.orderStore.Exists(trade.OrderID) {
 		log.Infof("identified trade %d with an existing order: %d", trade.ID, trade.OrderID)
 
-		q := fixedpoint.NewFromFloat(trade.Quantity)
+		q := trade.Quantity
 		if trade.Side == types.SideTypeSell {
 			q = -q
 		}
@@ -198,7 +211,7 @@ func (s *Strategy) handleTradeUpdate(trade types.Trade) {
 		pos := s.Position.AtomicLoad()
 		log.Warnf("position changed: %f", pos.Float64())
 
-		s.lastPrice = trade.Price
+		s.lastPrice = trade.Price.Float64()
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -42,7 +43,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			Symbol:   kline.Symbol,
 			Side:     types.SideTypeBuy,
 			Type:     types.OrderTypeMarket,
-			Quantity: 0.01,
+			Quantity: fixedpoint.NewFromFloat(0.01),
 		})
 
 		if err != nil {
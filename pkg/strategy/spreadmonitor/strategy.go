@@ -11,6 +11,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -56,11 +57,19 @@ type StrategyConfig struct {
 	SourceExchangeSide     string  `json:"sourceExchangeSide"`
 	SourceExchangeMarket   string  `json:"sourceExchangeMarket"`
 
+	// SourceExchangePriceMode is one of best, mid, microprice and vwap, the default is best.
+	// The vwap mode uses the average execution price of SourceExchangeQuantity on the side.
+	SourceExchangePriceMode string  `json:"sourceExchangePriceMode,omitempty"`
+	SourceExchangeQuantity  float64 `json:"sourceExchangeQuantity,omitempty"`
+
 	TargetExchange         string  `json:"targetExchange"`
 	TargetExchangeTakerFee float64 `json:"targetExchangeTakerFee,omitempty"`
 	TargetExchangeSide     string  `json:"targetExchangeSide"`
 	TargetExchangeMarket   string  `json:"targetExchangeMarket"`
 
+	TargetExchangePriceMode string  `json:"targetExchangePriceMode,omitempty"`
+	TargetExchangeQuantity  float64 `json:"targetExchangeQuantity,omitempty"`
+
 	UpperLimitMessage   string `json:"upperLimitMessage,omitempty"`
 	SpreadUpperLimitBps int64  `json:"spreadUpperLimitBps,omitempty"`
 	AboveLimitDuration  time.Duration
@@ -70,6 +79,9 @@ type StrategyConfig struct {
 
 	SlackChannelName string `json:"slackChannelName"`
 	QuietDuration    time.Duration
+
+	// UseBookTicker subscribes the best bid/ask feed instead of the full order book
+	UseBookTicker bool `json:"useBookTicker,omitempty"`
 }
 
 func (c *StrategyConfig) UnmarshalJSON(data []byte) error {
@@ -117,9 +129,24 @@ func (c *StrategyConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (c *StrategyConfig) Validate() error {
+	if err := validatePriceMode(c.SourceExchangePriceMode, c.SourceExchangeQuantity); err != nil {
+		return fmt.Errorf("source exchange %s: %w", c.SourceExchange, err)
+	}
+
+	if err := validatePriceMode(c.TargetExchangePriceMode, c.TargetExchangeQuantity); err != nil {
+		return fmt.Errorf("target exchange %s: %w", c.TargetExchange, err)
+	}
+
+	return nil
+}
+
 type message struct {
 	channelName string
 	msg         string
+
+	// sessions are the sessions that the message is about
+	sessions []string
 }
 
 type Strategy struct {
@@ -135,6 +162,12 @@ func (s *Strategy) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &c); err != nil {
 		return fmt.Errorf("failed to unmarshal %s config: %w", s.ID(), err)
 	}
+	for i := range c {
+		if err := c[i].Validate(); err != nil {
+			return fmt.Errorf("invalid %s config: %w", s.ID(), err)
+		}
+	}
+
 	s.Config = c
 	return nil
 }
@@ -158,11 +191,27 @@ func (s *Strategy) startNotifier(ctx context.Context) {
 		case <-tk.C:
 			s.Notify("i'm still alive.")
 		case m := <-s.notifyC:
-			s.NotifyTo(m.channelName, m.msg)
+			s.notifyMessage(m)
 		}
 	}
 }
 
+// notifyMessage sends the message through the session scoped notifiability, so that the message is
+// suppressed or downgraded if one of its sessions is in the maintenance window.
+func (s *Strategy) notifyMessage(m message) {
+	var notifiability = *s.Notifiability
+
+	now := time.Now()
+	for _, session := range m.sessions {
+		if _, ok := s.MaintenanceSchedule.Lookup(session, now); ok {
+			notifiability = s.Notifiability.WithSession(session)
+			break
+		}
+	}
+
+	notifiability.NotifyTo(m.channelName, m.msg)
+}
+
 func (s *Strategy) enqueueMessage(msg message) error {
 	select {
 	case s.notifyC <- msg:
@@ -188,28 +237,36 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 		}
 
 		targetStream := target.Stream
-		targetStream.Subscribe(types.BookChannel, c.TargetExchangeMarket, types.SubscribeOptions{})
 		targetBook := types.NewStreamBook(c.TargetExchangeMarket)
-		targetBook.BindStream(targetStream)
+		bindBook(targetStream, targetBook, c.UseBookTicker)
 
 		sourceStream := source.Stream
 		sourceStream.SetPublicOnly()
-		sourceStream.Subscribe(types.BookChannel, c.SourceExchangeMarket, types.SubscribeOptions{})
 		sourceBook := types.NewStreamBook(c.SourceExchangeMarket)
-		sourceBook.BindStream(sourceStream)
+		bindBook(sourceStream, sourceBook, c.UseBookTicker)
 
 		checkLowerLimit := compare(lessEqual(c.SpreadLowerLimitBps), c.BelowLimitDuration)
-		lowerLimitAlert := s.throttledNotifier(c.SlackChannelName, c.QuietDuration)
+		lowerLimitAlert := s.throttledNotifier(c.SlackChannelName, c.QuietDuration, c.SourceExchange, c.TargetExchange)
 
 		checkUpperLimit := compare(greaterThan(c.SpreadUpperLimitBps), c.AboveLimitDuration)
-		upperLimitAlert := s.throttledNotifier(c.SlackChannelName, c.QuietDuration)
+		upperLimitAlert := s.throttledNotifier(c.SlackChannelName, c.QuietDuration, c.SourceExchange, c.TargetExchange)
 
 		sourceBook.OnUpdate(func(sb *types.OrderBook) {
+			// stop alerting while any of the books is invalidated, until the next snapshot comes
+			if !sourceBook.IsReady() || !targetBook.IsReady() {
+				return
+			}
+
 			if !sourceTargetReady(sb, targetBook) {
 				return
 			}
 
-			spreadBps := toBps(sourceTargetSpread(c, sb, targetBook))
+			spread, ok := sourceTargetSpread(c, sb, targetBook)
+			if !ok {
+				return
+			}
+
+			spreadBps := toBps(spread)
 
 			checkLowerLimit(spreadBps, func() {
 				msg := fmt.Sprintf("%s.\nspread %d bps < %d bps", c.LowerLimitMessage, spreadBps, c.SpreadLowerLimitBps)
@@ -225,12 +282,25 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 	return nil
 }
 
-func (s *Strategy) throttledNotifier(channelName string, quietDuration time.Duration) func(msg string) {
+// bindBook subscribes the book of the market, the book ticker only maintains the best bid and ask,
+// which is enough for calculating the spread.
+func bindBook(stream types.Stream, book *types.StreamOrderBook, useBookTicker bool) {
+	if useBookTicker {
+		stream.Subscribe(types.BookTickerChannel, book.Symbol, types.SubscribeOptions{})
+		book.BindBookTickerStream(stream)
+		return
+	}
+
+	stream.Subscribe(types.BookChannel, book.Symbol, types.SubscribeOptions{})
+	book.BindStream(stream)
+}
+
+func (s *Strategy) throttledNotifier(channelName string, quietDuration time.Duration, sessions ...string) func(msg string) {
 	var lastNotifyTime time.Time
 	return func(msg string) {
 		now := time.Now()
 		if now.Sub(lastNotifyTime) > quietDuration {
-			if err := s.enqueueMessage(message{channelName: channelName, msg: msg}); err != nil {
+			if err := s.enqueueMessage(message{channelName: channelName, msg: msg, sessions: sessions}); err != nil {
 				log.Errorf("failed to enqueue the msg to %s", channelName)
 				return
 			}
@@ -285,27 +355,84 @@ func sourceTargetReady(sourceBook *types.OrderBook, targetBook *types.StreamOrde
 	return sb && sa && tb && ta
 }
 
-func sourceTargetSpread(c StrategyConfig, sourceBook *types.OrderBook, targetBook *types.StreamOrderBook) float64 {
+// sourceTargetSpread returns false if any of the books is not deep enough for the vwap price
+func sourceTargetSpread(c StrategyConfig, sourceBook *types.OrderBook, targetBook *types.StreamOrderBook) (float64, bool) {
 	t := targetBook.Get()
-	sourcePrice := getPrice(sourceBook, c.SourceExchangeSide, c.SourceExchangeTakerFee)
-	targetPrice := getPrice(&t, c.TargetExchangeSide, c.TargetExchangeTakerFee)
+	sourcePrice, ok := getPrice(sourceBook, c.SourceExchangeSide, c.SourceExchangePriceMode, c.SourceExchangeQuantity, c.SourceExchangeTakerFee)
+	if !ok {
+		return 0, false
+	}
 
-	return targetPrice / sourcePrice
+	targetPrice, ok := getPrice(&t, c.TargetExchangeSide, c.TargetExchangePriceMode, c.TargetExchangeQuantity, c.TargetExchangeTakerFee)
+	if !ok {
+		return 0, false
+	}
+
+	return targetPrice / sourcePrice, true
+}
+
+const (
+	PriceModeBest       = "best"
+	PriceModeMid        = "mid"
+	PriceModeMicroPrice = "microprice"
+	PriceModeVWAP       = "vwap"
+)
+
+func validatePriceMode(mode string, quantity float64) error {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", PriceModeBest, PriceModeMid, PriceModeMicroPrice:
+		return nil
+	case PriceModeVWAP:
+		if quantity <= 0 {
+			return fmt.Errorf("vwap price mode requires a positive quantity")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported price mode: %s", mode)
+	}
 }
 
 // actually we don't care about the precision loss here so using float.
 // bps is just a really small number.
-func getPrice(book *types.OrderBook, side string, takerFee float64) float64 {
+func getPrice(book *types.OrderBook, side, mode string, quantity, takerFee float64) (float64, bool) {
 	s := strings.ToLower(strings.TrimSpace(side))
 
-	var pv types.PriceVolume
-
+	// hitting the bids is selling, and lifting the asks is buying
+	takerSide := types.SideTypeBuy
 	if s == "bid" {
-		pv, _ = book.BestBid()
+		takerSide = types.SideTypeSell
 	} else {
-		pv, _ = book.BestAsk()
 		takerFee = -1 * takerFee
 	}
 
-	return pv.Price.Float64() * (1 + takerFee)
+	var price fixedpoint.Value
+	var ok bool
+
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case PriceModeMid:
+		price, ok = book.MidPrice()
+
+	case PriceModeMicroPrice:
+		price, ok = book.MicroPrice()
+
+	case PriceModeVWAP:
+		var result types.ExecutionPrice
+		result, ok = book.ExecutionPriceByQuantity(takerSide, fixedpoint.NewFromFloat(quantity))
+		price = result.AveragePrice
+
+	default:
+		var pv types.PriceVolume
+		if takerSide == types.SideTypeSell {
+			pv, ok = book.BestBid()
+		} else {
+			pv, ok = book.BestAsk()
+		}
+		price = pv.Price
+	}
+
+	if !ok {
+		return 0, false
+	}
+
+	return price.Float64() * (1 + takerFee), true
 }
@@ -0,0 +1,68 @@
+package spreadmonitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+	"github.com/ycdesu/spreaddog/pkg/types"
+)
+
+func Test_getPrice(t *testing.T) {
+	book := &types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(99.0), Volume: fixedpoint.NewFromFloat(3.0)},
+			{Price: fixedpoint.NewFromFloat(98.0), Volume: fixedpoint.NewFromFloat(1.0)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(102.0), Volume: fixedpoint.NewFromFloat(1.0)},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		book     *types.OrderBook
+		side     string
+		mode     string
+		quantity float64
+		takerFee float64
+		price    float64
+		ok       bool
+	}{
+		{name: "default mode is best", book: book, side: "bid", mode: "", price: 99, ok: true},
+		{name: "best bid", book: book, side: "bid", mode: PriceModeBest, price: 99, ok: true},
+		{name: "best ask", book: book, side: "ask", mode: PriceModeBest, price: 101, ok: true},
+		{name: "best bid with fee", book: book, side: "bid", mode: PriceModeBest, takerFee: 0.001, price: 99 * 1.001, ok: true},
+		{name: "best ask with fee", book: book, side: "ask", mode: PriceModeBest, takerFee: 0.001, price: 101 * 0.999, ok: true},
+		{name: "mid", book: book, side: "bid", mode: PriceModeMid, price: 100, ok: true},
+		{name: "mode is case insensitive", book: book, side: " Ask ", mode: " MID ", price: 100, ok: true},
+		// (99 * 1 + 101 * 3) / 4
+		{name: "microprice", book: book, side: "ask", mode: PriceModeMicroPrice, price: 100.5, ok: true},
+		// (101 + 102) / 2
+		{name: "vwap ask", book: book, side: "ask", mode: PriceModeVWAP, quantity: 2, price: 101.5, ok: true},
+		// (99 * 3 + 98) / 4
+		{name: "vwap bid", book: book, side: "bid", mode: PriceModeVWAP, quantity: 4, price: 98.75, ok: true},
+		{name: "vwap beyond the depth", book: book, side: "ask", mode: PriceModeVWAP, quantity: 3},
+		{name: "empty book", book: &types.OrderBook{Symbol: "BTCUSDT"}, side: "bid", mode: PriceModeBest},
+		{name: "mid of one sided book", book: &types.OrderBook{Symbol: "BTCUSDT", Bids: book.Bids}, side: "bid", mode: PriceModeMid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			price, ok := getPrice(test.book, test.side, test.mode, test.quantity, test.takerFee)
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.price, price, 1e-6)
+		})
+	}
+}
+
+func Test_validatePriceMode(t *testing.T) {
+	assert.NoError(t, validatePriceMode("", 0))
+	assert.NoError(t, validatePriceMode(PriceModeMicroPrice, 0))
+	assert.NoError(t, validatePriceMode(PriceModeVWAP, 1))
+	assert.Error(t, validatePriceMode(PriceModeVWAP, 0))
+	assert.Error(t, validatePriceMode("last", 0))
+}
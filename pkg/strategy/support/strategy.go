@@ -118,7 +118,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			Market:           market,
 			Side:             types.SideTypeBuy,
 			Type:             types.OrderTypeMarket,
-			Quantity:         quantity,
+			Quantity:         fixedpoint.NewFromFloat(quantity),
 			MarginSideEffect: s.MarginOrderSideEffect,
 		}
 
@@ -138,8 +138,8 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 				Market:   market,
 				Type:     types.OrderTypeLimit,
 				Side:     types.SideTypeSell,
-				Price:    targetPrice,
-				Quantity: targetQuantity,
+				Price:    fixedpoint.NewFromFloat(targetPrice),
+				Quantity: fixedpoint.NewFromFloat(targetQuantity),
 
 				MarginSideEffect: target.MarginOrderSideEffect,
 				TimeInForce:      "GTC",
@@ -8,6 +8,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/ycdesu/spreaddog/pkg/bbgo"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/types"
 )
 
@@ -137,7 +138,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 					Market:   s.Market,
 					Side:     types.SideTypeSell,
 					Type:     types.OrderTypeMarket,
-					Quantity: quantity,
+					Quantity: fixedpoint.NewFromFloat(quantity),
 				})
 				if err != nil {
 					log.WithError(err).Error("submit order error")
@@ -153,7 +154,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 					Market:   s.Market,
 					Side:     types.SideTypeBuy,
 					Type:     types.OrderTypeMarket,
-					Quantity: quantity,
+					Quantity: fixedpoint.NewFromFloat(quantity),
 				})
 				if err != nil {
 					log.WithError(err).Error("submit order error")
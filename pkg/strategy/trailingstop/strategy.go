@@ -91,6 +91,27 @@ func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
 	targetSession.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval.String()})
 }
 
+// stopOrderType returns the stop order type of the configured order type, the stop market order is the default
+func (s *Strategy) stopOrderType() types.OrderType {
+	if strings.ToLower(s.OrderType) == "limit" {
+		return types.OrderTypeStopLimit
+	}
+	return types.OrderTypeStopMarket
+}
+
+func (s *Strategy) RequiredCapabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		OrderTypes: []types.OrderType{s.stopOrderType()},
+	}
+}
+
+// CrossRequiredCapabilities requires the stop orders on the target session
+func (s *Strategy) CrossRequiredCapabilities() map[string]types.ExchangeCapabilities {
+	return map[string]types.ExchangeCapabilities{
+		s.TargetExchangeName: s.RequiredCapabilities(),
+	}
+}
+
 func (s *Strategy) clear(ctx context.Context, session *bbgo.ExchangeSession) {
 	if s.order.OrderID > 0 {
 		if err := session.Exchange.CancelOrders(ctx, s.order); err != nil {
@@ -118,13 +139,9 @@ func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor,
 	}
 
 	var price = 0.0
-	var orderType = types.OrderTypeStopMarket
+	var orderType = s.stopOrderType()
 
-	switch strings.ToLower(s.OrderType) {
-	case "market":
-		orderType = types.OrderTypeStopMarket
-	case "limit":
-		orderType = types.OrderTypeStopLimit
+	if orderType == types.OrderTypeStopLimit {
 		price = movingAveragePrice
 		if s.PriceRatio > 0 {
 			price = price * s.PriceRatio.Float64()
@@ -161,9 +178,9 @@ func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor,
 		Symbol:    s.Symbol,
 		Side:      types.SideTypeSell,
 		Type:      orderType,
-		Price:     price,
-		StopPrice: stopPrice,
-		Quantity:  quantity.Float64(),
+		Price:     fixedpoint.NewFromFloat(price),
+		StopPrice: fixedpoint.NewFromFloat(stopPrice),
+		Quantity:  quantity,
 	})
 	if err != nil {
 		log.WithError(err).Error("submit order error")
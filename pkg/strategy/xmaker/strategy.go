@@ -95,6 +95,21 @@ func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
 	makerSession.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: "1m"})
 }
 
+// CrossRequiredCapabilities requires the source book for quoting and the market orders for hedging on the source
+// session, and the limit orders for quoting on the maker session.
+func (s *Strategy) CrossRequiredCapabilities() map[string]types.ExchangeCapabilities {
+	return map[string]types.ExchangeCapabilities{
+		s.SourceExchange: {
+			Channels:   []types.Channel{types.BookChannel},
+			OrderTypes: []types.OrderType{types.OrderTypeMarket},
+		},
+		s.MakerExchange: {
+			Channels:   []types.Channel{types.KLineChannel},
+			OrderTypes: []types.OrderType{types.OrderTypeLimit},
+		},
+	}
+}
+
 func (s *Strategy) updateQuote(ctx context.Context) {
 	if err := s.makerSession.Exchange.CancelOrders(ctx, s.activeMakerOrders.Orders()...); err != nil {
 		log.WithError(err).Errorf("can not cancel orders")
@@ -104,6 +119,11 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 	// avoid unlock issue
 	time.Sleep(800 * time.Millisecond)
 
+	// the source book is invalidated, stop quoting until the next snapshot comes
+	if !s.book.IsReady() {
+		return
+	}
+
 	sourceBook := s.book.Get()
 	if len(sourceBook.Bids) == 0 || len(sourceBook.Asks) == 0 {
 		return
@@ -199,8 +219,8 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 					Symbol:      s.Symbol,
 					Type:        types.OrderTypeLimit,
 					Side:        types.SideTypeBuy,
-					Price:       bidPrice.Float64(),
-					Quantity:    bidQuantity.Float64(),
+					Price:       bidPrice,
+					Quantity:    bidQuantity,
 					TimeInForce: "GTC",
 					GroupID:     s.groupID,
 				})
@@ -223,8 +243,8 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 					Symbol:      s.Symbol,
 					Type:        types.OrderTypeLimit,
 					Side:        types.SideTypeSell,
-					Price:       askPrice.Float64(),
-					Quantity:    askQuantity.Float64(),
+					Price:       askPrice,
+					Quantity:    askQuantity,
 					TimeInForce: "GTC",
 					GroupID:     s.groupID,
 				})
@@ -299,7 +319,7 @@ func (s *Strategy) Hedge(ctx context.Context, pos fixedpoint.Value) {
 		Symbol:   s.Symbol,
 		Type:     types.OrderTypeMarket,
 		Side:     side,
-		Quantity: quantity.Float64(),
+		Quantity: quantity,
 	})
 
 	if err != nil {
@@ -321,7 +341,7 @@ func (s *Strategy) handleTradeUpdate(trade types.Trade) {
 		return
 	}
 
-	q := fixedpoint.NewFromFloat(trade.Quantity)
+	q := trade.Quantity
 	switch trade.Side {
 	case types.SideTypeSell:
 		q = -q
@@ -347,7 +367,7 @@ func (s *Strategy) handleTradeUpdate(trade types.Trade) {
 	log.Warnf("position changed: %f", pos.Float64())
 	s.Notifiability.Notify("%s position is changed to %f", s.Symbol, pos.Float64())
 
-	s.lastPrice = trade.Price
+	s.lastPrice = trade.Price.Float64()
 }
 
 func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
@@ -487,7 +507,7 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 			s.Notify("hedge position %f is saved", s.state.HedgePosition.Float64())
 		}
 
-		if err := s.makerSession.Exchange.CancelOrders(ctx, s.activeMakerOrders.Orders()...); err != nil {
+		if err := bbgo.CancelOrdersByGroup(ctx, s.makerSession.Exchange, s.groupID, s.activeMakerOrders.Orders()...); err != nil {
 			log.WithError(err).Errorf("can not cancel orders")
 		}
 	})
@@ -39,6 +39,13 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
 }
 
+func (s *Strategy) RequiredCapabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		Channels:   []types.Channel{types.BookChannel},
+		OrderTypes: []types.OrderType{types.OrderTypeLimit},
+	}
+}
+
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
 
 	s.book = types.NewStreamBook(s.Symbol)
@@ -172,8 +179,8 @@ func (s *Strategy) generateOrders(symbol string, side types.SideType, price, pri
 			Symbol:   symbol,
 			Side:     side,
 			Type:     types.OrderTypeLimit,
-			Price:    price.Float64(),
-			Quantity: volume,
+			Price:    price,
+			Quantity: fixedpoint.NewFromFloat(volume),
 		})
 
 		log.Infof("%s order: %.2f @ %f", side, volume, price.Float64())
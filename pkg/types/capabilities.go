@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExchangeCapabilities describes the optional features supported by an exchange. It's also used by the strategies to
+// describe the features they need, see ExchangeCapabilities.Missing.
+type ExchangeCapabilities struct {
+	// Margin is true if the exchange implements MarginExchange
+	Margin bool
+
+	// Futures is true if the session trades the futures markets, see FuturesExchange and ExchangeFuturesService
+	Futures bool
+
+	// Transfer is true if the exchange implements ExchangeTransferService
+	Transfer bool
+
+	// Rewards is true if the exchange implements ExchangeRewardService
+	Rewards bool
+
+	// Channels are the public channels supported by the stream
+	Channels []Channel
+
+	// OrderTypes are the order types accepted by SubmitOrders
+	OrderTypes []OrderType
+
+	// TimeInForce are the time in force values accepted by SubmitOrders, e.g., GTC, IOC and FOK
+	TimeInForce []string
+
+	// MaxOpenOrders is the max number of the open orders of a symbol, zero means unknown or unlimited
+	MaxOpenOrders int
+}
+
+func (c ExchangeCapabilities) HasChannel(channel Channel) bool {
+	for _, ch := range c.Channels {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ExchangeCapabilities) HasOrderType(orderType OrderType) bool {
+	for _, t := range c.OrderTypes {
+		if t == orderType {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ExchangeCapabilities) HasTimeInForce(timeInForce string) bool {
+	for _, tif := range c.TimeInForce {
+		if strings.EqualFold(tif, timeInForce) {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the descriptions of the required capabilities that are not supported, it returns nil if all the
+// required capabilities are supported.
+func (c ExchangeCapabilities) Missing(required ExchangeCapabilities) (missing []string) {
+	if required.Margin && !c.Margin {
+		missing = append(missing, "margin")
+	}
+	if required.Futures && !c.Futures {
+		missing = append(missing, "futures")
+	}
+	if required.Transfer && !c.Transfer {
+		missing = append(missing, "transfer")
+	}
+	if required.Rewards && !c.Rewards {
+		missing = append(missing, "rewards")
+	}
+
+	for _, ch := range required.Channels {
+		if !c.HasChannel(ch) {
+			missing = append(missing, fmt.Sprintf("channel %s", ch))
+		}
+	}
+
+	for _, t := range required.OrderTypes {
+		if !c.HasOrderType(t) {
+			missing = append(missing, fmt.Sprintf("order type %s", t))
+		}
+	}
+
+	for _, tif := range required.TimeInForce {
+		if !c.HasTimeInForce(tif) {
+			missing = append(missing, fmt.Sprintf("time in force %s", tif))
+		}
+	}
+
+	if required.MaxOpenOrders > 0 && c.MaxOpenOrders > 0 && required.MaxOpenOrders > c.MaxOpenOrders {
+		missing = append(missing, fmt.Sprintf("%d open orders (max %d)", required.MaxOpenOrders, c.MaxOpenOrders))
+	}
+
+	return missing
+}
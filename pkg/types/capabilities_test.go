@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeCapabilities_Missing(t *testing.T) {
+	c := ExchangeCapabilities{
+		Transfer:      true,
+		Channels:      []Channel{BookChannel, KLineChannel},
+		OrderTypes:    []OrderType{OrderTypeLimit, OrderTypeMarket},
+		TimeInForce:   []string{"GTC", "IOC"},
+		MaxOpenOrders: 200,
+	}
+
+	assert.Empty(t, c.Missing(ExchangeCapabilities{}))
+	assert.Empty(t, c.Missing(ExchangeCapabilities{
+		Transfer:      true,
+		Channels:      []Channel{BookChannel},
+		OrderTypes:    []OrderType{OrderTypeLimit},
+		TimeInForce:   []string{"ioc"},
+		MaxOpenOrders: 100,
+	}))
+
+	assert.Equal(t, []string{
+		"margin",
+		"futures",
+		"rewards",
+		"channel bookTicker",
+		"order type STOP_LIMIT",
+		"time in force FOK",
+		"300 open orders (max 200)",
+	}, c.Missing(ExchangeCapabilities{
+		Margin:        true,
+		Futures:       true,
+		Rewards:       true,
+		Channels:      []Channel{BookChannel, BookTickerChannel},
+		OrderTypes:    []OrderType{OrderTypeLimit, OrderTypeStopLimit},
+		TimeInForce:   []string{"GTC", "FOK"},
+		MaxOpenOrders: 300,
+	}))
+
+	// zero max open orders means the limit is unknown
+	assert.Empty(t, ExchangeCapabilities{}.Missing(ExchangeCapabilities{MaxOpenOrders: 1000}))
+}
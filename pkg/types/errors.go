@@ -0,0 +1,96 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The sentinel errors of the exchange api. The exchange adapters map their own error codes and messages to them, so
+// that the strategies could check the error with errors.Is, e.g. errors.Is(err, types.ErrInsufficientBalance)
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInvalidPrecision    = errors.New("invalid price or quantity precision")
+	ErrMarketClosed        = errors.New("market closed")
+	ErrInvalidSymbol       = errors.New("invalid symbol")
+	ErrAuthentication      = errors.New("authentication failed")
+)
+
+// ExchangeError is the error returned from the exchange api, Kind is one of the sentinel errors above and Err is the
+// original error of the exchange
+type ExchangeError struct {
+	Kind error
+	Err  error
+
+	// RetryAfter is the duration the exchange asks to wait before sending the next request, only for ErrRateLimited
+	RetryAfter time.Duration
+}
+
+func NewExchangeError(kind, err error) *ExchangeError {
+	return &ExchangeError{Kind: kind, Err: err}
+}
+
+func NewRateLimitError(retryAfter time.Duration, err error) *ExchangeError {
+	return &ExchangeError{Kind: ErrRateLimited, Err: err, RetryAfter: retryAfter}
+}
+
+func (e *ExchangeError) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Kind.Error(), e.Err.Error())
+}
+
+func (e *ExchangeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ExchangeError) Is(target error) bool {
+	return e.Kind == target
+}
+
+// RetryAfterDuration implements the interface checked by util.Retry to delay the next attempt
+func (e *ExchangeError) RetryAfterDuration() time.Duration {
+	return e.RetryAfter
+}
+
+// RetryAfter returns the duration the exchange asks to wait, the second return value is false when err is not a rate
+// limit error
+func RetryAfter(err error) (time.Duration, bool) {
+	var exchangeError *ExchangeError
+	if !errors.As(err, &exchangeError) || exchangeError.Kind != ErrRateLimited {
+		return 0, false
+	}
+
+	return exchangeError.RetryAfter, true
+}
+
+// IsRateLimitError is a util.RetryPredicator which only retries the rate limit errors
+func IsRateLimitError(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsOrderNotFoundError returns true if the order is not found, e.g., the order is already filled or canceled
+func IsOrderNotFoundError(err error) bool {
+	return errors.Is(err, ErrOrderNotFound)
+}
+
+// IsRetryableError is a util.RetryPredicator which retries the rate limit errors and the unknown errors like the
+// network errors, but not the errors that would fail again with the same request
+func IsRetryableError(err error) bool {
+	switch {
+	case errors.Is(err, ErrInsufficientBalance),
+		errors.Is(err, ErrOrderNotFound),
+		errors.Is(err, ErrInvalidPrecision),
+		errors.Is(err, ErrMarketClosed),
+		errors.Is(err, ErrInvalidSymbol),
+		errors.Is(err, ErrAuthentication):
+		return false
+	}
+
+	return true
+}
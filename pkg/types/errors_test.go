@@ -0,0 +1,47 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeError(t *testing.T) {
+	apiErr := errors.New("Account has insufficient balance for requested action.")
+	err := fmt.Errorf("failed to place order: %w", NewExchangeError(ErrInsufficientBalance, apiErr))
+
+	assert.True(t, errors.Is(err, ErrInsufficientBalance))
+	assert.True(t, errors.Is(err, apiErr))
+	assert.False(t, errors.Is(err, ErrRateLimited))
+	assert.False(t, IsOrderNotFoundError(err))
+	assert.EqualError(t, err, "failed to place order: insufficient balance: Account has insufficient balance for requested action.")
+	assert.False(t, IsRetryableError(err))
+
+	_, ok := RetryAfter(err)
+	assert.False(t, ok)
+}
+
+func TestOrderNotFoundError(t *testing.T) {
+	err := fmt.Errorf("failed to cancel order: %w", NewExchangeError(ErrOrderNotFound, errors.New("Unknown order sent.")))
+	assert.True(t, IsOrderNotFoundError(err))
+	assert.False(t, IsRetryableError(err))
+}
+
+func TestRateLimitError(t *testing.T) {
+	err := fmt.Errorf("failed to query open orders: %w", NewRateLimitError(10*time.Second, errors.New("Too many requests")))
+
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.True(t, IsRateLimitError(err))
+	assert.True(t, IsRetryableError(err))
+
+	retryAfter, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, retryAfter)
+
+	// the unknown errors like the network errors are retryable, but they are not rate limit errors
+	assert.True(t, IsRetryableError(errors.New("connection reset by peer")))
+	assert.False(t, IsRateLimitError(errors.New("connection reset by peer")))
+}
@@ -5,8 +5,12 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 )
 
 const DateFormat = "2006-01-02"
@@ -23,14 +27,13 @@ func (n *ExchangeName) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	switch s {
-	case "max", "binance", "ftx":
-		*n = ExchangeName(s)
-		return nil
-
+	name, err := ValidExchangeName(s)
+	if err != nil {
+		return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: %s", s, strings.Join(exchangeNameStrings(), ", "))
 	}
 
-	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, ftx", s)
+	*n = name
+	return nil
 }
 
 func (n ExchangeName) String() string {
@@ -41,16 +44,66 @@ const (
 	ExchangeMax     = ExchangeName("max")
 	ExchangeBinance = ExchangeName("binance")
 	ExchangeFTX     = ExchangeName("ftx")
+	ExchangeOKEx    = ExchangeName("okex")
+	ExchangeKraken  = ExchangeName("kraken")
 )
 
+// exchangeNames maps the lower case names and aliases to the exchange names. The built-in exchanges are registered
+// here, so that the config can be parsed without importing the exchange packages; the exchange registry in
+// pkg/exchange adds the names of the other exchanges.
+var exchangeNames = map[string]ExchangeName{
+	"max":     ExchangeMax,
+	"binance": ExchangeBinance,
+	"bn":      ExchangeBinance,
+	"ftx":     ExchangeFTX,
+	"okex":    ExchangeOKEx,
+	"okx":     ExchangeOKEx,
+	"kraken":  ExchangeKraken,
+}
+var exchangeNamesMutex sync.RWMutex
+
+// RegisterExchangeName registers the exchange name and its aliases, so that they can be parsed from the config.
+func RegisterExchangeName(name ExchangeName, aliases ...string) {
+	exchangeNamesMutex.Lock()
+	defer exchangeNamesMutex.Unlock()
+
+	for _, a := range append([]string{name.String()}, aliases...) {
+		exchangeNames[strings.ToLower(a)] = name
+	}
+}
+
+// ExchangeNames returns the registered exchange names in the alphabetical order
+func ExchangeNames() []ExchangeName {
+	exchangeNamesMutex.RLock()
+	defer exchangeNamesMutex.RUnlock()
+
+	var names []ExchangeName
+	for a, name := range exchangeNames {
+		if a == name.String() {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func exchangeNameStrings() []string {
+	var names []string
+	for _, n := range ExchangeNames() {
+		names = append(names, n.String())
+	}
+	return names
+}
+
 func ValidExchangeName(a string) (ExchangeName, error) {
-	switch strings.ToLower(a) {
-	case "max":
-		return ExchangeMax, nil
-	case "binance", "bn":
-		return ExchangeBinance, nil
-	case "ftx":
-		return ExchangeFTX, nil
+	exchangeNamesMutex.RLock()
+	defer exchangeNamesMutex.RUnlock()
+
+	if name, ok := exchangeNames[strings.ToLower(a)]; ok {
+		return name, nil
 	}
 
 	return "", fmt.Errorf("invalid exchange name: %s", a)
@@ -61,6 +114,9 @@ type Exchange interface {
 
 	PlatformFeeCurrency() string
 
+	// Capabilities returns the optional features supported by the exchange
+	Capabilities() ExchangeCapabilities
+
 	// required implementation
 	ExchangeMarketDataService
 
@@ -104,6 +160,34 @@ type ExchangeRewardService interface {
 	QueryRewards(ctx context.Context, startTime time.Time) ([]Reward, error)
 }
 
+// ExchangeOrderQueryService queries a single order by the order id or the client order id
+type ExchangeOrderQueryService interface {
+	QueryOrder(ctx context.Context, q OrderQuery) (*Order, error)
+}
+
+// OrderQuery is the query of a single order, the order id is used if both the order id and the client order id are set
+type OrderQuery struct {
+	Symbol        string
+	OrderID       uint64
+	ClientOrderID string
+}
+
+// ExchangeCancelAllService cancels the open orders in batch, the canceled orders are returned.
+// The exchanges without the order group cancel the orders by the client order id prefix of the group, see
+// NewGroupClientOrderID.
+type ExchangeCancelAllService interface {
+	CancelAllOrders(ctx context.Context) ([]Order, error)
+	CancelOrdersBySymbol(ctx context.Context, symbol string) ([]Order, error)
+	CancelOrdersByGroupID(ctx context.Context, groupID uint32) ([]Order, error)
+}
+
+// ExchangeOrderAmendService changes the price and the quantity of an open order, the quantity includes the executed
+// quantity of the order. The exchanges may replace the order with a new one, so the returned order could have a
+// different order id, see LocalActiveOrderBook.Replace.
+type ExchangeOrderAmendService interface {
+	AmendOrder(ctx context.Context, order Order, price, quantity fixedpoint.Value) (*Order, error)
+}
+
 type TradeQueryOptions struct {
 	StartTime   *time.Time
 	EndTime     *time.Time
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the exchange packages are not imported here, the built-in names should be valid without their registration
+func TestExchangeName_UnmarshalJSON(t *testing.T) {
+	var config struct {
+		Exchange ExchangeName `json:"exchange"`
+	}
+
+	for a, name := range map[string]ExchangeName{
+		"max":     ExchangeMax,
+		"Binance": ExchangeBinance,
+		"bn":      ExchangeBinance,
+		"ftx":     ExchangeFTX,
+		"okx":     ExchangeOKEx,
+		"kraken":  ExchangeKraken,
+	} {
+		err := json.Unmarshal([]byte(`{"exchange":"`+a+`"}`), &config)
+		if assert.NoError(t, err, a) {
+			assert.Equal(t, name, config.Exchange, a)
+		}
+	}
+
+	err := json.Unmarshal([]byte(`{"exchange":"unknown"}`), &config)
+	assert.Error(t, err)
+}
+
+func TestExchangeNames(t *testing.T) {
+	assert.Equal(t, []ExchangeName{ExchangeBinance, ExchangeFTX, ExchangeKraken, ExchangeMax, ExchangeOKEx}, ExchangeNames())
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+// FuturesExchange is the exchange that could switch the session to the perpetual futures markets
+type FuturesExchange interface {
+	UseFutures()
+	GetFuturesSettings() FuturesSettings
+}
+
+type FuturesSettings struct {
+	IsFutures bool
+}
+
+func (s FuturesSettings) GetFuturesSettings() FuturesSettings {
+	return s
+}
+
+func (s *FuturesSettings) UseFutures() {
+	s.IsFutures = true
+}
+
+// ExchangeFuturesService provides the futures specific market data and the position management of a futures session
+type ExchangeFuturesService interface {
+	QueryPremiumIndex(ctx context.Context, symbol string) (*PremiumIndex, error)
+	QueryFundingRateHistory(ctx context.Context, symbol string, since, until time.Time) ([]FundingRate, error)
+	QueryPositions(ctx context.Context) ([]FuturesPosition, error)
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+}
+
+// PremiumIndex is the mark price, the index price and the funding rate of a perpetual contract
+type PremiumIndex struct {
+	Symbol          string           `json:"symbol"`
+	MarkPrice       fixedpoint.Value `json:"markPrice"`
+	IndexPrice      fixedpoint.Value `json:"indexPrice"`
+	LastFundingRate fixedpoint.Value `json:"lastFundingRate"`
+	NextFundingTime time.Time        `json:"nextFundingTime"`
+	Time            time.Time        `json:"time"`
+}
+
+// Basis returns the difference between the mark price and the index price
+func (i PremiumIndex) Basis() fixedpoint.Value {
+	return i.MarkPrice.Sub(i.IndexPrice)
+}
+
+type FundingRate struct {
+	Symbol      string           `json:"symbol"`
+	FundingRate fixedpoint.Value `json:"fundingRate"`
+	FundingTime time.Time        `json:"fundingTime"`
+}
+
+// FuturesPosition is the position of a futures contract, the quantity is negative for the short positions
+type FuturesPosition struct {
+	Symbol           string           `json:"symbol"`
+	PositionSide     string           `json:"positionSide"`
+	Quantity         fixedpoint.Value `json:"quantity"`
+	EntryPrice       fixedpoint.Value `json:"entryPrice"`
+	MarkPrice        fixedpoint.Value `json:"markPrice"`
+	LiquidationPrice fixedpoint.Value `json:"liquidationPrice"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit"`
+	Leverage         int              `json:"leverage"`
+	Isolated         bool             `json:"isolated"`
+}
+
+func (p FuturesPosition) IsLong() bool {
+	return p.Quantity > 0
+}
+
+func (p FuturesPosition) IsShort() bool {
+	return p.Quantity < 0
+}
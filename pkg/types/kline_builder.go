@@ -0,0 +1,159 @@
+package types
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// KLineBuilder aggregates the public trades into klines for the exchanges that do not provide kline streams.
+// The kline is emitted on every trade, and the closed kline is emitted when the interval ends, either
+// by a trade of the next interval or by Tick.
+//go:generate callbackgen -type KLineBuilder
+type KLineBuilder struct {
+	Exchange string
+	Symbol   string
+	Interval Interval
+
+	mu    sync.Mutex
+	kline *KLine
+
+	// closedUntil is the end of the last closed interval, the trades before it are dropped
+	closedUntil time.Time
+
+	kLineCallbacks       []func(kline KLine)
+	kLineClosedCallbacks []func(kline KLine)
+}
+
+func NewKLineBuilder(exchange, symbol string, interval Interval) *KLineBuilder {
+	return &KLineBuilder{
+		Exchange: exchange,
+		Symbol:   symbol,
+		Interval: interval,
+	}
+}
+
+// Seed continues the aggregation from the last kline of the given (historical) klines,
+// so that the first kline emitted after the startup is not a partial one.
+func (b *KLineBuilder) Seed(klines []KLine) {
+	if len(klines) == 0 {
+		return
+	}
+
+	last := klines[len(klines)-1]
+	startTime := b.startTime(last.StartTime)
+	if !startTime.Equal(last.StartTime) {
+		// the seed kline is not aligned to our interval, drop it
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last.Exchange = b.Exchange
+	last.Symbol = b.Symbol
+	last.Interval = b.Interval
+	last.EndTime = b.endTime(startTime)
+	last.Closed = false
+	b.kline = &last
+	b.closedUntil = startTime
+}
+
+// AddTrade updates the current kline with the trade, the klines of the intervals without trades are emitted as flat klines.
+func (b *KLineBuilder) AddTrade(trade Trade) {
+	if trade.Symbol != b.Symbol {
+		return
+	}
+
+	startTime := b.startTime(trade.Time.Time())
+
+	b.mu.Lock()
+	if startTime.Before(b.closedUntil) {
+		// late trade of a closed interval
+		b.mu.Unlock()
+		return
+	}
+
+	closed := b.closeBefore(startTime)
+
+	price := trade.Price.Float64()
+	if b.kline == nil {
+		b.kline = b.newKLine(startTime, price)
+	}
+
+	k := b.kline
+	k.High = math.Max(k.High, price)
+	k.Low = math.Min(k.Low, price)
+	k.Close = price
+	k.Volume += trade.Quantity.Float64()
+	k.QuoteVolume += trade.QuoteQuantity.Float64()
+	k.NumberOfTrades++
+	if uint64(trade.ID) > k.LastTradeID {
+		k.LastTradeID = uint64(trade.ID)
+	}
+
+	current := *k
+	b.mu.Unlock()
+
+	b.emitClosed(closed)
+	b.EmitKLine(current)
+}
+
+// Tick closes the current kline if its interval has ended before now, so that the kline is closed even if no trade comes.
+func (b *KLineBuilder) Tick(now time.Time) {
+	b.mu.Lock()
+	closed := b.closeBefore(b.startTime(now))
+	b.mu.Unlock()
+
+	b.emitClosed(closed)
+}
+
+func (b *KLineBuilder) emitClosed(klines []KLine) {
+	for _, k := range klines {
+		b.EmitKLine(k)
+		b.EmitKLineClosed(k)
+	}
+}
+
+// closeBefore closes the current kline and fills the empty intervals until the given start time.
+// The caller must hold the lock.
+func (b *KLineBuilder) closeBefore(startTime time.Time) (closed []KLine) {
+	for b.kline != nil && b.kline.StartTime.Before(startTime) {
+		k := *b.kline
+		k.Closed = true
+		closed = append(closed, k)
+
+		next := k.StartTime.Add(b.Interval.Duration())
+		b.closedUntil = next
+		if next.Before(startTime) {
+			b.kline = b.newKLine(next, k.Close)
+		} else {
+			b.kline = nil
+		}
+	}
+
+	return closed
+}
+
+func (b *KLineBuilder) newKLine(startTime time.Time, price float64) *KLine {
+	return &KLine{
+		Exchange:  b.Exchange,
+		Symbol:    b.Symbol,
+		Interval:  b.Interval,
+		StartTime: startTime,
+		EndTime:   b.endTime(startTime),
+		Open:      price,
+		Close:     price,
+		High:      price,
+		Low:       price,
+	}
+}
+
+func (b *KLineBuilder) startTime(t time.Time) time.Time {
+	return t.Truncate(b.Interval.Duration())
+}
+
+// endTime follows the binance convention, the end time is the last millisecond of the interval
+func (b *KLineBuilder) endTime(startTime time.Time) time.Time {
+	return startTime.Add(b.Interval.Duration() - time.Millisecond)
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+func newTestTrade(id int64, t time.Time, price, quantity float64) Trade {
+	return Trade{
+		ID:            id,
+		Symbol:        "BTC/USDT",
+		Price:         fixedpoint.NewFromFloat(price),
+		Quantity:      fixedpoint.NewFromFloat(quantity),
+		QuoteQuantity: fixedpoint.NewFromFloat(price * quantity),
+		Time:          datatype.Time(t),
+	}
+}
+
+func TestKLineBuilder_AddTrade(t *testing.T) {
+	builder := NewKLineBuilder("ftx", "BTC/USDT", Interval1m)
+
+	var klines, closed []KLine
+	builder.OnKLine(func(kline KLine) { klines = append(klines, kline) })
+	builder.OnKLineClosed(func(kline KLine) { closed = append(closed, kline) })
+
+	startTime := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	builder.AddTrade(newTestTrade(1, startTime.Add(10*time.Second), 100, 1))
+	builder.AddTrade(newTestTrade(2, startTime.Add(20*time.Second), 110, 1))
+	builder.AddTrade(newTestTrade(3, startTime.Add(30*time.Second), 90, 2))
+	assert.Len(t, klines, 3)
+	assert.Empty(t, closed)
+
+	// the trade of the other symbol should be ignored
+	builder.AddTrade(Trade{Symbol: "ETH/USDT", Price: fixedpoint.NewFromFloat(1), Time: datatype.Time(startTime)})
+	assert.Len(t, klines, 3)
+
+	// the trade after 2 intervals closes the current kline and fills the empty interval
+	builder.AddTrade(newTestTrade(4, startTime.Add(2*time.Minute+time.Second), 95, 1))
+	if assert.Len(t, closed, 2) {
+		k := closed[0]
+		assert.Equal(t, startTime, k.StartTime)
+		assert.Equal(t, startTime.Add(time.Minute-time.Millisecond), k.EndTime)
+		assert.Equal(t, 100.0, k.Open)
+		assert.Equal(t, 110.0, k.High)
+		assert.Equal(t, 90.0, k.Low)
+		assert.Equal(t, 90.0, k.Close)
+		assert.Equal(t, 4.0, k.Volume)
+		assert.Equal(t, 390.0, k.QuoteVolume)
+		assert.Equal(t, uint64(3), k.NumberOfTrades)
+		assert.Equal(t, uint64(3), k.LastTradeID)
+		assert.True(t, k.Closed)
+
+		flat := closed[1]
+		assert.Equal(t, startTime.Add(time.Minute), flat.StartTime)
+		assert.Equal(t, 90.0, flat.Open)
+		assert.Equal(t, 90.0, flat.Close)
+		assert.Equal(t, 0.0, flat.Volume)
+	}
+
+	// the late trade of the closed interval should be dropped
+	builder.AddTrade(newTestTrade(5, startTime.Add(30*time.Second), 1000, 1))
+	assert.Equal(t, 95.0, klines[len(klines)-1].High)
+
+	builder.Tick(startTime.Add(3*time.Minute + time.Second))
+	if assert.Len(t, closed, 3) {
+		assert.Equal(t, startTime.Add(2*time.Minute), closed[2].StartTime)
+		assert.Equal(t, 95.0, closed[2].Close)
+	}
+}
+
+func TestKLineBuilder_Seed(t *testing.T) {
+	builder := NewKLineBuilder("ftx", "BTC/USDT", Interval5m)
+
+	var closed []KLine
+	builder.OnKLineClosed(func(kline KLine) { closed = append(closed, kline) })
+
+	startTime := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	builder.Seed([]KLine{
+		{Symbol: "BTCUSDT", StartTime: startTime.Add(-5 * time.Minute), Open: 80, High: 90, Low: 70, Close: 85, Closed: true},
+		{Symbol: "BTCUSDT", StartTime: startTime, Open: 85, High: 100, Low: 80, Close: 95, Volume: 10},
+	})
+
+	builder.AddTrade(newTestTrade(1, startTime.Add(time.Minute), 105, 1))
+	builder.Tick(startTime.Add(5 * time.Minute))
+	if assert.Len(t, closed, 1) {
+		k := closed[0]
+		assert.Equal(t, "BTC/USDT", k.Symbol)
+		assert.Equal(t, 85.0, k.Open)
+		assert.Equal(t, 105.0, k.High)
+		assert.Equal(t, 80.0, k.Low)
+		assert.Equal(t, 105.0, k.Close)
+		assert.Equal(t, 11.0, k.Volume)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"math"
 	"strconv"
 	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 )
 
 type Duration time.Duration
@@ -108,6 +110,17 @@ func formatQuantity(quantity float64, lot float64) string {
 	return strconv.FormatFloat(quantity, 'f', prec, 64)
 }
 
+// RoundPrice rounds the price to a multiple of the tick size, the price is returned as it is if there is no tick size.
+func (m Market) RoundPrice(price fixedpoint.Value, mode fixedpoint.RoundingMode) (fixedpoint.Value, error) {
+	return price.RoundToStep(fixedpoint.NewFromFloat(m.TickSize), mode)
+}
+
+// RoundQuantity rounds the quantity to a multiple of the step size, the quantity is returned as it is if there is
+// no step size.
+func (m Market) RoundQuantity(quantity fixedpoint.Value, mode fixedpoint.RoundingMode) (fixedpoint.Value, error) {
+	return quantity.RoundToStep(fixedpoint.NewFromFloat(m.StepSize), mode)
+}
+
 func (m Market) FormatVolume(val float64) string {
 	p := math.Pow10(m.VolumePrecision)
 	val = math.Trunc(val*p) / p
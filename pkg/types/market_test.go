@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 )
 
 func TestFormatQuantity(t *testing.T) {
@@ -24,6 +26,32 @@ func TestFormatPrice(t *testing.T) {
 	assert.Equal(t, "26.288", price)
 }
 
+func TestMarket_RoundPriceAndQuantity(t *testing.T) {
+	market := Market{Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.0001}
+
+	tests := []struct {
+		name     string
+		round    func(v fixedpoint.Value, mode fixedpoint.RoundingMode) (fixedpoint.Value, error)
+		v        string
+		mode     fixedpoint.RoundingMode
+		expected string
+	}{
+		{name: "bid price down", round: market.RoundPrice, v: "48123.456", mode: fixedpoint.RoundDown, expected: "48123.45"},
+		{name: "ask price up", round: market.RoundPrice, v: "48123.451", mode: fixedpoint.RoundUp, expected: "48123.46"},
+		{name: "price half even", round: market.RoundPrice, v: "48123.455", mode: fixedpoint.RoundHalfEven, expected: "48123.46"},
+		{name: "quantity down", round: market.RoundQuantity, v: "0.12345678", mode: fixedpoint.RoundDown, expected: "0.1234"},
+		{name: "no step size", round: Market{}.RoundQuantity, v: "0.12345678", mode: fixedpoint.RoundDown, expected: "0.12345678"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.round(fixedpoint.MustNewFromString(test.v), test.mode)
+			assert.NoError(t, err)
+			assert.Equal(t, fixedpoint.MustNewFromString(test.expected), actual)
+		})
+	}
+}
+
 func TestDurationParse(t *testing.T) {
 	type A struct {
 		Duration Duration `json:"duration"`
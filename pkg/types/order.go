@@ -3,12 +3,15 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
@@ -95,9 +98,9 @@ type SubmitOrder struct {
 	Side   SideType  `json:"side" db:"side"`
 	Type   OrderType `json:"orderType" db:"order_type"`
 
-	Quantity  float64 `json:"quantity" db:"quantity"`
-	Price     float64 `json:"price" db:"price"`
-	StopPrice float64 `json:"stopPrice,omitempty" db:"stop_price"`
+	Quantity  fixedpoint.Value `json:"quantity" db:"quantity"`
+	Price     fixedpoint.Value `json:"price" db:"price"`
+	StopPrice fixedpoint.Value `json:"stopPrice,omitempty" db:"stop_price"`
 
 	Market Market `json:"-" db:"-"`
 
@@ -113,12 +116,54 @@ type SubmitOrder struct {
 	MarginSideEffect MarginOrderSideEffectType `json:"marginSideEffect,omitempty"` // AUTO_REPAY = repay, MARGIN_BUY = borrow, defaults to  NO_SIDE_EFFECT
 }
 
+// maxClientOrderIDLength is the max length of the client order id accepted by all the exchanges, binance allows 36
+const maxClientOrderIDLength = 36
+
+// GroupClientOrderIDPrefix returns the client order id prefix of the order group, it's used to cancel the orders of a
+// group on the exchanges without the native order group
+func GroupClientOrderIDPrefix(groupID uint32) string {
+	return "g" + strconv.FormatUint(uint64(groupID), 10) + "-"
+}
+
+// NewGroupClientOrderID returns a new client order id with the prefix of the order group
+func NewGroupClientOrderID(groupID uint32) string {
+	id := GroupClientOrderIDPrefix(groupID) + strings.ReplaceAll(uuid.New().String(), "-", "")
+	if len(id) > maxClientOrderIDLength {
+		id = id[:maxClientOrderIDLength]
+	}
+	return id
+}
+
+// IsGroupClientOrderID returns true if the client order id is generated for the order group
+func IsGroupClientOrderID(clientOrderID string, groupID uint32) bool {
+	return strings.HasPrefix(clientOrderID, GroupClientOrderIDPrefix(groupID))
+}
+
+// ParseGroupClientOrderID returns the group id of the client order id generated by NewGroupClientOrderID
+func ParseGroupClientOrderID(clientOrderID string) (uint32, bool) {
+	if !strings.HasPrefix(clientOrderID, "g") {
+		return 0, false
+	}
+
+	idx := strings.Index(clientOrderID, "-")
+	if idx < 0 {
+		return 0, false
+	}
+
+	groupID, err := strconv.ParseUint(clientOrderID[1:idx], 10, 32)
+	if err != nil || groupID == 0 {
+		return 0, false
+	}
+
+	return uint32(groupID), true
+}
+
 func (o *SubmitOrder) String() string {
-	return fmt.Sprintf("SubmitOrder %s %s %s %f @ %f", o.Symbol, o.Type, o.Side, o.Quantity, o.Price)
+	return fmt.Sprintf("SubmitOrder %s %s %s %s @ %s", o.Symbol, o.Type, o.Side, o.Quantity, o.Price)
 }
 
 func (o *SubmitOrder) PlainText() string {
-	return fmt.Sprintf("SubmitOrder %s %s %s %f @ %f", o.Symbol, o.Type, o.Side, o.Quantity, o.Price)
+	return fmt.Sprintf("SubmitOrder %s %s %s %s @ %s", o.Symbol, o.Type, o.Side, o.Quantity, o.Price)
 }
 
 func (o *SubmitOrder) SlackAttachment() slack.Attachment {
@@ -143,14 +188,14 @@ func (o *SubmitOrder) SlackAttachment() slack.Attachment {
 type Order struct {
 	SubmitOrder
 
-	Exchange         string        `json:"exchange" db:"exchange"`
-	GID              uint64        `json:"gid" db:"gid"`
-	OrderID          uint64        `json:"orderID" db:"order_id"` // order id
-	Status           OrderStatus   `json:"status" db:"status"`
-	ExecutedQuantity float64       `json:"executedQuantity" db:"executed_quantity"`
-	IsWorking        bool          `json:"isWorking" db:"is_working"`
-	CreationTime     datatype.Time `json:"creationTime" db:"created_at"`
-	UpdateTime       datatype.Time `json:"updateTime" db:"updated_at"`
+	Exchange         string           `json:"exchange" db:"exchange"`
+	GID              uint64           `json:"gid" db:"gid"`
+	OrderID          uint64           `json:"orderID" db:"order_id"` // order id
+	Status           OrderStatus      `json:"status" db:"status"`
+	ExecutedQuantity fixedpoint.Value `json:"executedQuantity" db:"executed_quantity"`
+	IsWorking        bool             `json:"isWorking" db:"is_working"`
+	CreationTime     datatype.Time    `json:"creationTime" db:"created_at"`
+	UpdateTime       datatype.Time    `json:"updateTime" db:"updated_at"`
 
 	IsMargin   bool `json:"isMargin" db:"is_margin"`
 	IsIsolated bool `json:"isIsolated" db:"is_isolated"`
@@ -168,7 +213,7 @@ func (o Order) Backup() SubmitOrder {
 }
 
 func (o Order) String() string {
-	return fmt.Sprintf("order %s %s %f/%f at %f -> %s", o.Symbol, o.Side, o.ExecutedQuantity, o.Quantity, o.Price, o.Status)
+	return fmt.Sprintf("order %s %s %s/%s at %s -> %s", o.Symbol, o.Side, o.ExecutedQuantity, o.Quantity, o.Price, o.Status)
 }
 
 func (o Order) PlainText() string {
@@ -177,7 +222,7 @@ func (o Order) PlainText() string {
 		o.Type,
 		o.Symbol,
 		o.Side,
-		util.FormatFloat(o.Price, 2),
-		util.FormatFloat(o.ExecutedQuantity, 2),
-		util.FormatFloat(o.Quantity, 4), o.Status)
+		util.FormatFloat(o.Price.Float64(), 2),
+		util.FormatFloat(o.ExecutedQuantity.Float64(), 2),
+		util.FormatFloat(o.Quantity.Float64(), 4), o.Status)
 }
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGroupClientOrderID(t *testing.T) {
+	id := NewGroupClientOrderID(4294967295)
+	assert.Len(t, id, 36)
+	assert.True(t, IsGroupClientOrderID(id, 4294967295))
+	assert.False(t, IsGroupClientOrderID(id, 429496729))
+
+	assert.NotEqual(t, id, NewGroupClientOrderID(4294967295))
+	assert.Equal(t, "g1-", GroupClientOrderIDPrefix(1))
+}
+
+func TestParseGroupClientOrderID(t *testing.T) {
+	groupID, ok := ParseGroupClientOrderID(NewGroupClientOrderID(4294967295))
+	assert.True(t, ok)
+	assert.Equal(t, uint32(4294967295), groupID)
+
+	for _, id := range []string{"", "g-abc", "g0-abc", "gx-abc", "g4294967296-abc", "0c8a4c3e-2b1e-4f5a", "g123"} {
+		_, ok := ParseGroupClientOrderID(id)
+		assert.False(t, ok, id)
+	}
+}
+
+func TestOrderSlice_Canceled(t *testing.T) {
+	orders := OrderSlice{{OrderID: 1, Status: OrderStatusNew, IsWorking: true}}
+
+	canceled := orders.Canceled()
+	assert.Equal(t, OrderSlice{{OrderID: 1, Status: OrderStatusCanceled}}, canceled)
+	assert.Equal(t, OrderStatusNew, orders[0].Status)
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 
@@ -64,10 +65,12 @@ func (slice PriceVolumeSlice) IndexByVolumeDepth(requiredVolume fixedpoint.Value
 	return -1
 }
 
+// InsertAt inserts the pair at the index in place, the slice is only reallocated when it's out of capacity
 func (slice PriceVolumeSlice) InsertAt(idx int, pv PriceVolume) PriceVolumeSlice {
-	rear := append([]PriceVolume{}, slice[idx:]...)
-	newSlice := append(slice[:idx], pv)
-	return append(newSlice, rear...)
+	slice = append(slice, PriceVolume{})
+	copy(slice[idx+1:], slice[idx:])
+	slice[idx] = pv
+	return slice
 }
 
 func (slice PriceVolumeSlice) Remove(price fixedpoint.Value, descending bool) PriceVolumeSlice {
@@ -297,6 +300,23 @@ func (b *MutexOrderBook) Update(update OrderBook) {
 	defer b.Unlock()
 
 	b.OrderBook.update(update)
+
+	// copying the whole book is expensive, skip it if there is no update callback
+	if len(b.updateCallbacks) == 0 {
+		return
+	}
+
+	copied := b.OrderBook.Copy()
+	b.EmitUpdate(&copied)
+}
+
+// reload replaces the whole book and emits the update callbacks
+func (b *MutexOrderBook) reload(book OrderBook) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.OrderBook.Reset()
+	b.OrderBook.update(book)
 	copied := b.OrderBook.Copy()
 	b.EmitUpdate(&copied)
 }
@@ -307,6 +327,10 @@ type StreamOrderBook struct {
 	*MutexOrderBook
 
 	C sigchan.Chan
+
+	// ready is set when the snapshot is loaded, and it's cleared when the book is invalidated.
+	// The updates are dropped until the book is ready.
+	ready int32
 }
 
 func NewStreamBook(symbol string) *StreamOrderBook {
@@ -316,6 +340,32 @@ func NewStreamBook(symbol string) *StreamOrderBook {
 	}
 }
 
+// IsReady returns true if the book is loaded from a snapshot and is not invalidated since then
+func (sb *StreamOrderBook) IsReady() bool {
+	return atomic.LoadInt32(&sb.ready) == 1
+}
+
+// Load loads the snapshot into the book and marks the book as ready
+func (sb *StreamOrderBook) Load(book OrderBook) {
+	sb.MutexOrderBook.Load(book)
+	atomic.StoreInt32(&sb.ready, 1)
+}
+
+// Update applies the update to the book, the update is dropped if the book is not ready
+func (sb *StreamOrderBook) Update(book OrderBook) {
+	if !sb.IsReady() {
+		return
+	}
+
+	sb.MutexOrderBook.Update(book)
+}
+
+// Invalidate resets the book, the book will be ready again after the next snapshot
+func (sb *StreamOrderBook) Invalidate() {
+	atomic.StoreInt32(&sb.ready, 0)
+	sb.Reset()
+}
+
 func (sb *StreamOrderBook) BindStream(stream Stream) {
 	stream.OnBookSnapshot(func(book OrderBook) {
 		if sb.Symbol != book.Symbol {
@@ -334,4 +384,34 @@ func (sb *StreamOrderBook) BindStream(stream Stream) {
 		sb.Update(book)
 		sb.C.Emit()
 	})
+
+	sb.bindBookInvalidated(stream)
+}
+
+// BindBookTickerStream binds the book ticker feed instead of the depth feed, which is much cheaper than
+// maintaining the full order book. The book only keeps the best bid and the best ask.
+// Remember to subscribe the BookTickerChannel instead of the BookChannel.
+func (sb *StreamOrderBook) BindBookTickerStream(stream Stream) {
+	stream.OnBookTicker(func(ticker BookTicker) {
+		if sb.Symbol != ticker.Symbol {
+			return
+		}
+
+		sb.reload(ticker.OrderBook())
+		atomic.StoreInt32(&sb.ready, 1)
+		sb.C.Emit()
+	})
+
+	sb.bindBookInvalidated(stream)
+}
+
+func (sb *StreamOrderBook) bindBookInvalidated(stream Stream) {
+	stream.OnBookInvalidated(func(symbol string, reason BookInvalidReason) {
+		if sb.Symbol != symbol {
+			return
+		}
+
+		sb.Invalidate()
+		sb.C.Emit()
+	})
 }
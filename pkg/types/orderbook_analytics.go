@@ -0,0 +1,161 @@
+package types
+
+import (
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+// ExecutionPrice is the result of walking the book with a taker order
+type ExecutionPrice struct {
+	// AveragePrice is the volume weighted average price of the filled levels
+	AveragePrice fixedpoint.Value
+
+	// Quantity is the filled base quantity, and QuoteAmount is the filled quote amount
+	Quantity    fixedpoint.Value
+	QuoteAmount fixedpoint.Value
+
+	// SlippageBps is how much the average price is worse than the best price in bps
+	SlippageBps float64
+}
+
+// MidPrice returns the middle of the best bid and the best ask
+func (b *OrderBook) MidPrice() (fixedpoint.Value, bool) {
+	bid, hasBid := b.BestBid()
+	ask, hasAsk := b.BestAsk()
+	if !hasBid || !hasAsk {
+		return 0, false
+	}
+
+	return (bid.Price + ask.Price) / 2, true
+}
+
+// MicroPrice returns the mid price weighted by the volumes of the best levels, it leans to the ask price when the bid
+// volume is larger, since the price is more likely to move up.
+func (b *OrderBook) MicroPrice() (fixedpoint.Value, bool) {
+	bid, hasBid := b.BestBid()
+	ask, hasAsk := b.BestAsk()
+	if !hasBid || !hasAsk {
+		return 0, false
+	}
+
+	totalVolume := bid.Volume + ask.Volume
+	if totalVolume == 0 {
+		return 0, false
+	}
+
+	price := (bid.Price.Float64()*ask.Volume.Float64() + ask.Price.Float64()*bid.Volume.Float64()) / totalVolume.Float64()
+	return fixedpoint.NewFromFloat(price), true
+}
+
+// Imbalance returns (bidVolume - askVolume) / (bidVolume + askVolume) of the top levels, the result is in [-1, 1],
+// and a positive imbalance means there is more buying interest. Zero levels means all the levels.
+func (b *OrderBook) Imbalance(levels int) (float64, bool) {
+	bidVolume := sumVolume(b.Bids, levels)
+	askVolume := sumVolume(b.Asks, levels)
+	totalVolume := bidVolume + askVolume
+	if totalVolume == 0 {
+		return 0, false
+	}
+
+	return (bidVolume - askVolume).Float64() / totalVolume.Float64(), true
+}
+
+// DepthWithinBps returns the cumulative bid and ask volumes within the given bps of the mid price
+func (b *OrderBook) DepthWithinBps(bps float64) (bidVolume, askVolume fixedpoint.Value) {
+	mid, ok := b.MidPrice()
+	if !ok {
+		return 0, 0
+	}
+
+	minBidPrice := fixedpoint.NewFromFloat(mid.Float64() * (1 - bps/10000.0))
+	for _, pv := range b.Bids {
+		if pv.Price < minBidPrice {
+			break
+		}
+		bidVolume += pv.Volume
+	}
+
+	maxAskPrice := fixedpoint.NewFromFloat(mid.Float64() * (1 + bps/10000.0))
+	for _, pv := range b.Asks {
+		if pv.Price > maxAskPrice {
+			break
+		}
+		askVolume += pv.Volume
+	}
+
+	return bidVolume, askVolume
+}
+
+// ExecutionPriceByQuantity walks the book with a taker order of the base quantity, the buy order takes the asks and
+// the sell order takes the bids. It returns false if the book is not deep enough to fill the quantity.
+func (b *OrderBook) ExecutionPriceByQuantity(side SideType, quantity fixedpoint.Value) (ExecutionPrice, bool) {
+	return b.executionPrice(side, func(pv PriceVolume, filled ExecutionPrice) (fixedpoint.Value, bool) {
+		remaining := quantity - filled.Quantity
+		if pv.Volume >= remaining {
+			return remaining, true
+		}
+		return pv.Volume, false
+	})
+}
+
+// ExecutionPriceByQuoteAmount walks the book with a taker order of the quote amount, the buy order takes the asks and
+// the sell order takes the bids. It returns false if the book is not deep enough to fill the amount.
+func (b *OrderBook) ExecutionPriceByQuoteAmount(side SideType, amount fixedpoint.Value) (ExecutionPrice, bool) {
+	return b.executionPrice(side, func(pv PriceVolume, filled ExecutionPrice) (fixedpoint.Value, bool) {
+		remaining := amount - filled.QuoteAmount
+		if pv.Price.Mul(pv.Volume) >= remaining {
+			return remaining.Div(pv.Price), true
+		}
+		return pv.Volume, false
+	})
+}
+
+// executionPrice fills the levels from the best price, take returns the quantity taken from the level and whether the
+// order is completely filled.
+func (b *OrderBook) executionPrice(side SideType, take func(pv PriceVolume, filled ExecutionPrice) (fixedpoint.Value, bool)) (ExecutionPrice, bool) {
+	var pvs PriceVolumeSlice
+	switch side {
+	case SideTypeBuy:
+		pvs = b.Asks
+	case SideTypeSell:
+		pvs = b.Bids
+	}
+
+	var result ExecutionPrice
+	if len(pvs) == 0 {
+		return result, false
+	}
+
+	done := false
+	for _, pv := range pvs {
+		var quantity fixedpoint.Value
+		quantity, done = take(pv, result)
+		result.Quantity += quantity
+		result.QuoteAmount += pv.Price.Mul(quantity)
+		if done {
+			break
+		}
+	}
+
+	if result.Quantity == 0 {
+		return result, false
+	}
+
+	best := pvs[0].Price.Float64()
+	result.AveragePrice = result.QuoteAmount.Div(result.Quantity)
+	result.SlippageBps = (result.AveragePrice.Float64() - best) / best * 10000.0
+	if side == SideTypeSell {
+		result.SlippageBps = -result.SlippageBps
+	}
+
+	return result, done
+}
+
+func sumVolume(pvs PriceVolumeSlice, levels int) (volume fixedpoint.Value) {
+	for i, pv := range pvs {
+		if levels > 0 && i >= levels {
+			break
+		}
+		volume += pv.Volume
+	}
+	return volume
+}
@@ -0,0 +1,174 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+func newTestAnalyticsBook() *OrderBook {
+	return &OrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(99.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(98.0), fixedpoint.NewFromFloat(2.0)},
+			{fixedpoint.NewFromFloat(95.0), fixedpoint.NewFromFloat(5.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(3.0)},
+			{fixedpoint.NewFromFloat(102.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(110.0), fixedpoint.NewFromFloat(4.0)},
+		},
+	}
+}
+
+func TestOrderBook_ExecutionPriceByQuantity(t *testing.T) {
+	book := newTestAnalyticsBook()
+
+	tests := []struct {
+		name        string
+		side        SideType
+		quantity    float64
+		ok          bool
+		price       float64
+		filled      float64
+		slippageBps float64
+	}{
+		{name: "buy within the best ask", side: SideTypeBuy, quantity: 2.0, ok: true, price: 101.0, filled: 2.0, slippageBps: 0},
+		{name: "buy two levels", side: SideTypeBuy, quantity: 4.0, ok: true, price: 101.25, filled: 4.0, slippageBps: 24.752475},
+		{name: "sell two levels", side: SideTypeSell, quantity: 2.0, ok: true, price: 98.5, filled: 2.0, slippageBps: 50.505050},
+		{name: "sell more than the depth", side: SideTypeSell, quantity: 10.0, ok: false, price: 96.25, filled: 8.0, slippageBps: 277.777777},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := book.ExecutionPriceByQuantity(test.side, fixedpoint.NewFromFloat(test.quantity))
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.price, result.AveragePrice.Float64(), 1e-6)
+			assert.InDelta(t, test.filled, result.Quantity.Float64(), 1e-6)
+			assert.InDelta(t, test.slippageBps, result.SlippageBps, 1e-4)
+		})
+	}
+
+	_, ok := (&OrderBook{}).ExecutionPriceByQuantity(SideTypeBuy, fixedpoint.NewFromFloat(1.0))
+	assert.False(t, ok)
+}
+
+func TestOrderBook_ExecutionPriceByQuoteAmount(t *testing.T) {
+	book := newTestAnalyticsBook()
+
+	tests := []struct {
+		name   string
+		side   SideType
+		amount float64
+		ok     bool
+		price  float64
+		filled float64
+	}{
+		{name: "buy within the best ask", side: SideTypeBuy, amount: 202.0, ok: true, price: 101.0, filled: 2.0},
+		{name: "buy two levels", side: SideTypeBuy, amount: 405.0, ok: true, price: 101.25, filled: 4.0},
+		{name: "sell two levels", side: SideTypeSell, amount: 197.0, ok: true, price: 98.5, filled: 2.0},
+		{name: "buy more than the depth", side: SideTypeBuy, amount: 10000.0, ok: false, price: 105.625, filled: 8.0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := book.ExecutionPriceByQuoteAmount(test.side, fixedpoint.NewFromFloat(test.amount))
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.price, result.AveragePrice.Float64(), 1e-6)
+			assert.InDelta(t, test.filled, result.Quantity.Float64(), 1e-6)
+		})
+	}
+}
+
+func TestOrderBook_DepthWithinBps(t *testing.T) {
+	book := newTestAnalyticsBook()
+
+	tests := []struct {
+		name      string
+		bps       float64
+		bidVolume float64
+		askVolume float64
+	}{
+		{name: "nothing within 50 bps", bps: 50, bidVolume: 0, askVolume: 0},
+		{name: "best levels within 100 bps", bps: 100, bidVolume: 1.0, askVolume: 3.0},
+		{name: "two levels within 200 bps", bps: 200, bidVolume: 3.0, askVolume: 4.0},
+		{name: "all levels within 1000 bps", bps: 1000, bidVolume: 8.0, askVolume: 8.0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bidVolume, askVolume := book.DepthWithinBps(test.bps)
+			assert.Equal(t, fixedpoint.NewFromFloat(test.bidVolume), bidVolume)
+			assert.Equal(t, fixedpoint.NewFromFloat(test.askVolume), askVolume)
+		})
+	}
+}
+
+func TestOrderBook_Imbalance(t *testing.T) {
+	book := newTestAnalyticsBook()
+
+	tests := []struct {
+		name      string
+		book      *OrderBook
+		levels    int
+		ok        bool
+		imbalance float64
+	}{
+		{name: "best levels", book: book, levels: 1, ok: true, imbalance: -0.5},
+		{name: "two levels", book: book, levels: 2, ok: true, imbalance: -1.0 / 7.0},
+		{name: "all levels", book: book, levels: 0, ok: true, imbalance: 0},
+		{name: "empty book", book: &OrderBook{}, levels: 0, ok: false, imbalance: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imbalance, ok := test.book.Imbalance(test.levels)
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.imbalance, imbalance, 1e-9)
+		})
+	}
+}
+
+func TestOrderBook_MidPriceAndMicroPrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		book       *OrderBook
+		ok         bool
+		midPrice   float64
+		microPrice float64
+	}{
+		{name: "more ask volume", book: newTestAnalyticsBook(), ok: true, midPrice: 100.0, microPrice: 99.5},
+		{
+			name: "more bid volume",
+			book: &OrderBook{
+				Bids: PriceVolumeSlice{{fixedpoint.NewFromFloat(99.0), fixedpoint.NewFromFloat(3.0)}},
+				Asks: PriceVolumeSlice{{fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0)}},
+			},
+			ok:         true,
+			midPrice:   100.0,
+			microPrice: 100.5,
+		},
+		{
+			name: "empty asks",
+			book: &OrderBook{
+				Bids: PriceVolumeSlice{{fixedpoint.NewFromFloat(99.0), fixedpoint.NewFromFloat(3.0)}},
+			},
+			ok: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mid, ok := test.book.MidPrice()
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.midPrice, mid.Float64(), 1e-9)
+
+			micro, ok := test.book.MicroPrice()
+			assert.Equal(t, test.ok, ok)
+			assert.InDelta(t, test.microPrice, micro.Float64(), 1e-9)
+		})
+	}
+}
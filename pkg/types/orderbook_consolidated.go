@@ -0,0 +1,176 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+// OrderBookSource is the order book of a venue, both StreamOrderBook and TreeOrderBook are order book sources
+type OrderBookSource interface {
+	Get() OrderBook
+	IsReady() bool
+}
+
+// VenueOptions adjusts the prices of a venue before they are merged into the consolidated book
+type VenueOptions struct {
+	// QuoteRate converts the quote currency of the venue into the quote currency of the consolidated book,
+	// the price is multiplied by the rate. Zero means no conversion.
+	QuoteRate fixedpoint.Value
+
+	// FeeRate is the taker fee rate of the venue, the bid prices are decreased and the ask prices are increased by
+	// the fee, so that the levels are the executable prices.
+	FeeRate fixedpoint.Value
+}
+
+// VenueVolume is the volume of a venue at a price level
+type VenueVolume struct {
+	Venue  string           `json:"venue"`
+	Volume fixedpoint.Value `json:"volume"`
+}
+
+// ConsolidatedPriceLevel is the aggregated price level of the venues
+type ConsolidatedPriceLevel struct {
+	Price  fixedpoint.Value `json:"price"`
+	Volume fixedpoint.Value `json:"volume"`
+	Venues []VenueVolume    `json:"venues"`
+}
+
+type consolidatedVenue struct {
+	name    string
+	book    OrderBookSource
+	options VenueOptions
+}
+
+// ConsolidatedOrderBook merges the order books of several venues into one aggregated book, each level is tagged with
+// the venues. Only the ready books are merged, so an invalidated venue is left out until its next snapshot.
+type ConsolidatedOrderBook struct {
+	Symbol string
+
+	// mu protects venues
+	mu     sync.Mutex
+	venues []*consolidatedVenue
+}
+
+func NewConsolidatedOrderBook(symbol string) *ConsolidatedOrderBook {
+	return &ConsolidatedOrderBook{Symbol: symbol}
+}
+
+// AddVenue adds the book of the venue, the venue name is usually the session name
+func (b *ConsolidatedOrderBook) AddVenue(venue string, book OrderBookSource, options VenueOptions) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.venues = append(b.venues, &consolidatedVenue{
+		name:    venue,
+		book:    book,
+		options: options,
+	})
+}
+
+// SetQuoteRate updates the quote conversion rate of the venue, e.g., from the USDT/USD ticker
+func (b *ConsolidatedOrderBook) SetQuoteRate(venue string, rate fixedpoint.Value) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, v := range b.venues {
+		if v.name == venue {
+			v.options.QuoteRate = rate
+			return nil
+		}
+	}
+
+	return fmt.Errorf("venue %s is not found", venue)
+}
+
+// Get returns the aggregated bids (descending) and asks (ascending) of the ready venues
+func (b *ConsolidatedOrderBook) Get() (bids, asks []ConsolidatedPriceLevel) {
+	for _, v := range b.readyVenues() {
+		book := v.book.Get()
+		bids = mergePriceLevels(bids, v.name, v.adjust(book.Bids, SideTypeSell))
+		asks = mergePriceLevels(asks, v.name, v.adjust(book.Asks, SideTypeBuy))
+	}
+
+	sort.Slice(bids, func(i, j int) bool { return bids[i].Price > bids[j].Price })
+	sort.Slice(asks, func(i, j int) bool { return asks[i].Price < asks[j].Price })
+	return bids, asks
+}
+
+// BestBid returns the best executable bid price of all the venues
+func (b *ConsolidatedOrderBook) BestBid() (ConsolidatedPriceLevel, bool) {
+	bids, _ := b.Get()
+	if len(bids) == 0 {
+		return ConsolidatedPriceLevel{}, false
+	}
+	return bids[0], true
+}
+
+// BestAsk returns the best executable ask price of all the venues
+func (b *ConsolidatedOrderBook) BestAsk() (ConsolidatedPriceLevel, bool) {
+	_, asks := b.Get()
+	if len(asks) == 0 {
+		return ConsolidatedPriceLevel{}, false
+	}
+	return asks[0], true
+}
+
+func (b *ConsolidatedOrderBook) readyVenues() (venues []consolidatedVenue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, v := range b.venues {
+		if v.book.IsReady() {
+			venues = append(venues, *v)
+		}
+	}
+	return venues
+}
+
+// adjust converts the prices into the consolidated quote currency and applies the fee of the taker side,
+// the bids are hit by selling and the asks are lifted by buying.
+func (v consolidatedVenue) adjust(pvs PriceVolumeSlice, takerSide SideType) PriceVolumeSlice {
+	adjusted := make(PriceVolumeSlice, 0, len(pvs))
+	for _, pv := range pvs {
+		price := pv.Price
+		if v.options.QuoteRate > 0 {
+			price = price.Mul(v.options.QuoteRate)
+		}
+
+		if v.options.FeeRate > 0 {
+			fee := price.Mul(v.options.FeeRate)
+			switch takerSide {
+			case SideTypeSell:
+				price = price.Sub(fee)
+			case SideTypeBuy:
+				price = price.Add(fee)
+			}
+		}
+
+		adjusted = append(adjusted, PriceVolume{Price: price, Volume: pv.Volume})
+	}
+	return adjusted
+}
+
+// mergePriceLevels merges the price volumes of the venue into the levels, the levels are not sorted
+func mergePriceLevels(levels []ConsolidatedPriceLevel, venue string, pvs PriceVolumeSlice) []ConsolidatedPriceLevel {
+	index := make(map[fixedpoint.Value]int, len(levels))
+	for i, level := range levels {
+		index[level.Price] = i
+	}
+
+	for _, pv := range pvs {
+		i, ok := index[pv.Price]
+		if !ok {
+			i = len(levels)
+			index[pv.Price] = i
+			levels = append(levels, ConsolidatedPriceLevel{Price: pv.Price})
+		}
+
+		levels[i].Volume += pv.Volume
+		levels[i].Venues = append(levels[i].Venues, VenueVolume{Venue: venue, Volume: pv.Volume})
+	}
+
+	return levels
+}
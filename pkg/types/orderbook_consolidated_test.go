@@ -0,0 +1,82 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+func TestConsolidatedOrderBook(t *testing.T) {
+	binanceBook := NewStreamBook("BTCUSDT")
+	binanceBook.Load(OrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(99.0), fixedpoint.NewFromFloat(2.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(102.0), fixedpoint.NewFromFloat(1.0)},
+		},
+	})
+
+	maxBook := NewStreamBook("BTCTWD")
+	maxBook.Load(OrderBook{
+		Symbol: "BTCTWD",
+		Bids: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(2500.0), fixedpoint.NewFromFloat(0.5)},
+		},
+		Asks: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(2525.0), fixedpoint.NewFromFloat(0.5)},
+		},
+	})
+
+	ftxBook := NewStreamBook("BTCUSDT")
+
+	book := NewConsolidatedOrderBook("BTCUSDT")
+	book.AddVenue("binance", binanceBook, VenueOptions{})
+	book.AddVenue("max", maxBook, VenueOptions{QuoteRate: fixedpoint.NewFromFloat(0.04)})
+	book.AddVenue("ftx", ftxBook, VenueOptions{})
+
+	bids, asks := book.Get()
+	if assert.Len(t, bids, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(100.0), bids[0].Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(1.5), bids[0].Volume)
+		assert.Equal(t, []VenueVolume{
+			{Venue: "binance", Volume: fixedpoint.NewFromFloat(1.0)},
+			{Venue: "max", Volume: fixedpoint.NewFromFloat(0.5)},
+		}, bids[0].Venues)
+	}
+
+	if assert.Len(t, asks, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(101.0), asks[0].Price)
+		assert.Equal(t, "max", asks[0].Venues[0].Venue)
+	}
+
+	assert.NoError(t, book.SetQuoteRate("max", fixedpoint.NewFromFloat(0.04)))
+	assert.Error(t, book.SetQuoteRate("kraken", fixedpoint.NewFromFloat(1.0)))
+
+	feeBook := NewConsolidatedOrderBook("BTCUSDT")
+	feeBook.AddVenue("binance", binanceBook, VenueOptions{})
+	feeBook.AddVenue("max", maxBook, VenueOptions{
+		QuoteRate: fixedpoint.NewFromFloat(0.04),
+		FeeRate:   fixedpoint.NewFromFloat(0.02),
+	})
+
+	// the fee makes the max ask worse than the binance ask
+	ask, ok := feeBook.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(102.0), ask.Price)
+	assert.Equal(t, "binance", ask.Venues[0].Venue)
+
+	bid, ok := feeBook.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, []VenueVolume{{Venue: "binance", Volume: fixedpoint.NewFromFloat(1.0)}}, bid.Venues)
+
+	// the invalidated venue is left out
+	binanceBook.Invalidate()
+	bid, ok = feeBook.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, "max", bid.Venues[0].Venue)
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,3 +49,85 @@ func TestOrderBook_IsValid(t *testing.T) {
 	assert.False(t, isValid)
 	assert.EqualError(t, err, "bid price 80000.000000 > ask price 100.000000")
 }
+
+func TestMutexOrderBook_Reload(t *testing.T) {
+	book := NewMutexOrderBook("BTCUSDT")
+	book.Load(OrderBook{
+		Bids: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(1.5)},
+			{fixedpoint.NewFromFloat(90.0), fixedpoint.NewFromFloat(2.5)},
+		},
+		Asks: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(110.0), fixedpoint.NewFromFloat(1.5)},
+		},
+	})
+
+	updated := 0
+	book.OnUpdate(func(book *OrderBook) {
+		updated++
+	})
+
+	ticker := BookTicker{Symbol: "BTCUSDT", Buy: 101.0, BuySize: 0.5, Sell: 105.0, SellSize: 1.0}
+	book.reload(ticker.OrderBook())
+	assert.Equal(t, 1, updated)
+
+	b := book.Get()
+	assert.Len(t, b.Bids, 1)
+	assert.Len(t, b.Asks, 1)
+
+	bid, _ := b.BestBid()
+	assert.Equal(t, fixedpoint.NewFromFloat(101.0), bid.Price)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.5), bid.Volume)
+
+	ask, _ := b.BestAsk()
+	assert.Equal(t, fixedpoint.NewFromFloat(105.0), ask.Price)
+}
+
+type testStream struct {
+	StandardStream
+}
+
+func (s *testStream) SetPublicOnly()                    {}
+func (s *testStream) Connect(ctx context.Context) error { return nil }
+func (s *testStream) Close() error                      { return nil }
+
+func TestStreamOrderBook_BookInvalidated(t *testing.T) {
+	stream := &testStream{}
+	book := NewStreamBook("BTCUSDT")
+	book.BindStream(stream)
+
+	snapshot := OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   PriceVolumeSlice{{fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(1.5)}},
+		Asks:   PriceVolumeSlice{{fixedpoint.NewFromFloat(110.0), fixedpoint.NewFromFloat(1.5)}},
+	}
+	update := OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   PriceVolumeSlice{{fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0)}},
+	}
+
+	// the updates before the snapshot are dropped
+	stream.EmitBookUpdate(update)
+	assert.False(t, book.IsReady())
+	assert.Len(t, book.Get().Bids, 0)
+
+	stream.EmitBookSnapshot(snapshot)
+	stream.EmitBookUpdate(update)
+	assert.True(t, book.IsReady())
+	assert.Len(t, book.Get().Bids, 2)
+
+	// the book of the other symbol is not affected
+	stream.EmitBookInvalidated("ETHUSDT", BookInvalidReasonSequenceGap)
+	assert.True(t, book.IsReady())
+
+	stream.EmitBookInvalidated("BTCUSDT", BookInvalidReasonSequenceGap)
+	assert.False(t, book.IsReady())
+	assert.Len(t, book.Get().Bids, 0)
+
+	stream.EmitBookUpdate(update)
+	assert.Len(t, book.Get().Bids, 0)
+
+	stream.EmitBookSnapshot(snapshot)
+	assert.True(t, book.IsReady())
+	assert.Len(t, book.Get().Bids, 1)
+}
@@ -0,0 +1,410 @@
+package types
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+const priceLevelsMaxHeight = 16
+
+type priceLevel struct {
+	PriceVolume
+
+	next [priceLevelsMaxHeight]*priceLevel
+}
+
+// priceLevels is a skiplist of the price levels of one side of the book, the levels are sorted from the best price,
+// so the updates are O(log n). The removed nodes are reused, so the updates do not allocate in the steady state.
+type priceLevels struct {
+	// descending is true for the bids
+	descending bool
+
+	head   priceLevel
+	height int
+	length int
+
+	// free is the list of the removed nodes linked by next[0]
+	free *priceLevel
+
+	// rnd is the state of the xorshift random number generator for the node height
+	rnd uint64
+}
+
+func newPriceLevels(descending bool) priceLevels {
+	return priceLevels{
+		descending: descending,
+		height:     1,
+		rnd:        0x9E3779B97F4A7C15,
+	}
+}
+
+// better returns true if price a is better than price b
+func (l *priceLevels) better(a, b fixedpoint.Value) bool {
+	if l.descending {
+		return a > b
+	}
+	return a < b
+}
+
+// find returns the node of the price (or the node after it) and fills the previous nodes of each height into prev
+func (l *priceLevels) find(price fixedpoint.Value, prev *[priceLevelsMaxHeight]*priceLevel) *priceLevel {
+	x := &l.head
+	for i := l.height - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.better(x.next[i].Price, price) {
+			x = x.next[i]
+		}
+		prev[i] = x
+	}
+	return x.next[0]
+}
+
+func (l *priceLevels) upsert(pv PriceVolume) {
+	var prev [priceLevelsMaxHeight]*priceLevel
+	n := l.find(pv.Price, &prev)
+	if n != nil && n.Price == pv.Price {
+		n.Volume = pv.Volume
+		return
+	}
+
+	height := l.randomHeight()
+	for i := l.height; i < height; i++ {
+		prev[i] = &l.head
+	}
+	if height > l.height {
+		l.height = height
+	}
+
+	node := l.alloc()
+	node.PriceVolume = pv
+	for i := 0; i < height; i++ {
+		node.next[i] = prev[i].next[i]
+		prev[i].next[i] = node
+	}
+	l.length++
+}
+
+func (l *priceLevels) remove(price fixedpoint.Value) bool {
+	var prev [priceLevelsMaxHeight]*priceLevel
+	n := l.find(price, &prev)
+	if n == nil || n.Price != price {
+		return false
+	}
+
+	for i := 0; i < l.height && prev[i].next[i] == n; i++ {
+		prev[i].next[i] = n.next[i]
+	}
+
+	for l.height > 1 && l.head.next[l.height-1] == nil {
+		l.height--
+	}
+
+	l.length--
+	l.release(n)
+	return true
+}
+
+// truncate removes the worst levels until there are at most depth levels
+func (l *priceLevels) truncate(depth int) {
+	for l.length > depth {
+		x := &l.head
+		for i := l.height - 1; i >= 0; i-- {
+			for x.next[i] != nil {
+				x = x.next[i]
+			}
+		}
+		l.remove(x.Price)
+	}
+}
+
+func (l *priceLevels) first() (PriceVolume, bool) {
+	if n := l.head.next[0]; n != nil {
+		return n.PriceVolume, true
+	}
+	return PriceVolume{}, false
+}
+
+// appendTo appends the levels from the best price to the slice
+func (l *priceLevels) appendTo(pvs PriceVolumeSlice) PriceVolumeSlice {
+	for n := l.head.next[0]; n != nil; n = n.next[0] {
+		pvs = append(pvs, n.PriceVolume)
+	}
+	return pvs
+}
+
+func (l *priceLevels) reset() {
+	for n := l.head.next[0]; n != nil; {
+		next := n.next[0]
+		l.release(n)
+		n = next
+	}
+
+	l.head.next = [priceLevelsMaxHeight]*priceLevel{}
+	l.height = 1
+	l.length = 0
+}
+
+func (l *priceLevels) alloc() *priceLevel {
+	if l.free == nil {
+		return &priceLevel{}
+	}
+
+	n := l.free
+	l.free = n.next[0]
+	n.next = [priceLevelsMaxHeight]*priceLevel{}
+	return n
+}
+
+func (l *priceLevels) release(n *priceLevel) {
+	n.next = [priceLevelsMaxHeight]*priceLevel{}
+	n.next[0] = l.free
+	l.free = n
+}
+
+// randomHeight returns the height of the new node, the probability of each extra level is 1/4
+func (l *priceLevels) randomHeight() int {
+	l.rnd ^= l.rnd << 13
+	l.rnd ^= l.rnd >> 7
+	l.rnd ^= l.rnd << 17
+
+	height := 1
+	for r := l.rnd; height < priceLevelsMaxHeight && r&3 == 0; r >>= 2 {
+		height++
+	}
+	return height
+}
+
+// TreeOrderBook is an order book backed by skiplists, the level updates are O(log n) instead of the slice copying
+// of OrderBook. The best bid and ask are published with a sequence lock, so reading them is lock-free, and
+// CopyInto reuses the slices of the given book, so copying does not allocate once the slices are large enough.
+type TreeOrderBook struct {
+	Symbol string
+
+	// maxDepth is the max number of the levels of each side, the worst levels are dropped. Zero means unlimited.
+	maxDepth int
+
+	// mu protects bids, asks and the flattened levels
+	mu   sync.Mutex
+	bids priceLevels
+	asks priceLevels
+
+	// flatBids and flatAsks are the levels flattened into slices, they are rebuilt by the first copy after an update,
+	// so the copies between the updates are bulk slice copies instead of walking the lists.
+	flatBids, flatAsks PriceVolumeSlice
+	flatValid          bool
+
+	// seq is the sequence of the best bid and ask below, it's odd while they are being written
+	seq           uint64
+	bestBidPrice  int64
+	bestBidVolume int64
+	bestAskPrice  int64
+	bestAskVolume int64
+
+	// ready is set when the snapshot is loaded, and it's cleared when the book is invalidated.
+	ready int32
+}
+
+func NewTreeOrderBook(symbol string, maxDepth int) *TreeOrderBook {
+	return &TreeOrderBook{
+		Symbol:   symbol,
+		maxDepth: maxDepth,
+		bids:     newPriceLevels(true),
+		asks:     newPriceLevels(false),
+	}
+}
+
+// Load replaces the book with the snapshot
+func (b *TreeOrderBook) Load(book OrderBook) {
+	b.mu.Lock()
+	b.bids.reset()
+	b.asks.reset()
+	b.update(book)
+	b.mu.Unlock()
+
+	atomic.StoreInt32(&b.ready, 1)
+}
+
+// Update applies the levels of the update, the level of zero volume is removed
+func (b *TreeOrderBook) Update(book OrderBook) {
+	b.mu.Lock()
+	b.update(book)
+	b.mu.Unlock()
+}
+
+// Invalidate resets the book, the book will be ready again after the next snapshot
+func (b *TreeOrderBook) Invalidate() {
+	atomic.StoreInt32(&b.ready, 0)
+	b.Reset()
+}
+
+func (b *TreeOrderBook) Reset() {
+	b.mu.Lock()
+	b.bids.reset()
+	b.asks.reset()
+	b.flatValid = false
+	b.publishBest()
+	b.mu.Unlock()
+}
+
+// update applies the levels and publishes the best bid and ask, the caller must hold the lock.
+func (b *TreeOrderBook) update(book OrderBook) {
+	updatePriceLevels(&b.bids, book.Bids, b.maxDepth)
+	updatePriceLevels(&b.asks, book.Asks, b.maxDepth)
+	b.flatValid = false
+	b.publishBest()
+}
+
+// flatten rebuilds the flattened levels if the book is changed since the last copy, the caller must hold the lock.
+func (b *TreeOrderBook) flatten() {
+	if b.flatValid {
+		return
+	}
+
+	b.flatBids = b.bids.appendTo(b.flatBids[:0])
+	b.flatAsks = b.asks.appendTo(b.flatAsks[:0])
+	b.flatValid = true
+}
+
+func updatePriceLevels(levels *priceLevels, pvs PriceVolumeSlice, maxDepth int) {
+	for _, pv := range pvs {
+		if pv.Volume == 0 {
+			levels.remove(pv.Price)
+		} else {
+			levels.upsert(pv)
+		}
+	}
+
+	if maxDepth > 0 {
+		levels.truncate(maxDepth)
+	}
+}
+
+// publishBest writes the best bid and ask with the sequence lock, the caller must hold the lock.
+func (b *TreeOrderBook) publishBest() {
+	bid, _ := b.bids.first()
+	ask, _ := b.asks.first()
+
+	atomic.AddUint64(&b.seq, 1)
+	atomic.StoreInt64(&b.bestBidPrice, int64(bid.Price))
+	atomic.StoreInt64(&b.bestBidVolume, int64(bid.Volume))
+	atomic.StoreInt64(&b.bestAskPrice, int64(ask.Price))
+	atomic.StoreInt64(&b.bestAskVolume, int64(ask.Volume))
+	atomic.AddUint64(&b.seq, 1)
+}
+
+// BestBidAndAsk returns the best bid and ask without locking the book, the zero volume means the side is empty
+func (b *TreeOrderBook) BestBidAndAsk() (bid, ask PriceVolume) {
+	for {
+		seq := atomic.LoadUint64(&b.seq)
+		if seq&1 == 1 {
+			// the writer is publishing, yield instead of spinning on the writer's processor
+			runtime.Gosched()
+			continue
+		}
+
+		bid.Price = fixedpoint.Value(atomic.LoadInt64(&b.bestBidPrice))
+		bid.Volume = fixedpoint.Value(atomic.LoadInt64(&b.bestBidVolume))
+		ask.Price = fixedpoint.Value(atomic.LoadInt64(&b.bestAskPrice))
+		ask.Volume = fixedpoint.Value(atomic.LoadInt64(&b.bestAskVolume))
+
+		if atomic.LoadUint64(&b.seq) == seq {
+			return bid, ask
+		}
+	}
+}
+
+func (b *TreeOrderBook) BestBid() (PriceVolume, bool) {
+	bid, _ := b.BestBidAndAsk()
+	return bid, bid.Volume > 0
+}
+
+func (b *TreeOrderBook) BestAsk() (PriceVolume, bool) {
+	_, ask := b.BestBidAndAsk()
+	return ask, ask.Volume > 0
+}
+
+func (b *TreeOrderBook) IsValid() (bool, error) {
+	bid, ask := b.BestBidAndAsk()
+
+	if bid.Volume == 0 {
+		return false, errors.New("empty bids")
+	}
+
+	if ask.Volume == 0 {
+		return false, errors.New("empty asks")
+	}
+
+	if bid.Price > ask.Price {
+		return false, fmt.Errorf("bid price %f > ask price %f", bid.Price.Float64(), ask.Price.Float64())
+	}
+
+	return true, nil
+}
+
+// IsReady returns true if the book is loaded from a snapshot and is not invalidated since then
+func (b *TreeOrderBook) IsReady() bool {
+	return atomic.LoadInt32(&b.ready) == 1
+}
+
+// Len returns the number of the bid levels and the ask levels
+func (b *TreeOrderBook) Len() (bids, asks int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bids.length, b.asks.length
+}
+
+// CopyInto copies the book into the given book, the slices of the given book are reused.
+func (b *TreeOrderBook) CopyInto(book *OrderBook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flatten()
+	book.Symbol = b.Symbol
+	book.Bids = append(book.Bids[:0], b.flatBids...)
+	book.Asks = append(book.Asks[:0], b.flatAsks...)
+}
+
+// Get returns a copy of the book
+func (b *TreeOrderBook) Get() (book OrderBook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flatten()
+	book.Symbol = b.Symbol
+	book.Bids = append(make(PriceVolumeSlice, 0, len(b.flatBids)), b.flatBids...)
+	book.Asks = append(make(PriceVolumeSlice, 0, len(b.flatAsks)), b.flatAsks...)
+	return book
+}
+
+// BindStream loads the snapshots and applies the updates of the symbol, the updates are dropped until the book is
+// loaded from a snapshot, and the book is reset when it's invalidated.
+func (b *TreeOrderBook) BindStream(stream Stream) {
+	stream.OnBookSnapshot(func(book OrderBook) {
+		if b.Symbol != book.Symbol {
+			return
+		}
+
+		b.Load(book)
+	})
+
+	stream.OnBookUpdate(func(book OrderBook) {
+		if b.Symbol != book.Symbol || !b.IsReady() {
+			return
+		}
+
+		b.Update(book)
+	})
+
+	stream.OnBookInvalidated(func(symbol string, reason BookInvalidReason) {
+		if b.Symbol != symbol {
+			return
+		}
+
+		b.Invalidate()
+	})
+}
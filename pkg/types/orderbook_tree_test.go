@@ -0,0 +1,247 @@
+package types
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
+)
+
+// randomBookUpdates generates the updates around the mid price, about 1/4 of them remove the levels
+func randomBookUpdates(r *rand.Rand, n, levels int) []OrderBook {
+	updates := make([]OrderBook, n)
+	for i := range updates {
+		var bids, asks PriceVolumeSlice
+		for j := 0; j < 5; j++ {
+			volume := fixedpoint.NewFromFloat(float64(r.Intn(4)))
+			bids = append(bids, PriceVolume{Price: fixedpoint.NewFromFloat(float64(10000 - 1 - r.Intn(levels))), Volume: volume})
+
+			volume = fixedpoint.NewFromFloat(float64(r.Intn(4)))
+			asks = append(asks, PriceVolume{Price: fixedpoint.NewFromFloat(float64(10000 + r.Intn(levels))), Volume: volume})
+		}
+
+		updates[i] = OrderBook{Symbol: "BTCUSDT", Bids: bids, Asks: asks}
+	}
+	return updates
+}
+
+func TestTreeOrderBook_Update(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	updates := randomBookUpdates(r, 2000, 200)
+
+	expected := &OrderBook{Symbol: "BTCUSDT"}
+	book := NewTreeOrderBook("BTCUSDT", 0)
+	for _, update := range updates {
+		expected.Update(update)
+		book.Update(update)
+	}
+
+	// the tree book should be the same as the slice book
+	actual := book.Get()
+	assert.Equal(t, expected.Bids, actual.Bids)
+	assert.Equal(t, expected.Asks, actual.Asks)
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, expected.Bids[0], bid)
+
+	ask, ok := book.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, expected.Asks[0], ask)
+
+	var copied OrderBook
+	book.CopyInto(&copied)
+	assert.Equal(t, actual, copied)
+
+	// the copies after an update see the update
+	update := OrderBook{Symbol: "BTCUSDT", Bids: PriceVolumeSlice{{Price: expected.Bids[0].Price, Volume: 0}}}
+	expected.Update(update)
+	book.Update(update)
+	book.CopyInto(&copied)
+	assert.Equal(t, expected.Bids, copied.Bids)
+	assert.Equal(t, expected.Bids, book.Get().Bids)
+
+	book.Reset()
+	_, ok = book.BestBid()
+	assert.False(t, ok)
+	bids, asks := book.Len()
+	assert.Equal(t, 0, bids)
+	assert.Equal(t, 0, asks)
+	assert.Empty(t, book.Get().Bids)
+}
+
+func TestTreeOrderBook_MaxDepth(t *testing.T) {
+	book := NewTreeOrderBook("BTCUSDT", 2)
+	book.Load(OrderBook{
+		Bids: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(99.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(98.0), fixedpoint.NewFromFloat(1.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{fixedpoint.NewFromFloat(103.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0)},
+			{fixedpoint.NewFromFloat(102.0), fixedpoint.NewFromFloat(1.0)},
+		},
+	})
+
+	b := book.Get()
+	assert.Equal(t, PriceVolumeSlice{
+		{fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(1.0)},
+		{fixedpoint.NewFromFloat(99.0), fixedpoint.NewFromFloat(1.0)},
+	}, b.Bids)
+	assert.Equal(t, PriceVolumeSlice{
+		{fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0)},
+		{fixedpoint.NewFromFloat(102.0), fixedpoint.NewFromFloat(1.0)},
+	}, b.Asks)
+
+	valid, err := book.IsValid()
+	assert.True(t, valid)
+	assert.NoError(t, err)
+}
+
+func TestTreeOrderBook_BindStream(t *testing.T) {
+	stream := &testStream{}
+	book := NewTreeOrderBook("BTCUSDT", 0)
+	book.BindStream(stream)
+
+	update := OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   PriceVolumeSlice{{fixedpoint.NewFromFloat(101.0), fixedpoint.NewFromFloat(1.0)}},
+	}
+
+	// the updates before the snapshot are dropped
+	stream.EmitBookUpdate(update)
+	assert.False(t, book.IsReady())
+	_, ok := book.BestBid()
+	assert.False(t, ok)
+
+	stream.EmitBookSnapshot(OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   PriceVolumeSlice{{fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(1.5)}},
+	})
+	stream.EmitBookUpdate(update)
+	bid, _ := book.BestBid()
+	assert.Equal(t, fixedpoint.NewFromFloat(101.0), bid.Price)
+
+	stream.EmitBookInvalidated("BTCUSDT", BookInvalidReasonSequenceGap)
+	assert.False(t, book.IsReady())
+	_, ok = book.BestBid()
+	assert.False(t, ok)
+}
+
+func TestTreeOrderBook_ConcurrentRead(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	updates := randomBookUpdates(r, 1000, 50)
+	book := NewTreeOrderBook("BTCUSDT", 0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, update := range updates {
+			book.Update(update)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		bid, ask := book.BestBidAndAsk()
+		if bid.Volume > 0 && ask.Volume > 0 {
+			assert.True(t, bid.Price < ask.Price)
+		}
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkOrderBook_Update(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	updates := randomBookUpdates(r, 10000, 1000)
+
+	b.Run("MutexOrderBook", func(b *testing.B) {
+		// no update callback on both books, the update callbacks of MutexOrderBook copy the whole book
+		book := NewMutexOrderBook("BTCUSDT")
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			book.Update(updates[i%len(updates)])
+		}
+	})
+
+	b.Run("TreeOrderBook", func(b *testing.B) {
+		book := NewTreeOrderBook("BTCUSDT", 0)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			book.Update(updates[i%len(updates)])
+		}
+	})
+}
+
+func BenchmarkOrderBook_BestBid(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	updates := randomBookUpdates(r, 1000, 1000)
+
+	b.Run("MutexOrderBook", func(b *testing.B) {
+		book := NewMutexOrderBook("BTCUSDT")
+		for _, update := range updates {
+			book.Update(update)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			book.Lock()
+			_, _ = book.BestBid()
+			book.Unlock()
+		}
+	})
+
+	b.Run("TreeOrderBook", func(b *testing.B) {
+		book := NewTreeOrderBook("BTCUSDT", 0)
+		for _, update := range updates {
+			book.Update(update)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = book.BestBid()
+		}
+	})
+}
+
+func BenchmarkOrderBook_Copy(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	updates := randomBookUpdates(r, 1000, 1000)
+
+	b.Run("MutexOrderBook", func(b *testing.B) {
+		book := NewMutexOrderBook("BTCUSDT")
+		for _, update := range updates {
+			book.Update(update)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = book.Get()
+		}
+	})
+
+	b.Run("TreeOrderBook", func(b *testing.B) {
+		book := NewTreeOrderBook("BTCUSDT", 0)
+		for _, update := range updates {
+			book.Update(update)
+		}
+
+		var copied OrderBook
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			book.CopyInto(&copied)
+		}
+	})
+}
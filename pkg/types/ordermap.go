@@ -188,3 +188,14 @@ func (s OrderSlice) IDs() (ids []uint64) {
 	}
 	return ids
 }
+
+// Canceled returns the copies of the orders with the canceled status, it's used for the cancel apis which don't return
+// the canceled orders
+func (s OrderSlice) Canceled() (orders OrderSlice) {
+	for _, o := range s {
+		o.Status = OrderStatusCanceled
+		o.IsWorking = false
+		orders = append(orders, o)
+	}
+	return orders
+}
@@ -0,0 +1,281 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ShardedStream spreads the subscriptions across several streams (shards) by the per-connection subscription limit of
+// the exchange, and it looks like one stream to the callers. The private events are received by the first shard, the
+// other shards are public only. Each shard has its own connection and health state, so it reconnects on its own.
+// The stream is reported as connected only when all the shards are connected, see ShardStatuses for the health of
+// each shard.
+type ShardedStream struct {
+	StandardStream
+
+	// limit is the max number of the subscriptions of a shard
+	limit int
+
+	newShard func() Stream
+
+	// mu protects publicOnly, ctx and shards, the shards can be added after connecting
+	mu         sync.Mutex
+	publicOnly bool
+
+	// ctx is the context of Connect, the shards added after connecting are connected with it
+	ctx context.Context
+
+	shards []*streamShard
+}
+
+type streamShard struct {
+	Stream
+
+	monitor       *StreamMonitor
+	subscriptions []Subscription
+}
+
+func NewShardedStream(limit int, newShard func() Stream) *ShardedStream {
+	s := &ShardedStream{
+		limit:    limit,
+		newShard: newShard,
+	}
+
+	// the first shard is always created for the private events
+	s.addShard()
+	return s
+}
+
+func (s *ShardedStream) SetPublicOnly() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.publicOnly = true
+	if len(s.shards) > 0 {
+		s.shards[0].SetPublicOnly()
+	}
+}
+
+// Subscribe adds the subscription to the first shard with capacity, the repeated subscription is ignored
+func (s *ShardedStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
+	sub := Subscription{
+		Channel: channel,
+		Symbol:  symbol,
+		Options: options,
+	}
+
+	s.mu.Lock()
+
+	if s.hasSubscription(sub) {
+		s.mu.Unlock()
+		return
+	}
+
+	s.StandardStream.Subscribe(channel, symbol, options)
+
+	var shard *streamShard
+	for _, sh := range s.shards {
+		if s.limit <= 0 || len(sh.subscriptions) < s.limit {
+			shard = sh
+			break
+		}
+	}
+
+	newShard := shard == nil
+	if newShard {
+		shard = s.addShard()
+	}
+
+	shard.subscriptions = append(shard.subscriptions, sub)
+	shard.Subscribe(channel, symbol, options)
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	// the shard added after connecting is connected here, the subscriptions are sent after it's connected
+	if newShard && ctx != nil {
+		if err := shard.Connect(ctx); err != nil {
+			s.EmitError(fmt.Errorf("shard connect error: %w", err))
+		}
+	}
+}
+
+// Unsubscribe removes the subscriptions from the shards. The shard without subscriptions is closed and removed, except
+// the first shard which receives the private events.
+func (s *ShardedStream) Unsubscribe(channel Channel, symbol string) {
+	s.StandardStream.Unsubscribe(channel, symbol)
+
+	s.mu.Lock()
+
+	var shards, emptyShards []*streamShard
+	for i, shard := range s.shards {
+		var subscriptions []Subscription
+		for _, sub := range shard.subscriptions {
+			if sub.Channel == channel && sub.Symbol == symbol {
+				continue
+			}
+			subscriptions = append(subscriptions, sub)
+		}
+
+		if len(subscriptions) == len(shard.subscriptions) {
+			shards = append(shards, shard)
+			continue
+		}
+
+		shard.subscriptions = subscriptions
+		if len(subscriptions) == 0 && (i > 0 || s.publicOnly) {
+			emptyShards = append(emptyShards, shard)
+			continue
+		}
+
+		shard.Unsubscribe(channel, symbol)
+		shards = append(shards, shard)
+	}
+
+	s.shards = shards
+	connected := s.ctx != nil
+	s.mu.Unlock()
+
+	// the removed shards are closed without the lock, their disconnect events are ignored
+	if !connected {
+		return
+	}
+
+	for _, shard := range emptyShards {
+		if err := shard.Close(); err != nil {
+			s.EmitError(fmt.Errorf("shard close error: %w", err))
+		}
+	}
+}
+
+func (s *ShardedStream) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	s.ctx = ctx
+	shards := s.shards
+	s.mu.Unlock()
+
+	for i, shard := range shards {
+		if err := shard.Connect(ctx); err != nil {
+			return fmt.Errorf("shard %d connect error: %w", i, err)
+		}
+	}
+
+	s.EmitStart()
+	return nil
+}
+
+func (s *ShardedStream) Close() error {
+	s.mu.Lock()
+	shards := s.shards
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, shard := range shards {
+		if err := shard.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// hasSubscription returns true if the subscription is in one of the shards, the caller must hold the lock.
+func (s *ShardedStream) hasSubscription(sub Subscription) bool {
+	for _, shard := range s.shards {
+		for _, existing := range shard.subscriptions {
+			if existing == sub {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ShardStatuses returns the connection status of each shard
+func (s *ShardedStream) ShardStatuses() []StreamStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]StreamStatus, 0, len(s.shards))
+	for _, shard := range s.shards {
+		status := shard.monitor.Status()
+		status.Subscriptions = append([]Subscription{}, shard.subscriptions...)
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// addShard creates a new shard and forwards its events, the caller must hold the lock.
+func (s *ShardedStream) addShard() *streamShard {
+	shard := &streamShard{
+		Stream:  s.newShard(),
+		monitor: NewStreamMonitor(),
+	}
+
+	if len(s.shards) > 0 || s.publicOnly {
+		shard.SetPublicOnly()
+	}
+
+	shard.monitor.BindStream(shard.Stream)
+	s.forwardEvents(shard)
+	s.shards = append(s.shards, shard)
+	return shard
+}
+
+// handleShardConnect emits the connect event once all the shards are connected, so one connected shard does not
+// hide another broken shard. The shard monitor is bound before this handler, so it has the state of the shard.
+func (s *ShardedStream) handleShardConnect() {
+	s.mu.Lock()
+	connected := true
+	for _, shard := range s.shards {
+		if shard.monitor.Status().State != StreamStateConnected {
+			connected = false
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if connected {
+		s.EmitConnect()
+	}
+}
+
+// handleShardDisconnect emits the disconnect event unless the shard is removed and closed by Unsubscribe.
+func (s *ShardedStream) handleShardDisconnect(shard *streamShard) {
+	s.mu.Lock()
+	removed := true
+	for _, sh := range s.shards {
+		if sh == shard {
+			removed = false
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !removed {
+		s.EmitDisconnect()
+	}
+}
+
+// forwardEvents forwards the events of the shard except the start event, which is emitted once by Connect.
+// The stream is disconnected when any of the shards is disconnected.
+func (s *ShardedStream) forwardEvents(shard *streamShard) {
+	shard.OnConnect(s.handleShardConnect)
+	shard.OnDisconnect(func() {
+		s.handleShardDisconnect(shard)
+	})
+	shard.OnMessage(s.EmitMessage)
+	shard.OnError(s.EmitError)
+	shard.OnTradeUpdate(s.EmitTradeUpdate)
+	shard.OnOrderUpdate(s.EmitOrderUpdate)
+	shard.OnBalanceSnapshot(s.EmitBalanceSnapshot)
+	shard.OnBalanceUpdate(s.EmitBalanceUpdate)
+	shard.OnKLineClosed(s.EmitKLineClosed)
+	shard.OnKLine(s.EmitKLine)
+	shard.OnBookUpdate(s.EmitBookUpdate)
+	shard.OnBookSnapshot(s.EmitBookSnapshot)
+	shard.OnMarketTrade(s.EmitMarketTrade)
+	shard.OnBookTicker(s.EmitBookTicker)
+	shard.OnBookInvalidated(s.EmitBookInvalidated)
+}
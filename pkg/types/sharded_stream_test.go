@@ -0,0 +1,165 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testShardStream struct {
+	testStream
+
+	publicOnly bool
+	connected  bool
+	closed     bool
+}
+
+func (s *testShardStream) SetPublicOnly() { s.publicOnly = true }
+
+func (s *testShardStream) Connect(ctx context.Context) error {
+	s.connected = true
+	s.EmitConnect()
+	return nil
+}
+
+func (s *testShardStream) Close() error {
+	s.closed = true
+	s.EmitDisconnect()
+	return nil
+}
+
+func TestShardedStream(t *testing.T) {
+	var shards []*testShardStream
+	stream := NewShardedStream(2, func() Stream {
+		shard := &testShardStream{}
+		shards = append(shards, shard)
+		return shard
+	})
+
+	stream.Subscribe(BookChannel, "BTCUSDT", SubscribeOptions{})
+	stream.Subscribe(BookChannel, "ETHUSDT", SubscribeOptions{})
+	stream.Subscribe(BookChannel, "BNBUSDT", SubscribeOptions{})
+
+	// the repeated subscription is ignored
+	stream.Subscribe(BookChannel, "BTCUSDT", SubscribeOptions{})
+	if assert.Len(t, shards, 2) {
+		assert.False(t, shards[0].publicOnly, "the first shard receives the private events")
+		assert.True(t, shards[1].publicOnly)
+		assert.Len(t, shards[0].Subscriptions, 2)
+		assert.Len(t, shards[1].Subscriptions, 1)
+	}
+	assert.Len(t, stream.Subscriptions, 3)
+
+	var books []string
+	stream.OnBookSnapshot(func(book OrderBook) {
+		books = append(books, book.Symbol)
+	})
+
+	assert.NoError(t, stream.Connect(context.Background()))
+	shards[1].EmitBookSnapshot(OrderBook{Symbol: "BNBUSDT"})
+	assert.Equal(t, []string{"BNBUSDT"}, books)
+
+	// the subscription after connecting is added to the shard with capacity
+	stream.Unsubscribe(BookChannel, "ETHUSDT")
+	assert.Len(t, shards[0].Subscriptions, 1)
+	stream.Subscribe(BookChannel, "XRPUSDT", SubscribeOptions{})
+	assert.Len(t, shards[0].Subscriptions, 2)
+
+	// the new shard is connected immediately
+	stream.Subscribe(BookChannel, "ADAUSDT", SubscribeOptions{})
+	stream.Subscribe(BookChannel, "DOTUSDT", SubscribeOptions{})
+	if assert.Len(t, shards, 3) {
+		assert.True(t, shards[2].connected)
+	}
+
+	statuses := stream.ShardStatuses()
+	if assert.Len(t, statuses, 3) {
+		assert.Equal(t, StreamStateConnected, statuses[2].State)
+		assert.Len(t, statuses[2].Subscriptions, 1)
+	}
+
+	// one broken shard makes the stream unhealthy
+	shards[1].EmitDisconnect()
+	status := StreamStatus{State: StreamStateConnected, ConnectedTime: time.Now(), Shards: stream.ShardStatuses()}
+	assert.Error(t, status.Check(time.Now(), 0))
+}
+
+func TestShardedStream_ConnectionState(t *testing.T) {
+	var shards []*testShardStream
+	stream := NewShardedStream(1, func() Stream {
+		shard := &testShardStream{}
+		shards = append(shards, shard)
+		return shard
+	})
+
+	monitor := NewStreamMonitor()
+	monitor.BindStream(stream)
+
+	stream.Subscribe(BookChannel, "BTCUSDT", SubscribeOptions{})
+	stream.Subscribe(BookChannel, "ETHUSDT", SubscribeOptions{})
+	if !assert.Len(t, shards, 2) {
+		return
+	}
+
+	// the stream is connected after all the shards are connected
+	shards[0].EmitConnect()
+	assert.Equal(t, StreamStateConnecting, monitor.Status().State)
+	shards[1].EmitConnect()
+	assert.Equal(t, StreamStateConnected, monitor.Status().State)
+
+	// one disconnected shard disconnects the stream, the other connected shard does not hide it
+	shards[1].EmitDisconnect()
+	assert.Equal(t, StreamStateReconnecting, monitor.Status().State)
+	shards[0].EmitConnect()
+	assert.Equal(t, StreamStateReconnecting, monitor.Status().State)
+
+	shards[1].EmitConnect()
+	assert.Equal(t, StreamStateConnected, monitor.Status().State)
+
+	statuses := stream.ShardStatuses()
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, 1, statuses[1].ReconnectCount)
+	}
+}
+
+func TestShardedStream_UnsubscribeEmptyShard(t *testing.T) {
+	var shards []*testShardStream
+	stream := NewShardedStream(1, func() Stream {
+		shard := &testShardStream{}
+		shards = append(shards, shard)
+		return shard
+	})
+
+	monitor := NewStreamMonitor()
+	monitor.BindStream(stream)
+
+	stream.Subscribe(BookChannel, "BTCUSDT", SubscribeOptions{})
+	stream.Subscribe(BookChannel, "ETHUSDT", SubscribeOptions{})
+	stream.Subscribe(BookChannel, "BNBUSDT", SubscribeOptions{})
+	assert.NoError(t, stream.Connect(context.Background()))
+	if !assert.Len(t, shards, 3) {
+		return
+	}
+
+	// the empty shard is closed and removed, its disconnect event does not disconnect the stream
+	stream.Unsubscribe(BookChannel, "ETHUSDT")
+	assert.True(t, shards[1].closed)
+	assert.Len(t, stream.ShardStatuses(), 2)
+	assert.Equal(t, StreamStateConnected, monitor.Status().State)
+
+	// the first shard is kept for the private events
+	stream.Unsubscribe(BookChannel, "BTCUSDT")
+	assert.False(t, shards[0].closed)
+	statuses := stream.ShardStatuses()
+	if assert.Len(t, statuses, 2) {
+		assert.Empty(t, statuses[0].Subscriptions)
+		assert.Len(t, statuses[1].Subscriptions, 1)
+	}
+
+	// the first shard with capacity takes the new subscription
+	stream.Subscribe(BookChannel, "ETHUSDT", SubscribeOptions{})
+	assert.Len(t, shards, 3)
+	assert.Len(t, shards[0].Subscriptions, 1)
+}
@@ -32,6 +32,26 @@ func (stream *StandardStream) EmitDisconnect() {
 	}
 }
 
+func (stream *StandardStream) OnMessage(cb func()) {
+	stream.messageCallbacks = append(stream.messageCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitMessage() {
+	for _, cb := range stream.messageCallbacks {
+		cb()
+	}
+}
+
+func (stream *StandardStream) OnError(cb func(err error)) {
+	stream.errorCallbacks = append(stream.errorCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitError(err error) {
+	for _, cb := range stream.errorCallbacks {
+		cb(err)
+	}
+}
+
 func (stream *StandardStream) OnTradeUpdate(cb func(trade Trade)) {
 	stream.tradeUpdateCallbacks = append(stream.tradeUpdateCallbacks, cb)
 }
@@ -112,6 +132,36 @@ func (stream *StandardStream) EmitBookSnapshot(book OrderBook) {
 	}
 }
 
+func (stream *StandardStream) OnMarketTrade(cb func(trade Trade)) {
+	stream.marketTradeCallbacks = append(stream.marketTradeCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitMarketTrade(trade Trade) {
+	for _, cb := range stream.marketTradeCallbacks {
+		cb(trade)
+	}
+}
+
+func (stream *StandardStream) OnBookTicker(cb func(bookTicker BookTicker)) {
+	stream.bookTickerCallbacks = append(stream.bookTickerCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitBookTicker(bookTicker BookTicker) {
+	for _, cb := range stream.bookTickerCallbacks {
+		cb(bookTicker)
+	}
+}
+
+func (stream *StandardStream) OnBookInvalidated(cb func(symbol string, reason BookInvalidReason)) {
+	stream.bookInvalidatedCallbacks = append(stream.bookInvalidatedCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitBookInvalidated(symbol string, reason BookInvalidReason) {
+	for _, cb := range stream.bookInvalidatedCallbacks {
+		cb(symbol, reason)
+	}
+}
+
 type StandardStreamEventHub interface {
 	OnStart(cb func())
 
@@ -119,6 +169,10 @@ type StandardStreamEventHub interface {
 
 	OnDisconnect(cb func())
 
+	OnMessage(cb func())
+
+	OnError(cb func(err error))
+
 	OnTradeUpdate(cb func(trade Trade))
 
 	OnOrderUpdate(cb func(order Order))
@@ -134,4 +188,10 @@ type StandardStreamEventHub interface {
 	OnBookUpdate(cb func(book OrderBook))
 
 	OnBookSnapshot(cb func(book OrderBook))
+
+	OnMarketTrade(cb func(trade Trade))
+
+	OnBookTicker(cb func(bookTicker BookTicker))
+
+	OnBookInvalidated(cb func(symbol string, reason BookInvalidReason))
 }
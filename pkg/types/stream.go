@@ -2,12 +2,19 @@ package types
 
 import (
 	"context"
+	"sync"
 )
 
 type Stream interface {
 	StandardStreamEventHub
 
+	// Subscribe adds the subscription, it can be called before or after Connect
 	Subscribe(channel Channel, symbol string, options SubscribeOptions)
+
+	// Unsubscribe removes all the subscriptions of the channel and the symbol, e.g., the klines of all the intervals,
+	// it can be called before or after Connect
+	Unsubscribe(channel Channel, symbol string)
+
 	SetPublicOnly()
 	Connect(ctx context.Context) error
 	Close() error
@@ -19,8 +26,34 @@ var BookChannel = Channel("book")
 
 var KLineChannel = Channel("kline")
 
+// MarketTradeChannel is the public trade channel of the market
+var MarketTradeChannel = Channel("trade")
+
+// BookTickerChannel is the best bid/ask channel, it's lighter than the full depth book channel
+var BookTickerChannel = Channel("bookTicker")
+
+// BookInvalidReason is the reason why the order book is no longer consistent with the exchange
+type BookInvalidReason string
+
+const (
+	// BookInvalidReasonSequenceGap means some of the book updates are missing
+	BookInvalidReasonSequenceGap = BookInvalidReason("sequenceGap")
+
+	// BookInvalidReasonChecksum means the checksum of the local book doesn't match the exchange one
+	BookInvalidReasonChecksum = BookInvalidReason("checksum")
+
+	// BookInvalidReasonCrossed means the best bid price is higher than the best ask price
+	BookInvalidReasonCrossed = BookInvalidReason("crossed")
+
+	// BookInvalidReasonReconnect means the updates might be lost while reconnecting
+	BookInvalidReasonReconnect = BookInvalidReason("reconnect")
+)
+
 //go:generate callbackgen -type StandardStream -interface
 type StandardStream struct {
+	// subscriptionsMutex protects Subscriptions, the subscriptions can be changed after connecting from any goroutine
+	subscriptionsMutex sync.Mutex
+
 	Subscriptions []Subscription
 
 	startCallbacks []func()
@@ -29,6 +62,11 @@ type StandardStream struct {
 
 	disconnectCallbacks []func()
 
+	// message callbacks are called on every message received from the connection, they are used for the health check
+	messageCallbacks []func()
+
+	errorCallbacks []func(err error)
+
 	// private trade update callbacks
 	tradeUpdateCallbacks []func(trade Trade)
 
@@ -47,9 +85,21 @@ type StandardStream struct {
 	bookUpdateCallbacks []func(book OrderBook)
 
 	bookSnapshotCallbacks []func(book OrderBook)
+
+	// public market trade callbacks, the side of the trade is the taker side
+	marketTradeCallbacks []func(trade Trade)
+
+	// best bid/ask callbacks
+	bookTickerCallbacks []func(bookTicker BookTicker)
+
+	// book invalidated callbacks, the book of the symbol should be reset and wait for the next snapshot
+	bookInvalidatedCallbacks []func(symbol string, reason BookInvalidReason)
 }
 
 func (stream *StandardStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
+	stream.subscriptionsMutex.Lock()
+	defer stream.subscriptionsMutex.Unlock()
+
 	stream.Subscriptions = append(stream.Subscriptions, Subscription{
 		Channel: channel,
 		Symbol:  symbol,
@@ -57,6 +107,29 @@ func (stream *StandardStream) Subscribe(channel Channel, symbol string, options
 	})
 }
 
+// Unsubscribe removes the subscriptions of the channel and the symbol
+func (stream *StandardStream) Unsubscribe(channel Channel, symbol string) {
+	stream.subscriptionsMutex.Lock()
+	defer stream.subscriptionsMutex.Unlock()
+
+	var subscriptions []Subscription
+	for _, sub := range stream.Subscriptions {
+		if sub.Channel == channel && sub.Symbol == symbol {
+			continue
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	stream.Subscriptions = subscriptions
+}
+
+// GetSubscriptions returns a copy of the subscriptions, it's safe to call while the subscriptions are being changed
+func (stream *StandardStream) GetSubscriptions() []Subscription {
+	stream.subscriptionsMutex.Lock()
+	defer stream.subscriptionsMutex.Unlock()
+
+	return append([]Subscription(nil), stream.Subscriptions...)
+}
+
 // SubscribeOptions provides the standard stream options
 type SubscribeOptions struct {
 	Interval string `json:"interval,omitempty"`
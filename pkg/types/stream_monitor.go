@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type StreamState string
+
+const (
+	StreamStateConnecting   = StreamState("connecting")
+	StreamStateConnected    = StreamState("connected")
+	StreamStateReconnecting = StreamState("reconnecting")
+)
+
+// StreamStatus is the connection status report of a stream
+type StreamStatus struct {
+	State StreamState `json:"state"`
+
+	// ConnectedTime is the time of the last (re)connection
+	ConnectedTime time.Time `json:"connectedTime,omitempty"`
+
+	// LastMessageTime is the time of the last message received from the stream
+	LastMessageTime time.Time `json:"lastMessageTime,omitempty"`
+
+	// ReconnectCount is the number of the successful reconnections
+	ReconnectCount int `json:"reconnectCount"`
+
+	Subscriptions []Subscription `json:"subscriptions"`
+
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorTime time.Time `json:"lastErrorTime,omitempty"`
+
+	// Shards are the status of the connections if the stream is sharded
+	Shards []StreamStatus `json:"shards,omitempty"`
+}
+
+// Check returns an error if the stream (or any of its shards) is not connected, or there is no message received in maxIdle.
+// A zero maxIdle disables the idle check. The idle check only applies to the streams with the market data subscriptions,
+// the private only streams could be quiet for a long time if there is no account activity.
+func (s StreamStatus) Check(now time.Time, maxIdle time.Duration) error {
+	if s.State != StreamStateConnected {
+		return fmt.Errorf("stream is %s", s.State)
+	}
+
+	for i, shard := range s.Shards {
+		if err := shard.Check(now, maxIdle); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+
+	if maxIdle > 0 && len(s.Subscriptions) > 0 {
+		lastActiveTime := s.LastMessageTime
+		if lastActiveTime.Before(s.ConnectedTime) {
+			lastActiveTime = s.ConnectedTime
+		}
+
+		if idle := now.Sub(lastActiveTime); idle > maxIdle {
+			return fmt.Errorf("no message received in %s", idle)
+		}
+	}
+
+	return nil
+}
+
+// StreamMonitor tracks the connection status of a stream through the standard stream events
+type StreamMonitor struct {
+	mu     sync.Mutex
+	status StreamStatus
+}
+
+func NewStreamMonitor() *StreamMonitor {
+	return &StreamMonitor{
+		status: StreamStatus{State: StreamStateConnecting},
+	}
+}
+
+func (m *StreamMonitor) BindStream(stream Stream) {
+	stream.OnConnect(m.handleConnect)
+	stream.OnDisconnect(m.handleDisconnect)
+	stream.OnMessage(m.handleMessage)
+	stream.OnError(m.handleError)
+}
+
+// Status returns a copy of the current status, the subscriptions are not filled by the monitor.
+func (m *StreamMonitor) Status() StreamStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+func (m *StreamMonitor) handleConnect() {
+	m.mu.Lock()
+	if m.status.State == StreamStateReconnecting {
+		m.status.ReconnectCount++
+	}
+	m.status.State = StreamStateConnected
+	m.status.ConnectedTime = time.Now()
+	m.mu.Unlock()
+}
+
+func (m *StreamMonitor) handleDisconnect() {
+	m.mu.Lock()
+	m.status.State = StreamStateReconnecting
+	m.mu.Unlock()
+}
+
+func (m *StreamMonitor) handleMessage() {
+	m.mu.Lock()
+	m.status.LastMessageTime = time.Now()
+	m.mu.Unlock()
+}
+
+func (m *StreamMonitor) handleError(err error) {
+	m.mu.Lock()
+	m.status.LastError = err.Error()
+	m.status.LastErrorTime = time.Now()
+	m.mu.Unlock()
+}
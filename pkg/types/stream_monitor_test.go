@@ -0,0 +1,43 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMonitor(t *testing.T) {
+	stream := &testStream{}
+	monitor := NewStreamMonitor()
+	monitor.BindStream(stream)
+
+	status := monitor.Status()
+	assert.Equal(t, StreamStateConnecting, status.State)
+	assert.Error(t, status.Check(time.Now(), time.Minute))
+
+	stream.EmitConnect()
+	stream.EmitMessage()
+	status = monitor.Status()
+	assert.Equal(t, StreamStateConnected, status.State)
+	assert.Equal(t, 0, status.ReconnectCount)
+	assert.NoError(t, status.Check(time.Now(), time.Minute))
+	assert.NoError(t, status.Check(time.Now().Add(2*time.Minute), time.Minute), "the private only stream could be idle")
+
+	status.Subscriptions = []Subscription{{Channel: BookChannel, Symbol: "BTCUSDT"}}
+	assert.Error(t, status.Check(time.Now().Add(2*time.Minute), time.Minute), "the stream is idle")
+	assert.NoError(t, status.Check(time.Now().Add(2*time.Minute), 0), "the idle check is disabled")
+
+	stream.EmitError(errors.New("read error"))
+	stream.EmitDisconnect()
+	status = monitor.Status()
+	assert.Equal(t, StreamStateReconnecting, status.State)
+	assert.Equal(t, "read error", status.LastError)
+	assert.Error(t, status.Check(time.Now(), time.Minute))
+
+	stream.EmitConnect()
+	status = monitor.Status()
+	assert.Equal(t, StreamStateConnected, status.State)
+	assert.Equal(t, 1, status.ReconnectCount)
+}
@@ -2,11 +2,13 @@ package types
 
 import (
 	"time"
+
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 )
 
 type Ticker struct {
 	Time   time.Time
-	Volume float64 // `volume` from Max & binance
+	Volume float64 // `volume` from Max & binance, ftx has no base volume so it's approximated by `quoteVolume24h` / `last`
 	Last   float64 // `last` from Max, `lastPrice` from binance
 	Open   float64 // `open` from Max, `openPrice` from binance
 	High   float64 // `high` from Max, `highPrice` from binance
@@ -14,3 +16,33 @@ type Ticker struct {
 	Buy    float64 // `buy` from Max, `bidPrice` from binance
 	Sell   float64 // `sell` from Max, `askPrice` from binance
 }
+
+// BookTicker is the best bid and the best ask of the order book
+type BookTicker struct {
+	Symbol   string
+	Buy      float64 // best bid price
+	BuySize  float64 // best bid size
+	Sell     float64 // best ask price
+	SellSize float64 // best ask size
+}
+
+// OrderBook converts the book ticker to the order book that only contains the best bid and the best ask
+func (t BookTicker) OrderBook() OrderBook {
+	book := OrderBook{Symbol: t.Symbol}
+
+	if t.BuySize > 0 {
+		book.Bids = PriceVolumeSlice{{
+			Price:  fixedpoint.NewFromFloat(t.Buy),
+			Volume: fixedpoint.NewFromFloat(t.BuySize),
+		}}
+	}
+
+	if t.SellSize > 0 {
+		book.Asks = PriceVolumeSlice{{
+			Price:  fixedpoint.NewFromFloat(t.Sell),
+			Volume: fixedpoint.NewFromFloat(t.SellSize),
+		}}
+	}
+
+	return book
+}
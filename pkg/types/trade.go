@@ -8,6 +8,7 @@ import (
 	"github.com/slack-go/slack"
 
 	"github.com/ycdesu/spreaddog/pkg/datatype"
+	"github.com/ycdesu/spreaddog/pkg/fixedpoint"
 	"github.com/ycdesu/spreaddog/pkg/util"
 )
 
@@ -49,20 +50,20 @@ type Trade struct {
 	GID int64 `json:"gid" db:"gid"`
 
 	// ID is the source trade ID
-	ID            int64   `json:"id" db:"id"`
-	OrderID       uint64  `json:"orderID" db:"order_id"`
-	Exchange      string  `json:"exchange" db:"exchange"`
-	Price         float64 `json:"price" db:"price"`
-	Quantity      float64 `json:"quantity" db:"quantity"`
-	QuoteQuantity float64 `json:"quoteQuantity" db:"quote_quantity"`
-	Symbol        string  `json:"symbol" db:"symbol"`
-
-	Side        SideType      `json:"side" db:"side"`
-	IsBuyer     bool          `json:"isBuyer" db:"is_buyer"`
-	IsMaker     bool          `json:"isMaker" db:"is_maker"`
-	Time        datatype.Time `json:"tradedAt" db:"traded_at"`
-	Fee         float64       `json:"fee" db:"fee"`
-	FeeCurrency string        `json:"feeCurrency" db:"fee_currency"`
+	ID            int64            `json:"id" db:"id"`
+	OrderID       uint64           `json:"orderID" db:"order_id"`
+	Exchange      string           `json:"exchange" db:"exchange"`
+	Price         fixedpoint.Value `json:"price" db:"price"`
+	Quantity      fixedpoint.Value `json:"quantity" db:"quantity"`
+	QuoteQuantity fixedpoint.Value `json:"quoteQuantity" db:"quote_quantity"`
+	Symbol        string           `json:"symbol" db:"symbol"`
+
+	Side        SideType         `json:"side" db:"side"`
+	IsBuyer     bool             `json:"isBuyer" db:"is_buyer"`
+	IsMaker     bool             `json:"isMaker" db:"is_maker"`
+	Time        datatype.Time    `json:"tradedAt" db:"traded_at"`
+	Fee         fixedpoint.Value `json:"fee" db:"fee"`
+	FeeCurrency string           `json:"feeCurrency" db:"fee_currency"`
 
 	IsMargin   bool `json:"isMargin" db:"is_margin"`
 	IsIsolated bool `json:"isIsolated" db:"is_isolated"`
@@ -76,9 +77,9 @@ func (trade Trade) PlainText() string {
 		trade.Exchange,
 		trade.Symbol,
 		trade.Side,
-		util.FormatFloat(trade.Price, 2),
-		util.FormatFloat(trade.Quantity, 4),
-		util.FormatFloat(trade.QuoteQuantity, 2))
+		util.FormatFloat(trade.Price.Float64(), 2),
+		util.FormatFloat(trade.Quantity.Float64(), 4),
+		util.FormatFloat(trade.QuoteQuantity.Float64(), 2))
 }
 
 func (trade Trade) SlackAttachment() slack.Attachment {
@@ -95,10 +96,10 @@ func (trade Trade) SlackAttachment() slack.Attachment {
 		// Text:          "",
 		Fields: []slack.AttachmentField{
 			{Title: "Exchange", Value: trade.Exchange, Short: true},
-			{Title: "Price", Value: util.FormatFloat(trade.Price, 2), Short: true},
-			{Title: "Volume", Value: util.FormatFloat(trade.Quantity, 4), Short: true},
-			{Title: "Quantity", Value: util.FormatFloat(trade.QuoteQuantity, 2)},
-			{Title: "Fee", Value: util.FormatFloat(trade.Fee, 4), Short: true},
+			{Title: "Price", Value: util.FormatFloat(trade.Price.Float64(), 2), Short: true},
+			{Title: "Volume", Value: util.FormatFloat(trade.Quantity.Float64(), 4), Short: true},
+			{Title: "Quantity", Value: util.FormatFloat(trade.QuoteQuantity.Float64(), 2)},
+			{Title: "Fee", Value: util.FormatFloat(trade.Fee.Float64(), 4), Short: true},
 			{Title: "FeeCurrency", Value: trade.FeeCurrency, Short: true},
 		},
 		// Footer:     tradingCtx.TradeStartTime.Format(time.RFC822),
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // Response is wrapper for standard http.Response and provides
@@ -41,6 +43,22 @@ func (r *Response) IsError() bool {
 	return r.StatusCode >= 400
 }
 
+// IsRateLimited returns true if the server rejects the request for too many requests, binance returns 418 when the ip is
+// banned after the repeated 429 responses
+func (r *Response) IsRateLimited() bool {
+	return r.StatusCode == http.StatusTooManyRequests || r.StatusCode == http.StatusTeapot
+}
+
+// RetryAfter parses the Retry-After header in seconds, it returns zero if the header is not set
+func (r *Response) RetryAfter() time.Duration {
+	seconds, err := strconv.Atoi(r.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
 func (r *Response) IsJSON() bool {
 	switch r.Header.Get("content-type") {
 	case "text/json", "application/json", "application/json; charset=utf-8":
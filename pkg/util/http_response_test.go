@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -43,6 +44,18 @@ func TestResponse_IsError(t *testing.T) {
 	}
 }
 
+func TestResponse_RetryAfter(t *testing.T) {
+	resp := &Response{Response: &http.Response{StatusCode: 429, Header: http.Header{}}}
+	assert.True(t, resp.IsRateLimited())
+	assert.Equal(t, time.Duration(0), resp.RetryAfter())
+
+	resp.Header.Set("Retry-After", "30")
+	assert.Equal(t, 30*time.Second, resp.RetryAfter())
+
+	resp.StatusCode = 400
+	assert.False(t, resp.IsRateLimited())
+}
+
 func TestResponse_IsJSON(t *testing.T) {
 	cases := map[string]bool{
 		"text/json":                       true,
@@ -11,8 +11,15 @@ const (
 	InfiniteRetry = 0
 )
 
+// RetryPredicator decides whether the error should be retried, e.g. types.IsRetryableError and types.IsRateLimitError
 type RetryPredicator func(e error) bool
 
+// retryAfterError is the error which carries the delay asked by the server before the next attempt, e.g. the rate limit
+// error of the exchange
+type retryAfterError interface {
+	RetryAfterDuration() time.Duration
+}
+
 // Retry retrys the passed function for "attempts" times, if passed function return error. Setting attempts to zero means keep retrying.
 func Retry(ctx context.Context, attempts int, duration time.Duration, fnToRetry func() error, errHandler func(error), predicators ...RetryPredicator) (err error) {
 	infinite := false
@@ -46,15 +53,35 @@ func Retry(ctx context.Context, attempts int, duration time.Duration, fnToRetry
 
 			if !infinite {
 				attempts--
+				if attempts == 0 {
+					return err
+				}
 			}
 
-			time.Sleep(duration)
+			// the delay asked by the exchange could be long, e.g. the ip ban, so it's interrupted by the context
+			timer := time.NewTimer(retryDelay(err, duration))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Wrap(err, "return for context done")
+			case <-timer.C:
+			}
 		}
 	}
 
 	return err
 }
 
+// retryDelay returns the delay asked by the error if it's longer than the given duration
+func retryDelay(err error, duration time.Duration) time.Duration {
+	var e retryAfterError
+	if errors.As(err, &e) && e.RetryAfterDuration() > duration {
+		return e.RetryAfterDuration()
+	}
+
+	return duration
+}
+
 func needRetry(err error, predicators []RetryPredicator) bool {
 	if err == nil {
 		return false
@@ -104,3 +104,50 @@ func TestRetryCtxCancel(t *testing.T) {
 	fmt.Println("Error:", err.Error())
 	assert.Equal(t, int(0), result)
 }
+
+type testRetryAfterError struct {
+	retryAfter time.Duration
+}
+
+func (e testRetryAfterError) Error() string {
+	return "rate limited"
+}
+
+func (e testRetryAfterError) RetryAfterDuration() time.Duration {
+	return e.retryAfter
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, retryDelay(errors.New("some error"), time.Second))
+	assert.Equal(t, time.Second, retryDelay(testRetryAfterError{retryAfter: 100 * time.Millisecond}, time.Second))
+	assert.Equal(t, 3*time.Second, retryDelay(errors.Wrap(testRetryAfterError{retryAfter: 3 * time.Second}, "failed in retry"), time.Second))
+}
+
+func TestRetryDelayCtxCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	start := time.Now()
+	err := Retry(ctx, 3, time.Millisecond, func() error {
+		count++
+		return testRetryAfterError{retryAfter: time.Hour}
+	}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, time.Since(start) < time.Second, "the long delay should be interrupted by the context")
+}
+
+func TestRetryNoDelayAfterLastAttempt(t *testing.T) {
+	count := 0
+	start := time.Now()
+	err := Retry(context.Background(), 1, time.Hour, func() error {
+		count++
+		return errors.New("some error")
+	}, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, time.Since(start) < time.Second, "should not sleep after the last attempt")
+}